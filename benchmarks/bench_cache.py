@@ -0,0 +1,56 @@
+"""
+Benchmark of the parse cache.
+
+Generates the module of synthetic Go packages that bench_walker.py uses,
+extracts it into an empty cache (cold), again from the filled cache
+(warm), and without a cache, checks that the results are the same, and
+prints the timings:
+
+    python benchmarks/bench_cache.py --files 2000
+"""
+
+import argparse
+import os
+import tempfile
+import time
+from pathlib import Path
+from typing import Optional
+
+from bench_walker import generate
+from ctxd.symbols import extract_packages
+from ctxd.symbols.cache import SymbolCache
+
+
+def timed(root: Path, cache: Optional[SymbolCache], jobs: Optional[int]) -> tuple[float, dict]:
+    """Walk the tree, returning the time taken and the result."""
+    start = time.perf_counter()
+    packages = extract_packages(root, calls=True, metrics=True, cache=cache, jobs=jobs)
+    return time.perf_counter() - start, packages
+
+
+def main() -> None:
+    """Run the benchmark."""
+    parser = argparse.ArgumentParser(description=__doc__.strip().splitlines()[0])
+    parser.add_argument("--files", type=int, default=1000, help="Number of Go files to generate (default: 1000)")
+    parser.add_argument("--jobs", type=int, default=None, help="Worker processes for files not in the cache (default: the number of CPUs)")
+    args = parser.parse_args()
+
+    with tempfile.TemporaryDirectory() as directory:
+        root = Path(directory) / "module"
+        root.mkdir()
+        generate(root, args.files)
+        cache = SymbolCache(Path(directory) / "cache")
+
+        uncached_time, uncached = timed(root, None, args.jobs)
+        expected = {path: [s.to_dict() for s in symbols] for path, symbols in uncached.items()}
+        print(f"{args.files} files, {os.cpu_count()} CPUs")
+        print(f"  --no-cache: {uncached_time:.2f}s")
+        for run in ("cold", "warm"):
+            run_time, packages = timed(root, cache, args.jobs)
+            if {path: [s.to_dict() for s in symbols] for path, symbols in packages.items()} != expected:
+                raise SystemExit(f"{run} run gave different symbols than --no-cache")
+            print(f"  {run}: {run_time:.2f}s ({uncached_time / run_time:.1f}x)")
+
+
+if __name__ == "__main__":
+    main()
@@ -0,0 +1,124 @@
+"""
+Benchmark of serial and parallel directory walks.
+
+Generates a module of synthetic Go packages, extracts it with one job and
+then with each given number of jobs, checks that the results are the same,
+and prints the timings:
+
+    python benchmarks/bench_walker.py --files 2000 --jobs 2,4,8
+"""
+
+import argparse
+import os
+import tempfile
+import time
+from pathlib import Path
+
+from ctxd.symbols import extract_packages
+
+FILE_TEMPLATE = '''// Package pkg{package} is generated for benchmarking.
+package pkg{package}
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Store{file} holds values by key.
+type Store{file} struct {{
+	values map[string]int
+	limit  int
+}}
+
+// Reader{file} reads values.
+type Reader{file} interface {{
+	Get(key string) (int, error)
+}}
+
+// ErrMissing{file} is returned for unknown keys.
+var ErrMissing{file} = errors.New("missing")
+
+// Get returns the value of a key.
+func (s *Store{file}) Get(key string) (int, error) {{
+	value, ok := s.values[key]
+	if !ok {{
+		return 0, fmt.Errorf("get %q: %w", key, ErrMissing{file})
+	}}
+	return value, nil
+}}
+
+// Put stores a value, failing past the limit.
+func (s *Store{file}) Put(key string, value int) error {{
+	if len(s.values) >= s.limit && s.values[key] == 0 {{
+		return errors.New("full")
+	}}
+	for i := 0; i < value%3; i++ {{
+		switch {{
+		case i > 1 && value > 10:
+			value--
+		default:
+			value++
+		}}
+	}}
+	s.values[key] = value
+	return nil
+}}
+
+// Sum{file} adds the values of keys.
+func Sum{file}[T ~int | ~int64](r Reader{file}, keys ...string) (T, error) {{
+	var total T
+	for _, key := range keys {{
+		value, err := r.Get(key)
+		if err != nil {{
+			return 0, err
+		}}
+		total += T(value)
+	}}
+	return total, nil
+}}
+'''
+
+
+def generate(root: Path, files: int, per_package: int = 20) -> None:
+    """Write a module of `files` Go files, `per_package` to a package."""
+    (root / "go.mod").write_text("module example.com/bench\n\ngo 1.22\n")
+    for i in range(files):
+        package = i // per_package
+        directory = root / f"pkg{package}"
+        directory.mkdir(exist_ok=True)
+        (directory / f"file{i}.go").write_text(FILE_TEMPLATE.format(package=package, file=i))
+
+
+def timed(root: Path, jobs: int) -> tuple[float, dict]:
+    """Walk the tree without the cache, returning the time taken and the result."""
+    start = time.perf_counter()
+    packages = extract_packages(root, calls=True, metrics=True, jobs=jobs)
+    return time.perf_counter() - start, packages
+
+
+def main() -> None:
+    """Run the benchmark."""
+    parser = argparse.ArgumentParser(description=__doc__.strip().splitlines()[0])
+    parser.add_argument("--files", type=int, default=1000, help="Number of Go files to generate (default: 1000)")
+    parser.add_argument("--jobs", default=str(os.cpu_count() or 1), help="Comma-separated job counts to compare with 1 (default: the number of CPUs)")
+    args = parser.parse_args()
+
+    with tempfile.TemporaryDirectory() as directory:
+        root = Path(directory)
+        generate(root, args.files)
+
+        serial_time, serial = timed(root, 1)
+        expected = {path: [s.to_dict() for s in symbols] for path, symbols in serial.items()}
+        print(f"{args.files} files, {os.cpu_count()} CPUs")
+        print(f"  jobs=1: {serial_time:.2f}s")
+        for jobs in (int(j) for j in args.jobs.split(",")):
+            if jobs == 1:
+                continue
+            parallel_time, parallel = timed(root, jobs)
+            if {path: [s.to_dict() for s in symbols] for path, symbols in parallel.items()} != expected:
+                raise SystemExit(f"jobs={jobs} gave different symbols than jobs=1")
+            print(f"  jobs={jobs}: {parallel_time:.2f}s ({serial_time / parallel_time:.1f}x)")
+
+
+if __name__ == "__main__":
+    main()
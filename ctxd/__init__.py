@@ -7,6 +7,8 @@ This package provides:
 - Git-aware indexing with .gitignore support
 - Language-aware chunking (TreeSitter for Python, fallback for others)
 - MCP integration (Phase 2)
+- Go symbol extraction as a library (extract_file, extract_source, extract_dir,
+  extract_annotations)
 """
 
 from .models import CodeChunk, SearchResult, IndexStats, ChunkMetadata
@@ -16,6 +18,7 @@ from .store import VectorStore
 from .indexer import Indexer
 from .watcher import FileWatcher
 from .chunkers import ChunkStrategy, TreeSitterChunker, FallbackChunker
+from .symbols import Symbol, Index, Options, extract_file, extract_source, extract_dir, extract_fs, extract_annotations, load_options
 
 __version__ = "0.2.0"
 
@@ -35,4 +38,14 @@ __all__ = [
     "ChunkStrategy",
     "TreeSitterChunker",
     "FallbackChunker",
+    # Go symbols
+    "Symbol",
+    "Index",
+    "Options",
+    "extract_file",
+    "extract_dir",
+    "extract_fs",
+    "extract_source",
+    "extract_annotations",
+    "load_options",
 ]
@@ -5,11 +5,15 @@ Provides commands for initializing, indexing, searching, and managing
 the code index.
 """
 
+import json
 import logging
+import os
 import sys
 from pathlib import Path
+from typing import Optional
 import click
 from rich.console import Console
+from rich.markup import escape
 from rich.progress import Progress, SpinnerColumn, TextColumn, BarColumn, TimeElapsedColumn
 from rich.syntax import Syntax
 from rich.table import Table
@@ -19,6 +23,10 @@ from .store import VectorStore
 from .embeddings import EmbeddingModel
 from .indexer import Indexer
 from .progress import ProgressReporter
+from .symbols.formatters import FORMATTERS
+from .symbols.metrics import DEFAULT_COMPLEXITY_THRESHOLD
+from .symbols.paths import PATH_MODES
+from .symbols.tokenizers import TOKENIZERS
 from . import __version__
 
 # Setup logging
@@ -397,6 +405,1234 @@ def clean():
         sys.exit(1)
 
 
+@main.command("clear-cache")
+def clear_cache():
+    """Remove cached Go symbol parse results and saved server indexes."""
+    from .symbols.cache import SymbolCache
+    from .symbols.snapshot import clear_snapshots
+
+    cache = SymbolCache()
+    try:
+        count = cache.clear()
+        snapshots = clear_snapshots()
+    except OSError as e:
+        console.print(f"[red]Error clearing cache: {e}[/red]")
+        sys.exit(1)
+    console.print(f"[green]✓ Removed {count} cached entries from {cache.directory}[/green]")
+    if snapshots:
+        console.print(f"[green]✓ Removed {snapshots} saved server {'index' if snapshots == 1 else 'indexes'}[/green]")
+
+
+@main.command()
+@click.argument("path")
+@click.option("--format", "output_format", type=click.Choice(list(FORMATTERS)), default="text", help="Output format (default: text)")
+@click.option("--exported-only/--no-exported-only", default=False, help="Only include exported (public API) symbols")
+@click.option("-r", "--recursive/--no-recursive", default=False, help="Walk subdirectories when PATH is a directory")
+@click.option("--depth", type=click.IntRange(min=0), default=None, help="With -r, only walk this many directory levels below PATH (0: PATH's own files)")
+@click.option("--include-tests/--no-include-tests", default=False, help="Include _test.go files when PATH is a directory")
+@click.option("--include", "include_patterns", multiple=True, help="Only extract files matching this glob, relative to PATH (repeatable, supports **)")
+@click.option("--exclude", "exclude_patterns", multiple=True, help="Skip files matching this glob, relative to PATH (repeatable, wins over --include)")
+@click.option("--watch", is_flag=True, help="Keep running and print symbol changes as files are edited")
+@click.option("--calls", is_flag=True, help="Print the call graph instead of the symbols")
+@click.option("--group-methods/--no-group-methods", default=False, help="Nest methods under their receiver type")
+@click.option("--summary", is_flag=True, help="Precede each package with its name, doc, file count, and symbol counts")
+@click.option("--count", is_flag=True, help="Only print the number of symbols, in total and by kind and package")
+@click.option("--unused", is_flag=True, help="Only print unexported declarations their package never references (with --strict, exit 1 if any)")
+@click.option("--lint-receivers", is_flag=True, help="Warn on stderr about types mixing value and pointer receivers (with --strict, exit 1 if any)")
+@click.option("--context-for", "context_for", default=None, metavar="SYMBOL", help="Only print this symbol and the declarations it references, dependencies first")
+@click.option("--context-depth", type=click.IntRange(min=0), default=None, help="With --context-for, follow references this many levels deep (default: 1)")
+@click.option("--kind", "kind_list", default=None, help="Comma-separated kinds to include (func,method,struct,interface,type,alias,const,var,field,test,benchmark,example)")
+@click.option("--name", "name_pattern", default=None, metavar="REGEXP", help="Only include symbols whose name matches this regular expression (not anchored)")
+@click.option("--name-exclude", "name_exclude", default=None, metavar="REGEXP", help="Skip symbols whose name matches this regular expression (wins over --name)")
+@click.option("--max-tokens", type=click.IntRange(min=1), default=None, help="Drop lower-priority symbols to fit a token budget")
+@click.option("--budget-priority", default=None, help="Comma-separated budget tiers, most important first (default: exported_types,exported_functions,docs,unexported)")
+@click.option("--tokenizer", "tokenizer_name", type=click.Choice(list(TOKENIZERS)), default="heuristic", help="Token counter for --max-tokens (default: heuristic)")
+@click.option("--no-cache", is_flag=True, help="Re-parse every file instead of reusing cached results")
+@click.option("--strict/--no-strict", default=False, help="Fail on the first file with syntax errors instead of extracting what parses")
+@click.option("--filename", default=None, help="File name to report for source read from stdin (default: <stdin>)")
+@click.option("--config", "config_path", default=None, help="Read defaults from this file instead of the nearest .ctxd.yaml")
+@click.option("--annotations", is_flag=True, help="Print TODO/FIXME-style marker comments instead of the symbols")
+@click.option("--markers", "marker_list", default=None, help="Comma-separated marker words for --annotations (default: TODO,FIXME,HACK,XXX,BUG)")
+@click.option("--color", "color_mode", type=click.Choice(["auto", "always", "never"]), default="auto", help="Highlight text output: auto colors terminals unless NO_COLOR is set (default: auto)")
+@click.option("--metrics/--no-metrics", default=False, help="Report the cyclomatic complexity and lines of code of each function and method")
+@click.option("--complexity-threshold", type=click.IntRange(min=1), default=None, help=f"Flag functions above this complexity in text output with --metrics (default: {DEFAULT_COMPLEXITY_THRESHOLD})")
+@click.option("--include-source/--no-include-source", default=False, help="Include the source text of each declaration, bodies included")
+@click.option("--comments/--no-comments", default=True, help="Record doc comments and directives (default: on); --no-comments skips them")
+@click.option("--skip-bodies/--no-skip-bodies", default=False, help="Do not look inside function bodies, for fast signatures-only output (turns off --metrics)")
+@click.option("--out-dir", default=None, metavar="DIR", help="Write one file per package to DIR instead of printing (directories only)")
+@click.option("--since", "since_ref", default=None, metavar="REF", help="Only include symbols whose lines changed since this git revision")
+@click.option("--goos", default=None, help="Target operating system of build constraints (default: $GOOS or the host's)")
+@click.option("--goarch", default=None, help="Target architecture of build constraints (default: $GOARCH or the host's)")
+@click.option("-j", "--jobs", type=click.IntRange(min=1), default=None, help="Parse this many files at once (default: the number of CPUs)")
+@click.option("--sort", "sort_output", is_flag=True, help="With --format jsonl, write every symbol in canonical order once the walk ends instead of as files finish")
+@click.option("--path-mode", type=click.Choice(list(PATH_MODES)), default="rel", help="Show file paths relative to PATH, absolute, or as bare file names (default: rel)")
+def symbols(
+    path: str,
+    output_format: str,
+    exported_only: bool,
+    recursive: bool,
+    depth: Optional[int],
+    include_tests: bool,
+    include_patterns: tuple[str, ...],
+    exclude_patterns: tuple[str, ...],
+    watch: bool,
+    calls: bool,
+    group_methods: bool,
+    summary: bool,
+    count: bool,
+    unused: bool,
+    lint_receivers: bool,
+    context_for: Optional[str],
+    context_depth: Optional[int],
+    kind_list: Optional[str],
+    name_pattern: Optional[str],
+    name_exclude: Optional[str],
+    max_tokens: Optional[int],
+    budget_priority: Optional[str],
+    tokenizer_name: str,
+    no_cache: bool,
+    strict: bool,
+    filename: Optional[str],
+    config_path: Optional[str],
+    annotations: bool,
+    marker_list: Optional[str],
+    color_mode: str,
+    metrics: bool,
+    complexity_threshold: Optional[int],
+    include_source: bool,
+    comments: bool,
+    skip_bodies: bool,
+    out_dir: Optional[str],
+    since_ref: Optional[str],
+    goos: Optional[str],
+    goarch: Optional[str],
+    jobs: Optional[int],
+    sort_output: bool,
+    path_mode: str
+):
+    """Extract Go symbols (functions, methods, types) from a file or package tree.
+
+    Pass - as PATH to read a single Go file from stdin. Defaults for the
+    flags are read from the nearest .ctxd.yaml; flags given override it.
+
+    Examples:
+      ctxd symbols calculator.go
+      ctxd symbols calculator.go --format json | jq '.[].name'
+      ctxd symbols calculator.go --exported-only
+      cat calculator.go | ctxd symbols - --filename calculator.go
+      ctxd symbols calculator.go --kind interface
+      ctxd symbols calculator.go --name '^New'
+      ctxd symbols ./pkg -r --summary --format markdown
+      ctxd symbols . -r --exported-only --count
+      ctxd symbols . -r --unused --strict
+      ctxd symbols . -r --lint-receivers
+      ctxd symbols . -r --context-for example.com/calc.NewCalculator --context-depth 2
+      ctxd symbols . -r --format dot | dot -Tsvg > types.svg
+      ctxd symbols ./pkg -r
+      ctxd symbols . -r --depth 1
+      ctxd symbols . -r --exclude 'internal/**'
+      ctxd symbols . -r --watch
+      ctxd symbols . -r --calls
+      ctxd symbols . -r --annotations --markers TODO,FIXME
+      ctxd symbols . -r --max-tokens 4000
+      ctxd symbols . -r --metrics --complexity-threshold 15
+      ctxd symbols . -r --skip-bodies --no-comments
+      ctxd symbols calculator.go --include-source --format json
+      ctxd symbols . -r --since main
+      ctxd symbols . -r --format markdown --out-dir docs/api
+      ctxd symbols . -r --goos windows --goarch arm64
+      ctxd symbols . -r --jobs 4
+      ctxd symbols . -r --format jsonl | jq -c 'select(.kind == "interface")'
+      ctxd symbols . -r --no-cache
+    """
+    target = Path(path)
+    from_stdin = path == "-"
+
+    if filename is not None and not from_stdin:
+        console.print("[red]Error: --filename requires reading from stdin (PATH -)[/red]")
+        sys.exit(1)
+
+    if not from_stdin and not target.exists():
+        console.print(f"[red]Error: Path does not exist: {target}[/red]")
+        sys.exit(1)
+
+    if target.is_file() and target.suffix != ".go":
+        console.print(f"[red]Error: Not a Go file: {target}[/red]")
+        sys.exit(1)
+
+    from click.core import ParameterSource
+    from .symbols.config import ConfigError, load_options
+
+    try:
+        defaults = load_options(Path(config_path) if config_path else None)
+    except ConfigError as e:
+        console.print(f"[red]Error: {escape(str(e))}[/red]")
+        sys.exit(1)
+
+    # Settings from the file fill in flags that were not given. Those that
+    # cannot apply to this run are left out rather than reported as conflicts.
+    ctx = click.get_current_context()
+    given = {name for name in ctx.params if ctx.get_parameter_source(name) == ParameterSource.COMMANDLINE}
+    if "output_format" not in given and not (defaults.format == "dot" and (watch or annotations or count or unused)):
+        output_format = defaults.format
+    if "exported_only" not in given and not unused:
+        exported_only = defaults.exported_only
+    if "recursive" not in given:
+        recursive = defaults.recursive
+    if "include_tests" not in given:
+        include_tests = defaults.include_tests
+    if "strict" not in given and not watch:
+        strict = defaults.strict
+    if target.is_dir():
+        if "include_patterns" not in given:
+            include_patterns = tuple(defaults.include or ())
+        if "exclude_patterns" not in given:
+            exclude_patterns = tuple(defaults.exclude or ())
+        if "goos" not in given:
+            goos = defaults.goos
+        if "goarch" not in given:
+            goarch = defaults.goarch
+        if "depth" not in given and recursive:
+            depth = defaults.depth
+        if "jobs" not in given and not watch:
+            jobs = defaults.jobs
+    if "path_mode" not in given:
+        path_mode = defaults.path_mode
+    if "marker_list" not in given and defaults.markers is not None:
+        marker_list = ",".join(defaults.markers)
+    if not (watch or calls or annotations):
+        if "group_methods" not in given:
+            group_methods = defaults.group_methods
+        if "max_tokens" not in given and out_dir is None and not count and context_for is None and not unused:
+            max_tokens = defaults.max_tokens
+    if "kind_list" not in given and defaults.kinds is not None and not watch:
+        kind_list = ",".join(defaults.kinds)
+    if not (watch or annotations):
+        if "name_pattern" not in given:
+            name_pattern = defaults.name
+        if "name_exclude" not in given:
+            name_exclude = defaults.name_exclude
+    if not (calls or annotations):
+        if "metrics" not in given:
+            metrics = defaults.metrics
+        if "complexity_threshold" not in given:
+            complexity_threshold = defaults.complexity_threshold
+        if "include_source" not in given and not watch:
+            include_source = defaults.include_source
+    if "comments" not in given and not annotations:
+        comments = defaults.comments
+    if "skip_bodies" not in given and not (calls or annotations or context_for is not None):
+        skip_bodies = defaults.skip_bodies
+
+    if watch and not target.is_dir():
+        console.print(f"[red]Error: --watch requires a directory: {target}[/red]")
+        sys.exit(1)
+
+    if (include_patterns or exclude_patterns) and not target.is_dir():
+        console.print(f"[red]Error: --include and --exclude require a directory: {target}[/red]")
+        sys.exit(1)
+
+    if (goos or goarch) and not target.is_dir():
+        console.print(f"[red]Error: --goos and --goarch require a directory: {target}[/red]")
+        sys.exit(1)
+
+    if depth is not None and not target.is_dir():
+        console.print(f"[red]Error: --depth requires a directory: {target}[/red]")
+        sys.exit(1)
+
+    if depth is not None and not recursive:
+        console.print("[red]Error: --depth requires --recursive[/red]")
+        sys.exit(1)
+
+    if jobs is not None and not target.is_dir():
+        console.print(f"[red]Error: --jobs requires a directory: {target}[/red]")
+        sys.exit(1)
+
+    if jobs is not None and watch:
+        console.print("[red]Error: --jobs cannot be combined with --watch[/red]")
+        sys.exit(1)
+
+    if watch and calls:
+        console.print("[red]Error: --calls cannot be combined with --watch[/red]")
+        sys.exit(1)
+
+    if max_tokens is not None and (watch or calls):
+        console.print("[red]Error: --max-tokens cannot be combined with --watch or --calls[/red]")
+        sys.exit(1)
+
+    if group_methods and (watch or calls):
+        console.print("[red]Error: --group-methods cannot be combined with --watch or --calls[/red]")
+        sys.exit(1)
+
+    if strict and watch:
+        console.print("[red]Error: --strict cannot be combined with --watch[/red]")
+        sys.exit(1)
+
+    if summary and (watch or calls):
+        console.print("[red]Error: --summary cannot be combined with --watch or --calls[/red]")
+        sys.exit(1)
+
+    if count and (watch or calls or annotations or summary or max_tokens is not None or out_dir is not None):
+        console.print("[red]Error: --count cannot be combined with --watch, --calls, --annotations, --summary, --max-tokens, or --out-dir[/red]")
+        sys.exit(1)
+
+    if unused and (watch or calls or annotations or summary or count or max_tokens is not None or out_dir is not None or since_ref is not None):
+        console.print("[red]Error: --unused cannot be combined with --watch, --calls, --annotations, --summary, --count, --max-tokens, --out-dir, or --since[/red]")
+        sys.exit(1)
+
+    if unused and exported_only:
+        console.print("[red]Error: --unused only reports unexported symbols, so it cannot be combined with --exported-only[/red]")
+        sys.exit(1)
+
+    if context_for is not None and (from_stdin or watch or calls or annotations or summary or count or unused or max_tokens is not None or out_dir is not None or since_ref is not None):
+        console.print("[red]Error: --context-for cannot be combined with stdin (PATH -), --watch, --calls, --annotations, --summary, --count, --unused, --max-tokens, --out-dir, or --since[/red]")
+        sys.exit(1)
+
+    if context_for is not None and ({"kind_list", "name_pattern", "name_exclude", "group_methods"} & given):
+        console.print("[red]Error: --context-for follows references to every kind of declaration, so it cannot be combined with --kind, --name, --name-exclude, or --group-methods[/red]")
+        sys.exit(1)
+
+    if lint_receivers and (watch or annotations or unused or context_for is not None):
+        console.print("[red]Error: --lint-receivers cannot be combined with --watch, --annotations, --unused, or --context-for[/red]")
+        sys.exit(1)
+
+    if context_depth is not None and context_for is None:
+        console.print("[red]Error: --context-depth requires --context-for[/red]")
+        sys.exit(1)
+
+    if (count or unused) and output_format == "dot":
+        console.print(f"[red]Error: --{'count' if count else 'unused'} cannot be combined with --format dot[/red]")
+        sys.exit(1)
+
+    if kind_list is not None and watch:
+        console.print("[red]Error: --kind cannot be combined with --watch[/red]")
+        sys.exit(1)
+
+    if output_format == "dot" and (watch or annotations):
+        console.print("[red]Error: --format dot cannot be combined with --watch or --annotations[/red]")
+        sys.exit(1)
+
+    if (name_pattern is not None or name_exclude is not None) and (watch or annotations):
+        console.print("[red]Error: --name and --name-exclude cannot be combined with --watch or --annotations[/red]")
+        sys.exit(1)
+
+    if annotations and (watch or calls or summary or max_tokens is not None or group_methods):
+        console.print("[red]Error: --annotations cannot be combined with --watch, --calls, --summary, --max-tokens, or --group-methods[/red]")
+        sys.exit(1)
+
+    if "marker_list" in given and not annotations:
+        console.print("[red]Error: --markers requires --annotations[/red]")
+        sys.exit(1)
+
+    if metrics and (calls or annotations):
+        console.print("[red]Error: --metrics cannot be combined with --calls or --annotations[/red]")
+        sys.exit(1)
+
+    if include_source and (watch or calls or annotations):
+        console.print("[red]Error: --include-source cannot be combined with --watch, --calls, or --annotations[/red]")
+        sys.exit(1)
+
+    if skip_bodies and (calls or annotations or context_for is not None):
+        console.print("[red]Error: --skip-bodies cannot be combined with --calls, --annotations, or --context-for[/red]")
+        sys.exit(1)
+
+    if not comments and annotations:
+        console.print("[red]Error: --no-comments cannot be combined with --annotations[/red]")
+        sys.exit(1)
+
+    if out_dir is not None and not target.is_dir():
+        console.print(f"[red]Error: --out-dir requires a directory: {target}[/red]")
+        sys.exit(1)
+
+    if out_dir is not None and (watch or calls or annotations or max_tokens is not None):
+        console.print("[red]Error: --out-dir cannot be combined with --watch, --calls, --annotations, or --max-tokens[/red]")
+        sys.exit(1)
+
+    if since_ref is not None and (from_stdin or watch or annotations):
+        console.print("[red]Error: --since cannot be combined with stdin (PATH -), --watch, or --annotations[/red]")
+        sys.exit(1)
+
+    if sort_output and output_format != "jsonl":
+        console.print("[red]Error: --sort requires --format jsonl[/red]")
+        sys.exit(1)
+
+    if "complexity_threshold" in given and not metrics:
+        console.print("[red]Error: --complexity-threshold requires --metrics[/red]")
+        sys.exit(1)
+
+    # Metrics are measured on bodies; say so rather than report zeros
+    if metrics and skip_bodies:
+        click.echo("Note: --skip-bodies leaves out function bodies, so metrics are not reported", err=True)
+        metrics = False
+
+    markers = None
+    if marker_list is not None:
+        from .symbols.annotations import validate_markers
+
+        markers = [m.strip() for m in marker_list.split(",") if m.strip()]
+        try:
+            validate_markers(markers)
+        except ValueError as e:
+            console.print(f"[red]Error: --markers: {escape(str(e))}[/red]")
+            sys.exit(1)
+
+    from .symbols import Options, TextFormatter, build_call_graph, extract_dir, extract_file, extract_source, get_formatter
+    from .symbols.constraints import BuildContext
+    from .symbols.filters import NameFilter, validate_kinds
+    from .symbols.patterns import PathFilter
+
+    include = list(include_patterns)
+    exclude = list(exclude_patterns)
+    try:
+        PathFilter(include, exclude)
+        NameFilter(name_pattern, name_exclude)
+        # Build constraints only select the files of a directory
+        context = BuildContext.default(goos, goarch) if target.is_dir() else None
+    except ValueError as e:
+        console.print(f"[red]Error: {escape(str(e))}[/red]")
+        sys.exit(1)
+
+    kinds = None
+    if kind_list is not None:
+        kinds = [k.strip() for k in kind_list.split(",") if k.strip()]
+        if not kinds:
+            console.print("[red]Error: --kind requires at least one kind[/red]")
+            sys.exit(1)
+        try:
+            validate_kinds(kinds)
+        except ValueError as e:
+            console.print(f"[red]Error: {escape(str(e))}[/red]")
+            sys.exit(1)
+
+    if complexity_threshold is None:
+        complexity_threshold = DEFAULT_COMPLEXITY_THRESHOLD
+
+    if watch:
+        _watch_symbols(
+            target,
+            output_format,
+            exported_only,
+            recursive,
+            include_tests,
+            include,
+            exclude,
+            _use_color(color_mode),
+            metrics,
+            complexity_threshold,
+            context,
+            depth,
+            comments,
+            skip_bodies,
+            path_mode,
+        )
+        return
+
+    options = Options(
+        exported_only=exported_only,
+        recursive=recursive,
+        depth=depth,
+        include_tests=include_tests,
+        calls=calls,
+        group_methods=group_methods,
+        kinds=kinds,
+        name=name_pattern,
+        name_exclude=name_exclude,
+        include=include,
+        exclude=exclude,
+        cache=not no_cache,
+        strict=strict,
+        markers=markers,
+        metrics=metrics,
+        complexity_threshold=complexity_threshold,
+        include_source=include_source,
+        goos=goos,
+        goarch=goarch,
+        jobs=jobs,
+        comments=comments,
+        skip_bodies=skip_bodies,
+    )
+
+    if annotations:
+        _print_annotations(target, from_stdin, filename, options, output_format, path_mode)
+        return
+
+    if unused:
+        if output_format == "text":
+            formatter = TextFormatter(color=_use_color(color_mode), complexity_threshold=complexity_threshold)
+        else:
+            formatter = get_formatter(output_format)
+        _print_unused(target, from_stdin, filename, options, formatter, path_mode)
+        return
+
+    if context_for is not None:
+        if output_format == "text":
+            formatter = TextFormatter(color=_use_color(color_mode), complexity_threshold=complexity_threshold)
+        else:
+            formatter = get_formatter(output_format)
+        _print_context(target, context_for, 1 if context_depth is None else context_depth, options, formatter, path_mode)
+        return
+
+    # JSON Lines of a directory are written as files finish, unless the
+    # output needs every symbol first
+    if output_format == "jsonl" and target.is_dir() and not (
+        sort_output or calls or summary or count or group_methods or lint_receivers
+        or max_tokens is not None or out_dir is not None or since_ref is not None
+    ):
+        _stream_symbols(target, options, path_mode)
+        return
+
+    errors: list = []
+    try:
+        if from_stdin:
+            from .symbols import PackageSummary
+            from .symbols.walker import collect_imports, count_kinds, file_directives, parse_package_clause
+
+            content = click.get_text_stream("stdin").read()
+            extracted = extract_source(content, filename or "<stdin>", options, errors=errors)
+            package_name, package_doc = parse_package_clause(content)
+            summaries = {"": PackageSummary(
+                name=package_name,
+                doc=package_doc if comments else "",
+                files=1,
+                kinds=count_kinds(extracted),
+                imports=collect_imports(extracted),
+                directives=file_directives(content) if comments else [],
+            )}
+            if metrics:
+                from .symbols import GoSymbolExtractor
+
+                summary = summaries[""]
+                summary.lines, summary.loc = GoSymbolExtractor().count_lines(content)
+                summary.file_loc[filename or "<stdin>"] = {"lines": summary.lines, "loc": summary.loc}
+        elif target.is_dir():
+            index = extract_dir(target, options)
+            extracted = index.symbols()
+            summaries = index.summaries
+            errors = index.errors
+        else:
+            from .symbols.walker import summarize_package
+
+            extracted = extract_file(target, options, errors=errors)
+            summaries = {"": summarize_package("", [target], extracted, metrics, comments)}
+    except Exception as e:
+        console.print(f"[red]Error extracting symbols: {escape(str(e))}[/red]")
+        if logger.isEnabledFor(logging.DEBUG):
+            raise
+        sys.exit(1)
+
+    # Checked before --since and --max-tokens narrow the symbols, so every
+    # method of a type counts
+    mixes: list = []
+    if lint_receivers:
+        from .symbols import check_receivers
+
+        mixes = check_receivers(extracted)
+
+    if since_ref is not None:
+        from .symbols.changes import ChangesError, changed_since
+
+        try:
+            changes = changed_since(target, since_ref)
+        except ChangesError as e:
+            click.echo(f"Warning: --since: {e}; including all symbols", err=True)
+        else:
+            extracted, summaries = _filter_changed(extracted, summaries, changes)
+
+    # Only rewritten for display, once --since has read the files back
+    extracted = _show_paths(extracted, target, path_mode)
+    errors = _show_paths(errors, target, path_mode)
+    mixes = _show_paths(mixes, target, path_mode)
+
+    formatter = get_formatter(output_format)
+    if output_format == "text":
+        formatter = TextFormatter(complexity_threshold=complexity_threshold)
+
+    if count:
+        click.echo(formatter.format_counts(summaries))
+        _report_parse_errors(errors)
+        _report_receiver_mixes(mixes, strict)
+        return
+
+    omitted = 0
+    if max_tokens is not None:
+        from .symbols.budget import fit_to_budget
+        from .symbols.tokenizers import get_tokenizer
+
+        priority = [t.strip() for t in budget_priority.split(",") if t.strip()] if budget_priority else None
+        try:
+            extracted, omitted = fit_to_budget(
+                extracted,
+                max_tokens,
+                formatter,
+                tokenizer=get_tokenizer(tokenizer_name),
+                priority=priority,
+            )
+        except (ValueError, ImportError) as e:
+            console.print(f"[red]Error: {escape(str(e))}[/red]")
+            sys.exit(1)
+
+    if out_dir is not None:
+        from .symbols.output import write_packages
+
+        try:
+            written = write_packages(Path(out_dir), summaries, extracted, formatter, with_summary=summary)
+        except (ValueError, OSError) as e:
+            console.print(f"[red]Error writing {escape(out_dir)}: {escape(str(e))}[/red]")
+            sys.exit(1)
+        click.echo(f"Wrote {len(written)} {'file' if len(written) == 1 else 'files'} to {out_dir}", err=True)
+        _report_parse_errors(errors)
+        _report_receiver_mixes(mixes, strict)
+        return
+
+    # Colored after budgeting, so escape codes do not count as tokens
+    color = _use_color(color_mode)
+    if color and output_format == "text":
+        formatter = TextFormatter(color=True, complexity_threshold=complexity_threshold)
+
+    # Plain echo rather than rich markup: signatures such as `[T any]`
+    # would otherwise be swallowed as style tags. click strips escape codes
+    # when stdout is not a terminal unless color is forced.
+    if calls:
+        click.echo(formatter.format_calls(build_call_graph(extracted)))
+    elif summary:
+        click.echo(formatter.format_packages(summaries, extracted), color=color or None)
+    else:
+        click.echo(formatter.format(extracted), color=color or None)
+
+    if omitted:
+        note = f"{omitted} symbols omitted to fit budget"
+        formatted_note = formatter.format_note(note)
+        if formatted_note:
+            click.echo(formatted_note)
+        else:
+            click.echo(note, err=True)
+
+    _report_parse_errors(errors)
+    _report_receiver_mixes(mixes, strict)
+
+
+def _stream_symbols(target: Path, options, path_mode: str = "rel") -> None:
+    """Write the symbols of a directory as JSON Lines, each file's as soon as it is parsed."""
+    from .symbols import JsonlFormatter, stream_dir
+
+    formatter = JsonlFormatter()
+    errors: list = []
+    try:
+        for symbol in stream_dir(target, options, errors=errors):
+            # click.echo flushes after each line, so readers see it right away
+            click.echo(formatter.format_symbol(_show_paths([symbol], target, path_mode)[0]))
+    except Exception as e:
+        console.print(f"[red]Error extracting symbols: {escape(str(e))}[/red]")
+        if logger.isEnabledFor(logging.DEBUG):
+            raise
+        sys.exit(1)
+    _report_parse_errors(_show_paths(errors, target, path_mode))
+
+
+def _filter_changed(symbols: list, summaries: dict, changes) -> tuple[list, dict]:
+    """Narrow symbols to changed lines, recounting the summaries of packages that still have symbols."""
+    from dataclasses import replace
+    from .symbols.filters import filter_changed
+    from .symbols.walker import collect_imports, count_kinds
+
+    symbols = filter_changed(symbols, changes)
+    by_package: dict = {}
+    for symbol in symbols:
+        by_package.setdefault(symbol.package, []).append(symbol)
+    summaries = {
+        path: replace(summary, kinds=count_kinds(by_package.get(path, [])), imports=collect_imports(by_package.get(path, [])))
+        for path, summary in summaries.items()
+        if path in by_package or not path
+    }
+    return symbols, summaries
+
+
+def _print_annotations(
+    target: Path, from_stdin: bool, filename: Optional[str], options, output_format: str, path_mode: str = "rel"
+) -> None:
+    """Print the marker comments of stdin, a file, or a package tree."""
+    from .symbols import AnnotationScanner, extract_annotations, get_formatter
+
+    try:
+        if from_stdin:
+            content = click.get_text_stream("stdin").read()
+            found = AnnotationScanner(options.markers).scan(content, filename or "<stdin>")
+        else:
+            found = extract_annotations(target, options)
+    except Exception as e:
+        console.print(f"[red]Error scanning annotations: {escape(str(e))}[/red]")
+        if logger.isEnabledFor(logging.DEBUG):
+            raise
+        sys.exit(1)
+
+    output = get_formatter(output_format).format_annotations(_show_paths(found, target, path_mode))
+    if output:
+        click.echo(output)
+
+
+def _print_unused(
+    target: Path, from_stdin: bool, filename: Optional[str], options, formatter, path_mode: str = "rel"
+) -> None:
+    """Print the unused unexported symbols of stdin, a file, or a package tree; under --strict, exit 1 if there are any."""
+    from .symbols import GoSymbolExtractor, extract_source, extract_unused
+    from .symbols.unused import find_unused
+
+    errors: list = []
+    try:
+        if from_stdin:
+            content = click.get_text_stream("stdin").read()
+            extracted = extract_source(content, filename or "<stdin>", options, errors=errors)
+            found = find_unused(extracted, GoSymbolExtractor().references(content))
+        else:
+            found = extract_unused(target, options, errors=errors)
+    except Exception as e:
+        console.print(f"[red]Error finding unused symbols: {escape(str(e))}[/red]")
+        if logger.isEnabledFor(logging.DEBUG):
+            raise
+        sys.exit(1)
+
+    output = formatter.format(_show_paths(found, target, path_mode))
+    if output:
+        click.echo(output, color=getattr(formatter, "color", False) or None)
+    _report_parse_errors(_show_paths(errors, target, path_mode))
+    if found:
+        click.echo(f"{len(found)} unused {'symbol' if len(found) == 1 else 'symbols'}", err=True)
+        if options.strict:
+            sys.exit(1)
+
+
+def _print_context(target: Path, name: str, depth: int, options, formatter, path_mode: str = "rel") -> None:
+    """Print a symbol of a file or package tree after the declarations it references."""
+    from .symbols import extract_context
+
+    errors: list = []
+    try:
+        context = extract_context(target, name, options, depth=depth, errors=errors)
+    except ValueError as e:
+        console.print(f"[red]Error: --context-for: {escape(str(e))}[/red]")
+        sys.exit(1)
+    except Exception as e:
+        console.print(f"[red]Error extracting symbols: {escape(str(e))}[/red]")
+        if logger.isEnabledFor(logging.DEBUG):
+            raise
+        sys.exit(1)
+
+    click.echo(formatter.format(_show_paths(context, target, path_mode)), color=getattr(formatter, "color", False) or None)
+    _report_parse_errors(_show_paths(errors, target, path_mode))
+
+
+def _show_paths(items: list, target: Path, path_mode: str) -> list:
+    """Rewrite the file paths of symbols or other records for output, relative to PATH (or a file's directory)."""
+    from .symbols import rewrite_paths
+
+    return rewrite_paths(items, target if target.is_dir() else target.parent, path_mode)
+
+
+def _use_color(mode: str) -> bool:
+    """Decide whether to color text output for a --color mode."""
+    if mode != "auto":
+        return mode == "always"
+    # https://no-color.org: any non-empty value disables color
+    if os.environ.get("NO_COLOR"):
+        return False
+    return sys.stdout.isatty()
+
+
+def _report_parse_errors(errors: list) -> None:
+    """List parse errors on stderr, so that structured output stays parseable."""
+    if not errors:
+        return
+    files_with_errors = len({e.file for e in errors})
+    click.echo(f"{files_with_errors} {'file' if files_with_errors == 1 else 'files'} with parse errors, symbols extracted where possible:", err=True)
+    for error in errors:
+        click.echo(f"  {error}", err=True)
+
+
+def _report_receiver_mixes(mixes: list, strict: bool) -> None:
+    """Warn on stderr about types mixing receiver modes; under --strict, exit 1 if there are any."""
+    for mix in mixes:
+        click.echo(f"Warning: {mix}", err=True)
+    if mixes and strict:
+        sys.exit(1)
+
+
+def _watch_symbols(
+    target: Path,
+    output_format: str,
+    exported_only: bool,
+    recursive: bool,
+    include_tests: bool,
+    include: list[str],
+    exclude: list[str],
+    color: bool = False,
+    metrics: bool = False,
+    complexity_threshold: int = DEFAULT_COMPLEXITY_THRESHOLD,
+    context=None,
+    depth: Optional[int] = None,
+    comments: bool = True,
+    skip_bodies: bool = False,
+    path_mode: str = "rel"
+):
+    """Print a tree's symbols, then print symbol diffs as its files change."""
+    from .symbols import GoSymbolExtractor, SymbolDiff, SymbolIndex, TextFormatter, get_formatter, sort_symbols
+    from .symbols.watcher import SymbolWatcher
+
+    if output_format == "text":
+        formatter = TextFormatter(color=color, complexity_threshold=complexity_threshold)
+    else:
+        formatter = get_formatter(output_format)
+    watcher = SymbolWatcher(SymbolIndex(GoSymbolExtractor(
+        exported_only=exported_only, metrics=metrics, comments=comments, skip_bodies=skip_bodies,
+    )))
+
+    try:
+        watcher.build(
+            target,
+            recursive=recursive,
+            include_tests=include_tests,
+            include=include,
+            exclude=exclude,
+            context=context,
+            depth=depth,
+        )
+    except Exception as e:
+        console.print(f"[red]Error extracting symbols: {e}[/red]")
+        if logger.isEnabledFor(logging.DEBUG):
+            raise
+        sys.exit(1)
+
+    click.echo(formatter.format(_show_paths(sort_symbols(watcher.index.symbols()), target, path_mode)), color=color or None)
+    # Status goes to stderr so stdout stays parseable in JSON mode
+    click.echo(f"Watching {target} for changes... Press Ctrl+C to stop.", err=True)
+
+    def show_diff(diff: SymbolDiff) -> SymbolDiff:
+        lists = ("added", "removed", "modified", "previous", "deprecated")
+        return SymbolDiff(**{name: _show_paths(getattr(diff, name), target, path_mode) for name in lists})
+
+    watcher.start(
+        target,
+        recursive=recursive,
+        include_tests=include_tests,
+        on_diff=lambda diff: click.echo(formatter.format_diff(show_diff(diff))),
+        include=include,
+        exclude=exclude,
+        context=context,
+        depth=depth,
+    )
+    click.echo("Stopped watching.", err=True)
+
+
+@main.command("diff")
+@click.argument("old")
+@click.argument("new")
+# A graph of types cannot show what changed
+@click.option("--format", "output_format", type=click.Choice([f for f in FORMATTERS if f != "dot"]), default="text", help="Output format (default: text)")
+@click.option("--exported-only", is_flag=True, help="Only report changes to exported (public API) symbols")
+@click.option("-r", "--recursive", is_flag=True, help="Walk subdirectories of both trees")
+@click.option("--include-tests", is_flag=True, help="Include _test.go files")
+@click.option("--no-cache", is_flag=True, help="Re-parse every file instead of reusing cached results")
+@click.option("--path-mode", type=click.Choice(list(PATH_MODES)), default="rel", help="Show file paths relative to OLD or NEW, absolute, or as bare file names (default: rel)")
+def diff_command(
+    old: str,
+    new: str,
+    output_format: str,
+    exported_only: bool,
+    recursive: bool,
+    include_tests: bool,
+    no_cache: bool,
+    path_mode: str
+):
+    """Compare the Go API of two directory trees, e.g. checkouts of two revisions.
+
+    Reports added, removed, signature-changed, and newly deprecated
+    symbols per package.
+    Exits with status 1 when an exported symbol was removed or its
+    signature changed, so it can gate CI.
+
+    Examples:
+      ctxd diff ./v1 ./v2 -r
+      ctxd diff ./v1 ./v2 -r --exported-only --format json
+    """
+    from .symbols import Options, SymbolDiff, extract_dir, get_formatter
+    from .symbols.compare import breaking_changes, diff_packages
+
+    for path in (old, new):
+        if not Path(path).is_dir():
+            console.print(f"[red]Error: Not a directory: {path}[/red]")
+            sys.exit(1)
+
+    options = Options(
+        exported_only=exported_only,
+        recursive=recursive,
+        include_tests=include_tests,
+        cache=not no_cache,
+    )
+    try:
+        old_index = extract_dir(old, options)
+        new_index = extract_dir(new, options)
+    except Exception as e:
+        console.print(f"[red]Error extracting symbols: {escape(str(e))}[/red]")
+        if logger.isEnabledFor(logging.DEBUG):
+            raise
+        sys.exit(1)
+
+    diffs = diff_packages(old_index.packages, new_index.packages)
+    # Removed symbols and the previous versions of modified ones are from OLD
+    shown = {
+        path: SymbolDiff(
+            added=_show_paths(d.added, Path(new), path_mode),
+            removed=_show_paths(d.removed, Path(old), path_mode),
+            modified=_show_paths(d.modified, Path(new), path_mode),
+            previous=_show_paths(d.previous, Path(old), path_mode),
+            deprecated=_show_paths(d.deprecated, Path(new), path_mode),
+        )
+        for path, d in diffs.items()
+    }
+    output = get_formatter(output_format).format_package_diffs(shown)
+    if output:
+        click.echo(output)
+
+    _report_parse_errors(_show_paths(old_index.errors, Path(old), path_mode) + _show_paths(new_index.errors, Path(new), path_mode))
+
+    breaking = [s for d in diffs.values() for s in breaking_changes(d)]
+    if not diffs:
+        click.echo("No API changes.", err=True)
+    elif breaking:
+        click.echo(f"{len(breaking)} breaking {'change' if len(breaking) == 1 else 'changes'}:", err=True)
+        for symbol in breaking:
+            click.echo(f"  {symbol.qualified_name}", err=True)
+        sys.exit(1)
+
+
+@main.command()
+@click.argument("path", default=".")
+@click.option("--addr", default="localhost:8080", help="Address to listen on, HOST:PORT or :PORT for all interfaces (default: localhost:8080)")
+@click.option("--watch", is_flag=True, help="Re-index when Go files change")
+@click.option("--exported-only", is_flag=True, help="Only serve exported (public API) symbols")
+@click.option("-r", "--recursive", is_flag=True, help="Walk subdirectories of PATH")
+@click.option("--include-tests", is_flag=True, help="Include _test.go files")
+@click.option("--include", "include_patterns", multiple=True, help="Only index files matching this glob, relative to PATH (repeatable)")
+@click.option("--exclude", "exclude_patterns", multiple=True, help="Skip files matching this glob, relative to PATH (repeatable)")
+@click.option("--cors-origin", default="*", help="Access-Control-Allow-Origin of responses (default: *)")
+@click.option("--no-cache", is_flag=True, help="Re-parse every file instead of reusing cached results or the saved index")
+def serve(
+    path: str,
+    addr: str,
+    watch: bool,
+    exported_only: bool,
+    recursive: bool,
+    include_tests: bool,
+    include_patterns: tuple,
+    exclude_patterns: tuple,
+    cors_origin: str,
+    no_cache: bool
+):
+    """Serve the Go symbols of a directory tree as a JSON HTTP API.
+
+    Endpoints: GET /symbols (filter with ?kind=, ?exported=, ?package=),
+    GET /symbols/{name}, GET /packages, GET /search?q=, and the
+    WebSocket GET /events?snapshot=true, which streams the changes each
+    re-index finds.
+
+    Examples:
+      ctxd serve . -r
+      ctxd serve ./pkg -r --addr :8080 --watch
+    """
+    from .symbols import Options
+    from .symbols.cache import SymbolCache
+    from .symbols.patterns import PathFilter
+    from .symbols.server import SymbolServer, parse_addr
+    from .symbols.snapshot import IndexSnapshot, default_snapshot_path
+
+    target = Path(path)
+    if not target.is_dir():
+        console.print(f"[red]Error: Not a directory: {target}[/red]")
+        sys.exit(1)
+
+    try:
+        host, port = parse_addr(addr)
+        PathFilter(list(include_patterns), list(exclude_patterns))
+    except ValueError as e:
+        console.print(f"[red]Error: {escape(str(e))}[/red]")
+        sys.exit(1)
+
+    options = Options(
+        exported_only=exported_only,
+        recursive=recursive,
+        include_tests=include_tests,
+        include=list(include_patterns),
+        exclude=list(exclude_patterns),
+        cache=not no_cache,
+    )
+    # The index is saved on shutdown, so a restart only parses changed files
+    snapshot = None if no_cache else IndexSnapshot(default_snapshot_path(target), SymbolCache())
+    try:
+        server = SymbolServer(target, options, cors_origin=cors_origin, snapshot=snapshot)
+    except Exception as e:
+        console.print(f"[red]Error extracting symbols: {escape(str(e))}[/red]")
+        if logger.isEnabledFor(logging.DEBUG):
+            raise
+        sys.exit(1)
+    _report_parse_errors(server.index.errors)
+
+    try:
+        server.bind((host, port))
+    except OSError as e:
+        console.print(f"[red]Error: Cannot listen on {escape(addr)}: {escape(e.strerror or str(e))}[/red]")
+        sys.exit(1)
+
+    bound_host, bound_port = server.address
+    click.echo(f"Serving {len(server.index)} symbols from {target} on http://{bound_host}:{bound_port} ... Press Ctrl+C to stop.", err=True)
+
+    if not watch:
+        try:
+            server.serve_forever()
+        except KeyboardInterrupt:
+            pass
+        server.shutdown()
+        click.echo("Stopped serving.", err=True)
+        return
+
+    import threading
+    from .symbols.watcher import SymbolWatcher
+
+    threading.Thread(target=server.serve_forever, daemon=True).start()
+    watcher = SymbolWatcher()
+    watcher.start(
+        target,
+        recursive=recursive,
+        include_tests=include_tests,
+        include=options.include,
+        exclude=options.exclude,
+        on_change=server.reload,
+    )
+    server.shutdown()
+    click.echo("Stopped serving.", err=True)
+
+
+@main.command("mcp")
+@click.argument("path", default=".")
+@click.option("--no-cache", is_flag=True, help="Re-parse every file instead of reusing cached results")
+def mcp_command(path: str, no_cache: bool):
+    """Serve the Go symbols of a directory tree as MCP tools over stdio.
+
+    Tools: list_symbols, get_symbol, and search_symbols. Their paths are
+    relative to PATH, and their parameters mirror the `ctxd symbols` flags.
+    Register the command with an MCP client rather than running it by hand.
+
+    Examples:
+      ctxd mcp .
+      ctxd mcp ./pkg --no-cache
+    """
+    from .symbols.mcp_server import SymbolTools, create_server
+
+    target = Path(path)
+    if not target.is_dir():
+        console.print(f"[red]Error: Not a directory: {target}[/red]")
+        sys.exit(1)
+
+    try:
+        server = create_server(SymbolTools(target, cache=not no_cache))
+    except ImportError as e:
+        console.print(f"[red]Error: The mcp package is required: {escape(str(e))}[/red]")
+        sys.exit(1)
+
+    # stdout carries the protocol; status goes to stderr
+    click.echo(f"Serving Go symbols from {target.resolve()} over MCP stdio ...", err=True)
+    server.run()
+
+
+@main.command("find")
+@click.argument("query")
+@click.argument("path", default=".")
+@click.option("--in-doc", is_flag=True, help="Also match symbols whose doc comment contains QUERY")
+@click.option("--limit", "-n", type=click.IntRange(min=1), default=20, help="Maximum number of results (default: 20)")
+@click.option("--format", "output_format", type=click.Choice(["text", "json"]), default="text", help="Output format (default: text)")
+@click.option("--exported-only", is_flag=True, help="Only search exported (public API) symbols")
+@click.option("-r", "--recursive/--no-recursive", default=True, help="Walk subdirectories of PATH (default: on)")
+@click.option("--include-tests", is_flag=True, help="Include _test.go files")
+@click.option("--kind", "kind_list", default=None, help="Comma-separated kinds to search (func,method,struct,interface,type,alias,const,var,field,test,benchmark,example)")
+@click.option("--include", "include_patterns", multiple=True, help="Only search files matching this glob, relative to PATH (repeatable)")
+@click.option("--exclude", "exclude_patterns", multiple=True, help="Skip files matching this glob, relative to PATH (repeatable)")
+@click.option("--no-cache", is_flag=True, help="Re-parse every file instead of reusing cached results")
+@click.option("--path-mode", type=click.Choice(list(PATH_MODES)), default="rel", help="Show file paths relative to PATH, absolute, or as bare file names (default: rel)")
+def find_command(
+    query: str,
+    path: str,
+    in_doc: bool,
+    limit: int,
+    output_format: str,
+    exported_only: bool,
+    recursive: bool,
+    include_tests: bool,
+    kind_list: Optional[str],
+    include_patterns: tuple,
+    exclude_patterns: tuple,
+    no_cache: bool,
+    path_mode: str
+):
+    """Fuzzy-find Go symbols by name across a directory tree.
+
+    QUERY matches names whose characters include it in order, ignoring
+    case, as in fzf; exact names and prefixes rank first. Each result is
+    printed as file:line: signature. Exits with status 1 when nothing
+    matches. (`ctxd search` is the semantic search of the ctxd index.)
+
+    Examples:
+      ctxd find calcadd
+      ctxd find parse ./pkg --kind func,method
+      ctxd find retry --in-doc -n 5 --format json
+    """
+    from dataclasses import replace
+    from .symbols import Options, extract_dir
+    from .symbols.filters import validate_kinds
+    from .symbols.patterns import PathFilter
+    from .symbols.search import search
+
+    target = Path(path)
+    if not target.is_dir():
+        console.print(f"[red]Error: Not a directory: {target}[/red]")
+        sys.exit(1)
+    if not query.strip():
+        console.print("[red]Error: QUERY must not be empty[/red]")
+        sys.exit(1)
+
+    kinds = None
+    if kind_list is not None:
+        kinds = [k.strip() for k in kind_list.split(",") if k.strip()]
+        if not kinds:
+            console.print("[red]Error: --kind requires at least one kind[/red]")
+            sys.exit(1)
+    try:
+        if kinds is not None:
+            validate_kinds(kinds)
+        PathFilter(list(include_patterns), list(exclude_patterns))
+    except ValueError as e:
+        console.print(f"[red]Error: {escape(str(e))}[/red]")
+        sys.exit(1)
+
+    options = Options(
+        exported_only=exported_only,
+        recursive=recursive,
+        include_tests=include_tests,
+        kinds=kinds,
+        include=list(include_patterns),
+        exclude=list(exclude_patterns),
+        cache=not no_cache,
+    )
+    try:
+        index = extract_dir(target, options)
+    except Exception as e:
+        console.print(f"[red]Error extracting symbols: {escape(str(e))}[/red]")
+        if logger.isEnabledFor(logging.DEBUG):
+            raise
+        sys.exit(1)
+
+    matches = [
+        replace(match, symbol=_show_paths([match.symbol], target, path_mode)[0])
+        for match in search(index.symbols(), query, in_doc=in_doc, limit=limit)
+    ]
+    if output_format == "json":
+        click.echo(json.dumps([match.to_dict() for match in matches], indent=2))
+    else:
+        for match in matches:
+            click.echo(f"{match.symbol.file}:{match.symbol.line}: {match.symbol.signature}")
+
+    _report_parse_errors(_show_paths(index.errors, target, path_mode))
+    if not matches:
+        click.echo("No matches.", err=True)
+        sys.exit(1)
+
+
+@main.command("verify-implements")
+@click.argument("type_name", metavar="TYPE")
+@click.argument("interface_name", metavar="INTERFACE")
+@click.argument("path", default=".")
+@click.option("--format", "output_format", type=click.Choice(["text", "json"]), default="text", help="Output format (default: text)")
+@click.option("--include-tests", is_flag=True, help="Include _test.go files")
+@click.option("--no-cache", is_flag=True, help="Re-parse every file instead of reusing cached results")
+def verify_implements(type_name: str, interface_name: str, path: str, output_format: str, include_tests: bool, no_cache: bool):
+    """Check that a Go type satisfies an interface, across the packages under PATH.
+
+    TYPE and INTERFACE are qualified by import path
+    (example.com/shapes.Rect) or, when unambiguous, named within their
+    package (Rect). Prefix TYPE with * to check the pointer type's method
+    set. Prints PASS, or FAIL with each missing or mismatched method, and
+    exits with status 1 on FAIL so it can gate CI.
+
+    Examples:
+      ctxd verify-implements example.com/shapes.Rect example.com/shapes/geo.Shape
+      ctxd verify-implements '*Buffer' Writer ./pkg
+    """
+    from .symbols import Options, check_implements, extract_dir
+
+    target = Path(path)
+    if not target.is_dir():
+        console.print(f"[red]Error: Not a directory: {target}[/red]")
+        sys.exit(1)
+
+    options = Options(recursive=True, include_tests=include_tests, cache=not no_cache)
+    try:
+        index = extract_dir(target, options)
+    except Exception as e:
+        console.print(f"[red]Error extracting symbols: {escape(str(e))}[/red]")
+        if logger.isEnabledFor(logging.DEBUG):
+            raise
+        sys.exit(1)
+    _report_parse_errors(index.errors)
+
+    pointer = type_name.startswith("*")
+    concrete = _resolve_declaration(index, type_name.lstrip("*"), ("struct", "type"), "type")
+    iface = _resolve_declaration(index, interface_name, ("interface",), "interface")
+
+    try:
+        mismatches = check_implements(concrete, iface, index.symbols(), pointer=pointer)
+    except ValueError as e:
+        console.print(f"[red]Error: {escape(str(e))}[/red]")
+        sys.exit(1)
+
+    type_label = f"{'*' if pointer else ''}{concrete.qualified_name}"
+    if output_format == "json":
+        click.echo(json.dumps({
+            "type": type_label,
+            "interface": iface.qualified_name,
+            "implements": not mismatches,
+            "mismatches": [m.to_dict() for m in mismatches],
+        }, indent=2))
+    elif not mismatches:
+        click.echo(f"PASS: {type_label} implements {iface.qualified_name}")
+    else:
+        click.echo(f"FAIL: {type_label} does not implement {iface.qualified_name}")
+        for mismatch in mismatches:
+            click.echo(f"  {mismatch}")
+        if all(m.reason == "pointer_receiver" for m in mismatches):
+            click.echo(f"  (*{concrete.qualified_name} implements {iface.qualified_name})")
+    if mismatches:
+        sys.exit(1)
+
+
+def _resolve_declaration(index, name: str, kinds: tuple[str, ...], noun: str):
+    """Look up the one type or interface a command-line name denotes, or exit with an error."""
+    matches = [s for s in index.find(name) if s.kind in kinds or s.kind == "alias"]
+    if not matches:
+        others = index.find(name)
+        if others:
+            kind = others[0].kind
+            article = "an" if kind[0] in "aeiou" else "a"
+            console.print(f"[red]Error: Not a {noun}: {escape(name)} is {article} {kind}[/red]")
+        else:
+            console.print(f"[red]Error: Unknown {noun}: {escape(name)}[/red]")
+        sys.exit(1)
+    if len(matches) > 1:
+        candidates = ", ".join(s.qualified_name for s in matches)
+        console.print(f"[red]Error: Ambiguous {noun}: {escape(name)} (candidates: {escape(candidates)})[/red]")
+        sys.exit(1)
+    if matches[0].kind == "alias":
+        console.print(f"[red]Error: {escape(name)} is an alias of {escape(matches[0].type)}; name the {noun} it denotes[/red]")
+        sys.exit(1)
+    return matches[0]
+
+
 @main.command()
 def version():
     """Show ctxd version."""
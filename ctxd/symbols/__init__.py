@@ -0,0 +1,108 @@
+"""
+Symbol extraction for ctxd.
+
+Provides structured extraction of Go declarations (functions, methods,
+types) with rendered signatures, for feeding API surface context to
+AI coding assistants:
+- extract_file / extract_source / extract_dir / extract_fs: library entry points taking extraction Options
+- stream_dir: the symbols of a directory, yielded file by file as they are parsed
+- MapFS: in-memory file tree for extract_fs
+- Index: symbols of a directory tree grouped by package
+- load_options: Options from the nearest .ctxd.yaml
+- GoSymbolExtractor: tree-sitter based extractor for Go source
+- Symbol: a single extracted declaration
+- PackageSummary: name, doc, and size of a package
+- ParseError: a syntax error met while extracting
+- extract_annotations / AnnotationScanner: TODO/FIXME marker comments
+- extract_unused: unexported declarations their package never references
+- extract_context: a symbol and the declarations it depends on, dependencies first
+- SymbolFormatter: output formats (text, JSON, JSON Lines, Markdown, LSP DocumentSymbol)
+- extract_packages: walk a directory tree and group symbols by package
+- SymbolIndex: per-file symbols for incremental re-extraction (watch mode)
+- build_call_graph: caller -> callee adjacency list
+- gather_context: a symbol's dependencies among extracted symbols
+- check_implements: the methods keeping a type from satisfying an interface
+- check_receivers: types whose methods mix value and pointer receivers
+- normalize_type / TypeNormalizer: canonical spellings of type expressions for comparison
+- sort_symbols: canonical output order
+- rewrite_paths: file paths relative to the scanned directory, absolute, or bare
+- Tokenizer: pluggable token counting for output budgets
+- Transformer / Redactor: post-processing of symbols before output (Options.transformers)
+"""
+
+from .models import Annotation, MethodMismatch, PackageSummary, ParseError, ReceiverMix, Symbol, SymbolDiff
+from .extractor import GoSymbolExtractor, GoSyntaxError
+from .formatters import SymbolFormatter, TextFormatter, JsonFormatter, JsonlFormatter, MarkdownFormatter, LspFormatter, DotFormatter, get_formatter
+from .walker import extract_packages, find_go_files, summarize_package
+from .index import SymbolIndex, diff_symbols
+from .dependencies import gather_context
+from .resolve import build_call_graph, check_implements, group_methods
+from .lint import check_receivers
+from .normalize import TypeNormalizer, normalize_type
+from .ordering import sort_symbols
+from .paths import PATH_MODES, rewrite_paths
+from .tokenizers import Tokenizer, HeuristicTokenizer, get_tokenizer
+from .transformers import Redactor, Transformer, apply_transformers
+from .budget import fit_to_budget
+from .annotations import DEFAULT_MARKERS, AnnotationScanner
+from .fs import MapFS
+from .api import Index, Options, extract_annotations, extract_context, extract_dir, extract_file, extract_fs, extract_source, extract_unused, stream_dir
+from .config import ConfigError, find_config, load_options
+
+__all__ = [
+    "extract_file",
+    "extract_dir",
+    "extract_fs",
+    "stream_dir",
+    "extract_source",
+    "extract_annotations",
+    "extract_unused",
+    "extract_context",
+    "Options",
+    "Index",
+    "MapFS",
+    "load_options",
+    "find_config",
+    "ConfigError",
+    "Symbol",
+    "SymbolDiff",
+    "MethodMismatch",
+    "ReceiverMix",
+    "PackageSummary",
+    "ParseError",
+    "Annotation",
+    "AnnotationScanner",
+    "DEFAULT_MARKERS",
+    "GoSymbolExtractor",
+    "GoSyntaxError",
+    "SymbolFormatter",
+    "TextFormatter",
+    "JsonFormatter",
+    "JsonlFormatter",
+    "MarkdownFormatter",
+    "LspFormatter",
+    "DotFormatter",
+    "get_formatter",
+    "extract_packages",
+    "find_go_files",
+    "summarize_package",
+    "SymbolIndex",
+    "diff_symbols",
+    "build_call_graph",
+    "gather_context",
+    "check_implements",
+    "check_receivers",
+    "normalize_type",
+    "TypeNormalizer",
+    "group_methods",
+    "sort_symbols",
+    "rewrite_paths",
+    "PATH_MODES",
+    "Tokenizer",
+    "HeuristicTokenizer",
+    "get_tokenizer",
+    "Transformer",
+    "Redactor",
+    "apply_transformers",
+    "fit_to_budget",
+]
@@ -0,0 +1,191 @@
+"""
+TODO/FIXME marker extraction from Go comments.
+
+Only comment nodes of the syntax tree are scanned, so a marker inside a
+string literal is never reported. A marker must start a comment line, as
+in `// TODO: ...`, `// FIXME(ana) ...`, or a line of a `/* ... */` block,
+which keeps prose that merely mentions "TODO" out of the list.
+"""
+
+import logging
+import re
+from pathlib import Path
+from typing import Optional
+from tree_sitter import Node, Parser
+
+from ..chunkers.treesitter import TreeSitterChunker
+from .models import Annotation
+
+logger = logging.getLogger(__name__)
+
+DEFAULT_MARKERS = ["TODO", "FIXME", "HACK", "XXX", "BUG"]
+
+_MARKER_NAME_RE = re.compile(r"^\w+$")
+
+# Declarations that an annotation inside them is attributed to
+_DECLARATIONS = {"function_declaration", "method_declaration", "type_spec", "type_alias", "const_spec", "var_spec"}
+
+
+def validate_markers(markers: list[str]) -> None:
+    """
+    Check that every marker is a single word.
+
+    Raises:
+        ValueError: If the list is empty or a marker is not a word
+    """
+    if not markers:
+        raise ValueError("At least one marker is required")
+    invalid = [m for m in markers if not _MARKER_NAME_RE.match(m)]
+    if invalid:
+        raise ValueError(f"Invalid marker: {', '.join(invalid)} (markers are single words, e.g. TODO)")
+
+
+class AnnotationScanner:
+    """Finds marker comments in Go source."""
+
+    def __init__(self, markers: Optional[list[str]] = None):
+        """
+        Initialize the scanner with a Go tree-sitter parser.
+
+        Args:
+            markers: Marker words to look for, matched case-sensitively
+                (defaults to DEFAULT_MARKERS)
+
+        Raises:
+            ValueError: If a marker is not a single word
+        """
+        self.markers = list(markers) if markers is not None else list(DEFAULT_MARKERS)
+        validate_markers(self.markers)
+        alternatives = "|".join(re.escape(m) for m in sorted(self.markers, key=len, reverse=True))
+        self._pattern = re.compile(
+            rf"(?P<marker>{alternatives})(?:\((?P<owner>[^)]*)\))?(?=[:\s]|$):?\s*(?P<text>.*?)\s*$"
+        )
+        self.parser = Parser(TreeSitterChunker._get_language("go"))
+
+    def scan(self, content: str, path: str) -> list[Annotation]:
+        """
+        Find the annotations in Go source code.
+
+        Source with syntax errors is scanned as far as it parses.
+
+        Args:
+            content: The Go source code
+            path: File path recorded on each annotation
+
+        Returns:
+            Annotations in source order
+        """
+        source = bytes(content, "utf8")
+        tree = self.parser.parse(source)
+        self._source_lines = source.split(b"\n")
+
+        annotations = []
+        stack = [tree.root_node]
+        while stack:
+            node = stack.pop()
+            if node.type == "comment":
+                annotations.extend(self._scan_comment(node, path))
+                continue
+            stack.extend(reversed(node.children))
+
+        logger.debug(f"Found {len(annotations)} annotations in {path}")
+        return annotations
+
+    def scan_file(self, path: Path) -> list[Annotation]:
+        """
+        Find the annotations in a Go file on disk.
+
+        Args:
+            path: Path to the Go file
+
+        Returns:
+            Annotations in source order
+        """
+        with open(path, "r", encoding="utf-8", errors="ignore") as f:
+            content = f.read()
+        return self.scan(content, str(path))
+
+    def _scan_comment(self, node: Node, path: str) -> list[Annotation]:
+        """Match the lines of one comment node against the markers."""
+        text = node.text.decode("utf8", errors="replace")
+        # Drop the `//` or `/*` and `*/` delimiters
+        text = text[2:-2] if text.startswith("/*") and text.endswith("*/") else text[2:]
+
+        start_row, start_byte = node.start_point
+        start_column = len(self._source_lines[start_row][:start_byte].decode("utf8", errors="replace"))
+        symbol = ""
+        annotations = []
+        for i, line in enumerate(text.split("\n")):
+            line = line.rstrip("\r")
+            # Leading space and the `*` of star-prefixed block comment lines
+            stripped = line.lstrip(" \t*")
+            match = self._pattern.match(stripped)
+            if not match:
+                continue
+            column = len(line) - len(stripped) + 1
+            if i == 0:
+                column += start_column + 2
+            if not annotations:
+                symbol = self._owner_symbol(node)
+            annotations.append(Annotation(
+                marker=match.group("marker"),
+                text=match.group("text"),
+                file=path,
+                line=start_row + i + 1,
+                column=column,
+                owner=(match.group("owner") or "").strip(),
+                symbol=symbol,
+            ))
+        return annotations
+
+    def _owner_symbol(self, comment: Node) -> str:
+        """
+        Name the declaration a comment belongs to: the innermost one around
+        it, the one it trails on the same line, or the one it documents.
+        """
+        node = comment.parent
+        while node is not None:
+            if node.type in _DECLARATIONS:
+                return self._declaration_name(node)
+            node = node.parent
+
+        previous = comment.prev_sibling
+        if previous is not None and previous.end_point[0] == comment.start_point[0]:
+            return self._declaration_name(previous)
+
+        # A doc comment: the declaration starting on the line after the
+        # block of adjacent comments
+        expected_row = comment.end_point[0] + 1
+        sibling = comment.next_sibling
+        while sibling is not None and sibling.type == "comment" and sibling.start_point[0] == expected_row:
+            expected_row = sibling.end_point[0] + 1
+            sibling = sibling.next_sibling
+        if sibling is None or sibling.start_point[0] != expected_row:
+            return ""
+        return self._declaration_name(sibling)
+
+    @staticmethod
+    def _declaration_name(node: Node) -> str:
+        """
+        Get a declaration's name, with the receiver type for methods. Type,
+        const, and var declarations are named after their first spec.
+        """
+        if node.type not in _DECLARATIONS:
+            specs = [spec for spec in node.named_children if spec.type in _DECLARATIONS]
+            return AnnotationScanner._declaration_name(specs[0]) if specs else ""
+
+        name = node.child_by_field_name("name")
+        if name is None:
+            return ""
+        text = name.text.decode("utf8")
+        if node.type != "method_declaration":
+            return text
+
+        receiver = node.child_by_field_name("receiver")
+        for param in receiver.named_children if receiver else []:
+            receiver_type = param.child_by_field_name("type")
+            if receiver_type is not None:
+                # `*Stack[T]` -> `Stack`
+                base = receiver_type.text.decode("utf8").lstrip("*").split("[", 1)[0].strip()
+                return f"{base}.{text}"
+        return text
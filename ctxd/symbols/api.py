@@ -0,0 +1,654 @@
+"""
+Library entry points for Go symbol extraction.
+
+Wraps the extractor, walker, and post-processing steps behind two calls so
+that other tools can use ctxd without shelling out to the CLI:
+
+    from ctxd.symbols import Options, extract_dir, extract_file
+
+    symbols = extract_file("calculator.go")
+    symbols = extract_source(sys.stdin.read(), "main.go")
+    index = extract_dir("./pkg", Options(recursive=True, exported_only=True))
+    for symbol in stream_dir("./huge", Options(recursive=True)):
+        print(symbol.signature)
+    todos = extract_annotations("./pkg", Options(recursive=True))
+    dead = extract_unused("./pkg", Options(recursive=True))
+    context = extract_context("./pkg", "example.com/calc.NewCalculator", Options(recursive=True))
+"""
+
+import logging
+from dataclasses import dataclass, field, replace
+from pathlib import Path
+from typing import Iterator, Optional, Union
+
+from .annotations import AnnotationScanner
+from .cache import SymbolCache
+from .constraints import BuildContext
+from .dependencies import gather_context
+from .extractor import GoSymbolExtractor
+from .filters import NameFilter, filter_kinds, validate_kinds
+from .metrics import DEFAULT_COMPLEXITY_THRESHOLD
+from .fs import Traversable
+from .models import Annotation, PackageSummary, ParseError, Symbol
+from .ordering import sort_symbols
+from .resolve import build_call_graph, group_methods
+from .snapshot import IndexSnapshot
+from .transformers import Transformer, apply_transformers
+from .unused import find_unused
+from .walker import extract_packages, find_go_files, stream_packages, summarize_packages
+
+logger = logging.getLogger(__name__)
+
+
+@dataclass
+class Options:
+    """
+    Extraction settings, mirroring the `ctxd symbols` flags.
+
+    Attributes:
+        exported_only: Drop unexported symbols (--exported-only)
+        recursive: Walk subdirectories of a directory (-r/--recursive)
+        include_tests: Include `_test.go` files of a directory (--include-tests)
+        calls: Record the callees of each function and method body (--calls)
+        group_methods: Nest methods under their receiver type (--group-methods)
+        kinds: Only keep symbols of these kinds, e.g. ["interface"] (--kind)
+        include: Glob patterns of file paths, relative to the directory, to
+            extract (--include)
+        exclude: Glob patterns of relative file paths to skip; exclude wins
+            over include (--exclude)
+        cache: Reuse parse results of unchanged files from the on-disk
+            cache (on in the CLI unless --no-cache)
+        cache_dir: Cache location (defaults to $XDG_CACHE_HOME/ctxd/symbols)
+        strict: Raise on the first file with syntax errors instead of
+            extracting what can be recovered (--strict)
+        format: Output format name (--format); only used by the CLI
+        max_tokens: Token budget for the output (--max-tokens); only used
+            by the CLI
+        markers: Marker words that extract_annotations() looks for, e.g.
+            ["TODO", "FIXME"] (--markers; defaults to DEFAULT_MARKERS)
+        metrics: Record the cyclomatic complexity and lines of code of each
+            function and method, and the lines of code of each package
+            (--metrics)
+        complexity_threshold: Complexity above which text output flags a
+            function (--complexity-threshold); only used by the CLI
+        include_source: Record the source text of each declaration as its
+            `source` (--include-source)
+        goos: Target operating system of build constraints when walking a
+            directory (--goos; defaults to $GOOS or the host's)
+        goarch: Target architecture of build constraints (--goarch;
+            defaults to $GOARCH or the host's)
+        jobs: Number of files of a directory parsed at once (--jobs;
+            defaults to the number of CPUs)
+        depth: Directory levels below a directory to walk when recursive,
+            0 for its own files only (--depth; None for no limit)
+        name: Only keep symbols whose name matches this regular
+            expression anywhere, e.g. "^New" (--name)
+        name_exclude: Drop symbols whose name matches this regular
+            expression; wins over name (--name-exclude)
+        transformers: Chain of Transformers that rewrite or drop symbols
+            after filtering, in list order (see ctxd.symbols.transformers);
+            library only
+        comments: Record doc comments, summaries, deprecation notes, and
+            directives, and read package docs (--no-comments to skip them)
+        skip_bodies: Do not look inside function and method bodies, for a
+            faster signatures-only extraction (--skip-bodies); calls and
+            metrics need bodies, so both are turned off with a warning
+        path_mode: How output shows file paths, one of PATH_MODES
+            (--path-mode); only used by the CLI, see rewrite_paths()
+    """
+    exported_only: bool = False
+    recursive: bool = False
+    include_tests: bool = False
+    calls: bool = False
+    group_methods: bool = False
+    kinds: Optional[list[str]] = None
+    include: Optional[list[str]] = None
+    exclude: Optional[list[str]] = None
+    cache: bool = False
+    cache_dir: Optional[Path] = None
+    strict: bool = False
+    format: str = "text"
+    max_tokens: Optional[int] = None
+    markers: Optional[list[str]] = None
+    metrics: bool = False
+    complexity_threshold: int = DEFAULT_COMPLEXITY_THRESHOLD
+    include_source: bool = False
+    goos: Optional[str] = None
+    goarch: Optional[str] = None
+    jobs: Optional[int] = None
+    depth: Optional[int] = None
+    name: Optional[str] = None
+    name_exclude: Optional[str] = None
+    transformers: Optional[list[Transformer]] = None
+    comments: bool = True
+    skip_bodies: bool = False
+    path_mode: str = "rel"
+
+
+@dataclass
+class Index:
+    """
+    Symbols of a directory tree, grouped by package.
+
+    Attributes:
+        root: Directory that was extracted, a Path or other Traversable
+        packages: Mapping of import path to that package's symbols, in
+            canonical order
+        summaries: Mapping of import path to the package's summary, with
+            kind counts of the symbols in `packages`
+        errors: Syntax errors and unreadable files met during the walk
+    """
+    root: Traversable
+    packages: dict[str, list[Symbol]]
+    summaries: dict[str, PackageSummary] = field(default_factory=dict)
+    errors: list[ParseError] = field(default_factory=list)
+
+    def symbols(self) -> list[Symbol]:
+        """Get the symbols of every package in canonical order."""
+        return [s for symbols in self.packages.values() for s in symbols]
+
+    def package(self, import_path: str) -> list[Symbol]:
+        """Get the symbols of one package (empty if it is not in the index)."""
+        return self.packages.get(import_path, [])
+
+    def find(self, name: str, package: Optional[str] = None) -> list[Symbol]:
+        """
+        Look up symbols by qualified name ("example.com/calc.Calculator.Add"),
+        or else by name within their package ("Calculator.Add").
+
+        Args:
+            name: Qualified or local name
+            package: Only look in this package
+
+        Returns:
+            Matching symbols; more than one when a local name is ambiguous
+        """
+        symbols = self.package(package) if package is not None else self.symbols()
+        matches = [s for s in symbols if s.qualified_name == name]
+        return matches or [s for s in symbols if s.local_name == name]
+
+    def call_graph(self) -> dict[str, list[str]]:
+        """Get the caller -> callees graph (requires `Options.calls`)."""
+        return build_call_graph(self.symbols())
+
+    def __iter__(self) -> Iterator[Symbol]:
+        """Iterate over all symbols in canonical order."""
+        return iter(self.symbols())
+
+    def __len__(self) -> int:
+        """Get the number of symbols."""
+        return sum(len(symbols) for symbols in self.packages.values())
+
+    def __repr__(self) -> str:
+        """String representation."""
+        return f"Index(root={str(self.root)!r}, packages={len(self.packages)}, symbols={len(self)})"
+
+
+def extract_file(
+    path: Union[str, Path],
+    options: Optional[Options] = None,
+    errors: Optional[list[ParseError]] = None
+) -> list[Symbol]:
+    """
+    Extract the symbols of a single Go file.
+
+    Args:
+        path: Go source file
+        options: Extraction settings (`recursive`, `include_tests`,
+            `include`, and `exclude` are ignored for files)
+        errors: List to append the file's syntax errors to
+
+    Returns:
+        Symbols in canonical order
+
+    Raises:
+        FileNotFoundError: If the file does not exist
+        GoSyntaxError: If options.strict is set and the file has syntax errors
+        ValueError: If options.kinds names an unknown kind, or a name
+            pattern is invalid
+    """
+    options = options or Options()
+    _validate(options)
+    options = _without_bodies(options)
+    extractor = _extractor(options)
+    symbols = extractor.extract_file(Path(path))
+    if errors is not None:
+        errors.extend(extractor.errors)
+    return _finish(symbols, options, NameFilter(options.name, options.name_exclude))
+
+
+def extract_source(
+    content: str,
+    filename: str = "<stdin>",
+    options: Optional[Options] = None,
+    errors: Optional[list[ParseError]] = None
+) -> list[Symbol]:
+    """
+    Extract the symbols of Go source held in memory, e.g. read from stdin.
+
+    Args:
+        content: Go source code
+        filename: Name recorded as each symbol's file; need not exist
+        options: Extraction settings (`recursive`, `include_tests`,
+            `include`, and `exclude` are ignored)
+        errors: List to append syntax errors to, with lines relative to
+            `content`
+
+    Returns:
+        Symbols in canonical order; line numbers are relative to `content`
+
+    Raises:
+        GoSyntaxError: If options.strict is set and the source has syntax errors
+        ValueError: If options.kinds names an unknown kind, or a name
+            pattern is invalid
+    """
+    options = options or Options()
+    _validate(options)
+    options = _without_bodies(options)
+    extractor = _extractor(options)
+    symbols = extractor.extract_cached(content, filename)
+    if errors is not None:
+        errors.extend(extractor.errors)
+    return _finish(symbols, options, NameFilter(options.name, options.name_exclude))
+
+
+def extract_dir(
+    root: Union[str, Path, Traversable],
+    options: Optional[Options] = None,
+    snapshot: Optional[IndexSnapshot] = None
+) -> Index:
+    """
+    Extract the symbols of every Go package under a directory.
+
+    Args:
+        root: Directory to walk: a path, or any Traversable such as a
+            zipfile.Path or MapFS (see extract_fs)
+        options: Extraction settings
+        snapshot: Snapshot of an earlier extraction to reuse, which is
+            updated to this one; only files changed since are parsed, and
+            the saved index is returned when none are
+
+    Returns:
+        Index of the tree's packages
+
+    Raises:
+        NotADirectoryError: If root is not a directory
+        GoSyntaxError: If options.strict is set and a file has syntax errors
+        OSError: If options.strict is set and a file cannot be read
+        ValueError: If options.kinds names an unknown kind, a glob
+            or name pattern is invalid, the GOOS or GOARCH is unknown,
+            jobs is less than 1, or depth is negative
+    """
+    options = options or Options()
+    _validate(options)
+    options = _without_bodies(options)
+    root = Path(root) if isinstance(root, str) else root
+    if not root.is_dir():
+        raise NotADirectoryError(f"Not a directory: {root}")
+
+    files = find_go_files(
+        root,
+        recursive=options.recursive,
+        include_tests=options.include_tests,
+        include=options.include,
+        exclude=options.exclude,
+        context=build_context(options),
+        depth=options.depth,
+    )
+    if snapshot is not None:
+        index = snapshot.lookup(root, files, options)
+        if index is not None:
+            return index
+    errors: list[ParseError] = []
+    packages = extract_packages(
+        root,
+        exported_only=options.exported_only,
+        calls=options.calls,
+        cache=snapshot if snapshot is not None else _cache(options),
+        files=files,
+        strict=options.strict,
+        errors=errors,
+        metrics=options.metrics,
+        include_source=options.include_source,
+        jobs=options.jobs,
+        comments=options.comments,
+        skip_bodies=options.skip_bodies,
+    )
+    names = NameFilter(options.name, options.name_exclude)
+    packages = {path: _finish(symbols, options, names) for path, symbols in packages.items()}
+    index = Index(root=root, packages=packages, summaries=summarize_packages(root, packages, files, options.metrics, options.comments), errors=errors)
+    if snapshot is not None:
+        snapshot.record(index, options)
+    return index
+
+
+def stream_dir(
+    root: Union[str, Path, Traversable],
+    options: Optional[Options] = None,
+    errors: Optional[list[ParseError]] = None
+) -> Iterator[Symbol]:
+    """
+    Extract the symbols of every Go package under a directory, yielding
+    each file's symbols as soon as it is parsed.
+
+    Nothing is buffered past one file, so this suits trees too large to
+    hold in memory at once, at the cost of what needs the whole tree:
+    implemented and embedded interfaces and the subjects of examples are
+    only resolved within a file, and methods cannot be grouped. Each
+    file's symbols are in canonical order; files come in walk order when
+    parsed in-process, but in the order they finish when parsed by
+    several workers (see options.jobs). Sort the symbols, or use
+    extract_dir(), for a deterministic order.
+
+    Args:
+        root: Directory to walk, a path or any Traversable
+        options: Extraction settings
+        errors: List to append syntax and read errors to, as each file
+            finishes
+
+    Yields:
+        Symbols, file by file
+
+    Raises:
+        NotADirectoryError: If root is not a directory
+        GoSyntaxError: If options.strict is set and a file has syntax errors
+        OSError: If options.strict is set and a file cannot be read
+        ValueError: As for extract_dir(), or if options.group_methods is set
+    """
+    options = options or Options()
+    _validate(options)
+    options = _without_bodies(options)
+    if options.group_methods:
+        raise ValueError("Methods cannot be grouped when streaming, since types and their methods may be in different files")
+    root = Path(root) if isinstance(root, str) else root
+    if not root.is_dir():
+        raise NotADirectoryError(f"Not a directory: {root}")
+
+    files = find_go_files(
+        root,
+        recursive=options.recursive,
+        include_tests=options.include_tests,
+        include=options.include,
+        exclude=options.exclude,
+        context=build_context(options),
+        depth=options.depth,
+    )
+    names = NameFilter(options.name, options.name_exclude)
+    for _, symbols in stream_packages(
+        root,
+        files,
+        exported_only=options.exported_only,
+        calls=options.calls,
+        cache=_cache(options),
+        strict=options.strict,
+        errors=errors,
+        metrics=options.metrics,
+        include_source=options.include_source,
+        jobs=options.jobs,
+        comments=options.comments,
+        skip_bodies=options.skip_bodies,
+    ):
+        yield from _finish(symbols, options, names)
+
+
+def extract_fs(fsys: Traversable, root: str = ".", options: Optional[Options] = None) -> Index:
+    """
+    Extract the symbols of every Go package under a directory of a file tree.
+
+    The tree is read only through the Traversable protocol, so archives and
+    in-memory trees need no files on disk; extract_dir(path) is the same walk
+    over an OS directory. Symbols record each file's path within the tree.
+
+    Args:
+        fsys: Tree to read, e.g. MapFS({"go.mod": ..., "geo/point.go": ...})
+        root: Slash-separated directory within the tree to walk
+        options: Extraction settings
+
+    Returns:
+        Index of the packages below root
+
+    Raises:
+        NotADirectoryError: If root is not a directory of the tree
+        GoSyntaxError: If options.strict is set and a file has syntax errors
+        ValueError: If options.kinds names an unknown kind, a glob
+            or name pattern is invalid, or the GOOS or GOARCH is unknown
+    """
+    parts = [part for part in root.split("/") if part not in ("", ".")]
+    return extract_dir(fsys.joinpath(*parts) if parts else fsys, options)
+
+
+def extract_annotations(path: Union[str, Path], options: Optional[Options] = None) -> list[Annotation]:
+    """
+    Find the TODO/FIXME-style marker comments of a Go file or package tree.
+
+    Args:
+        path: Go source file or directory to walk
+        options: Settings; `markers` picks the marker words, and
+            `recursive`, `depth`, `include_tests`, `include`, `exclude`,
+            `goos`, and `goarch` select a directory's files. Other settings
+            are ignored.
+
+    Returns:
+        Annotations in file order, then source order
+
+    Raises:
+        FileNotFoundError: If path does not exist
+        ValueError: If a marker is not a single word, a glob pattern is
+            invalid, or the GOOS or GOARCH is unknown
+    """
+    options = options or Options()
+    scanner = AnnotationScanner(options.markers)
+    path = Path(path)
+    if not path.is_dir():
+        return scanner.scan_file(path)
+
+    files = find_go_files(
+        path,
+        recursive=options.recursive,
+        include_tests=options.include_tests,
+        include=options.include,
+        exclude=options.exclude,
+        context=build_context(options),
+        depth=options.depth,
+    )
+    annotations = []
+    for file_path in files:
+        annotations.extend(scanner.scan_file(file_path))
+    return annotations
+
+
+def extract_unused(
+    path: Union[str, Path],
+    options: Optional[Options] = None,
+    errors: Optional[list[ParseError]] = None
+) -> list[Symbol]:
+    """
+    Find the unexported declarations of a Go file or package tree that
+    their own package never references.
+
+    Symbols are extracted with the options, so `kinds` and the name
+    patterns narrow what is reported. References are collected from every
+    Go file of each package's directory, as ctxd.symbols.unused describes;
+    packages with a file that cannot be read are not reported on.
+
+    Args:
+        path: Go source file or directory to walk
+        options: Extraction settings
+        errors: List to append syntax errors to
+
+    Returns:
+        Unused symbols in canonical order
+
+    Raises:
+        FileNotFoundError: If path does not exist
+        GoSyntaxError: If options.strict is set and a file has syntax errors
+        ValueError: If options.kinds names an unknown kind, a glob or name
+            pattern is invalid, or the GOOS or GOARCH is unknown
+    """
+    options = options or Options()
+    path = Path(path)
+    if path.is_dir():
+        index = extract_dir(path, options)
+        symbols = index.symbols()
+        if errors is not None:
+            errors.extend(index.errors)
+    else:
+        symbols = extract_file(path, options, errors)
+
+    by_directory: dict[Path, list[Symbol]] = {}
+    for symbol in symbols:
+        by_directory.setdefault(Path(symbol.file).parent, []).append(symbol)
+
+    extractor = GoSymbolExtractor()
+    unused = []
+    for directory, package_symbols in by_directory.items():
+        referenced: set[str] = set()
+        try:
+            for file_path in sorted(p for p in directory.glob("*.go") if p.is_file()):
+                referenced |= extractor.references(file_path.read_text(encoding="utf-8", errors="replace"))
+        except OSError as e:
+            logger.warning(f"Not checking {directory} for unused symbols: {e}")
+            continue
+        unused.extend(find_unused(package_symbols, referenced))
+    return sort_symbols(unused)
+
+
+def extract_context(
+    path: Union[str, Path],
+    name: str,
+    options: Optional[Options] = None,
+    depth: int = 1,
+    errors: Optional[list[ParseError]] = None
+) -> list[Symbol]:
+    """
+    Gather a symbol and the declarations it depends on from a Go file or
+    package tree.
+
+    Dependencies are the types the symbol's declaration and body name and
+    the functions and methods it calls, followed `depth` levels deep, as
+    ctxd.symbols.dependencies describes. For NewCalculator at depth 1 that
+    is the Calculator struct it returns and constructs.
+
+    Symbols are extracted with the options, except that `kinds`, the name
+    patterns, `group_methods`, and `skip_bodies` do not apply: every
+    declaration can be a dependency, and bodies hold references. Calls and
+    source text are recorded to find references, but only kept in the
+    result when the options ask for them.
+
+    Args:
+        path: Go source file or directory to walk
+        name: Qualified ("example.com/calc.NewCalculator") or local
+            ("NewCalculator", "Calculator.Add") name of the symbol
+        options: Extraction settings
+        depth: Levels of references to follow; 0 gives just the symbol
+        errors: List to append syntax errors to
+
+    Returns:
+        The symbol and its dependencies, each after the declarations it
+        references, so the symbol itself comes last
+
+    Raises:
+        FileNotFoundError: If path does not exist
+        GoSyntaxError: If options.strict is set and a file has syntax errors
+        ValueError: If no symbol or more than one has the name, depth is
+            negative, or a glob is invalid or the GOOS or GOARCH unknown
+    """
+    options = options or Options()
+    if depth < 0:
+        raise ValueError(f"Invalid depth: {depth} (expected at least 0)")
+    extraction = replace(
+        options, kinds=None, name=None, name_exclude=None, group_methods=False, calls=True, include_source=True, skip_bodies=False,
+    )
+    path = Path(path)
+    if path.is_dir():
+        index = extract_dir(path, extraction)
+        symbols = index.symbols()
+        if errors is not None:
+            errors.extend(index.errors)
+    else:
+        symbols = extract_file(path, extraction, errors)
+
+    declarations = [s for s in symbols if s.kind != "field"]
+    matches = [s for s in declarations if s.qualified_name == name] or [s for s in declarations if s.local_name == name]
+    if not matches:
+        raise ValueError(f"Unknown symbol: {name}")
+    if len(matches) > 1:
+        raise ValueError(f"Ambiguous symbol: {name} (candidates: {', '.join(s.qualified_name for s in matches)})")
+
+    context = gather_context(symbols, matches[0], depth)
+    return [_without_extras(s, options) for s in context]
+
+
+def build_context(options: Options) -> BuildContext:
+    """
+    Get the target platform that options select a directory's files for.
+
+    Raises:
+        ValueError: If the GOOS or GOARCH is unknown
+    """
+    return BuildContext.default(options.goos, options.goarch)
+
+
+def _validate(options: Options) -> None:
+    """Reject invalid options before doing any work."""
+    if options.kinds is not None:
+        validate_kinds(options.kinds)
+    if options.jobs is not None and options.jobs < 1:
+        raise ValueError(f"Invalid jobs: {options.jobs} (expected at least 1)")
+    if options.depth is not None and options.depth < 0:
+        raise ValueError(f"Invalid depth: {options.depth} (expected at least 0)")
+    NameFilter(options.name, options.name_exclude)
+
+
+def _extractor(options: Options) -> GoSymbolExtractor:
+    """Create an extractor for single-file extraction."""
+    return GoSymbolExtractor(
+        exported_only=options.exported_only,
+        calls=options.calls,
+        cache=_cache(options),
+        strict=options.strict,
+        metrics=options.metrics,
+        include_source=options.include_source,
+        comments=options.comments,
+        skip_bodies=options.skip_bodies,
+    )
+
+
+def _without_bodies(options: Options) -> Options:
+    """Turn off the settings that need function bodies when options.skip_bodies is set, warning about them."""
+    needed = [name for name in ("calls", "metrics") if getattr(options, name)]
+    if not options.skip_bodies or not needed:
+        return options
+    logger.warning(f"Function bodies are skipped, so {' and '.join(needed)} will not be recorded")
+    return replace(options, calls=False, metrics=False)
+
+
+def _cache(options: Options) -> Optional[SymbolCache]:
+    """Open the symbol cache if the options enable it."""
+    return SymbolCache(options.cache_dir) if options.cache else None
+
+
+def _without_extras(symbol: Symbol, options: Options) -> Symbol:
+    """Clear the calls and source text that options did not ask for from a symbol and its members."""
+    if options.calls and options.include_source:
+        return symbol
+    return replace(
+        symbol,
+        calls=symbol.calls if options.calls else [],
+        source=symbol.source if options.include_source else "",
+        fields=[_without_extras(f, options) for f in symbol.fields],
+        methods=[_without_extras(m, options) for m in symbol.methods],
+    )
+
+
+def _finish(symbols: list[Symbol], options: Options, names: NameFilter) -> list[Symbol]:
+    """Apply kind and name filtering, transformers, method grouping, and canonical ordering."""
+    if options.kinds is not None:
+        symbols = filter_kinds(symbols, options.kinds)
+    if names:
+        symbols = names.filter(symbols)
+    if options.transformers:
+        symbols = apply_transformers(symbols, options.transformers)
+    if options.group_methods:
+        symbols = group_methods(symbols)
+    return sort_symbols(symbols)
@@ -0,0 +1,139 @@
+"""
+Token budgeting for symbol output.
+
+Trims an extraction to fit a token budget by dropping the least important
+symbols first, so the most useful API surface survives in a limited
+context window.
+"""
+
+import logging
+from dataclasses import replace
+from typing import Optional
+
+from .formatters import SymbolFormatter
+from .models import TEST_KINDS, Symbol
+from .tokenizers import HeuristicTokenizer, Tokenizer
+
+logger = logging.getLogger(__name__)
+
+# Budget tiers from most to least important:
+# - exported_types: exported types and the exported methods of exported types
+# - exported_functions: exported package-level functions
+# - docs: doc comments of symbols already included
+# - unexported: everything else, tests and examples too, included with docs
+PRIORITY_TIERS = ["exported_types", "exported_functions", "docs", "unexported"]
+
+DEFAULT_PRIORITY = list(PRIORITY_TIERS)
+
+
+def fit_to_budget(
+    symbols: list[Symbol],
+    max_tokens: int,
+    formatter: SymbolFormatter,
+    tokenizer: Optional[Tokenizer] = None,
+    priority: Optional[list[str]] = None
+) -> tuple[list[Symbol], int]:
+    """
+    Drop lower-priority symbols until the formatted output fits a budget.
+
+    Tiers are filled in `priority` order; within a tier, symbols are taken
+    in input order and any symbol that still fits is kept. Symbols added
+    before the "docs" tier are included without doc comments, which the
+    "docs" tier then restores as budget allows. Tiers missing from
+    `priority` are left out entirely.
+
+    Args:
+        symbols: Symbols in output order
+        max_tokens: Token budget for the formatted output
+        formatter: Formatter whose output is measured
+        tokenizer: Token counter (defaults to HeuristicTokenizer)
+        priority: Tier names from most to least important (defaults to
+            DEFAULT_PRIORITY)
+
+    Returns:
+        (kept symbols in input order, number of symbols omitted)
+
+    Raises:
+        ValueError: If `priority` names an unknown tier
+    """
+    tokenizer = tokenizer or HeuristicTokenizer()
+    priority = priority or DEFAULT_PRIORITY
+    unknown = [tier for tier in priority if tier not in PRIORITY_TIERS]
+    if unknown:
+        raise ValueError(f"Unknown budget priority: {unknown}. Supported: {PRIORITY_TIERS}")
+
+    if tokenizer.count(formatter.format(symbols)) <= max_tokens:
+        return symbols, 0
+
+    def cost(symbol: Symbol) -> int:
+        return tokenizer.count(formatter.format([symbol]))
+
+    tiers: dict[str, list[int]] = {tier: [] for tier in PRIORITY_TIERS}
+    for i, symbol in enumerate(symbols):
+        tiers[_tier(symbol)].append(i)
+
+    selected: dict[int, Symbol] = {}
+    additions: list[tuple[str, int]] = []  # (what, index) in the order they were made
+    used = 0
+    docs_included = False
+
+    for tier in priority:
+        if tier == "docs":
+            docs_included = True
+            for i in sorted(selected):
+                if selected[i] is symbols[i]:
+                    continue
+                extra = cost(symbols[i]) - cost(selected[i])
+                if used + extra <= max_tokens:
+                    selected[i] = symbols[i]
+                    used += extra
+                    additions.append(("doc", i))
+            continue
+
+        for i in tiers[tier]:
+            candidate = symbols[i] if docs_included else _without_docs(symbols[i])
+            symbol_cost = cost(candidate)
+            if used + symbol_cost <= max_tokens:
+                selected[i] = candidate
+                used += symbol_cost
+                additions.append(("symbol", i))
+
+    # Per-symbol costs ignore separators and headers; undo the least
+    # important additions until the real output fits
+    def kept() -> list[Symbol]:
+        return [selected[i] for i in sorted(selected)]
+
+    while additions and tokenizer.count(formatter.format(kept())) > max_tokens:
+        what, i = additions.pop()
+        if what == "doc":
+            selected[i] = _without_docs(symbols[i])
+        else:
+            del selected[i]
+
+    omitted = len(symbols) - len(selected)
+    logger.debug(f"Budget of {max_tokens} tokens kept {len(selected)} symbols, omitted {omitted}")
+    return kept(), omitted
+
+
+def _tier(symbol: Symbol) -> str:
+    """Classify a symbol into its budget tier."""
+    if not symbol.exported or symbol.kind in TEST_KINDS:
+        return "unexported"
+    if symbol.kind == "func":
+        return "exported_functions"
+    if symbol.kind == "method":
+        return "exported_types" if symbol.receiver_type_name[:1].isupper() else "unexported"
+    return "exported_types"
+
+
+def _without_docs(symbol: Symbol) -> Symbol:
+    """Copy a symbol with doc comments removed from it and its members."""
+    if not (symbol.doc or symbol.fields or symbol.methods):
+        return symbol
+    return replace(
+        symbol,
+        doc="",
+        summary="",
+        fields=[replace(f, doc="", summary="") for f in symbol.fields],
+        methods=[replace(m, doc="", summary="") for m in symbol.methods],
+    )
@@ -0,0 +1,147 @@
+"""
+On-disk cache of extracted symbols.
+
+Entries are keyed by the SHA-256 of a file's contents, so an unchanged file
+is loaded from its serialized symbols instead of being re-parsed, wherever
+it lives. The key also covers the cache schema, the ctxd version, and the
+extractor settings, so upgrading ctxd or changing flags never reads stale
+entries.
+"""
+
+import hashlib
+import json
+import logging
+import os
+import shutil
+import tempfile
+from pathlib import Path
+from typing import Optional
+
+from .models import Symbol
+
+logger = logging.getLogger(__name__)
+
+# Bump whenever extraction output changes, so entries written by an older
+# extractor are not served with fields missing
+SCHEMA_VERSION = 14
+
+
+def default_cache_dir() -> Path:
+    """Get the symbol cache directory: $XDG_CACHE_HOME/ctxd/symbols, or ~/.cache/ctxd/symbols."""
+    base = os.environ.get("XDG_CACHE_HOME") or Path.home() / ".cache"
+    return Path(base) / "ctxd" / "symbols"
+
+
+class SymbolCache:
+    """
+    Content-addressed store of per-file symbol lists.
+
+    Failures to read or write entries are logged and treated as cache
+    misses; the cache never makes extraction fail.
+    """
+
+    def __init__(self, directory: Optional[Path] = None):
+        """
+        Initialize the cache.
+
+        Args:
+            directory: Where entries are stored (defaults to default_cache_dir())
+        """
+        self.directory = Path(directory) if directory else default_cache_dir()
+        self.hits = 0
+        self.misses = 0
+
+    def key(self, content: str, settings: str = "") -> str:
+        """
+        Compute the cache key for a file.
+
+        Args:
+            content: File contents
+            settings: Extractor settings that affect the result
+
+        Returns:
+            Hex SHA-256 digest
+        """
+        from .. import __version__
+
+        sha256 = hashlib.sha256()
+        sha256.update(f"{SCHEMA_VERSION}\0{__version__}\0{settings}\0".encode("utf-8"))
+        sha256.update(content.encode("utf-8"))
+        return sha256.hexdigest()
+
+    def get(self, key: str, path: str) -> Optional[list[Symbol]]:
+        """
+        Load cached symbols.
+
+        Args:
+            key: Cache key from key()
+            path: File path to record on the loaded symbols
+
+        Returns:
+            Symbols, or None on a miss
+        """
+        entry = self._entry_path(key)
+        try:
+            with open(entry, "r", encoding="utf-8") as f:
+                symbols = [Symbol.from_dict(d) for d in json.load(f)]
+        except FileNotFoundError:
+            self.misses += 1
+            return None
+        except (OSError, ValueError, TypeError) as e:
+            logger.debug(f"Ignoring unreadable cache entry {entry}: {e}")
+            self.misses += 1
+            return None
+
+        self.hits += 1
+        # The same contents may have been cached under another path
+        for symbol in symbols:
+            set_file(symbol, path)
+        return symbols
+
+    def put(self, key: str, symbols: list[Symbol]) -> None:
+        """
+        Store symbols under a key.
+
+        Args:
+            key: Cache key from key()
+            symbols: Extracted symbols
+        """
+        entry = self._entry_path(key)
+        try:
+            entry.parent.mkdir(parents=True, exist_ok=True)
+            # Write then rename so that concurrent runs never see a partial entry
+            fd, tmp_path = tempfile.mkstemp(dir=entry.parent, suffix=".tmp")
+            with os.fdopen(fd, "w", encoding="utf-8") as f:
+                json.dump([s.to_dict() for s in symbols], f)
+            os.replace(tmp_path, entry)
+        except OSError as e:
+            logger.debug(f"Failed to write cache entry {entry}: {e}")
+
+    def clear(self) -> int:
+        """
+        Delete every entry.
+
+        Returns:
+            Number of entries removed
+        """
+        if not self.directory.exists():
+            return 0
+        count = sum(1 for _ in self.directory.rglob("*.json"))
+        shutil.rmtree(self.directory)
+        logger.debug(f"Cleared {count} cache entries from {self.directory}")
+        return count
+
+    def _entry_path(self, key: str) -> Path:
+        """Get the file holding an entry, sharded by key prefix."""
+        return self.directory / key[:2] / f"{key}.json"
+
+    def __repr__(self) -> str:
+        """String representation."""
+        return f"SymbolCache(directory={str(self.directory)!r}, hits={self.hits}, misses={self.misses})"
+
+
+def set_file(symbol: Symbol, path: str) -> None:
+    """Set the file of a symbol and its members."""
+    symbol.file = path
+    for member in symbol.fields + symbol.methods:
+        set_file(member, path)
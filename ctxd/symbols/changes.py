@@ -0,0 +1,180 @@
+"""
+Lines changed since a git revision.
+
+Runs `git diff --unified=0 <ref>` over the working tree and reads the new
+line ranges of its hunk headers, so that symbols can be narrowed to the
+declarations touched since that revision. Files git does not track yet
+count as changed throughout.
+"""
+
+import re
+import subprocess
+from dataclasses import dataclass, field
+from pathlib import Path
+from typing import Optional
+
+from ..git_utils import GitUtils
+from .models import Symbol
+
+# New-file side of a hunk header: "@@ -12,3 +14,5 @@ func Add()"
+_HUNK_RE = re.compile(r"^@@ -\d+(?:,\d+)? \+(\d+)(?:,(\d+))? @@")
+
+# Escapes git uses in quoted path names (with core.quotePath off)
+_QUOTED_ESCAPE_RE = re.compile(r"\\(.)")
+_QUOTED_ESCAPES = {"t": "\t", "n": "\n"}
+
+# Seconds to wait for a git command
+_GIT_TIMEOUT = 30
+
+
+class ChangesError(Exception):
+    """git could not report changes, e.g. outside a repository or for an unknown ref."""
+
+
+@dataclass(frozen=True)
+class Hunk:
+    """
+    Lines of a file's new version that differ from the revision.
+
+    Attributes:
+        start: First changed line (1-indexed); for a deletion, the line
+            after which lines were removed
+        count: Number of changed lines, 0 for a pure deletion
+    """
+    start: int
+    count: int
+
+    def overlaps(self, line: int, end_line: int) -> bool:
+        """Check whether the hunk touches lines `line` through `end_line`."""
+        if self.count == 0:
+            # Removed between start and start + 1, so both must be in the span
+            return line <= self.start and self.start + 1 <= end_line
+        return line <= self.start + self.count - 1 and self.start <= end_line
+
+
+@dataclass
+class ChangeSet:
+    """
+    Changed lines of a working tree, by resolved file path.
+
+    Attributes:
+        hunks: Changed line ranges of each modified file
+        new_files: Files absent from the revision or untracked, changed as a whole
+    """
+    hunks: dict[Path, list[Hunk]] = field(default_factory=dict)
+    new_files: set[Path] = field(default_factory=set)
+
+    def touches(self, symbol: Symbol) -> bool:
+        """Check whether a symbol's line span overlaps a change."""
+        file_path = Path(symbol.file).resolve()
+        if file_path in self.new_files:
+            return True
+        end_line = max(symbol.end_line, symbol.line)
+        return any(h.overlaps(symbol.line, end_line) for h in self.hunks.get(file_path, ()))
+
+
+def parse_diff(diff: str) -> dict[str, list[Hunk]]:
+    """
+    Read the hunks of `git diff --unified=0` output.
+
+    Args:
+        diff: Diff text with `a/` and `b/` path prefixes
+
+    Returns:
+        Mapping of each file's path in the new version, as git prints it
+        (relative to the repository root), to its hunks in file order.
+        Deleted files are left out.
+    """
+    hunks: dict[str, list[Hunk]] = {}
+    current = None
+    for line in diff.splitlines():
+        if line.startswith("+++ "):
+            current = _diff_path(line[len("+++ "):])
+            if current is not None:
+                hunks.setdefault(current, [])
+        elif line.startswith("@@") and current is not None:
+            match = _HUNK_RE.match(line)
+            if match:
+                count = int(match.group(2)) if match.group(2) is not None else 1
+                hunks[current].append(Hunk(int(match.group(1)), count))
+    return hunks
+
+
+def changed_since(path: Path, ref: str) -> ChangeSet:
+    """
+    Find the lines changed in a working tree since a git revision.
+
+    Committed, staged, and unstaged edits all count, as do files git does
+    not track yet (unless ignored).
+
+    Args:
+        path: File or directory inside the repository; only changes below
+            it are reported
+        ref: Revision to compare against, e.g. "main" or "HEAD~3"
+
+    Returns:
+        The changed lines
+
+    Raises:
+        ChangesError: If path is not in a git repository, ref does not
+            name a commit, or git fails
+    """
+    path = Path(path).resolve()
+    cwd = path if path.is_dir() else path.parent
+    root = GitUtils.get_git_root(cwd)
+    if root is None:
+        raise ChangesError(f"Not a git repository: {path}")
+    root = root.resolve()
+
+    if ref.startswith("-"):
+        raise ChangesError(f"Invalid git revision: {ref}")
+    try:
+        _git(cwd, "rev-parse", "--verify", "--quiet", f"{ref}^{{commit}}")
+    except ChangesError:
+        raise ChangesError(f"Unknown git revision: {ref}") from None
+
+    pathspec = str(path)
+    # Explicit prefixes, since diff.mnemonicPrefix and diff.noprefix change them
+    diff = _git(
+        cwd, "diff", "--unified=0", "--no-color", "--no-ext-diff", "--find-renames",
+        "--src-prefix=a/", "--dst-prefix=b/", ref, "--", pathspec,
+    )
+    untracked = _git(cwd, "ls-files", "--others", "--exclude-standard", "--full-name", "--", pathspec)
+
+    changes = ChangeSet()
+    for name, file_hunks in parse_diff(diff).items():
+        changes.hunks[root / name] = file_hunks
+    for name in untracked.splitlines():
+        changes.new_files.add(root / name)
+    return changes
+
+
+def _diff_path(text: str) -> Optional[str]:
+    """Get the path of a `+++` line without its `b/` prefix; None for /dev/null."""
+    text = text.rstrip("\t")
+    if text.startswith('"') and text.endswith('"'):
+        # Names with tabs, quotes, or backslashes stay C-quoted
+        text = _QUOTED_ESCAPE_RE.sub(lambda m: _QUOTED_ESCAPES.get(m.group(1), m.group(1)), text[1:-1])
+    if text == "/dev/null":
+        return None
+    return text[len("b/"):] if text.startswith("b/") else text
+
+
+def _git(cwd: Path, *args: str) -> str:
+    """Run a git command and return its stdout; paths are printed unquoted."""
+    try:
+        result = subprocess.run(
+            ["git", "-c", "core.quotePath=false", *args],
+            cwd=cwd,
+            capture_output=True,
+            text=True,
+            timeout=_GIT_TIMEOUT,
+            check=True
+        )
+    except FileNotFoundError:
+        raise ChangesError("git is not installed") from None
+    except subprocess.TimeoutExpired:
+        raise ChangesError(f"git {args[0]} timed out") from None
+    except subprocess.CalledProcessError as e:
+        raise ChangesError(e.stderr.strip() or f"git {args[0]} failed") from None
+    return result.stdout
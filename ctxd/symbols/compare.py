@@ -0,0 +1,132 @@
+"""
+API comparison between two extractions of a package tree.
+
+Packages are matched by import path and symbols by their name within the
+package. Struct fields and interface method sets are compared member by
+member, so removing a field or changing one method shows up as that
+member's change rather than as a change of the whole type. Signatures are
+compared with their types normalized (see normalize.py). Exported
+symbols that gain a `Deprecated:` paragraph are reported on their own.
+"""
+
+from dataclasses import replace
+
+from .filters import filter_exported
+from .index import diff_symbols
+from .models import Symbol, SymbolDiff
+from .normalize import TypeNormalizer
+
+
+def diff_packages(old: dict[str, list[Symbol]], new: dict[str, list[Symbol]]) -> dict[str, SymbolDiff]:
+    """
+    Compare the API of two package trees.
+
+    A symbol counts as modified when its kind or rendered signature
+    changed, unless the signatures only spell the same types differently,
+    such as `interface{}` for `any` or an alias for its target (see
+    normalize.py). Doc comments and positions are ignored, except that
+    exported symbols deprecated in `new` but not in `old` are listed as
+    deprecated.
+
+    Args:
+        old: Import path to symbols, as returned by extract_packages()
+        new: Import path to symbols of the other tree
+
+    Returns:
+        Import path to changes, sorted by import path, for packages with
+        any change. Members are reported with `receiver` set to their type,
+        e.g. "Point.X" as their `local_name`.
+    """
+    old_types = _Signatures([s for symbols in old.values() for s in symbols])
+    new_types = _Signatures([s for symbols in new.values() for s in symbols])
+    diffs = {}
+    for path in sorted(set(old) | set(new)):
+        old_members, new_members = _members(old.get(path, [])), _members(new.get(path, []))
+        diff = diff_symbols(old_members, new_members, signatures_only=True)
+        _drop_respellings(diff, old_types, new_types)
+        diff.deprecated = newly_deprecated(old_members, new_members)
+        if diff:
+            diffs[path] = diff
+    return diffs
+
+
+def breaking_changes(diff: SymbolDiff) -> list[Symbol]:
+    """
+    Get the changes that can break importers of a package.
+
+    These are removals and signature changes of the exported API surface
+    (see filter_exported). Additions are never breaking here, including
+    methods added to an interface.
+
+    Args:
+        diff: Changes of one package from diff_packages()
+
+    Returns:
+        Old versions of removed symbols followed by new versions of
+        modified ones
+    """
+    return filter_exported(diff.removed) + filter_exported(diff.modified)
+
+
+def newly_deprecated(old: list[Symbol], new: list[Symbol]) -> list[Symbol]:
+    """
+    Get the exported symbols that were deprecated between two versions.
+
+    Symbols added already deprecated are not included: they are additions.
+
+    Args:
+        old: Symbols of the previous version
+        new: Symbols of the current version
+
+    Returns:
+        New versions of the deprecated symbols, in new source order
+    """
+    old_by_key = {s.local_name: s for s in old}
+    deprecated = []
+    for symbol in new:
+        previous = old_by_key.get(symbol.local_name)
+        if symbol.deprecated and previous is not None and not previous.deprecated:
+            deprecated.append(symbol)
+    return filter_exported(deprecated)
+
+
+def _drop_respellings(diff: SymbolDiff, old_types: "_Signatures", new_types: "_Signatures") -> None:
+    """Remove the modifications whose signatures normalize the same, in place."""
+    pairs = [
+        (symbol, previous) for symbol, previous in zip(diff.modified, diff.previous)
+        if symbol.kind != previous.kind or new_types.signature(symbol) != old_types.signature(previous)
+    ]
+    diff.modified = [symbol for symbol, _ in pairs]
+    diff.previous = [previous for _, previous in pairs]
+
+
+class _Signatures:
+    """Normalized signatures of a tree's symbols and of the members listed by _members()."""
+
+    def __init__(self, symbols: list[Symbol]):
+        self.types = TypeNormalizer(symbols)
+        self.types_by_name = {(s.package, s.name): s for s in symbols if s.kind in ("struct", "interface")}
+
+    def signature(self, symbol: Symbol) -> str:
+        """Normalize a signature; members use the imports of the type declaring them."""
+        owner = self.types_by_name.get((symbol.package, symbol.receiver))
+        if owner is not None and not symbol.imports:
+            symbol = replace(symbol, imports=owner.imports)
+        return self.types.signature(symbol)
+
+
+def _members(symbols: list[Symbol]) -> list[Symbol]:
+    """List symbols with struct fields and interface methods as top-level entries."""
+    result = []
+    for symbol in symbols:
+        result.append(replace(symbol, fields=[], methods=[]))
+        members = symbol.fields + (symbol.methods if symbol.kind == "interface" else [])
+        for member in members:
+            result.append(replace(
+                member,
+                # Embedded fields are named after their type
+                name=member.name or member.type.lstrip("*"),
+                receiver=symbol.name,
+                package=symbol.package,
+            ))
+    return result
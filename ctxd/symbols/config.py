@@ -0,0 +1,192 @@
+"""
+Project defaults for `ctxd symbols` from a `.ctxd.yaml` file.
+
+The file is looked up in the current directory and then in each parent,
+so a file at the repository root applies to every subdirectory. Keys are
+the Options field names:
+
+    format: markdown
+    exported_only: true
+    recursive: true
+    depth: 2
+    exclude: ["**/*_gen.go", "internal/**"]
+    kinds: [func, interface]
+    name_exclude: ^Test
+    max_tokens: 4000
+    markers: [TODO, FIXME, NOTE]
+    metrics: true
+    complexity_threshold: 15
+    goos: linux
+    jobs: 4
+    path_mode: rel
+
+Command-line flags given explicitly override the file.
+"""
+
+import logging
+from dataclasses import fields as dataclass_fields
+from pathlib import Path
+from typing import Any, Optional
+
+from .annotations import validate_markers
+from .api import Options
+from .constraints import KNOWN_ARCH, KNOWN_OS
+from .filters import NameFilter, validate_kinds
+from .formatters import FORMATTERS
+from .paths import PATH_MODES
+
+logger = logging.getLogger(__name__)
+
+CONFIG_FILENAME = ".ctxd.yaml"
+
+_BOOL_KEYS = {
+    "exported_only", "recursive", "include_tests", "group_methods", "strict", "metrics", "include_source",
+    "comments", "skip_bodies",
+}
+_LIST_KEYS = {"include", "exclude", "kinds", "markers"}
+# Options fields that only make sense per run: `calls` switches the output
+# to a call graph, and the cache is controlled by --no-cache; transformers
+# are Python objects
+_UNSUPPORTED_KEYS = {"calls", "cache", "cache_dir", "transformers"}
+
+
+class ConfigError(ValueError):
+    """Raised for a config file that cannot be read or has invalid settings."""
+
+
+def find_config(start: Optional[Path] = None) -> Optional[Path]:
+    """
+    Find the nearest config file.
+
+    Args:
+        start: Directory to search from, walking up to the filesystem root
+            (defaults to the current directory)
+
+    Returns:
+        Path of the nearest `.ctxd.yaml`, or None
+    """
+    start = Path(start or Path.cwd()).resolve()
+    for directory in [start, *start.parents]:
+        candidate = directory / CONFIG_FILENAME
+        if candidate.is_file():
+            return candidate
+    return None
+
+
+def load_options(path: Optional[Path] = None, start: Optional[Path] = None) -> Options:
+    """
+    Load Options from a config file.
+
+    Args:
+        path: Config file to read; when omitted, the nearest `.ctxd.yaml`
+            from `start` is used, if any
+        start: Directory to search from (defaults to the current directory)
+
+    Returns:
+        Options with the file's settings, and defaults for the rest (all
+        defaults when there is no file)
+
+    Raises:
+        ConfigError: If the file is missing, is not valid YAML, or has an
+            unknown key or a value of the wrong type
+    """
+    if path is None:
+        path = find_config(start)
+        if path is None:
+            logger.debug("No .ctxd.yaml found, using defaults")
+            return Options()
+
+    path = Path(path)
+    try:
+        text = path.read_text(encoding="utf-8")
+    except OSError as e:
+        raise ConfigError(f"Cannot read config file {path}: {e.strerror or e}") from e
+
+    import yaml
+
+    try:
+        data = yaml.safe_load(text)
+    except yaml.YAMLError as e:
+        raise ConfigError(f"Invalid YAML in config file {path}: {e}") from e
+
+    if data is None:
+        data = {}
+    if not isinstance(data, dict):
+        raise ConfigError(f"Invalid config file {path}: expected a mapping of settings, got {type(data).__name__}")
+
+    options = Options(**{key: _check(path, key, value) for key, value in data.items()})
+    logger.debug(f"Loaded symbol options from {path}")
+    return options
+
+
+def _check(path: Path, key: Any, value: Any) -> Any:
+    """Validate one setting and return it in its Options form."""
+    known = {f.name for f in dataclass_fields(Options)} - _UNSUPPORTED_KEYS
+    if key not in known:
+        expected = ", ".join(sorted(known))
+        raise ConfigError(f"Unknown setting {key!r} in config file {path} (expected one of: {expected})")
+
+    if key in _BOOL_KEYS:
+        if not isinstance(value, bool):
+            raise ConfigError(f"Invalid {key} in config file {path}: expected true or false, got {value!r}")
+        return value
+
+    if key in _LIST_KEYS:
+        if key in ("kinds", "markers") and isinstance(value, str):
+            value = [k.strip() for k in value.split(",") if k.strip()]
+        if not isinstance(value, list) or not all(isinstance(v, str) for v in value):
+            raise ConfigError(f"Invalid {key} in config file {path}: expected a list of strings, got {value!r}")
+        if key == "kinds":
+            if not value:
+                raise ConfigError(f"Invalid kinds in config file {path}: expected at least one kind")
+            try:
+                validate_kinds(value)
+            except ValueError as e:
+                raise ConfigError(f"Invalid kinds in config file {path}: {e}") from e
+        if key == "markers":
+            try:
+                validate_markers(value)
+            except ValueError as e:
+                raise ConfigError(f"Invalid markers in config file {path}: {e}") from e
+        return value
+
+    if key in ("goos", "goarch"):
+        known_values = KNOWN_OS if key == "goos" else KNOWN_ARCH
+        if value not in known_values:
+            raise ConfigError(
+                f"Invalid {key} in config file {path}: {value!r} (expected one of: {', '.join(sorted(known_values))})"
+            )
+        return value
+
+    if key == "format":
+        if value not in FORMATTERS:
+            raise ConfigError(
+                f"Invalid format in config file {path}: {value!r} (expected one of: {', '.join(FORMATTERS)})"
+            )
+        return value
+
+    if key == "path_mode":
+        if value not in PATH_MODES:
+            raise ConfigError(
+                f"Invalid path_mode in config file {path}: {value!r} (expected one of: {', '.join(PATH_MODES)})"
+            )
+        return value
+
+    if key in ("name", "name_exclude"):
+        if not isinstance(value, str):
+            raise ConfigError(f"Invalid {key} in config file {path}: expected a regular expression, got {value!r}")
+        try:
+            NameFilter(value)
+        except ValueError as e:
+            raise ConfigError(f"Invalid {key} in config file {path}: {e}") from e
+        return value
+
+    if key == "depth":
+        if isinstance(value, bool) or not isinstance(value, int) or value < 0:
+            raise ConfigError(f"Invalid depth in config file {path}: expected a non-negative integer, got {value!r}")
+        return value
+
+    # max_tokens, complexity_threshold, and jobs
+    if isinstance(value, bool) or not isinstance(value, int) or value < 1:
+        raise ConfigError(f"Invalid {key} in config file {path}: expected a positive integer, got {value!r}")
+    return value
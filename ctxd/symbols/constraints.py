@@ -0,0 +1,308 @@
+"""
+Build constraints of Go files.
+
+Decides whether the go tool would compile a file for a target platform, as
+go/build does: a file is left out when its name ends in a GOOS or GOARCH
+suffix of another platform (`net_windows.go`, `asm_arm64.go`,
+`sys_linux_amd64_test.go`), or when the `//go:build` line of its header
+(or, in files without one, its `// +build` lines) does not hold.
+
+Tags satisfied by a BuildContext are its GOOS and GOARCH, "unix" on Unix
+systems, "gc", "cgo" when cgo is enabled, and every "go1.N" release tag,
+since a current toolchain is assumed. As with go/build, "android" also
+satisfies "linux", "illumos" satisfies "solaris", and "ios" satisfies
+"darwin". Any other tag, such as "ignore", is never satisfied.
+"""
+
+import logging
+import os
+import platform
+import re
+from dataclasses import dataclass
+from typing import Callable, Optional
+
+logger = logging.getLogger(__name__)
+
+# From go/build's syslist.go
+KNOWN_OS = frozenset({
+    "aix", "android", "darwin", "dragonfly", "freebsd", "hurd", "illumos", "ios", "js", "linux",
+    "nacl", "netbsd", "openbsd", "plan9", "solaris", "wasip1", "windows", "zos",
+})
+UNIX_OS = frozenset({
+    "aix", "android", "darwin", "dragonfly", "freebsd", "hurd", "illumos", "ios", "linux",
+    "netbsd", "openbsd", "solaris",
+})
+KNOWN_ARCH = frozenset({
+    "386", "amd64", "amd64p32", "arm", "armbe", "arm64", "arm64be", "loong64", "mips", "mipsle",
+    "mips64", "mips64le", "mips64p32", "mips64p32le", "ppc", "ppc64", "ppc64le", "riscv", "riscv64",
+    "s390", "s390x", "sparc", "sparc64", "wasm",
+})
+
+# GOOS values that also satisfy another tag
+_IMPLIED_OS = {"android": "linux", "illumos": "solaris", "ios": "darwin"}
+
+# platform.machine() names that differ from GOARCH
+_HOST_ARCH = {
+    "x86_64": "amd64", "aarch64": "arm64", "i386": "386", "i686": "386", "x86": "386",
+    "armv6l": "arm", "armv7l": "arm", "loongarch64": "loong64",
+}
+
+_RELEASE_TAG_RE = re.compile(r"^go1\.\d+$")
+_TOKEN_RE = re.compile(r"\s*(\(|\)|!|&&|\|\||[A-Za-z0-9_.]+)")
+_GO_BUILD_RE = re.compile(r"^//go:build(?:[ \t]|$)")
+_PLUS_BUILD_RE = re.compile(r"^//\s*\+build(?:[ \t]|$)")
+
+
+class ConstraintError(ValueError):
+    """Raised for a build constraint line that cannot be parsed."""
+
+
+@dataclass(frozen=True)
+class BuildContext:
+    """
+    Target platform that build constraints are evaluated for.
+
+    Attributes:
+        goos: Target operating system, e.g. "linux"
+        goarch: Target architecture, e.g. "amd64"
+        cgo: Whether the "cgo" tag is satisfied
+    """
+    goos: str
+    goarch: str
+    cgo: bool = False
+
+    @classmethod
+    def default(cls, goos: Optional[str] = None, goarch: Optional[str] = None) -> "BuildContext":
+        """
+        Get the context for a target, defaulting to the go tool's own.
+
+        Unset values come from $GOOS and $GOARCH, then from the host. cgo
+        is enabled as $CGO_ENABLED says, or else when building for the host.
+
+        Args:
+            goos: Target operating system
+            goarch: Target architecture
+
+        Returns:
+            The build context
+
+        Raises:
+            ValueError: If goos or goarch is not a known value
+        """
+        host_os, host_arch = _host()
+        goos = goos or os.environ.get("GOOS") or host_os
+        goarch = goarch or os.environ.get("GOARCH") or host_arch
+        if goos not in KNOWN_OS:
+            raise ValueError(f"Unknown GOOS: {goos!r} (expected one of: {', '.join(sorted(KNOWN_OS))})")
+        if goarch not in KNOWN_ARCH:
+            raise ValueError(f"Unknown GOARCH: {goarch!r} (expected one of: {', '.join(sorted(KNOWN_ARCH))})")
+
+        cgo_enabled = os.environ.get("CGO_ENABLED")
+        if cgo_enabled in ("0", "1"):
+            cgo = cgo_enabled == "1"
+        else:
+            cgo = (goos, goarch) == (host_os, host_arch)
+        return cls(goos=goos, goarch=goarch, cgo=cgo)
+
+    def matches_tag(self, tag: str) -> bool:
+        """Check whether a build tag is satisfied."""
+        if tag in (self.goos, self.goarch, "gc"):
+            return True
+        if tag == "cgo":
+            return self.cgo
+        if tag == "unix":
+            return self.goos in UNIX_OS
+        if _IMPLIED_OS.get(self.goos) == tag:
+            return True
+        return bool(_RELEASE_TAG_RE.match(tag))
+
+    def matches_filename(self, name: str) -> bool:
+        """
+        Check a file name's GOOS and GOARCH suffixes, ignoring `_test`.
+
+        As with the go tool, the part before the first underscore is never
+        a suffix, so "linux.go" has no constraint but "net_linux.go" does.
+        """
+        stem = name[:-len(".go")] if name.endswith(".go") else name
+        parts = stem.split("_")[1:]
+        if parts and parts[-1] == "test":
+            parts.pop()
+        if len(parts) >= 2 and parts[-2] in KNOWN_OS and parts[-1] in KNOWN_ARCH:
+            return self.matches_tag(parts[-2]) and self.matches_tag(parts[-1])
+        if parts and (parts[-1] in KNOWN_OS or parts[-1] in KNOWN_ARCH):
+            return self.matches_tag(parts[-1])
+        return True
+
+    def matches_source(self, content: str) -> bool:
+        """
+        Check the build constraint lines of a file's header.
+
+        A file whose constraint cannot be parsed is kept, so that its
+        declarations are still extracted.
+        """
+        go_build, plus_build = _constraint_lines(content)
+        try:
+            if go_build is not None:
+                return parse_expr(go_build[len("//go:build"):])(self.matches_tag)
+            return all(_parse_plus_build(line)(self.matches_tag) for line in plus_build)
+        except ConstraintError as e:
+            logger.debug(f"Ignoring invalid build constraint: {e}")
+            return True
+
+
+def parse_expr(text: str) -> Callable[[Callable[[str], bool]], bool]:
+    """
+    Parse a `//go:build` expression such as "linux && (amd64 || arm64)".
+
+    Args:
+        text: Expression after `//go:build`
+
+    Returns:
+        Function evaluating the expression given a tag predicate
+
+    Raises:
+        ConstraintError: If the expression is malformed
+    """
+    tokens = []
+    position = 0
+    text = text.rstrip()
+    while position < len(text):
+        match = _TOKEN_RE.match(text, position)
+        if not match:
+            raise ConstraintError(f"Unexpected {text[position:].strip()!r} in //go:build {text.strip()}")
+        tokens.append(match.group(1))
+        position = match.end()
+    if not tokens:
+        raise ConstraintError("Empty //go:build line")
+
+    parser = _ExprParser(tokens, text.strip())
+    expr = parser.parse_or()
+    if parser.position != len(tokens):
+        raise ConstraintError(f"Unexpected {tokens[parser.position]!r} in //go:build {text.strip()}")
+    return expr
+
+
+class _ExprParser:
+    """Recursive descent over `//go:build` tokens; && binds tighter than ||."""
+
+    def __init__(self, tokens: list[str], text: str):
+        """Start parsing tokens of an expression; text is used in error messages."""
+        self.tokens = tokens
+        self.text = text
+        self.position = 0
+
+    def parse_or(self) -> Callable[[Callable[[str], bool]], bool]:
+        """Parse `a || b || ...`."""
+        terms = [self.parse_and()]
+        while self._accept("||"):
+            terms.append(self.parse_and())
+        return terms[0] if len(terms) == 1 else lambda match: any(term(match) for term in terms)
+
+    def parse_and(self) -> Callable[[Callable[[str], bool]], bool]:
+        """Parse `a && b && ...`."""
+        terms = [self.parse_not()]
+        while self._accept("&&"):
+            terms.append(self.parse_not())
+        return terms[0] if len(terms) == 1 else lambda match: all(term(match) for term in terms)
+
+    def parse_not(self) -> Callable[[Callable[[str], bool]], bool]:
+        """Parse a negation, a parenthesized expression, or a tag."""
+        if self._accept("!"):
+            term = self.parse_not()
+            return lambda match: not term(match)
+        if self._accept("("):
+            expr = self.parse_or()
+            if not self._accept(")"):
+                raise ConstraintError(f"Missing ) in //go:build {self.text}")
+            return expr
+        token = self._next()
+        if token is None or not _is_tag(token):
+            raise ConstraintError(f"Expected a tag in //go:build {self.text}")
+        return lambda match: match(token)
+
+    def _accept(self, token: str) -> bool:
+        """Consume the next token if it is `token`."""
+        if self.position < len(self.tokens) and self.tokens[self.position] == token:
+            self.position += 1
+            return True
+        return False
+
+    def _next(self) -> Optional[str]:
+        """Consume and return the next token, or None at the end."""
+        if self.position >= len(self.tokens):
+            return None
+        self.position += 1
+        return self.tokens[self.position - 1]
+
+
+def _parse_plus_build(line: str) -> Callable[[Callable[[str], bool]], bool]:
+    """Parse a `// +build` line: space-separated options, each comma-separated terms."""
+    options = []
+    for option in line.split("+build", 1)[1].split():
+        terms = []
+        for term in option.split(","):
+            negated = term.startswith("!")
+            tag = term[1:] if negated else term
+            if not _is_tag(tag):
+                raise ConstraintError(f"Invalid term {term!r} in {line}")
+            terms.append((tag, negated))
+        options.append(terms)
+    return lambda match: any(all(match(tag) != negated for tag, negated in terms) for terms in options)
+
+
+def _constraint_lines(content: str) -> tuple[Optional[str], list[str]]:
+    """
+    Find the constraint lines of a file header, as go/build does.
+
+    The header is the run of comments and blank lines before the first
+    other text. A `//go:build` line counts anywhere in it, but `// +build`
+    lines only before its last blank line, so that they are not part of
+    the package doc comment.
+
+    Returns:
+        The `//go:build` line if any, and the `// +build` lines
+    """
+    go_build = None
+    plus_build: list[str] = []
+    candidates: list[str] = []
+    in_block = False
+    for raw in content.splitlines():
+        line = raw.strip()
+        if in_block:
+            if "*/" not in line:
+                continue
+            in_block = False
+            line = line.split("*/", 1)[1].strip()
+            if not line:
+                continue
+        if not line:
+            plus_build.extend(candidates)
+            candidates = []
+            continue
+        if line.startswith("//"):
+            if _GO_BUILD_RE.match(line):
+                if go_build is not None:
+                    logger.debug("Ignoring extra //go:build line")
+                else:
+                    go_build = line
+            elif _PLUS_BUILD_RE.match(line):
+                candidates.append(line)
+            continue
+        if line.startswith("/*"):
+            in_block = "*/" not in line[2:]
+            if in_block or not line.split("*/", 1)[1].strip():
+                continue
+        break
+    return go_build, plus_build
+
+
+def _is_tag(token: str) -> bool:
+    """Check whether a token is a build tag name."""
+    return bool(token) and all(c.isalnum() or c in "_." for c in token)
+
+
+def _host() -> tuple[str, str]:
+    """Get the GOOS and GOARCH of the machine running ctxd."""
+    system = platform.system().lower()
+    machine = platform.machine().lower()
+    return system, _HOST_ARCH.get(machine, machine)
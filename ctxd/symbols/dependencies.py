@@ -0,0 +1,167 @@
+"""
+Context slices: a declaration together with the declarations it uses.
+
+gather_context() starts from one symbol and follows the declarations it
+names directly, level by level up to a depth:
+
+- types written in its declaration: parameter and result types, field and
+  embedded types, and the receiver type of a method
+- types named in its body, as in composite literals (`Calculator{}`),
+  conversions, `new(T)`, and local variable declarations
+- functions and methods it calls, as call extraction names them, so calls
+  through a method's receiver resolve but calls on other values do not
+
+Names resolve against the symbols given: bare names within the symbol's
+own package, qualified names (`geo.Point`) through the package names of
+the imports it uses. Anything else, such as the standard library or
+packages outside the tree, is left out. The slice comes back in dependency
+order, each declaration after those it references, so it reads top to
+bottom and ends with the symbol it was gathered for.
+"""
+
+from typing import Iterable, Optional
+
+from tree_sitter import Node
+
+from .imports import assumed_package_name
+from .models import Symbol
+from .normalize import PREDECLARED_TYPES, type_parameters
+from .parsing import FRAGMENT_PREFIX, fragment_parser, node_text
+
+# Builtins whose first argument is a type, which parses as an expression
+_TYPE_ARGUMENT_BUILTINS = {"new"}
+
+
+def gather_context(symbols: Iterable[Symbol], target: Symbol, depth: int = 1) -> list[Symbol]:
+    """
+    Gather a symbol and its transitive dependencies among a set of symbols.
+
+    The symbols must have been extracted with call extraction and source
+    text enabled; without them, only the types of the signature resolve.
+
+    Args:
+        symbols: Symbols to resolve names against, possibly spanning
+            several packages
+        target: Symbol to gather the context of
+        depth: Levels of references to follow; 0 gathers just the target
+
+    Returns:
+        The target and the declarations it reaches, dependencies first
+
+    Raises:
+        ValueError: If depth is negative
+    """
+    if depth < 0:
+        raise ValueError(f"Invalid depth: {depth} (expected at least 0)")
+
+    resolver = _Resolver(symbols)
+    gathered = {id(target): target}
+    frontier = [target]
+    for _ in range(depth):
+        reached = []
+        for symbol in frontier:
+            for dependency in resolver.references(symbol):
+                if id(dependency) not in gathered:
+                    gathered[id(dependency)] = dependency
+                    reached.append(dependency)
+        frontier = reached
+
+    # Post-order over the gathered declarations, visiting references in
+    # source order; a reference back to a declaration on the stack is a
+    # cycle and is not followed
+    ordered: list[Symbol] = []
+    visited = {id(target)}
+    stack = [(target, iter(resolver.references(target)))]
+    while stack:
+        symbol, pending = stack[-1]
+        for dependency in pending:
+            if id(dependency) in gathered and id(dependency) not in visited:
+                visited.add(id(dependency))
+                stack.append((dependency, iter(resolver.references(dependency))))
+                break
+        else:
+            stack.pop()
+            ordered.append(symbol)
+    return ordered
+
+
+def referenced_names(symbol: Symbol) -> list[str]:
+    """
+    List the names of the declarations a symbol uses, for gather_context().
+
+    Args:
+        symbol: A symbol extracted with its source text, and with call
+            extraction for the callees of functions and methods
+
+    Returns:
+        Type names in source order ("Calculator", "geo.Point"), then
+        callees ("NewCalculator", "Calculator.Add"), without repeats; the
+        symbol's own name, its type parameters, and predeclared types are
+        left out
+    """
+    source = (FRAGMENT_PREFIX + (symbol.source or symbol.signature)).encode("utf8")
+    own = set(type_parameters(symbol)) | PREDECLARED_TYPES | {symbol.name}
+    names: list[str] = []
+    stack = [fragment_parser().parse(source).root_node]
+    while stack:
+        node = stack.pop()
+        name = ""
+        if node.type == "type_identifier":
+            name = node_text(node, source)
+        elif node.type == "qualified_type":
+            name = f"{node_text(node.child_by_field_name('package'), source)}.{node_text(node.child_by_field_name('name'), source)}"
+        elif node.type == "call_expression":
+            name = _type_argument(node, source)
+        if name and name not in own and name not in names:
+            names.append(name)
+        if node.type != "qualified_type":
+            # Reversed so the stack visits children in source order
+            stack.extend(reversed(node.named_children))
+    names.extend(callee for callee in symbol.calls if callee not in names)
+    return names
+
+
+class _Resolver:
+    """Resolves the names a symbol uses to the declarations they denote, remembering each symbol's."""
+
+    def __init__(self, symbols: Iterable[Symbol]):
+        self.declarations: dict[tuple[str, str], Symbol] = {}
+        for symbol in symbols:
+            if symbol.kind != "field":
+                self.declarations.setdefault((symbol.package, symbol.local_name), symbol)
+        self.resolved: dict[int, list[Symbol]] = {}
+
+    def references(self, symbol: Symbol) -> list[Symbol]:
+        """Get the declarations a symbol references directly, in the order it names them."""
+        if id(symbol) not in self.resolved:
+            references: list[Symbol] = []
+            for name in referenced_names(symbol):
+                dependency = self.resolve(name, symbol)
+                if dependency is not None and dependency is not symbol and all(r is not dependency for r in references):
+                    references.append(dependency)
+            self.resolved[id(symbol)] = references
+        return self.resolved[id(symbol)]
+
+    def resolve(self, name: str, symbol: Symbol) -> Optional[Symbol]:
+        """Find the declaration a name used by a symbol denotes, if it is among the symbols."""
+        local = self.declarations.get((symbol.package, name))
+        if local is not None:
+            return local
+        qualifier, _, rest = name.partition(".")
+        if rest:
+            for path in symbol.imports:
+                if assumed_package_name(path) == qualifier:
+                    return self.declarations.get((path, rest))
+        return None
+
+
+def _type_argument(call: Node, source: bytes) -> str:
+    """Get the type a call such as `new(Calculator)` names, or ""."""
+    function = call.child_by_field_name("function")
+    arguments = call.child_by_field_name("arguments")
+    if function is None or arguments is None or node_text(function, source) not in _TYPE_ARGUMENT_BUILTINS:
+        return ""
+    argument = next(iter(arguments.named_children), None)
+    if argument is not None and argument.type in ("identifier", "selector_expression"):
+        return node_text(argument, source)
+    return ""
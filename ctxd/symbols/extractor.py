@@ -0,0 +1,1192 @@
+"""
+Go symbol extraction using tree-sitter.
+
+Walks the top-level declarations of a Go source file and extracts
+functions, methods, types, constants, and variables as Symbols with
+signatures rendered back from the AST.
+"""
+
+import ast
+import logging
+import re
+from pathlib import Path
+from typing import Any, Optional
+from tree_sitter import Parser, Node
+
+from ..chunkers.treesitter import TreeSitterChunker
+from .cache import SymbolCache
+from .filters import filter_exported
+from .imports import FileImports
+from .metrics import code_lines, cyclomatic_complexity, statement_count
+from .models import ParseError, Symbol
+from .resolve import find_implementations, flatten_interfaces, link_examples
+from .unused import referenced_names
+
+logger = logging.getLogger(__name__)
+
+# Predeclared functions; calls to these are not call graph edges
+GO_BUILTINS = {
+    "append", "cap", "clear", "close", "complex", "copy", "delete", "imag",
+    "len", "make", "max", "min", "new", "panic", "print", "println", "real",
+    "recover",
+}
+
+
+# Directive comments such as "//go:generate stringer -type=Kind"; with a
+# space after the slashes the line is an ordinary comment
+DIRECTIVE_RE = re.compile(r"^//go:\w")
+
+# Functions `go test` runs: name prefix, kind, and the function type
+# required, with "{}" standing for the testing package's qualifier
+_TEST_FUNCTIONS = (
+    ("Test", "test", "func(*{}T)"),
+    ("Benchmark", "benchmark", "func(*{}B)"),
+    ("Example", "example", "func()"),
+)
+
+# Default types of untyped literals, for inferring variable types
+_LITERAL_TYPES = {
+    "int_literal": "int",
+    "float_literal": "float64",
+    "imaginary_literal": "complex128",
+    "rune_literal": "rune",
+    "interpreted_string_literal": "string",
+    "raw_string_literal": "string",
+}
+
+_INT_BINOPS = {
+    ast.Add: lambda a, b: a + b,
+    ast.Sub: lambda a, b: a - b,
+    ast.Mult: lambda a, b: a * b,
+    ast.Mod: lambda a, b: a % b,
+    ast.LShift: lambda a, b: a << b,
+    ast.RShift: lambda a, b: a >> b,
+    ast.BitAnd: lambda a, b: a & b,
+    ast.BitOr: lambda a, b: a | b,
+    ast.BitXor: lambda a, b: a ^ b,
+}
+
+
+def _eval_int(expression: str) -> Optional[int]:
+    """
+    Evaluate an integer constant expression such as "1 << (10 * 2)".
+
+    Only integer literals, parentheses, and operators with the same meaning
+    in Go and Python are accepted; anything else returns None.
+    """
+    try:
+        tree = ast.parse(expression, mode="eval")
+    except SyntaxError:
+        return None
+
+    def evaluate(node: ast.AST) -> int:
+        if isinstance(node, ast.Constant) and type(node.value) is int:
+            return node.value
+        if isinstance(node, ast.BinOp) and type(node.op) in _INT_BINOPS:
+            return _INT_BINOPS[type(node.op)](evaluate(node.left), evaluate(node.right))
+        if isinstance(node, ast.BinOp) and isinstance(node.op, ast.Div):
+            left, right = evaluate(node.left), evaluate(node.right)
+            # Go integer division truncates toward zero
+            return int(left / right) if right else _fail()
+        if isinstance(node, ast.UnaryOp) and isinstance(node.op, (ast.USub, ast.UAdd)):
+            value = evaluate(node.operand)
+            return -value if isinstance(node.op, ast.USub) else value
+        return _fail()
+
+    def _fail() -> int:
+        raise ValueError("not an integer constant expression")
+
+    try:
+        return evaluate(tree.body)
+    except (ValueError, OverflowError, MemoryError):
+        return None
+
+
+class GoSyntaxError(ValueError):
+    """
+    Raised in strict mode for a file that does not parse cleanly.
+
+    Attributes:
+        error: The first syntax error in the file
+    """
+
+    def __init__(self, error: ParseError):
+        super().__init__(str(error))
+        self.error = error
+
+
+class GoSymbolExtractor:
+    """
+    Extracts Go declarations as structured symbols.
+
+    Signatures are rebuilt from the AST rather than copied from the source,
+    so bodies are dropped while type parameter lists (including constraints
+    such as ``comparable`` or ``~int | ~string``) are preserved verbatim.
+
+    Files with syntax errors yield the declarations tree-sitter recovers,
+    and their errors are appended to `errors`.
+    """
+
+    def __init__(
+        self,
+        exported_only: bool = False,
+        calls: bool = False,
+        cache: Optional[SymbolCache] = None,
+        strict: bool = False,
+        metrics: bool = False,
+        include_source: bool = False,
+        comments: bool = True,
+        skip_bodies: bool = False
+    ):
+        """
+        Initialize the extractor with a Go tree-sitter parser.
+
+        Args:
+            exported_only: Drop unexported symbols, fields, and methods on
+                unexported types
+            calls: Record the callees of each function and method body
+            cache: Reuse the symbols of unchanged files in extract_file
+            strict: Raise GoSyntaxError instead of extracting from a file
+                with syntax errors
+            metrics: Record the cyclomatic complexity and lines of code of
+                each function and method body
+            include_source: Record the source text of each declaration
+            comments: Record doc comments and directives, like go/parser's
+                ParseComments mode
+            skip_bodies: Do not look inside function and method bodies, for
+                signatures only; calls and metrics are then not recorded,
+                and imports only used in bodies are not listed
+        """
+        self.exported_only = exported_only
+        self.skip_bodies = skip_bodies
+        # Both come from bodies
+        self.calls = calls and not skip_bodies
+        self.cache = cache
+        self.strict = strict
+        self.metrics = metrics and not skip_bodies
+        self.include_source = include_source
+        self.comments = comments
+        self.errors: list[ParseError] = []
+
+        # Reuse the chunker's lazy language cache so Go is only loaded once
+        self.parser = Parser(TreeSitterChunker._get_language("go"))
+
+    def extract(self, content: str, path: str) -> list[Symbol]:
+        """
+        Extract symbols from Go source code.
+
+        Args:
+            content: The Go source code
+            path: File path recorded on each symbol
+
+        Returns:
+            List of symbols in source order
+
+        Raises:
+            GoSyntaxError: In strict mode, if the source has syntax errors
+        """
+        if not content.strip():
+            return []
+
+        source = bytes(content, "utf8")
+        tree = self.parser.parse(source)
+        root_node = tree.root_node
+
+        # Kept for converting byte offsets to character columns and slicing
+        # declarations out of the source
+        self._source = source
+        self._source_lines = source.split(b"\n")
+        self._imports = FileImports(root_node, self._top_level_names(root_node))
+
+        if root_node.has_error:
+            errors = self._syntax_errors(root_node, path)
+            if self.strict:
+                raise GoSyntaxError(errors[0])
+            self.errors.extend(errors)
+            logger.debug(f"{len(errors)} parse errors in {path}, first at line {errors[0].line}, extracting symbols anyway")
+
+        symbols = []
+        for node in root_node.children:
+            if node.type == "function_declaration":
+                symbols.append(self._extract_function(node, path))
+            elif node.type == "method_declaration":
+                symbols.append(self._extract_method(node, path))
+            elif node.type == "type_declaration":
+                for spec in node.named_children:
+                    if spec.type == "type_spec":
+                        symbols.append(self._extract_type(spec, node, path))
+                    elif spec.type == "type_alias":
+                        symbols.append(self._extract_alias(spec, node, path))
+            elif node.type == "const_declaration":
+                symbols.extend(self._extract_consts(node, path))
+            elif node.type == "var_declaration":
+                symbols.extend(self._extract_vars(node, path))
+
+        flatten_interfaces(symbols)
+        find_implementations(symbols)
+        link_examples(symbols)
+
+        if self.exported_only:
+            symbols = filter_exported(symbols)
+
+        logger.debug(f"Extracted {len(symbols)} symbols from {path}")
+        return symbols
+
+    def extract_file(self, path: Path) -> list[Symbol]:
+        """
+        Extract symbols from a Go file on disk.
+
+        Args:
+            path: Path to the Go file
+
+        Returns:
+            List of symbols in source order
+        """
+        with open(path, "r", encoding="utf-8", errors="ignore") as f:
+            content = f.read()
+        return self.extract_cached(content, str(path))
+
+    def extract_cached(self, content: str, path: str) -> list[Symbol]:
+        """
+        Extract symbols, reusing the cache when one is configured.
+
+        Args:
+            content: The Go source code
+            path: File path recorded on each symbol; need not exist
+
+        Returns:
+            List of symbols in source order
+        """
+        if self.cache is None:
+            return self.extract(content, path)
+
+        key = self.cache_key(content)
+        symbols = self.cache.get(key, path)
+        if symbols is None:
+            error_count = len(self.errors)
+            symbols = self.extract(content, path)
+            # Files with errors are re-parsed so that their errors are reported again
+            if len(self.errors) == error_count:
+                self.cache.put(key, symbols)
+        return symbols
+
+    def cache_key(self, content: str) -> str:
+        """Get the cache key of a file's contents under this extractor's settings."""
+        return self.cache.key(content, (
+            f"exported_only={self.exported_only},calls={self.calls},"
+            f"metrics={self.metrics},include_source={self.include_source},"
+            f"comments={self.comments},skip_bodies={self.skip_bodies}"
+        ))
+
+    def count_lines(self, content: str) -> tuple[int, int]:
+        """
+        Count the code lines and statements of a whole file.
+
+        Args:
+            content: The Go source code
+
+        Returns:
+            (code lines, statements and declarations), as described in
+            ctxd.symbols.metrics
+        """
+        root = self.parser.parse(content.encode("utf8")).root_node
+        # Not the root's own span, which includes trailing blank lines
+        return code_lines(root, inner=True), statement_count(root)
+
+    def references(self, content: str) -> set[str]:
+        """
+        Collect the names a file references, as ctxd.symbols.unused describes.
+
+        Args:
+            content: The Go source code
+
+        Returns:
+            Names used outside their own declaration, or kept alive by a
+            directive or string literal
+        """
+        source = content.encode("utf8")
+        return referenced_names(self.parser.parse(source).root_node, source)
+
+    # ===== Declaration extractors =====
+
+    def _extract_function(self, node: Node, path: str) -> Symbol:
+        """Extract a package-level function declaration."""
+        name = self._text(node.child_by_field_name("name"))
+        type_params = self._render_type_params(node.child_by_field_name("type_parameters"))
+        signature = (
+            f"func {name}{type_params}"
+            f"{self._render_signature_tail(node)}"
+        )
+        doc = self._doc_comment(node)
+        func_type = self._render_func_type(node)
+        return Symbol(
+            name=name,
+            kind=self._function_kind(node, name, func_type, path),
+            file=path,
+            **self._span(node),
+            **self._name_position(node.child_by_field_name("name")),
+            signature=signature,
+            doc=doc,
+            summary=self._summary(doc, name),
+            **self._deprecation(doc),
+            directives=self._directives(node),
+            exported=self._is_exported(name),
+            type=func_type,
+            calls=self._extract_calls(node.child_by_field_name("body")),
+            complexity=self._complexity(node),
+            **self._line_counts(node),
+            imports=self._imports.used_by(node, bodies=not self.skip_bodies),
+        )
+
+    def _function_kind(self, node: Node, name: str, func_type: str, path: str) -> str:
+        """
+        Classify a package-level function as `go test` does.
+
+        In a `_test.go` file, TestXxx(*testing.T), BenchmarkXxx(*testing.B),
+        and ExampleXxx() are run by `go test`, provided they have no type
+        parameters. The prefix may be the whole name or be followed by
+        anything but a lowercase letter, so Test_add is a test and Testify
+        is not.
+
+        Returns:
+            "test", "benchmark", "example", or "func" for any other function
+        """
+        if not path.endswith("_test.go") or node.child_by_field_name("type_parameters") is not None:
+            return "func"
+        qualifiers = [f"{alias}." for alias, import_path in self._imports.by_name.items() if import_path == "testing"]
+        if "testing" in self._imports.dot_imports:
+            qualifiers.append("")
+        for prefix, kind, required in _TEST_FUNCTIONS:
+            rest = name[len(prefix):]
+            if not name.startswith(prefix) or rest[:1].islower():
+                continue
+            if func_type in ({required.format(q) for q in qualifiers} if "{}" in required else {required}):
+                return kind
+        return "func"
+
+    def _extract_method(self, node: Node, path: str) -> Symbol:
+        """Extract a method declaration, including its receiver."""
+        name = self._text(node.child_by_field_name("name"))
+        receiver_list = node.child_by_field_name("receiver")
+        signature = (
+            f"func {self._render_parameters(receiver_list)} {name}"
+            f"{self._render_signature_tail(node)}"
+        )
+        doc = self._doc_comment(node)
+        receiver = self._receiver_type(receiver_list)
+        return Symbol(
+            name=name,
+            kind="method",
+            file=path,
+            **self._span(node),
+            **self._name_position(node.child_by_field_name("name")),
+            signature=signature,
+            doc=doc,
+            summary=self._summary(doc, name),
+            **self._deprecation(doc),
+            directives=self._directives(node),
+            receiver=receiver,
+            pointer_receiver=receiver.startswith("*"),
+            receiver_mode="pointer" if receiver.startswith("*") else "value",
+            exported=self._is_exported(name),
+            type=self._render_func_type(node),
+            calls=self._extract_calls(node.child_by_field_name("body"), receiver_list),
+            complexity=self._complexity(node),
+            **self._line_counts(node),
+            imports=self._imports.used_by(node, bodies=not self.skip_bodies),
+        )
+
+    def _extract_type(self, spec: Node, decl: Node, path: str) -> Symbol:
+        """
+        Extract a type specification.
+
+        Args:
+            spec: The type_spec node
+            decl: The enclosing type_declaration node
+            path: File path recorded on the symbol
+        """
+        name = self._text(spec.child_by_field_name("name"))
+        type_params = self._render_type_params(spec.child_by_field_name("type_parameters"))
+        type_node = spec.child_by_field_name("type")
+
+        fields: list[Symbol] = []
+        methods: list[Symbol] = []
+        embeds: list[str] = []
+        if type_node.type == "struct_type":
+            kind, underlying = "struct", "struct"
+            fields = self._extract_fields(type_node, path)
+        elif type_node.type == "interface_type":
+            kind, underlying = "interface", "interface"
+            methods, embeds = self._extract_interface_elements(type_node, path)
+        else:
+            kind, underlying = "type", self._render_type(type_node)
+
+        # Ungrouped declarations (`type X struct`) carry the doc comment on the
+        # type_declaration; grouped ones (`type ( ... )`) carry it on the spec
+        anchor = decl if self._is_single_spec(decl) else spec
+        doc = self._doc_comment(anchor)
+
+        return Symbol(
+            name=name,
+            kind=kind,
+            file=path,
+            **self._span(anchor),
+            **self._name_position(spec.child_by_field_name("name")),
+            signature=f"type {name}{type_params} {underlying}",
+            doc=doc,
+            summary=self._summary(doc, name),
+            **self._deprecation(doc),
+            directives=self._directives(anchor),
+            exported=self._is_exported(name),
+            fields=fields,
+            methods=methods,
+            embeds=embeds,
+            imports=self._imports.used_by(spec),
+        )
+
+    def _extract_alias(self, spec: Node, decl: Node, path: str) -> Symbol:
+        """
+        Extract a type alias (`type Foo = Bar`).
+
+        Aliases denote the target type itself rather than a new type, so they
+        get kind "alias" and record the target in `type`; they have no
+        fields or method set of their own.
+        """
+        name = self._text(spec.child_by_field_name("name"))
+        type_params = self._render_type_params(spec.child_by_field_name("type_parameters"))
+        target = self._render_type(spec.child_by_field_name("type"))
+
+        anchor = decl if self._is_single_spec(decl) else spec
+        doc = self._doc_comment(anchor)
+
+        return Symbol(
+            name=name,
+            kind="alias",
+            file=path,
+            **self._span(anchor),
+            **self._name_position(spec.child_by_field_name("name")),
+            signature=f"type {name}{type_params} = {target}",
+            doc=doc,
+            summary=self._summary(doc, name),
+            **self._deprecation(doc),
+            directives=self._directives(anchor),
+            exported=self._is_exported(name),
+            type=target,
+            imports=self._imports.used_by(spec),
+        )
+
+    def _extract_consts(self, decl: Node, path: str) -> list[Symbol]:
+        """
+        Extract the constants of a const_declaration, one symbol per name.
+
+        Specs without a value repeat the previous spec's type and
+        expressions, as Go does, and `iota` takes the spec's index in the
+        block. Integer expressions of `iota` are evaluated ("1 << iota"
+        becomes "4" for the third spec); other expressions keep their source
+        text with `iota` replaced by its value. Untyped constants keep
+        their literal as written and an empty `type`.
+        """
+        symbols: list[Symbol] = []
+        specs = [c for c in decl.named_children if c.type == "const_spec"]
+        anchor_decl = len(specs) == 1 and self._is_single_spec(decl)
+        type_text, values = "", []
+
+        for iota, spec in enumerate(specs):
+            value_list = spec.child_by_field_name("value")
+            if value_list is not None:
+                type_text = self._render_type(spec.child_by_field_name("type"))
+                values = [self._collapse(v) for v in value_list.named_children]
+
+            anchor = decl if anchor_decl else spec
+            doc = self._doc_comment(anchor)
+            name_nodes = spec.children_by_field_name("name")
+
+            for i, name_node in enumerate(name_nodes):
+                name = self._text(name_node)
+                if name == "_":
+                    continue
+                value = self._const_value(values[i], iota) if i < len(values) else ""
+                signature = f"const {name} {type_text}".rstrip()
+                if value:
+                    signature += f" = {value}"
+                symbols.append(Symbol(
+                    name=name,
+                    kind="const",
+                    file=path,
+                    **self._span(anchor),
+                    **self._name_position(name_node),
+                    signature=signature,
+                    doc=doc,
+                    summary=self._summary(doc, name),
+                    **self._deprecation(doc),
+                    directives=self._directives(anchor),
+                    exported=self._is_exported(name),
+                    type=type_text,
+                    value=value,
+                    imports=self._imports.used_by(spec),
+                ))
+
+        return symbols
+
+    def _extract_vars(self, decl: Node, path: str) -> list[Symbol]:
+        """
+        Extract the variables of a var_declaration, one symbol per name.
+
+        The type is the declared one, or inferred from literal initializers
+        (`5` is int, `Config{}` is Config, `&Config{}` is *Config); it is
+        left empty when it depends on a call or another expression.
+        """
+        spec_list = next((c for c in decl.named_children if c.type == "var_spec_list"), None)
+        specs = [c for c in (spec_list or decl).named_children if c.type == "var_spec"]
+        grouped = spec_list is not None
+
+        symbols: list[Symbol] = []
+        for spec in specs:
+            value_list = spec.child_by_field_name("value")
+            value_nodes = value_list.named_children if value_list is not None else []
+            declared_type = self._render_type(spec.child_by_field_name("type"))
+
+            anchor = spec if grouped else decl
+            doc = self._doc_comment(anchor)
+            name_nodes = spec.children_by_field_name("name")
+
+            for i, name_node in enumerate(name_nodes):
+                name = self._text(name_node)
+                if name == "_":
+                    continue
+                value_node = value_nodes[i] if i < len(value_nodes) else None
+                type_text = declared_type or self._infer_type(value_node)
+                value = self._collapse(value_node)
+
+                signature = f"var {name} {type_text}".rstrip()
+                if not type_text and value:
+                    signature += f" = {value}"
+                symbols.append(Symbol(
+                    name=name,
+                    kind="var",
+                    file=path,
+                    **self._span(anchor),
+                    **self._name_position(name_node),
+                    signature=signature,
+                    doc=doc,
+                    summary=self._summary(doc, name),
+                    **self._deprecation(doc),
+                    directives=self._directives(anchor),
+                    exported=self._is_exported(name),
+                    type=type_text,
+                    value=value,
+                    imports=self._imports.used_by(spec),
+                ))
+
+        return symbols
+
+    def _const_value(self, expression: str, iota: int) -> str:
+        """Get a constant's effective value for its position in the block."""
+        if not re.search(r"\biota\b", expression):
+            return expression
+        substituted = re.sub(r"\biota\b", str(iota), expression)
+        evaluated = _eval_int(substituted)
+        return substituted if evaluated is None else str(evaluated)
+
+    def _infer_type(self, value: Optional[Node]) -> str:
+        """Infer the type of a variable from a literal initializer, or ""."""
+        if value is None:
+            return ""
+        if value.type in _LITERAL_TYPES:
+            return _LITERAL_TYPES[value.type]
+        if value.type in ("true", "false") or (value.type == "identifier" and self._text(value) in ("true", "false")):
+            return "bool"
+        if value.type == "composite_literal":
+            return self._render_type(value.child_by_field_name("type"))
+        if value.type == "func_literal":
+            return f"func{self._render_signature_tail(value)}"
+        if value.type == "unary_expression" and self._text(value.child_by_field_name("operator")) == "&":
+            operand = value.child_by_field_name("operand")
+            if operand is not None and operand.type == "composite_literal":
+                return "*" + self._render_type(operand.child_by_field_name("type"))
+        return ""
+
+    def _extract_interface_elements(
+        self,
+        interface_node: Node,
+        path: str
+    ) -> tuple[list[Symbol], list[str]]:
+        """
+        Extract the declared methods and embedded elements of an interface_type.
+
+        Returns:
+            (methods, embeds) where methods are "method" symbols with
+            receiver-less signatures (e.g. "Add(a, b int) int") and embeds are
+            embedded interfaces or type-set elements as written
+        """
+        methods: list[Symbol] = []
+        embeds: list[str] = []
+
+        for elem in interface_node.named_children:
+            if elem.type == "method_elem":
+                name = self._text(elem.child_by_field_name("name"))
+                doc = self._doc_comment(elem)
+                methods.append(Symbol(
+                    name=name,
+                    kind="method",
+                    file=path,
+                    **self._span(elem),
+                    **self._name_position(elem.child_by_field_name("name")),
+                    signature=f"{name}{self._render_signature_tail(elem)}",
+                    doc=doc,
+                    summary=self._summary(doc, name),
+                    **self._deprecation(doc),
+                    directives=self._directives(elem),
+                    exported=self._is_exported(name),
+                    type=self._render_func_type(elem),
+                ))
+            elif elem.type == "type_elem":
+                embeds.append(self._render_type(elem))
+
+        return methods, embeds
+
+    def _extract_fields(self, struct_node: Node, path: str) -> list[Symbol]:
+        """
+        Extract the fields of a struct_type as "field" symbols.
+
+        Grouped names (`X, Y int`) produce one symbol per name. Embedded
+        fields get an empty name and the embedded type (e.g. "*Base"); they
+        are exported when the embedded type name is.
+        """
+        fields: list[Symbol] = []
+        field_list = next(
+            (c for c in struct_node.named_children if c.type == "field_declaration_list"),
+            None
+        )
+        if field_list is None:
+            return fields
+
+        for decl in field_list.named_children:
+            if decl.type != "field_declaration":
+                continue
+
+            type_text = self._render_type(decl.child_by_field_name("type"))
+            tag = self._unquote_tag(decl.child_by_field_name("tag"))
+            doc = self._doc_comment(decl)
+            span = self._span(decl)
+            name_nodes = decl.children_by_field_name("name")
+
+            if not name_nodes:
+                # Embedded field: `*Base` keeps the pointer marker as a separate token
+                if any(c.type == "*" for c in decl.children):
+                    type_text = "*" + type_text
+                embedded_name = type_text.lstrip("*").split("[")[0].split(".")[-1]
+                entries = [("", self._is_exported(embedded_name), decl.child_by_field_name("type"))]
+            else:
+                entries = [(self._text(n), self._is_exported(self._text(n)), n) for n in name_nodes]
+
+            for field_name, exported, name_node in entries:
+                signature = f"{field_name} {type_text}".strip()
+                if tag:
+                    signature += f" `{tag}`"
+                fields.append(Symbol(
+                    name=field_name,
+                    kind="field",
+                    file=path,
+                    **span,
+                    **self._name_position(name_node),
+                    signature=signature,
+                    doc=doc,
+                    summary=self._summary(doc, field_name),
+                    **self._deprecation(doc),
+                    directives=self._directives(decl),
+                    exported=exported,
+                    type=type_text,
+                    tag=tag,
+                ))
+
+        return fields
+
+    def _extract_calls(self, body: Optional[Node], receiver_list: Optional[Node] = None) -> list[str]:
+        """
+        Collect the callees of a function body in first-call order.
+
+        Calls through the method receiver (`c.Add` in a `*Calculator` method)
+        are rewritten to the receiver type ("Calculator.Add"); other callees
+        keep their source text ("fmt.Printf", "helper"). Calls inside
+        function literals count toward the enclosing function, and builtins
+        such as `len` are skipped.
+
+        Returns:
+            Unique callee names, or [] when call extraction is disabled
+        """
+        if not self.calls or body is None:
+            return []
+
+        receiver_name = ""
+        receiver_type = self._receiver_type(receiver_list).lstrip("*").split("[")[0]
+        if receiver_list is not None:
+            for param in receiver_list.named_children:
+                if param.type == "parameter_declaration":
+                    receiver_name = self._text(param.child_by_field_name("name"))
+
+        callees: list[str] = []
+        stack = [body]
+        while stack:
+            node = stack.pop()
+            if node.type == "call_expression":
+                callee = self._callee_name(node.child_by_field_name("function"), receiver_name, receiver_type)
+                if callee and callee not in callees:
+                    callees.append(callee)
+            # Reversed so the stack visits children in source order
+            stack.extend(reversed(node.named_children))
+
+        return callees
+
+    @staticmethod
+    def _top_level_names(root: Node) -> list[str]:
+        """List the names declared at the top level of a file."""
+        names = []
+        for node in root.named_children:
+            if node.type in ("function_declaration", "method_declaration"):
+                names.append(GoSymbolExtractor._text(node.child_by_field_name("name")))
+                continue
+            for spec in node.named_children:
+                if spec.type.endswith("_spec_list"):
+                    specs = spec.named_children
+                else:
+                    specs = [spec]
+                for inner in specs:
+                    if inner.type in ("type_spec", "type_alias", "const_spec", "var_spec"):
+                        names.extend(GoSymbolExtractor._text(n) for n in inner.children_by_field_name("name"))
+        return names
+
+    def _complexity(self, node: Node) -> int:
+        """Get the cyclomatic complexity of a function or method, or 0 when metrics are disabled."""
+        if not self.metrics:
+            return 0
+        return cyclomatic_complexity(node.child_by_field_name("body"))
+
+    def _line_counts(self, node: Node) -> dict[str, int]:
+        """Get the code lines and statements of a function or method body, or nothing when metrics are disabled."""
+        if not self.metrics:
+            return {}
+        body = node.child_by_field_name("body")
+        return {"lines": code_lines(body, inner=True), "loc": statement_count(body)}
+
+    def _callee_name(self, function: Optional[Node], receiver_name: str, receiver_type: str) -> str:
+        """Name the function being called, or "" when it is not a named callee."""
+        if function is None:
+            return ""
+        if function.type == "identifier":
+            name = self._text(function)
+            return "" if name in GO_BUILTINS else name
+        if function.type == "selector_expression":
+            operand = function.child_by_field_name("operand")
+            field_name = self._text(function.child_by_field_name("field"))
+            if receiver_name and operand.type == "identifier" and self._text(operand) == receiver_name:
+                return f"{receiver_type}.{field_name}"
+            return self._collapse(function)
+        # Explicit instantiation such as Map[int, string](...)
+        if function.type in ("index_expression", "generic_type"):
+            return self._callee_name(function.named_children[0], receiver_name, receiver_type)
+        return ""
+
+    # ===== Rendering helpers =====
+
+    def _render_signature_tail(self, node: Node) -> str:
+        """
+        Render the parameter list and result of a function or method.
+
+        Parameters and named results keep their names and grouping as
+        declared ("(a, b int) (n int, err error)"); a single unnamed result
+        is rendered without parentheses unless the source has them.
+        """
+        params = self._render_parameters(node.child_by_field_name("parameters"))
+        result = node.child_by_field_name("result")
+        if result is None:
+            return params
+        if result.type == "parameter_list":
+            return f"{params} {self._render_parameters(result)}"
+        return f"{params} {self._render_type(result)}"
+
+    def _render_parameters(self, param_list: Optional[Node]) -> str:
+        """
+        Render a parameter_list such as ``(format string, args ...any)``.
+
+        Names sharing a type stay grouped, variadics keep their ellipsis,
+        and comments and line breaks inside the list are dropped.
+        """
+        if param_list is None:
+            return "()"
+
+        parts = []
+        for param in param_list.named_children:
+            if param.type not in ("parameter_declaration", "variadic_parameter_declaration"):
+                continue
+            type_text = self._render_type(param.child_by_field_name("type"))
+            if param.type == "variadic_parameter_declaration":
+                type_text = f"...{type_text}"
+            names = [self._text(n) for n in param.children_by_field_name("name")]
+            parts.append(f"{', '.join(names)} {type_text}" if names else type_text)
+        return f"({', '.join(parts)})"
+
+    def _render_func_type(self, node: Node) -> str:
+        """
+        Render the type of a function or method without parameter names.
+
+        Grouped parameters are expanded and results unwrapped, so
+        "(a, b int) (sum int)" becomes "func(int, int) int". Two methods
+        with equal types and names are interchangeable for interface
+        satisfaction.
+        """
+        params = ", ".join(self._parameter_types(node.child_by_field_name("parameters")))
+        result = node.child_by_field_name("result")
+        if result is None:
+            return f"func({params})"
+        if result.type != "parameter_list":
+            return f"func({params}) {self._render_type(result)}"
+        results = self._parameter_types(result)
+        if len(results) == 1:
+            return f"func({params}) {results[0]}"
+        return f"func({params}) ({', '.join(results)})"
+
+    def _parameter_types(self, param_list: Optional[Node]) -> list[str]:
+        """Get one type per parameter of a parameter_list ("...T" for variadics)."""
+        types = []
+        for param in (param_list.named_children if param_list is not None else []):
+            if param.type not in ("parameter_declaration", "variadic_parameter_declaration"):
+                continue
+            type_text = self._render_type(param.child_by_field_name("type"))
+            if param.type == "variadic_parameter_declaration":
+                type_text = f"...{type_text}"
+            count = len(param.children_by_field_name("name")) or 1
+            types.extend([type_text] * count)
+        return types
+
+    def _receiver_type(self, receiver_list: Optional[Node]) -> str:
+        """Get the receiver type (e.g. "*Calculator") from a receiver parameter list."""
+        if receiver_list is None:
+            return ""
+        for param in receiver_list.named_children:
+            if param.type == "parameter_declaration":
+                return self._render_type(param.child_by_field_name("type"))
+        return ""
+
+    def _render_type_params(self, node: Optional[Node]) -> str:
+        """
+        Render a type_parameter_list such as ``[K comparable, V any]``.
+
+        Names sharing a constraint stay grouped (``[T, U any]``) and the
+        constraint expression is kept as written.
+        """
+        if node is None:
+            return ""
+
+        decls = []
+        for decl in node.named_children:
+            if decl.type != "type_parameter_declaration":
+                continue
+            names = ", ".join(self._text(n) for n in decl.children_by_field_name("name"))
+            constraint = self._render_type(decl.child_by_field_name("type"))
+            decls.append(f"{names} {constraint}")
+
+        return "[" + ", ".join(decls) + "]"
+
+    def _render_type(self, node: Optional[Node]) -> str:
+        """
+        Render a type expression on one line, as gofmt would.
+
+        Inline struct and interface types, whose source usually spans
+        several lines, have their members joined with "; " so the result is
+        still valid Go ("struct{ X, Y int; *Base }"), and function, map,
+        slice, array, channel, pointer, and generic types are rendered from
+        their parts so the types nested in them are too. Anything else is
+        its collapsed source text.
+        """
+        if node is None:
+            return ""
+        kind = node.type
+        if kind == "struct_type":
+            field_list = next((c for c in node.named_children if c.type == "field_declaration_list"), None)
+            fields = [
+                self._render_field(f) for f in (field_list.named_children if field_list is not None else [])
+                if f.type == "field_declaration"
+            ]
+            return f"struct{{ {'; '.join(fields)} }}" if fields else "struct{}"
+        if kind == "interface_type":
+            elems = []
+            for elem in node.named_children:
+                if elem.type in ("method_elem", "method_spec"):
+                    elems.append(self._text(elem.child_by_field_name("name")) + self._render_signature_tail(elem))
+                elif elem.type != "comment":
+                    elems.append(self._render_type(elem))
+            return f"interface{{ {'; '.join(elems)} }}" if elems else "interface{}"
+        if kind == "function_type":
+            return "func" + self._render_signature_tail(node)
+        if kind == "map_type":
+            key = self._render_type(node.child_by_field_name("key"))
+            return f"map[{key}]{self._render_type(node.child_by_field_name('value'))}"
+        if kind == "slice_type":
+            return "[]" + self._render_type(node.child_by_field_name("element"))
+        if kind == "array_type":
+            length = self._collapse(node.child_by_field_name("length"))
+            return f"[{length}]{self._render_type(node.child_by_field_name('element'))}"
+        if kind == "pointer_type":
+            return "*" + self._render_type(node.named_children[0])
+        if kind == "channel_type":
+            tokens = [c.type for c in node.children if not c.is_named]
+            if tokens[:1] == ["<-"]:
+                direction = "<-chan"
+            else:
+                direction = "chan<-" if "<-" in tokens else "chan"
+            return f"{direction} {self._render_type(node.child_by_field_name('value'))}"
+        if kind == "parenthesized_type":
+            return f"({self._render_type(node.named_children[0])})"
+        if kind == "generic_type":
+            # The type_arguments field is missing on receivers, so find the node
+            arguments = next((c for c in node.named_children if c.type == "type_arguments"), None)
+            if arguments is None:
+                return self._collapse(node)
+            rendered = [self._render_type(a) for a in arguments.named_children if a.type != "comment"]
+            return f"{self._render_type(node.named_children[0])}[{', '.join(rendered)}]"
+        if kind in ("type_elem", "union_type"):
+            return " | ".join(self._render_type(c) for c in node.named_children if c.type != "comment")
+        if kind == "negated_type":
+            return "~" + self._render_type(node.named_children[0])
+        return self._collapse(node)
+
+    def _render_field(self, decl: Node) -> str:
+        """Render a field_declaration of an inline struct ("X, Y int `json:\"x\"`", "*Base")."""
+        type_text = self._render_type(decl.child_by_field_name("type"))
+        names = [self._text(n) for n in decl.children_by_field_name("name")]
+        if names:
+            text = f"{', '.join(names)} {type_text}"
+        else:
+            text = ("*" if any(c.type == "*" for c in decl.children) else "") + type_text
+        tag = decl.child_by_field_name("tag")
+        return f"{text} {self._text(tag)}" if tag is not None else text
+
+    def _span(self, node: Node) -> dict[str, Any]:
+        """
+        Get a node's source range as 1-based line and character columns,
+        and its source text when enabled.
+
+        The end column points just past the last character, like go/token's
+        End(). Tree-sitter reports byte columns, so multi-byte characters
+        earlier on the line are counted once.
+        """
+        start_row, start_byte = node.start_point
+        end_row, end_byte = node.end_point
+        span: dict[str, Any] = {
+            "line": start_row + 1,
+            "column": self._char_column(start_row, start_byte),
+            "end_line": end_row + 1,
+            "end_column": self._char_column(end_row, end_byte),
+        }
+        if self.include_source:
+            # The exact bytes, so tabs, spaces, and a missing final newline are kept as written
+            span["source"] = self._source[node.start_byte:node.end_byte].decode("utf-8", errors="replace")
+        return span
+
+    def _name_position(self, name_node: Optional[Node]) -> dict[str, int]:
+        """Get the 1-based line and character column where a declared name starts."""
+        if name_node is None:
+            return {}
+        row, byte_column = name_node.start_point
+        return {"name_line": row + 1, "name_column": self._char_column(row, byte_column)}
+
+    def _char_column(self, row: int, byte_column: int) -> int:
+        """Convert a 0-based byte column on a row to a 1-based character column."""
+        prefix = self._source_lines[row][:byte_column]
+        return len(prefix.decode("utf8", errors="replace")) + 1
+
+    def _syntax_errors(self, node: Node, path: str) -> list[ParseError]:
+        """
+        Collect the syntax errors under a node in source order.
+
+        tree-sitter marks skipped input with ERROR nodes and tokens it
+        inserted with missing nodes; errors nested inside an ERROR node are
+        not reported separately.
+        """
+        errors = []
+        stack = [node]
+        while stack:
+            current = stack.pop()
+            if current.is_error or current.is_missing:
+                row, byte_column = current.start_point
+                if row >= len(self._source_lines):
+                    # An error at end of input
+                    row = len(self._source_lines) - 1
+                    byte_column = len(self._source_lines[row])
+                errors.append(ParseError(
+                    file=path,
+                    line=row + 1,
+                    column=self._char_column(row, byte_column),
+                    message=self._error_message(current),
+                ))
+                continue
+            stack.extend(reversed([c for c in current.children if c.has_error or c.is_missing]))
+
+        if not errors:
+            # has_error without a located node; report the start of the input
+            errors.append(ParseError(file=path, line=1, column=1, message="syntax error"))
+        return errors
+
+    def _error_message(self, node: Node) -> str:
+        """Describe an ERROR or missing node."""
+        if node.is_missing:
+            return f'missing "{node.type}"'
+        text = self._text(node).strip()
+        if not text:
+            return "unexpected end of file"
+        token = text.split(None, 1)[0]
+        if len(token) > 20:
+            token = token[:20] + "..."
+        return f'unexpected "{token}"'
+
+    def _doc_comment(self, node: Node) -> str:
+        """
+        Collect the comment block immediately preceding a declaration.
+
+        Only comments on the lines directly above the node count; a blank
+        line separates a doc comment from unrelated comments. Directives in
+        the block are not part of the doc (see _directives()), nor is the
+        empty `//` line that conventionally separates them from it.
+        """
+        lines = [self._strip_comment(text) for text in self._comment_block(node) if not DIRECTIVE_RE.match(text)]
+        while lines and not lines[-1].strip():
+            lines.pop()
+        return "\n".join(lines)
+
+    def _directives(self, node: Node) -> list[str]:
+        """Get the `//go:` directives in the comment block preceding a declaration, as written."""
+        return [text.rstrip() for text in self._comment_block(node) if DIRECTIVE_RE.match(text)]
+
+    def _comment_block(self, node: Node) -> list[str]:
+        """Get the text of the comments on the lines directly above a node, first to last (none without comments)."""
+        texts: list[str] = []
+        if not self.comments:
+            return texts
+        expected_row = node.start_point[0] - 1
+        prev = node.prev_sibling
+
+        while prev is not None and prev.type == "comment" and prev.end_point[0] == expected_row:
+            texts.insert(0, self._text(prev))
+            expected_row = prev.start_point[0] - 1
+            prev = prev.prev_sibling
+
+        return texts
+
+    @staticmethod
+    def _summary(doc: str, name: str) -> str:
+        """
+        Get the first sentence of a doc comment, godoc style.
+
+        The sentence ends at the first period followed by whitespace or the
+        end of the text. A leading mention of the symbol's own name
+        ("Add adds ...") is dropped since it repeats the signature.
+        """
+        text = " ".join(doc.split())
+        if not text:
+            return ""
+
+        match = re.search(r"\.(\s|$)", text)
+        if match:
+            text = text[:match.start() + 1]
+
+        if name and text.startswith(name + " "):
+            text = text[len(name) + 1:]
+        return text
+
+    @staticmethod
+    def _deprecation(doc: str) -> dict:
+        """
+        Detect a `Deprecated:` paragraph in a doc comment, godoc style.
+
+        The note is the rest of the paragraph after the marker, joined into
+        one line.
+        """
+        paragraph: Optional[list[str]] = None
+        for line in doc.splitlines():
+            if paragraph is None:
+                if line.startswith("Deprecated:"):
+                    paragraph = [line[len("Deprecated:"):]]
+            elif line.strip():
+                paragraph.append(line)
+            else:
+                break
+        if paragraph is None:
+            return {}
+        return {"deprecated": True, "deprecation_note": " ".join(" ".join(paragraph).split())}
+
+    @staticmethod
+    def _strip_comment(text: str) -> str:
+        """Strip comment markers from a single comment node."""
+        # Line comments in CRLF files keep the carriage return
+        text = text.rstrip("\r")
+        if text.startswith("//"):
+            text = text[2:]
+            return text[1:] if text.startswith(" ") else text
+        if text.startswith("/*"):
+            return text[2:-2].strip()
+        return text
+
+    def _unquote_tag(self, tag_node: Optional[Node]) -> str:
+        """Get struct tag contents without the surrounding quotes or backticks."""
+        if tag_node is None:
+            return ""
+        raw = self._text(tag_node)
+        if raw.startswith("`"):
+            return raw[1:-1]
+        try:
+            return ast.literal_eval(raw)
+        except (ValueError, SyntaxError):
+            return raw[1:-1]
+
+    @staticmethod
+    def _is_exported(name: str) -> bool:
+        """Check whether a Go identifier is exported."""
+        return bool(name) and name[0].isupper()
+
+    @staticmethod
+    def _is_single_spec(decl: Node) -> bool:
+        """Check whether a type_declaration is ungrouped (no parentheses)."""
+        return not any(child.type == "(" for child in decl.children)
+
+    def _code_text(self, node: Optional[Node]) -> str:
+        """Get a node's source text with the comments inside it replaced by spaces."""
+        if node is None:
+            return ""
+        comments = []
+        stack = [node]
+        while stack:
+            current = stack.pop()
+            if current.type == "comment":
+                comments.append(current)
+            else:
+                stack.extend(current.children)
+        if not comments:
+            return self._text(node)
+
+        source = node.text
+        parts = []
+        pos = 0
+        for comment in sorted(comments, key=lambda c: c.start_byte):
+            parts.append(source[pos:comment.start_byte - node.start_byte])
+            pos = comment.end_byte - node.start_byte
+        parts.append(source[pos:])
+        return " ".join(part.decode("utf8") for part in parts)
+
+    @staticmethod
+    def _text(node: Optional[Node]) -> str:
+        """Get the source text of a node."""
+        if node is None:
+            return ""
+        return node.text.decode("utf8")
+
+    def _collapse(self, node: Optional[Node]) -> str:
+        """
+        Get a node's source text on a single line, without comments.
+
+        Whitespace runs collapse to one space, and the padding and trailing
+        commas left behind by multi-line parameter lists are removed.
+        """
+        text = " ".join(self._code_text(node).split())
+        text = re.sub(r"([(\[]) ", r"\1", text)
+        return re.sub(r",? ([)\]])", r"\1", text)
@@ -0,0 +1,202 @@
+"""
+Filters for narrowing extracted symbols.
+
+Filters take a list of symbols and return a new list; nested symbols
+(struct fields) are filtered too, without mutating the input.
+"""
+
+import re
+from dataclasses import replace
+from typing import Optional
+
+from .changes import ChangeSet
+from .models import SYMBOL_KINDS, Symbol
+
+
+def filter_exported(symbols: list[Symbol]) -> list[Symbol]:
+    """
+    Keep only the exported API surface.
+
+    Drops unexported functions, types, fields, and interface methods,
+    methods on unexported types even when the method name itself is
+    capitalized, unexported interfaces from `implements` lists, and the
+    subjects of examples documenting unexported symbols.
+
+    Args:
+        symbols: Symbols to filter
+
+    Returns:
+        New list of exported symbols with unexported members removed
+    """
+    result = []
+    for symbol in symbols:
+        if not symbol.exported:
+            continue
+        if symbol.receiver and not symbol.receiver_type_name[:1].isupper():
+            continue
+        if symbol.fields or symbol.methods:
+            symbol = replace(
+                symbol,
+                fields=filter_exported(symbol.fields),
+                methods=filter_exported(symbol.methods),
+            )
+        if symbol.implements or symbol.pointer_implements:
+            symbol = replace(
+                symbol,
+                implements=[i for i in symbol.implements if _is_exported_name(i)],
+                pointer_implements=[i for i in symbol.pointer_implements if _is_exported_name(i)],
+            )
+        if symbol.subject and not all(_is_exported_name(name) for name in symbol.subject.split(".")):
+            symbol = replace(symbol, subject="")
+        result.append(symbol)
+    return result
+
+
+def _is_exported_name(name: str) -> bool:
+    """Check whether a possibly qualified name ("example.com/a.Reader") is exported."""
+    return name.rsplit(".", 1)[-1][:1].isupper()
+
+
+def validate_kinds(kinds: list[str]) -> list[str]:
+    """
+    Check symbol kind names.
+
+    Args:
+        kinds: Kind names such as "func" or "interface"
+
+    Returns:
+        The kinds, unchanged
+
+    Raises:
+        ValueError: If a name is not a known symbol kind
+    """
+    unknown = [k for k in kinds if k not in SYMBOL_KINDS]
+    if unknown:
+        raise ValueError(
+            f"Unknown symbol kind: {', '.join(unknown)} "
+            f"(expected one of: {', '.join(SYMBOL_KINDS)})"
+        )
+    return kinds
+
+
+def filter_kinds(symbols: list[Symbol], kinds: list[str]) -> list[Symbol]:
+    """
+    Keep only declarations of the given kinds.
+
+    Struct fields stay with their struct. When "field" is requested without
+    "struct", the fields of structs are listed on their own instead, with
+    the struct's name as their `receiver`.
+
+    Args:
+        symbols: Symbols to filter
+        kinds: Kinds to keep (validated with validate_kinds)
+
+    Returns:
+        New list of matching symbols
+
+    Raises:
+        ValueError: If a kind name is unknown
+    """
+    wanted = set(validate_kinds(kinds))
+    lift_fields = "field" in wanted and "struct" not in wanted
+
+    result = []
+    for symbol in symbols:
+        if symbol.kind in wanted:
+            result.append(symbol)
+        elif lift_fields and symbol.kind == "struct":
+            result.extend(
+                replace(f, receiver=symbol.name, package=symbol.package)
+                for f in symbol.fields
+            )
+    return result
+
+
+class NameFilter:
+    """
+    Regular expressions that symbol names must, and must not, match.
+
+    Patterns are searched for anywhere in the name, as Go's
+    regexp.MatchString does, so they are only anchored where they say so:
+    "^New" matches "NewCalculator" but not "RenewLease". The syntax is that
+    of Python's re module, which accepts the RE2 syntax of Go's regexp.
+    """
+
+    def __init__(self, include: Optional[str] = None, exclude: Optional[str] = None):
+        """
+        Compile the patterns.
+
+        Args:
+            include: Pattern names must match, or None for any name
+            exclude: Pattern names must not match, or None
+
+        Raises:
+            ValueError: If a pattern is not a valid regular expression
+        """
+        self.include = include
+        self.exclude = exclude
+        self._include = _compile_name_pattern(include, "name") if include is not None else None
+        self._exclude = _compile_name_pattern(exclude, "name exclude") if exclude is not None else None
+
+    def matches(self, name: str) -> bool:
+        """Check whether a name passes the filter."""
+        if self._exclude is not None and self._exclude.search(name):
+            return False
+        return self._include is None or bool(self._include.search(name))
+
+    def filter(self, symbols: list[Symbol]) -> list[Symbol]:
+        """
+        Keep the declarations whose name passes the filter.
+
+        Methods are matched by their own name ("Add"), not their receiver
+        type's. Struct fields and interface methods stay with their type.
+
+        Args:
+            symbols: Symbols to filter
+
+        Returns:
+            New list of matching symbols
+        """
+        return [s for s in symbols if self.matches(s.name)]
+
+    def __bool__(self) -> bool:
+        """Whether any pattern is set."""
+        return self.include is not None or self.exclude is not None
+
+    def __repr__(self) -> str:
+        """String representation."""
+        return f"NameFilter(include={self.include!r}, exclude={self.exclude!r})"
+
+
+def _compile_name_pattern(pattern: str, role: str) -> re.Pattern:
+    """Compile a name pattern, saying which one it is when it is invalid."""
+    try:
+        return re.compile(pattern)
+    except re.error as e:
+        raise ValueError(f"Invalid {role} pattern {pattern!r}: {e}") from e
+
+
+def filter_changed(symbols: list[Symbol], changes: ChangeSet) -> list[Symbol]:
+    """
+    Keep only declarations whose line span overlaps a change.
+
+    Struct fields and interface methods stay with their type. Methods
+    grouped under a type are filtered on their own, and the type is kept
+    when any of them changed.
+
+    Args:
+        symbols: Symbols to filter
+        changes: Changed lines, e.g. from changes.changed_since()
+
+    Returns:
+        New list of changed symbols
+    """
+    result = []
+    for symbol in symbols:
+        if symbol.methods and symbol.kind != "interface":
+            methods = [m for m in symbol.methods if changes.touches(m)]
+            if methods or changes.touches(symbol):
+                result.append(replace(symbol, methods=methods))
+        elif changes.touches(symbol):
+            result.append(symbol)
+    return result
@@ -0,0 +1,1023 @@
+"""
+Output formatters for extracted symbols.
+
+Each formatter renders a list of symbols to a string. New formats are added
+by implementing SymbolFormatter and registering the class in FORMATTERS.
+"""
+
+import json
+import re
+from abc import ABC, abstractmethod
+from dataclasses import replace
+from typing import Optional
+
+from .imports import assumed_package_name
+from .metrics import DEFAULT_COMPLEXITY_THRESHOLD
+from .models import SYMBOL_KINDS, TEST_KINDS, Annotation, PackageSummary, Symbol, SymbolDiff
+
+
+class SymbolFormatter(ABC):
+    """Abstract base class for symbol output formats."""
+
+    # File name extension of the output, without the dot (--out-dir)
+    extension = "txt"
+
+    @abstractmethod
+    def format(self, symbols: list[Symbol]) -> str:
+        """
+        Render symbols for output.
+
+        Args:
+            symbols: Symbols to render, in output order
+
+        Returns:
+            Formatted output text
+        """
+        pass
+
+    def format_packages(self, summaries: dict[str, PackageSummary], symbols: list[Symbol]) -> str:
+        """
+        Render symbols preceded by the summary of each package.
+
+        The default emits one comment block per package above format()'s
+        output. Formats with a structured representation override this.
+
+        Args:
+            summaries: Import path ("" for a single file) to package summary
+            symbols: Symbols to render, in output order
+
+        Returns:
+            Formatted output text
+        """
+        blocks = []
+        for summary in summaries.values():
+            suffix = f" ({summary.path})" if summary.path else ""
+            lines = [f"// package {summary.name}{suffix}: {describe_counts(summary)}"]
+            lines.extend(f"//   {doc_line}".rstrip() for doc_line in summary.doc.splitlines())
+            blocks.append("\n".join(lines))
+        body = self.format(symbols)
+        if body:
+            blocks.append(body)
+        return "\n\n".join(blocks)
+
+    def format_counts(self, summaries: dict[str, PackageSummary]) -> str:
+        """
+        Render symbol tallies only: one `package: 3 func, 4 method` line per
+        package with symbols, then the total and its breakdown by kind.
+
+        Formats with a structured representation override this.
+
+        Args:
+            summaries: Import path ("" for a single file) to package
+                summary, whose `kinds` count the symbols to report
+
+        Returns:
+            Formatted output text
+        """
+        lines = [
+            f"{summary.path or summary.name}: {_tally(summary.kinds)}"
+            for summary in summaries.values()
+            if summary.kinds
+        ]
+        kinds = total_kinds(summaries)
+        total = _count(sum(kinds.values()), "symbol")
+        lines.append(f"total: {total} ({_tally(kinds)})" if kinds else f"total: {total}")
+        return "\n".join(lines)
+
+    def format_diff(self, diff: SymbolDiff) -> str:
+        """
+        Render symbol changes, one `+`/`-`/`~` prefixed line per symbol.
+
+        Formats with a structured representation override this.
+
+        Args:
+            diff: Changes to render
+
+        Returns:
+            Formatted output text
+        """
+        lines = []
+        for marker, symbols in (("+", diff.added), ("-", diff.removed), ("~", diff.modified)):
+            for symbol in symbols:
+                lines.append(f"{marker} {symbol.file}:{symbol.line}: {symbol.signature}")
+        return "\n".join(lines)
+
+    def format_package_diffs(self, diffs: dict[str, SymbolDiff]) -> str:
+        """
+        Render the changes of several packages, each under a `package` header.
+
+        Lines are as in format_diff(), with the old signature of each
+        modified symbol on a `was:` line below the new one. Newly
+        deprecated symbols follow, marked `!`, with their deprecation note.
+
+        Args:
+            diffs: Import path to that package's changes
+
+        Returns:
+            Formatted output text
+        """
+        blocks = []
+        for path, diff in diffs.items():
+            lines = [f"package {path}"]
+            lines.extend(f"- {s.file}:{s.line}: {s.signature}" for s in diff.removed)
+            for symbol, previous in zip(diff.modified, diff.previous):
+                lines.append(f"~ {symbol.file}:{symbol.line}: {symbol.signature}")
+                lines.append(f"    was: {previous.signature}")
+            lines.extend(f"+ {s.file}:{s.line}: {s.signature}" for s in diff.added)
+            for symbol in diff.deprecated:
+                lines.append(f"! {symbol.file}:{symbol.line}: {symbol.signature}")
+                if symbol.deprecation_note:
+                    lines.append(f"    deprecated: {symbol.deprecation_note}")
+            blocks.append("\n".join(lines))
+        return "\n\n".join(blocks)
+
+    def format_note(self, note: str) -> str:
+        """
+        Render a trailing note such as "3 symbols omitted to fit budget".
+
+        Formats that cannot carry a note return "" and the caller reports it
+        elsewhere.
+
+        Args:
+            note: Note text
+
+        Returns:
+            Formatted note, or ""
+        """
+        return f"// {note}"
+
+    def format_index(self, summaries: dict[str, PackageSummary], files: dict[str, str]) -> str:
+        """
+        Render the index of a directory of per-package files.
+
+        Formats whose files need no index return "" and none is written.
+
+        Args:
+            summaries: Import path to package summary
+            files: Import path to the path of its file, relative to the index
+
+        Returns:
+            Formatted index, or ""
+        """
+        return ""
+
+    def format_calls(self, graph: dict[str, list[str]]) -> str:
+        """
+        Render a call graph: each caller followed by its indented callees.
+
+        Formats with a structured representation override this.
+
+        Args:
+            graph: Caller name to callee names
+
+        Returns:
+            Formatted output text
+        """
+        lines = []
+        for caller, callees in graph.items():
+            lines.append(caller)
+            lines.extend(f"    {callee}" for callee in callees)
+        return "\n".join(lines)
+
+    def format_annotations(self, annotations: list[Annotation]) -> str:
+        """
+        Render marker comments, one `file:line: MARKER: text` line each,
+        followed by the declaration they belong to.
+
+        Formats with a structured representation override this.
+
+        Args:
+            annotations: Annotations in output order
+
+        Returns:
+            Formatted output text
+        """
+        lines = []
+        for annotation in annotations:
+            suffix = f"  // in {annotation.symbol}" if annotation.symbol else ""
+            lines.append(f"{annotation}{suffix}")
+        return "\n".join(lines)
+
+
+class TextFormatter(SymbolFormatter):
+    """
+    Human-readable output: one `file:line: signature` line per symbol,
+    followed by its doc comment and its members: struct fields, grouped
+    methods, or an interface's embedded elements and full method set, and
+    for types the interfaces they implement. Symbols from a directory walk
+    are preceded by a `package <import path>` header. Methods are marked
+    with their receiver mode, and deprecated symbols are tagged
+    `[deprecated]`. Functions extracted with metrics show their
+    complexity, and symbols extracted with their source show it in place of
+    their members.
+    """
+
+    def __init__(self, color: bool = False, complexity_threshold: int = DEFAULT_COMPLEXITY_THRESHOLD):
+        """
+        Initialize the text formatter.
+
+        Args:
+            color: Highlight names, receivers, and comments with ANSI escape
+                codes, for terminals
+            complexity_threshold: Flag functions whose complexity is above
+                this
+        """
+        self.color = color
+        self.complexity_threshold = complexity_threshold
+
+    def format(self, symbols: list[Symbol]) -> str:
+        """Render symbols as plain text."""
+        lines = []
+        current_package = ""
+        for symbol in symbols:
+            if symbol.package and symbol.package != current_package:
+                if lines:
+                    lines.append("")
+                lines.append(self._style("package", f"package {symbol.package}"))
+                current_package = symbol.package
+            lines.append(f"{symbol.file}:{symbol.line}: {self._signature(symbol)}{self._notes(symbol)}")
+            for doc_line in symbol.doc.splitlines():
+                lines.append("    " + self._style("doc", f"// {doc_line}"))
+            for directive in symbol.directives:
+                lines.append("    " + self._style("doc", directive))
+            if symbol.complexity:
+                lines.append(f"    {self._complexity(symbol)}")
+            if symbol.source:
+                # Fields and interface methods are part of the source
+                lines.extend(f"    {source_line}".rstrip() for source_line in symbol.source.splitlines())
+            else:
+                for struct_field in symbol.fields:
+                    lines.append(f"    {self._signature(struct_field)}")
+                for embed in symbol.embeds:
+                    lines.append(f"    {self._style('type', embed)}")
+            methods = [] if symbol.source and symbol.kind == "interface" else symbol.methods
+            for method in methods:
+                complexity = f"  {self._complexity(method)}" if method.complexity else ""
+                lines.append(f"    {self._signature(method)}{self._notes(method)}{complexity}")
+            if symbol.implements:
+                lines.append("    " + self._style("doc", f"// implements {', '.join(symbol.implements)}"))
+            if symbol.pointer_implements:
+                lines.append("    " + self._style("doc", f"// *{symbol.name} implements {', '.join(symbol.pointer_implements)}"))
+            if symbol.subject:
+                lines.append("    " + self._style("doc", f"// example of {symbol.subject}"))
+        return "\n".join(lines)
+
+    def _notes(self, symbol: Symbol) -> str:
+        """Render the comment after a method's signature: its receiver mode and where it was promoted from."""
+        notes = [f"{symbol.receiver_mode} receiver"] if symbol.receiver_mode else []
+        if symbol.origin:
+            notes.append(f"from {symbol.origin}")
+        return "  " + self._style("doc", f"// {', '.join(notes)}") if notes else ""
+
+    def _signature(self, symbol: Symbol) -> str:
+        """Render a signature, tagged when the symbol is deprecated."""
+        signature = self._highlight(symbol)
+        if symbol.deprecated:
+            signature += " " + self._style("warning", "[deprecated]")
+        return signature
+
+    def _highlight(self, symbol: Symbol) -> str:
+        """Render a signature with its receiver and declared name highlighted."""
+        signature = symbol.signature
+        if not self.color:
+            return signature
+
+        start = 0
+        prefix = ""
+        if symbol.kind == "method" and signature.startswith("func ("):
+            end = _closing_paren(signature, len("func "))
+            if end > 0:
+                prefix = "func " + self._style("receiver", signature[len("func "):end + 1])
+                start = end + 1
+
+        style = _NAME_STYLES.get(symbol.kind)
+        match = re.search(rf"\b{re.escape(symbol.name)}\b", signature[start:]) if style and symbol.name else None
+        if not match:
+            return prefix + signature[start:]
+        name_start, name_end = start + match.start(), start + match.end()
+        return (
+            prefix
+            + signature[start:name_start]
+            + self._style(style, signature[name_start:name_end])
+            + signature[name_end:]
+        )
+
+    def _complexity(self, symbol: Symbol) -> str:
+        """
+        Render a function's metrics comment, with its complexity flagged when
+        above the threshold and followed by its lines of code.
+        """
+        size = f", {_count(symbol.lines, 'line')}, {_count(symbol.loc, 'statement')}" if symbol.lines else ""
+        if symbol.complexity > self.complexity_threshold:
+            return (
+                self._style("warning", f"// complexity {symbol.complexity} (over {self.complexity_threshold})")
+                + self._style("doc", size)
+            )
+        return self._style("doc", f"// complexity {symbol.complexity}{size}")
+
+    def _style(self, style: str, text: str) -> str:
+        """Wrap text in the escape codes of a style when color is on."""
+        if not self.color:
+            return text
+        return f"{_ANSI_STYLES[style]}{text}{_ANSI_RESET}"
+
+
+class JsonFormatter(SymbolFormatter):
+    """
+    Machine-readable output: a top-level JSON array of symbol objects,
+    suitable for piping into `jq`.
+    """
+
+    extension = "json"
+
+    def __init__(self, indent: int = 2):
+        """
+        Initialize the JSON formatter.
+
+        Args:
+            indent: Indentation width for pretty-printing
+        """
+        self.indent = indent
+
+    def format(self, symbols: list[Symbol]) -> str:
+        """Render symbols as a JSON array."""
+        return json.dumps([s.to_dict() for s in symbols], indent=self.indent)
+
+    def format_packages(self, summaries: dict[str, PackageSummary], symbols: list[Symbol]) -> str:
+        """Render an object of a `packages` array of summaries and the `symbols` array."""
+        return json.dumps({
+            "packages": [summary.to_dict() for summary in summaries.values()],
+            "symbols": [s.to_dict() for s in symbols],
+        }, indent=self.indent)
+
+    def format_counts(self, summaries: dict[str, PackageSummary]) -> str:
+        """
+        Render an object of the `total`, its `kinds` breakdown, and a
+        `packages` array of each package's `path`, `name`, `total`, and
+        `kinds`.
+        """
+        kinds = total_kinds(summaries)
+        return json.dumps({
+            "total": sum(kinds.values()),
+            "kinds": kinds,
+            "packages": [
+                {"path": summary.path, "name": summary.name, "total": sum(summary.kinds.values()), "kinds": summary.kinds}
+                for summary in summaries.values()
+                if summary.kinds
+            ],
+        }, indent=self.indent)
+
+    def format_diff(self, diff: SymbolDiff) -> str:
+        """Render changes as an object of added, removed, and modified arrays."""
+        return json.dumps({
+            "added": self._render_all(diff.added),
+            "removed": self._render_all(diff.removed),
+            "modified": self._render_all(diff.modified),
+        }, indent=self.indent)
+
+    def format_package_diffs(self, diffs: dict[str, SymbolDiff]) -> str:
+        """
+        Render an object mapping each import path to its added, removed,
+        modified, and deprecated arrays, with the old versions of modified
+        symbols under `previous`.
+        """
+        return json.dumps({
+            path: {
+                "added": self._render_all(diff.added),
+                "removed": self._render_all(diff.removed),
+                "modified": self._render_all(diff.modified),
+                "previous": self._render_all(diff.previous),
+                "deprecated": self._render_all(diff.deprecated),
+            }
+            for path, diff in diffs.items()
+        }, indent=self.indent)
+
+    def _render_all(self, symbols: list[Symbol]) -> list[dict]:
+        """Convert symbols to JSON objects."""
+        return [s.to_dict() for s in symbols]
+
+    def format_note(self, note: str) -> str:
+        """JSON output has no room for comments; notes are left to the caller."""
+        return ""
+
+    def format_index(self, summaries: dict[str, PackageSummary], files: dict[str, str]) -> str:
+        """Render an object of a `packages` array of summaries, each with its `file`."""
+        return json.dumps({
+            "packages": [{**summary.to_dict(), "file": files[path]} for path, summary in summaries.items()],
+        }, indent=self.indent)
+
+    def format_calls(self, graph: dict[str, list[str]]) -> str:
+        """Render a call graph as an object of caller to callee arrays."""
+        return json.dumps(graph, indent=self.indent)
+
+    def format_annotations(self, annotations: list[Annotation]) -> str:
+        """Render marker comments as a JSON array of annotation objects."""
+        return json.dumps([a.to_dict() for a in annotations], indent=self.indent)
+
+
+class JsonlFormatter(JsonFormatter):
+    """
+    JSON Lines: one compact JSON object per line, so that output can be
+    processed as it is written (e.g. by `jq -c` or a line reader) without
+    buffering a whole array.
+
+    Symbols are objects as in JSON output. Package summaries precede them
+    as objects of kind "package", and changes are symbol objects with a
+    `change` of "added", "removed", "modified", or "deprecated".
+    """
+
+    extension = "jsonl"
+
+    def __init__(self):
+        """Initialize the JSON Lines formatter."""
+        super().__init__(indent=None)
+
+    def format(self, symbols: list[Symbol]) -> str:
+        """Render one symbol object per line."""
+        return "\n".join(self.format_symbol(s) for s in symbols)
+
+    def format_symbol(self, symbol: Symbol) -> str:
+        """Render one symbol as a line, for writing symbols as they are extracted."""
+        return json.dumps(symbol.to_dict())
+
+    def format_packages(self, summaries: dict[str, PackageSummary], symbols: list[Symbol]) -> str:
+        """Render a line per package summary, then a line per symbol."""
+        lines = [json.dumps({"kind": "package", **summary.to_dict()}) for summary in summaries.values()]
+        return "\n".join(lines + [self.format_symbol(s) for s in symbols])
+
+    def format_diff(self, diff: SymbolDiff) -> str:
+        """Render a line per added, removed, and modified symbol."""
+        return "\n".join(self._changes(diff))
+
+    def format_package_diffs(self, diffs: dict[str, SymbolDiff]) -> str:
+        """
+        Render a line per change of every package, in package order; the
+        old version of a modified symbol is its `previous`.
+        """
+        lines = []
+        for diff in diffs.values():
+            lines.extend(self._changes(diff))
+            lines.extend(json.dumps({"change": "deprecated", **s.to_dict()}) for s in diff.deprecated)
+        return "\n".join(lines)
+
+    def _changes(self, diff: SymbolDiff) -> list[str]:
+        """Render the added, removed, and modified symbols of a diff as lines."""
+        lines = [json.dumps({"change": "added", **s.to_dict()}) for s in diff.added]
+        lines.extend(json.dumps({"change": "removed", **s.to_dict()}) for s in diff.removed)
+        previous = diff.previous or [None] * len(diff.modified)
+        for symbol, old in zip(diff.modified, previous):
+            change = {"change": "modified", **symbol.to_dict()}
+            if old is not None:
+                change["previous"] = old.to_dict()
+            lines.append(json.dumps(change))
+        return lines
+
+    def format_calls(self, graph: dict[str, list[str]]) -> str:
+        """Render a line per caller, with its `callees`."""
+        return "\n".join(json.dumps({"caller": caller, "callees": callees}) for caller, callees in graph.items())
+
+    def format_annotations(self, annotations: list[Annotation]) -> str:
+        """Render one annotation object per line."""
+        return "\n".join(json.dumps(a.to_dict()) for a in annotations)
+
+
+class MarkdownFormatter(SymbolFormatter):
+    """
+    Markdown API reference: a section per package, a subsection per type
+    with its fields or method set and its methods, and a subsection per
+    function. Signatures are shown in fenced Go code blocks followed by the
+    doc comment.
+
+    Output depends only on the input order, so it can be committed and
+    diffed.
+    """
+
+    extension = "md"
+
+    def format(self, symbols: list[Symbol]) -> str:
+        """Render symbols as a Markdown document."""
+        sections = []
+        for title, group in self._group_by_package(symbols):
+            sections.append(self._format_package(title, group))
+        return "\n\n".join(sections)
+
+    def format_packages(self, summaries: dict[str, PackageSummary], symbols: list[Symbol]) -> str:
+        """
+        Render a section per summarized package, headed by its name and
+        followed by its import path, size, and package doc.
+        """
+        grouped: dict[str, list[Symbol]] = {}
+        for symbol in symbols:
+            grouped.setdefault(symbol.package, []).append(symbol)
+
+        sections = []
+        for path, summary in summaries.items():
+            details = [f"`{path}`"] if path else []
+            details.append(describe_counts(summary))
+            intro = [" · ".join(details)]
+            if summary.doc:
+                intro.append(summary.doc)
+            sections.append(self._format_package(f"package {summary.name}", grouped.get(path, []), intro))
+        return "\n\n".join(sections)
+
+    def _group_by_package(self, symbols: list[Symbol]) -> list[tuple[str, list[Symbol]]]:
+        """Group symbols by package, falling back to the file for single files."""
+        groups: dict[str, list[Symbol]] = {}
+        for symbol in symbols:
+            title = f"package {symbol.package}" if symbol.package else symbol.file
+            groups.setdefault(title, []).append(symbol)
+        return list(groups.items())
+
+    def _format_package(self, title: str, symbols: list[Symbol], intro: Optional[list[str]] = None) -> str:
+        """Render one package section, with optional paragraphs under the heading."""
+        type_names = {s.name for s in symbols if s.kind not in ("func", "method", *TEST_KINDS)}
+        methods_by_type: dict[str, list[Symbol]] = {}
+        for symbol in symbols:
+            if symbol.kind == "method":
+                methods_by_type.setdefault(symbol.receiver_type_name, []).append(symbol)
+
+        blocks = [f"## {title}", *(intro or [])]
+        for symbol in symbols:
+            if symbol.kind == "func" or symbol.kind in TEST_KINDS:
+                blocks.append(self._format_callable(symbol, "###"))
+            elif symbol.kind != "method":
+                blocks.append(self._format_type(symbol, methods_by_type.get(symbol.name, [])))
+
+        # Methods whose receiver type is declared outside the input
+        for receiver, methods in methods_by_type.items():
+            if receiver not in type_names:
+                blocks.append(f"### {receiver}")
+                blocks.extend(self._format_callable(m, "####") for m in methods)
+
+        return "\n\n".join(blocks)
+
+    def _format_type(self, symbol: Symbol, methods: list[Symbol]) -> str:
+        """Render a type subsection with its members and methods."""
+        blocks = [f"### {symbol.name}", self._code_block(symbol.source or self._type_declaration(symbol))]
+        if symbol.doc:
+            blocks.append(symbol.doc)
+        if symbol.fields:
+            blocks.append(self._field_table(symbol.fields))
+        if symbol.implements:
+            blocks.append(f"Implements: {', '.join(f'`{i}`' for i in symbol.implements)}")
+        if symbol.pointer_implements:
+            implemented = ", ".join(f"`{i}`" for i in symbol.pointer_implements)
+            blocks.append(f"`*{symbol.name}` implements: {implemented}")
+        if symbol.kind != "interface":
+            # Methods already grouped under the type by --group-methods
+            promoted = [m for m in symbol.methods if m.promotion_depth]
+            if promoted:
+                listed = ", ".join(f"`{m.name}` (from `{m.origin}`)" for m in promoted)
+                blocks.append(f"Promoted methods: {listed}")
+            methods = [m for m in symbol.methods if not m.promotion_depth] + methods
+        blocks.extend(self._format_callable(m, "####") for m in methods)
+        return "\n\n".join(blocks)
+
+    def _format_callable(self, symbol: Symbol, heading: str) -> str:
+        """Render a function or method subsection."""
+        blocks = [f"{heading} {symbol.local_name}", self._code_block(symbol.source or symbol.signature)]
+        if symbol.doc:
+            blocks.append(symbol.doc)
+        if symbol.subject:
+            blocks.append(f"Example of: `{symbol.subject}`")
+        if symbol.complexity:
+            blocks.append(f"Cyclomatic complexity: {symbol.complexity}")
+        if symbol.lines:
+            blocks.append(f"Lines of code: {symbol.lines} ({_count(symbol.loc, 'statement')})")
+        return "\n\n".join(blocks)
+
+    def _type_declaration(self, symbol: Symbol) -> str:
+        """
+        Render a type's declaration, spelling out an interface's method set.
+
+        Promoted methods are listed as comments so the block stays valid Go.
+        """
+        if symbol.kind != "interface" or not (symbol.methods or symbol.embeds):
+            return symbol.signature
+
+        lines = [f"{symbol.signature} {{"]
+        lines.extend(f"\t{embed}" for embed in symbol.embeds)
+        for method in symbol.methods:
+            if method.origin:
+                lines.append(f"\t// {method.signature} (from {method.origin})")
+            else:
+                lines.append(f"\t{method.signature}")
+        lines.append("}")
+        return "\n".join(lines)
+
+    def _field_table(self, fields: list[Symbol]) -> str:
+        """Render struct fields as a Markdown table."""
+        rows = ["| Field | Type | Tag | Description |", "| --- | --- | --- | --- |"]
+        for struct_field in fields:
+            name = f"`{struct_field.name}`" if struct_field.name else "*(embedded)*"
+            tag = f"`{self._escape_cell(struct_field.tag)}`" if struct_field.tag else ""
+            rows.append(
+                f"| {name} | `{self._escape_cell(struct_field.type)}` | {tag} "
+                f"| {self._escape_cell(struct_field.summary)} |"
+            )
+        return "\n".join(rows)
+
+    def format_package_diffs(self, diffs: dict[str, SymbolDiff]) -> str:
+        """Render a section per package with its changes as a fenced diff block."""
+        sections = []
+        for path, diff in diffs.items():
+            lines = [f"- {s.signature}" for s in diff.removed]
+            for symbol, previous in zip(diff.modified, diff.previous):
+                lines.extend([f"- {previous.signature}", f"+ {symbol.signature}"])
+            lines.extend(f"+ {s.signature}" for s in diff.added)
+            lines.extend(f"! {s.signature}" for s in diff.deprecated)
+            body = "\n".join(lines)
+            sections.append(f"## package {path}\n\n```diff\n{body}\n```")
+        return "\n\n".join(sections)
+
+    def format_annotations(self, annotations: list[Annotation]) -> str:
+        """Render marker comments as a table of location, symbol, marker, and text."""
+        if not annotations:
+            return ""
+        rows = ["| Location | Symbol | Marker | Text |", "| --- | --- | --- | --- |"]
+        for annotation in annotations:
+            marker = f"{annotation.marker}({annotation.owner})" if annotation.owner else annotation.marker
+            symbol = f"`{annotation.symbol}`" if annotation.symbol else ""
+            rows.append(
+                f"| `{annotation.file}:{annotation.line}` | {symbol} | {marker} "
+                f"| {self._escape_cell(annotation.text)} |"
+            )
+        return "\n".join(rows)
+
+    def format_note(self, note: str) -> str:
+        """Render a note as an emphasized paragraph."""
+        return f"*{note}*"
+
+    @staticmethod
+    def _code_block(code: str) -> str:
+        """Wrap code in a fenced Go block."""
+        return f"```go\n{code}\n```"
+
+    @staticmethod
+    def _escape_cell(text: str) -> str:
+        """Escape text for use inside a table cell."""
+        return text.replace("|", "\\|").replace("\n", " ")
+
+
+class LspFormatter(JsonFormatter):
+    """
+    LSP `DocumentSymbol` JSON, as returned by `textDocument/documentSymbol`.
+
+    Struct fields, interface methods, and methods declared in the same file
+    as their receiver type are nested as `children`. A single file renders
+    as an array of DocumentSymbols; symbols from a directory walk render as
+    an object mapping each file path to its array.
+
+    Positions are converted from the model's 1-based lines and columns to
+    the 0-based ones LSP requires. Columns count characters, which equal
+    UTF-16 code units except for characters outside the Basic Multilingual
+    Plane.
+    """
+
+    # LSP SymbolKind values
+    SYMBOL_KINDS = {
+        "func": 12,       # Function
+        "method": 6,      # Method
+        "interface": 11,  # Interface
+        "struct": 23,     # Struct
+        "field": 8,       # Field
+        "type": 5,        # Class
+        "alias": 5,       # Class
+        "const": 14,      # Constant
+        "var": 13,        # Variable
+        "test": 12,       # Function
+        "benchmark": 12,  # Function
+        "example": 12,    # Function
+    }
+
+    # LSP SymbolTag.Deprecated
+    SYMBOL_TAG_DEPRECATED = 1
+
+    def format(self, symbols: list[Symbol]) -> str:
+        """Render symbols as DocumentSymbol arrays."""
+        documents: dict[str, list[Symbol]] = {}
+        for symbol in self._ungroup(symbols):
+            documents.setdefault(symbol.file, []).append(symbol)
+
+        rendered = {path: self._document(file_symbols) for path, file_symbols in documents.items()}
+        if any(s.package for s in symbols):
+            return json.dumps(rendered, indent=self.indent)
+        return json.dumps([d for document in rendered.values() for d in document], indent=self.indent)
+
+    def format_packages(self, summaries: dict[str, PackageSummary], symbols: list[Symbol]) -> str:
+        """Render an object of a `packages` array of summaries and the DocumentSymbols as `symbols`."""
+        return json.dumps({
+            "packages": [summary.to_dict() for summary in summaries.values()],
+            "symbols": json.loads(self.format(symbols)),
+        }, indent=self.indent)
+
+    def _render_all(self, symbols: list[Symbol]) -> list[dict]:
+        """Render symbols as DocumentSymbols, for diffs."""
+        return [self._document_symbol(s) for s in symbols]
+
+    def _ungroup(self, symbols: list[Symbol]) -> list[Symbol]:
+        """Move methods grouped under a type back to the top level, where they are re-nested per file."""
+        result = []
+        for symbol in symbols:
+            if symbol.kind != "interface" and symbol.methods:
+                result.append(replace(symbol, methods=[]))
+                # Promoted methods are already listed under the type declaring them
+                result.extend(m for m in symbol.methods if not m.promotion_depth)
+            else:
+                result.append(symbol)
+        return result
+
+    def _document(self, symbols: list[Symbol]) -> list[dict]:
+        """Render the symbols of one file, nesting methods under their receiver type."""
+        type_names = {s.name for s in symbols if s.kind not in ("func", "method", *TEST_KINDS)}
+        methods: dict[str, list[Symbol]] = {}
+        for symbol in symbols:
+            if symbol.kind == "method" and symbol.receiver_type_name in type_names:
+                methods.setdefault(symbol.receiver_type_name, []).append(symbol)
+
+        document = []
+        for symbol in symbols:
+            if symbol.kind == "method" and symbol.receiver_type_name in type_names:
+                continue
+            document.append(self._document_symbol(symbol, methods.get(symbol.name, [])))
+        return document
+
+    def _document_symbol(self, symbol: Symbol, methods: Optional[list[Symbol]] = None, nested: bool = False) -> dict:
+        """
+        Render one DocumentSymbol with its children.
+
+        Methods outside their type's children are named like gopls does,
+        e.g. "(*Calculator).Add".
+        """
+        name = symbol.name or symbol.type.lstrip("*")
+        full_range = self._range(symbol.line, symbol.column, symbol.end_line, symbol.end_column)
+        if symbol.name_line:
+            selection = self._range(symbol.name_line, symbol.name_column, symbol.name_line, symbol.name_column + len(name))
+        else:
+            selection = full_range
+
+        document_symbol = {
+            "name": f"({symbol.receiver}).{name}" if symbol.receiver and not nested else name,
+            "detail": symbol.type if symbol.kind == "field" else symbol.signature,
+            "kind": self.SYMBOL_KINDS.get(symbol.kind, 13),
+            "range": full_range,
+            "selectionRange": selection,
+        }
+        if symbol.receiver_mode:
+            # Not part of DocumentSymbol; clients ignore unknown properties
+            document_symbol["receiverMode"] = symbol.receiver_mode
+        if symbol.deprecated:
+            document_symbol["tags"] = [self.SYMBOL_TAG_DEPRECATED]
+        members = symbol.fields + [m for m in symbol.methods if not m.origin] + (methods or [])
+        if members:
+            document_symbol["children"] = [self._document_symbol(m, nested=True) for m in members]
+        return document_symbol
+
+    @staticmethod
+    def _range(line: int, column: int, end_line: int, end_column: int) -> dict:
+        """Convert a 1-based range to a 0-based LSP Range."""
+        return {
+            "start": {"line": max(line - 1, 0), "character": max(column - 1, 0)},
+            "end": {"line": max(end_line - 1, 0), "character": max(end_column - 1, 0)},
+        }
+
+
+class DotFormatter(SymbolFormatter):
+    """
+    Graphviz graph of types and their relationships, for `dot -Tsvg`.
+
+    Each struct, interface, and other named type is a box listing its
+    methods; interfaces are dashed. Embedded interfaces and struct fields
+    are solid edges from the embedding type, and interfaces a type
+    implements are dashed edges to the interface, labeled `*` when only
+    the pointer type implements it. Types of each package are clustered.
+    Embedded types that are not part of the output (e.g. "io.Reader") are
+    drawn dotted.
+
+    Node IDs are qualified names, quoted, so they stay the same between
+    runs and generic or pointer types need no special treatment.
+    """
+
+    extension = "dot"
+
+    TYPE_KINDS = ("struct", "interface", "type", "alias")
+
+    def format(self, symbols: list[Symbol]) -> str:
+        """Render the types of symbols as a digraph."""
+        types = [s for s in symbols if s.kind in self.TYPE_KINDS]
+        ids = {s.qualified_name for s in types}
+        methods: dict[str, list[Symbol]] = {}
+        for symbol in symbols:
+            if symbol.kind == "method":
+                owner = f"{symbol.package}.{symbol.receiver_type_name}" if symbol.package else symbol.receiver_type_name
+                methods.setdefault(owner, []).append(symbol)
+
+        lines = ["digraph symbols {", "    rankdir=LR;", '    node [shape=box, fontname="Helvetica"];']
+        packages: dict[str, list[Symbol]] = {}
+        for symbol in types:
+            packages.setdefault(symbol.package, []).append(symbol)
+        for package, package_types in packages.items():
+            indent = "    "
+            if package:
+                lines.append(f"    subgraph {_dot_id('cluster_' + package)} {{")
+                lines.append(f"        label={_dot_id(package)};")
+                indent = "        "
+            for symbol in package_types:
+                lines.append(indent + self._node(symbol, methods.get(symbol.qualified_name, [])))
+            if package:
+                lines.append("    }")
+
+        external: list[str] = []
+        for symbol in types:
+            for target, attributes in self._edges(symbol):
+                target_id = self._resolve(target, symbol)
+                if target_id not in ids and target_id not in external:
+                    external.append(target_id)
+                lines.append(f"    {_dot_id(symbol.qualified_name)} -> {_dot_id(target_id)}{attributes};")
+        for target_id in external:
+            lines.append(f"    {_dot_id(target_id)} [style=dotted];")
+        lines.append("}")
+        return "\n".join(lines)
+
+    def format_calls(self, graph: dict[str, list[str]]) -> str:
+        """Render a call graph as a digraph of caller -> callee edges."""
+        lines = ["digraph calls {", "    rankdir=LR;", '    node [shape=box, fontname="Helvetica"];']
+        for caller, callees in graph.items():
+            lines.append(f"    {_dot_id(caller)};")
+            lines.extend(f"    {_dot_id(caller)} -> {_dot_id(callee)};" for callee in callees)
+        lines.append("}")
+        return "\n".join(lines)
+
+    def _node(self, symbol: Symbol, methods: list[Symbol]) -> str:
+        """Render a type's node, labeled with its declaration and methods."""
+        heading = symbol.signature.removeprefix("type ")
+        if symbol.kind in ("struct", "interface"):
+            heading = heading.removesuffix(f" {symbol.kind}")
+        members = [m for m in symbol.methods if not m.origin] if symbol.kind == "interface" \
+            else [m for m in symbol.methods if not m.promotion_depth] + methods
+        label = _dot_escape(heading)
+        if members:
+            label += "\\n\\n" + "".join(_dot_escape(self._method(m)) + "\\l" for m in members)
+        style = ", style=dashed" if symbol.kind == "interface" else ""
+        return f'{_dot_id(symbol.qualified_name)} [label="{label}"{style}];'
+
+    @staticmethod
+    def _method(method: Symbol) -> str:
+        """Get a method's signature without `func` and its receiver, e.g. "Add(n int)"."""
+        signature = method.signature
+        if signature.startswith("func ("):
+            signature = signature[_closing_paren(signature, len("func ")) + 1:].lstrip()
+        return signature
+
+    @staticmethod
+    def _edges(symbol: Symbol) -> list[tuple[str, str]]:
+        """Get the (target, attributes) of a type's embedding and implements edges."""
+        edges = [(embed, ' [arrowhead=empty, label="embeds"]') for embed in symbol.embeds if _EMBED_RE.match(embed)]
+        edges.extend((f.type, ' [arrowhead=empty, label="embeds"]') for f in symbol.fields if not f.name)
+        edges.extend((iface, " [arrowhead=empty, style=dashed]") for iface in symbol.implements)
+        edges.extend((iface, ' [arrowhead=empty, style=dashed, label="*"]') for iface in symbol.pointer_implements)
+        return edges
+
+    @staticmethod
+    def _resolve(name: str, owner: Symbol) -> str:
+        """Get the node ID of a type named in an owner's declaration, e.g. "*geo.Point[T]"."""
+        base = name.lstrip("*").split("[", 1)[0]
+        qualifier, dot, local = base.rpartition(".")
+        if not dot:
+            return f"{owner.package}.{base}" if owner.package else base
+        paths = {assumed_package_name(path): path for path in owner.imports}
+        return f"{paths[qualifier]}.{local}" if qualifier in paths else base
+
+
+# Embedded interface elements that name a type, rather than a type set
+# such as "~int | ~string"
+_EMBED_RE = re.compile(r"^\*?[\w./]+(\[.*\])?$")
+
+
+def _dot_id(text: str) -> str:
+    """Quote text as a DOT ID."""
+    return f'"{_dot_escape(text)}"'
+
+
+def _dot_escape(text: str) -> str:
+    """Escape text for a double-quoted DOT string."""
+    return text.replace("\\", "\\\\").replace('"', '\\"').replace("\n", "\\n")
+
+
+# ANSI escape codes of the text format's highlights
+_ANSI_STYLES = {
+    "package": "\033[1m",
+    "type": "\033[36m",
+    "func": "\033[32m",
+    "receiver": "\033[33m",
+    "doc": "\033[2m",
+    "warning": "\033[31m",
+}
+_ANSI_RESET = "\033[0m"
+
+# Highlight style of each kind's declared name; consts, vars, and fields
+# are left plain
+_NAME_STYLES = {
+    "func": "func",
+    "method": "func",
+    "test": "func",
+    "benchmark": "func",
+    "example": "func",
+    "struct": "type",
+    "interface": "type",
+    "type": "type",
+    "alias": "type",
+}
+
+
+def _closing_paren(text: str, start: int) -> int:
+    """Find the index of the parenthesis closing the one at `start`, or -1."""
+    depth = 0
+    for i in range(start, len(text)):
+        if text[i] == "(":
+            depth += 1
+        elif text[i] == ")":
+            depth -= 1
+            if depth == 0:
+                return i
+    return -1
+
+
+# Plural nouns for package summary counts
+_KIND_PLURALS = {
+    "func": "funcs",
+    "method": "methods",
+    "struct": "structs",
+    "interface": "interfaces",
+    "type": "types",
+    "alias": "aliases",
+    "const": "consts",
+    "var": "vars",
+    "field": "fields",
+    "test": "tests",
+    "benchmark": "benchmarks",
+    "example": "examples",
+}
+
+
+def describe_counts(summary: PackageSummary) -> str:
+    """Describe a package's size, e.g. "1 file, 3 funcs, 4 methods"."""
+    parts = [f"{summary.files} {'file' if summary.files == 1 else 'files'}"]
+    for kind, count in summary.kinds.items():
+        parts.append(f"{count} {kind if count == 1 else _KIND_PLURALS.get(kind, kind)}")
+    if summary.lines:
+        parts.append(f"{_count(summary.lines, 'line')} of code")
+        parts.append(_count(summary.loc, "statement"))
+    return ", ".join(parts)
+
+
+def total_kinds(summaries: dict[str, PackageSummary]) -> dict[str, int]:
+    """Add up the kind counts of packages, in SYMBOL_KINDS order."""
+    totals = {kind: 0 for kind in SYMBOL_KINDS}
+    for summary in summaries.values():
+        for kind, count in summary.kinds.items():
+            totals[kind] += count
+    return {kind: count for kind, count in totals.items() if count}
+
+
+def _tally(kinds: dict[str, int]) -> str:
+    """List kind counts, e.g. "2 func, 4 method"."""
+    return ", ".join(f"{count} {kind}" for kind, count in kinds.items())
+
+
+def _count(count: int, noun: str) -> str:
+    """Describe a count of a noun, e.g. "1 line" or "3 lines"."""
+    return f"{count} {noun if count == 1 else noun + 's'}"
+
+
+# Registry of available formats (name -> formatter class)
+FORMATTERS: dict[str, type[SymbolFormatter]] = {
+    "text": TextFormatter,
+    "json": JsonFormatter,
+    "jsonl": JsonlFormatter,
+    "markdown": MarkdownFormatter,
+    "lsp": LspFormatter,
+    "dot": DotFormatter,
+}
+
+
+def get_formatter(name: str) -> SymbolFormatter:
+    """
+    Create a formatter by format name.
+
+    Args:
+        name: Format name (e.g. "text", "json")
+
+    Returns:
+        SymbolFormatter instance
+
+    Raises:
+        ValueError: If the format is not registered
+    """
+    formatter_cls = FORMATTERS.get(name)
+    if formatter_cls is None:
+        raise ValueError(
+            f"Unsupported format: {name}. "
+            f"Supported: {list(FORMATTERS.keys())}"
+        )
+    return formatter_cls()
@@ -0,0 +1,125 @@
+"""
+File tree abstraction for the package walker.
+
+The walker reads trees through the Traversable protocol of
+importlib.resources (`iterdir`, `is_dir`, `is_file`, `name`, `open`, and
+`/`), plus `parent` for finding go.mod above the walked directory. OS
+directories (pathlib.Path), zip archives (zipfile.Path), and in-memory
+trees (MapFS) are therefore walked the same way:
+
+    from ctxd.symbols import MapFS, Options, extract_fs
+
+    fsys = MapFS({
+        "go.mod": "module example.com/shapes\\n",
+        "geo/point.go": "package geo\\n\\ntype Point struct{}\\n",
+    })
+    index = extract_fs(fsys, options=Options(recursive=True))
+"""
+
+import io
+import posixpath
+from typing import Iterator, Optional, Union
+
+try:
+    from importlib.resources.abc import Traversable
+except ImportError:  # Python 3.10
+    from importlib.abc import Traversable
+
+
+class MapFS(Traversable):
+    """
+    An in-memory file tree built from a mapping of path to contents.
+
+    Paths are slash-separated and relative to the root; directories exist
+    implicitly as the parents of files. Each file's path, e.g. "geo/point.go",
+    is what str() returns and what extracted symbols record as their file.
+    """
+
+    def __init__(self, files: dict[str, Union[str, bytes]], _path: Optional[str] = None):
+        """
+        Initialize the tree.
+
+        Args:
+            files: File path to text or bytes, e.g. {"a/a.go": "package a"}
+        """
+        # Entries of a tree share its cleaned mapping
+        self._files = files if _path is not None else {_clean(path): data for path, data in files.items()}
+        self._path = _path or ""
+
+    @property
+    def name(self) -> str:
+        """Get the last path component ("" for the root)."""
+        return posixpath.basename(self._path)
+
+    @property
+    def parent(self) -> "MapFS":
+        """Get the containing directory; the root is its own parent."""
+        return MapFS(self._files, posixpath.dirname(self._path)) if self._path else self
+
+    def is_file(self) -> bool:
+        """Check whether this path is a file of the tree."""
+        return self._path in self._files
+
+    def is_dir(self) -> bool:
+        """Check whether this path is the root or the parent of a file."""
+        prefix = self._path + "/"
+        return not self._path or any(path.startswith(prefix) for path in self._files)
+
+    def iterdir(self) -> Iterator["MapFS"]:
+        """Iterate over the direct children, sorted by name."""
+        if not self.is_dir():
+            raise NotADirectoryError(f"Not a directory: {self}")
+        prefix = self._path + "/" if self._path else ""
+        names = {path[len(prefix):].split("/", 1)[0] for path in self._files if path.startswith(prefix)}
+        for name in sorted(names):
+            yield MapFS(self._files, prefix + name)
+
+    def joinpath(self, *descendants: str) -> "MapFS":
+        """Get a path below this one."""
+        path = posixpath.join(self._path, *descendants) if descendants else self._path
+        return MapFS(self._files, _clean(path))
+
+    def __truediv__(self, child: str) -> "MapFS":
+        """Get a path below this one: `fsys / "geo" / "point.go"`."""
+        return self.joinpath(child)
+
+    def open(self, mode: str = "r", *args, **kwargs):
+        """
+        Open the file for reading.
+
+        Raises:
+            FileNotFoundError: If the path is not a file of the tree
+            ValueError: If a write mode is requested
+        """
+        if mode not in ("r", "rb"):
+            raise ValueError(f"MapFS is read-only, cannot open with mode {mode!r}")
+        if not self.is_file():
+            raise FileNotFoundError(f"No such file: {self}")
+        data = self._files[self._path]
+        if mode == "rb":
+            return io.BytesIO(data if isinstance(data, bytes) else data.encode("utf-8"))
+        if isinstance(data, bytes):
+            data = data.decode(kwargs.get("encoding") or "utf-8", kwargs.get("errors") or "strict")
+        return io.StringIO(data)
+
+    def __str__(self) -> str:
+        """Get the path relative to the root, "." for the root itself."""
+        return self._path or "."
+
+    def __eq__(self, other: object) -> bool:
+        """Paths are equal when they name the same entry of the same tree."""
+        return isinstance(other, MapFS) and other._files is self._files and other._path == self._path
+
+    def __hash__(self) -> int:
+        """Hash by path."""
+        return hash(self._path)
+
+    def __repr__(self) -> str:
+        """String representation."""
+        return f"MapFS({str(self)!r}, files={len(self._files)})"
+
+
+def _clean(path: str) -> str:
+    """Normalize a slash-separated relative path ("./a//b.go" -> "a/b.go")."""
+    path = posixpath.normpath(path.strip("/"))
+    return "" if path == "." else path
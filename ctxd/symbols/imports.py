@@ -0,0 +1,172 @@
+"""
+Import usage of Go declarations.
+
+Resolves the package references in a declaration (`fmt.Println`,
+`yaml.Node`) against the import specs of its file, so that each symbol can
+list the import paths it actually uses. Aliased imports are resolved by
+their alias, blank imports are never used by a declaration, and dot
+imports are matched best-effort (see FileImports.used_by).
+"""
+
+import re
+from typing import Iterable, Optional
+
+from tree_sitter import Node
+
+# Trailing major version of gopkg.in paths, e.g. "yaml.v3"
+_GOPKG_VERSION_RE = re.compile(r"\.v\d+$")
+
+# Major version path elements, e.g. "v2" in "github.com/x/y/v2"
+_MAJOR_VERSION_RE = re.compile(r"^v\d+$")
+
+
+def assumed_package_name(import_path: str) -> str:
+    """
+    Guess the package name of an import path, as goimports does.
+
+    The name is the last path element, skipping a major version element
+    and dropping a "go-" prefix or a gopkg.in version suffix:
+    "github.com/x/go-yaml/v2" -> "yaml", "gopkg.in/yaml.v3" -> "yaml".
+
+    Args:
+        import_path: Import path without quotes
+
+    Returns:
+        The assumed package name
+    """
+    elements = import_path.split("/")
+    name = elements[-1]
+    if _MAJOR_VERSION_RE.match(name) and len(elements) > 1:
+        name = elements[-2]
+    name = _GOPKG_VERSION_RE.sub("", name)
+    if name.startswith("go-"):
+        name = name[len("go-"):]
+    # Up to the first character that cannot be part of an identifier
+    match = re.match(r"[A-Za-z0-9_]*", name)
+    return match.group(0) or name
+
+
+class FileImports:
+    """
+    The import specs of one file, keyed by the name they are referenced by.
+    """
+
+    def __init__(self, root: Node, top_level_names: Iterable[str] = ()):
+        """
+        Read the import declarations of a parsed file.
+
+        Args:
+            root: The source_file node
+            top_level_names: Names declared at the top level of the file,
+                which never resolve to a dot import
+        """
+        self.by_name: dict[str, str] = {}
+        self.dot_imports: list[str] = []
+        self.top_level_names = set(top_level_names)
+
+        for decl in root.named_children:
+            if decl.type != "import_declaration":
+                continue
+            for spec in _import_specs(decl):
+                path_node = spec.child_by_field_name("path")
+                if path_node is None:
+                    continue
+                import_path = _text(path_node).strip("\"`")
+                name_node = spec.child_by_field_name("name")
+                if name_node is None:
+                    self.by_name[assumed_package_name(import_path)] = import_path
+                elif name_node.type == "dot":
+                    self.dot_imports.append(import_path)
+                elif name_node.type != "blank_identifier":
+                    self.by_name[_text(name_node)] = import_path
+
+    def used_by(self, node: Node, bodies: bool = True) -> list[str]:
+        """
+        List the import paths a declaration references.
+
+        Qualified references (`str.ToUpper` for `import str "strings"`) are
+        resolved exactly. Without type information, a dot import is
+        counted as used when the declaration calls an exported function or
+        names an exported type that the file does not declare itself; with
+        several dot imports, each of them is.
+
+        Args:
+            node: The declaration, e.g. a function_declaration or type_spec
+            bodies: Look inside function bodies and literals too
+
+        Returns:
+            Unique import paths, sorted
+        """
+        used: set[str] = set()
+        unqualified: list[str] = []
+        type_params: set[str] = set()
+        stack = [node]
+        while stack:
+            current = stack.pop()
+            if current.type == "block" and not bodies:
+                continue
+            if current.type == "selector_expression":
+                operand = current.child_by_field_name("operand")
+                if operand is not None and operand.type == "identifier":
+                    package = self.by_name.get(_text(operand))
+                    if package:
+                        used.add(package)
+            elif current.type == "qualified_type":
+                package = self.by_name.get(_text(current.child_by_field_name("package")))
+                if package:
+                    used.add(package)
+                # The type name belongs to that package, not to a dot import
+                continue
+            elif current.type == "type_parameter_declaration":
+                type_params.update(_text(n) for n in current.children_by_field_name("name"))
+            elif self.dot_imports:
+                name = self._unqualified_reference(current)
+                if name:
+                    unqualified.append(name)
+            stack.extend(current.named_children)
+
+        if any(name not in type_params for name in unqualified):
+            used.update(self.dot_imports)
+        return sorted(used)
+
+    def _unqualified_reference(self, node: Node) -> str:
+        """Get the exported name a node calls or names as a type, if the file does not declare it."""
+        if node.type == "type_identifier":
+            if _is_declared_name(node):
+                return ""
+            name = _text(node)
+        elif node.type == "call_expression":
+            function = node.child_by_field_name("function")
+            if function is None or function.type != "identifier":
+                return ""
+            name = _text(function)
+        else:
+            return ""
+        return name if name[:1].isupper() and name not in self.top_level_names else ""
+
+
+def _import_specs(decl: Node) -> list[Node]:
+    """List the specs of `import "x"` and `import ( ... )`."""
+    specs = []
+    for child in decl.named_children:
+        if child.type == "import_spec":
+            specs.append(child)
+        elif child.type == "import_spec_list":
+            specs.extend(c for c in child.named_children if c.type == "import_spec")
+    return specs
+
+
+def _is_declared_name(node: Node) -> bool:
+    """Check whether a type identifier is the name being declared, e.g. `T` in `type T int`."""
+    parent = node.parent
+    if parent is None:
+        return False
+    name = parent.child_by_field_name("name")
+    return name is not None and name.start_byte == node.start_byte and parent.type in (
+        "type_spec", "type_alias", "type_parameter_declaration",
+    )
+
+
+def _text(node: Optional[Node]) -> str:
+    """Get the source text of a node."""
+    return node.text.decode("utf8") if node is not None else ""
@@ -0,0 +1,131 @@
+"""
+In-memory symbol index for incremental re-extraction.
+
+Keeps the symbols of each Go file so that a changed file can be
+re-parsed on its own and compared against its previous extraction.
+"""
+
+import logging
+from pathlib import Path
+from typing import Optional
+
+from .extractor import GoSymbolExtractor
+from .models import Symbol, SymbolDiff
+
+logger = logging.getLogger(__name__)
+
+
+def diff_symbols(old: list[Symbol], new: list[Symbol], signatures_only: bool = False) -> SymbolDiff:
+    """
+    Compare two extractions of the same file or package.
+
+    Symbols are matched by `Symbol.local_name`, so methods do not collide
+    with a function of the same name. A symbol counts as modified when its
+    declaration changes (signature, doc, or members); moving it to another
+    line does not.
+
+    Args:
+        old: Previous symbols
+        new: Current symbols
+        signatures_only: Only count changes of kind or rendered signature
+            as modifications, ignoring docs and members
+
+    Returns:
+        SymbolDiff with added and modified symbols in new source order and
+        removed symbols in old source order
+    """
+    fingerprint = _signature if signatures_only else _fingerprint
+    old_by_key = {s.local_name: s for s in old}
+    new_keys = {s.local_name for s in new}
+
+    diff = SymbolDiff()
+    for symbol in new:
+        previous = old_by_key.get(symbol.local_name)
+        if previous is None:
+            diff.added.append(symbol)
+        elif fingerprint(previous) != fingerprint(symbol):
+            diff.modified.append(symbol)
+            diff.previous.append(previous)
+    diff.removed = [s for s in old if s.local_name not in new_keys]
+    return diff
+
+
+def _fingerprint(symbol: Symbol) -> tuple:
+    """Get the position-independent content of a symbol for comparison."""
+    return (
+        symbol.kind,
+        symbol.signature,
+        symbol.doc,
+        tuple(f.signature for f in symbol.fields),
+        tuple(m.signature for m in symbol.methods),
+        tuple(symbol.embeds),
+    )
+
+
+def _signature(symbol: Symbol) -> tuple:
+    """Get the parts of a symbol that importers depend on, members aside."""
+    return (symbol.kind, symbol.signature)
+
+
+class SymbolIndex:
+    """
+    Symbols of a set of Go files, kept per file.
+
+    Each update re-extracts a single file and reports what changed.
+    """
+
+    def __init__(self, extractor: Optional[GoSymbolExtractor] = None):
+        """
+        Initialize an empty index.
+
+        Args:
+            extractor: Extractor used to parse files (defaults to a new one)
+        """
+        self.extractor = extractor or GoSymbolExtractor()
+        self._files: dict[str, list[Symbol]] = {}
+
+    def update_file(self, path: Path) -> SymbolDiff:
+        """
+        Re-extract a file and replace its symbols.
+
+        Args:
+            path: Go file to parse
+
+        Returns:
+            Changes relative to the file's previous symbols
+        """
+        key = str(Path(path))
+        symbols = self.extractor.extract_file(Path(path))
+        diff = diff_symbols(self._files.get(key, []), symbols)
+        self._files[key] = symbols
+        logger.debug(f"Updated {key}: {len(symbols)} symbols")
+        return diff
+
+    def remove_file(self, path: Path) -> SymbolDiff:
+        """
+        Drop a file from the index.
+
+        Args:
+            path: Go file that was deleted
+
+        Returns:
+            Diff listing the file's symbols as removed
+        """
+        return SymbolDiff(removed=self._files.pop(str(Path(path)), []))
+
+    def symbols(self) -> list[Symbol]:
+        """Get all indexed symbols ordered by file path and source position."""
+        return [s for key in sorted(self._files) for s in self._files[key]]
+
+    @property
+    def files(self) -> list[str]:
+        """Get the indexed file paths in sorted order."""
+        return sorted(self._files)
+
+    def __len__(self) -> int:
+        """Get the number of indexed files."""
+        return len(self._files)
+
+    def __repr__(self) -> str:
+        """String representation."""
+        return f"SymbolIndex(files={len(self._files)})"
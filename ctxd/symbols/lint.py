@@ -0,0 +1,59 @@
+"""
+Advisory checks over extracted symbols.
+
+check_receivers() flags types whose methods mix value and pointer
+receivers. Go allows the mix, but it is a common source of bugs: value
+methods work on a copy, so changes they make are lost, and only the
+pointer type has every method, so the type itself satisfies fewer
+interfaces than its methods suggest. Go's code review guidelines advise
+one receiver mode per type.
+"""
+
+from typing import Iterable
+
+from .models import ReceiverMix, Symbol
+
+
+def check_receivers(symbols: Iterable[Symbol]) -> list[ReceiverMix]:
+    """
+    Find the types whose declared methods mix value and pointer receivers.
+
+    Only methods declared on the type count, not those promoted from
+    embedded fields. Methods grouped under their type are checked as well.
+
+    Args:
+        symbols: Extracted symbols, possibly spanning several packages
+
+    Returns:
+        One ReceiverMix per mixing type, in the order the types (or,
+        without them, their first methods) appear
+    """
+    # (package, type name) of each receiver type to its declaration and
+    # methods, in input order
+    owners: dict[tuple[str, str], Symbol] = {}
+    methods: dict[tuple[str, str], list[Symbol]] = {}
+    for symbol in symbols:
+        if symbol.kind == "method":
+            key = (symbol.package, symbol.receiver_type_name)
+            owners.setdefault(key, symbol)
+            methods.setdefault(key, []).append(symbol)
+        elif symbol.kind != "interface":
+            key = (symbol.package, symbol.name)
+            owners[key] = symbol
+            methods.setdefault(key, []).extend(m for m in symbol.methods if not m.promotion_depth)
+
+    mixes = []
+    for key, owner in owners.items():
+        declared = sorted(methods[key], key=lambda m: (m.file, m.line))
+        pointer = [m.name for m in declared if m.pointer_receiver]
+        value = [m.name for m in declared if not m.pointer_receiver]
+        if pointer and value:
+            package, name = key
+            mixes.append(ReceiverMix(
+                type=f"{package}.{name}" if package else name,
+                file=owner.file,
+                line=owner.line,
+                pointer_methods=pointer,
+                value_methods=value,
+            ))
+    return mixes
@@ -0,0 +1,355 @@
+"""
+MCP server over the symbols of a Go package tree.
+
+Lets assistants query Go declarations as Model Context Protocol tools over
+stdio (`ctxd mcp`):
+
+- list_symbols: the symbols of a directory, optionally of one package
+- get_symbol: one symbol by qualified or local name, with its source range
+- search_symbols: symbols whose name or doc matches a query, ranked as
+  by `ctxd find` and the HTTP server's /search
+
+Every tool takes the extraction settings of Options as parameters and
+extracts through extract_dir() with the parse cache, so results match
+`ctxd symbols` and repeated calls only re-parse changed files. The tools
+are plain methods of SymbolTools, so they can be used without the MCP
+transport; create_server() registers them with FastMCP, which handles the
+initialize handshake and returns each result as structured JSON content.
+"""
+
+import logging
+from pathlib import Path
+from typing import Any, Optional, Union
+
+from .api import Index, Options, extract_dir
+from .paths import rewrite_paths
+from .search import search
+from .server import match_dict, symbol_dict, with_span
+
+logger = logging.getLogger(__name__)
+
+# Default and maximum number of search_symbols results
+DEFAULT_SEARCH_LIMIT = 20
+MAX_SEARCH_LIMIT = 200
+
+
+class SymbolTools:
+    """
+    The MCP tools, answering with JSON-serializable dicts.
+
+    Failures are reported as an `error` key rather than raised, as the
+    semantic search tools of ctxd.mcp_server do.
+    """
+
+    def __init__(self, root: Path, cache: bool = True):
+        """
+        Initialize the tools.
+
+        Args:
+            root: Directory that tool paths, and the file paths of
+                results, are relative to; paths outside it are refused
+            cache: Reuse parse results of unchanged files between calls
+        """
+        self.root = Path(root).resolve()
+        self.cache = cache
+
+    def list_symbols(
+        self,
+        path: str = ".",
+        package: Optional[str] = None,
+        recursive: bool = True,
+        exported_only: bool = False,
+        include_tests: bool = False,
+        kinds: Optional[list[str]] = None,
+        include: Optional[list[str]] = None,
+        exclude: Optional[list[str]] = None,
+        group_methods: bool = False,
+        calls: bool = False,
+        metrics: bool = False,
+        include_source: bool = False,
+    ) -> dict[str, Any]:
+        """
+        List the symbols of a directory.
+
+        The remaining parameters are the extraction settings of Options.
+
+        Args:
+            path: Directory relative to the root
+            package: Only list this package (an import path)
+
+        Returns:
+            `packages` (summaries), `symbols`, and `errors` (parse errors)
+        """
+        index = self._index(path, Options(
+            recursive=recursive,
+            exported_only=exported_only,
+            include_tests=include_tests,
+            kinds=kinds,
+            include=include,
+            exclude=exclude,
+            group_methods=group_methods,
+            calls=calls,
+            metrics=metrics,
+            include_source=include_source,
+        ))
+        if isinstance(index, dict):
+            return index
+        if package is not None:
+            if package not in index.packages:
+                return {"error": f"Unknown package: {package}"}
+            summaries = [index.summaries[package]]
+            symbols = index.package(package)
+        else:
+            summaries = list(index.summaries.values())
+            symbols = index.symbols()
+        return {
+            "packages": [summary.to_dict() for summary in summaries],
+            "symbols": [symbol_dict(s, self.root) for s in symbols],
+            "errors": [str(e) for e in rewrite_paths(index.errors, self.root, "rel")],
+        }
+
+    def get_symbol(
+        self,
+        name: str,
+        path: str = ".",
+        package: Optional[str] = None,
+        recursive: bool = True,
+        exported_only: bool = False,
+        include_tests: bool = False,
+        calls: bool = False,
+        metrics: bool = False,
+        include_source: bool = False,
+    ) -> dict[str, Any]:
+        """
+        Look up one symbol by qualified name ("example.com/calc.Calculator.Add")
+        or by name within its package ("Calculator.Add").
+
+        The remaining parameters are the extraction settings of Options.
+
+        Args:
+            name: Qualified or local name
+            path: Directory relative to the root
+            package: Only look in this package (an import path)
+
+        Returns:
+            The symbol with its source range under `span`, or an `error`
+            with the `candidates` when the name is ambiguous
+        """
+        index = self._index(path, Options(
+            recursive=recursive,
+            exported_only=exported_only,
+            include_tests=include_tests,
+            calls=calls,
+            metrics=metrics,
+            include_source=include_source,
+        ))
+        if isinstance(index, dict):
+            return index
+        if package is not None and package not in index.packages:
+            return {"error": f"Unknown package: {package}"}
+
+        matches = index.find(name, package)
+        if not matches:
+            return {"error": f"Unknown symbol: {name}"}
+        if len(matches) > 1:
+            return {
+                "error": f"Ambiguous symbol: {name}",
+                "candidates": [s.qualified_name for s in matches],
+            }
+        return with_span(matches[0], self.root)
+
+    def search_symbols(
+        self,
+        query: str,
+        path: str = ".",
+        limit: int = DEFAULT_SEARCH_LIMIT,
+        recursive: bool = True,
+        exported_only: bool = False,
+        include_tests: bool = False,
+        kinds: Optional[list[str]] = None,
+        include: Optional[list[str]] = None,
+        exclude: Optional[list[str]] = None,
+        metrics: bool = False,
+        include_source: bool = False,
+    ) -> dict[str, Any]:
+        """
+        Fuzzy-find symbols by name, or by doc comment, with search(), as
+        `ctxd find --in-doc` and the HTTP server's /search?doc=true do.
+
+        Exact names and prefixes rank first, then other name matches, then
+        doc comments containing the query. The remaining parameters are
+        the extraction settings of Options.
+
+        Args:
+            query: Text to look for
+            path: Directory relative to the root
+            limit: Maximum number of results
+
+        Returns:
+            `query`, `count`, and the best `symbols`, at most `limit`, each
+            with its `score` and `matched` field
+        """
+        if not query.strip():
+            return {"error": "Empty query"}
+        if not 1 <= limit <= MAX_SEARCH_LIMIT:
+            return {"error": f"Invalid limit: {limit} (expected 1 to {MAX_SEARCH_LIMIT})"}
+        index = self._index(path, Options(
+            recursive=recursive,
+            exported_only=exported_only,
+            include_tests=include_tests,
+            kinds=kinds,
+            include=include,
+            exclude=exclude,
+            metrics=metrics,
+            include_source=include_source,
+        ))
+        if isinstance(index, dict):
+            return index
+
+        matches = search(index.symbols(), query, in_doc=True, limit=limit)
+        return {"query": query, "count": len(matches), "symbols": [match_dict(m, self.root) for m in matches]}
+
+    def _index(self, path: str, options: Options) -> Union[Index, dict[str, Any]]:
+        """Extract the directory a tool call names, or describe why it cannot be."""
+        directory = (self.root / path).resolve()
+        if directory != self.root and self.root not in directory.parents:
+            return {"error": f"Path is outside the served directory: {path}"}
+        if not directory.is_dir():
+            return {"error": f"Not a directory: {path}"}
+
+        options.cache = self.cache
+        try:
+            return extract_dir(directory, options)
+        except ValueError as e:
+            return {"error": str(e)}
+        except Exception as e:
+            logger.error(f"Failed to extract symbols from {directory}: {e}", exc_info=True)
+            return {"error": str(e)}
+
+
+def create_server(tools: SymbolTools):
+    """
+    Register the tools with an MCP server.
+
+    Args:
+        tools: Tool implementations
+
+    Returns:
+        A FastMCP server; run() serves it over stdio
+    """
+    from mcp.server.fastmcp import FastMCP
+
+    server = FastMCP("ctxd-symbols")
+
+    @server.tool()
+    def list_symbols(
+        path: str = ".",
+        package: Optional[str] = None,
+        recursive: bool = True,
+        exported_only: bool = False,
+        include_tests: bool = False,
+        kinds: Optional[list[str]] = None,
+        include: Optional[list[str]] = None,
+        exclude: Optional[list[str]] = None,
+        group_methods: bool = False,
+        calls: bool = False,
+        metrics: bool = False,
+        include_source: bool = False,
+    ) -> dict[str, Any]:
+        """
+        List the Go symbols (functions, methods, types, constants, variables) of a directory.
+
+        Args:
+            path: Directory relative to the served root (default: ".")
+            package: Only list this package, by import path
+            recursive: Walk subdirectories (default: true)
+            exported_only: Only include the exported API surface
+            include_tests: Include _test.go files
+            kinds: Only these kinds: func, method, struct, interface, type, alias, const, var, field,
+                test, benchmark, example
+            include: Glob patterns of files to extract, relative to path
+            exclude: Glob patterns of files to skip
+            group_methods: Nest methods under their receiver type
+            calls: Record the callees of each function and method
+            metrics: Record the cyclomatic complexity and lines of code of each function and method
+            include_source: Include the source text of each declaration
+
+        Returns:
+            Package summaries, symbols, and parse errors
+        """
+        return tools.list_symbols(
+            path, package, recursive, exported_only, include_tests, kinds, include, exclude,
+            group_methods, calls, metrics, include_source,
+        )
+
+    @server.tool()
+    def get_symbol(
+        name: str,
+        path: str = ".",
+        package: Optional[str] = None,
+        recursive: bool = True,
+        exported_only: bool = False,
+        include_tests: bool = False,
+        calls: bool = False,
+        metrics: bool = False,
+        include_source: bool = False,
+    ) -> dict[str, Any]:
+        """
+        Get one Go symbol by qualified name ("example.com/calc.Calculator.Add") or local name ("Calculator.Add").
+
+        Args:
+            name: Qualified or local name; methods are qualified by their receiver type
+            path: Directory relative to the served root (default: ".")
+            package: Only look in this package, by import path
+            recursive: Walk subdirectories (default: true)
+            exported_only: Only include the exported API surface
+            include_tests: Include _test.go files
+            calls: Record the callees of functions and methods
+            metrics: Record cyclomatic complexity and lines of code
+            include_source: Include the declaration's source text
+
+        Returns:
+            The symbol with its source range, or an error listing candidates when ambiguous
+        """
+        return tools.get_symbol(name, path, package, recursive, exported_only, include_tests, calls, metrics, include_source)
+
+    @server.tool()
+    def search_symbols(
+        query: str,
+        path: str = ".",
+        limit: int = DEFAULT_SEARCH_LIMIT,
+        recursive: bool = True,
+        exported_only: bool = False,
+        include_tests: bool = False,
+        kinds: Optional[list[str]] = None,
+        include: Optional[list[str]] = None,
+        exclude: Optional[list[str]] = None,
+        metrics: bool = False,
+        include_source: bool = False,
+    ) -> dict[str, Any]:
+        """
+        Fuzzy-find Go symbols by name, as fzf does, or by doc comment; exact names and prefixes rank first.
+
+        Args:
+            query: Characters to look for in order, ignoring case, e.g. "parse" or "calcadd"
+            path: Directory relative to the served root (default: ".")
+            limit: Maximum number of results (default: 20)
+            recursive: Walk subdirectories (default: true)
+            exported_only: Only include the exported API surface
+            include_tests: Include _test.go files
+            kinds: Only these kinds: func, method, struct, interface, type, alias, const, var, field,
+                test, benchmark, example
+            include: Glob patterns of files to search, relative to path
+            exclude: Glob patterns of files to skip
+            metrics: Record cyclomatic complexity and lines of code
+            include_source: Include each declaration's source text
+
+        Returns:
+            The best matches, best first
+        """
+        return tools.search_symbols(
+            query, path, limit, recursive, exported_only, include_tests, kinds, include, exclude,
+            metrics, include_source,
+        )
+
+    return server
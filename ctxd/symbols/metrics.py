@@ -0,0 +1,130 @@
+"""
+Code metrics for function and method bodies.
+
+Cyclomatic complexity is counted the way gocyclo does: one for the function,
+plus one per decision point. Decision points are `if` and `for` statements,
+non-default `case` clauses of expression, type, and select switches, and
+the `&&` and `||` operators. Bodies of function literals count toward the
+enclosing function.
+
+Lines of code are counted two ways. Code lines are the source lines
+holding code other than comments, so blank lines and comment-only lines
+are left out. Logical lines are the statements and declarations of the
+syntax tree, so a call split over several lines counts once and
+`if x { return }` counts twice, however the code is formatted. Blocks and
+empty statements are not counted, and the statement a label is attached
+to counts once. Each package-level declaration counts once, as a
+function's own statements do; grouped declarations such as `var ( ... )`
+count once however many specs they hold.
+"""
+
+from typing import Optional
+
+from tree_sitter import Node
+
+# Complexity above which text output flags a function (McCabe's suggested limit)
+DEFAULT_COMPLEXITY_THRESHOLD = 10
+
+# Statements and clauses that each add a path through the function
+_BRANCH_NODES = {"if_statement", "for_statement", "expression_case", "type_case", "communication_case"}
+
+# Short-circuit operators, which branch within an expression
+_BRANCH_OPERATORS = {"&&", "||"}
+
+# Literals whose every line is code
+_STRING_NODES = {"raw_string_literal", "interpreted_string_literal"}
+
+# Statements and declarations counted as logical lines of code
+_STATEMENT_NODES = {
+    "import_declaration", "function_declaration", "method_declaration",
+    "expression_statement", "send_statement", "inc_statement", "dec_statement",
+    "assignment_statement", "short_var_declaration", "var_declaration", "const_declaration",
+    "type_declaration", "return_statement", "go_statement", "defer_statement", "if_statement",
+    "for_statement", "expression_switch_statement", "type_switch_statement", "select_statement",
+    "fallthrough_statement", "break_statement", "continue_statement", "goto_statement",
+}
+
+
+def cyclomatic_complexity(body: Optional[Node]) -> int:
+    """
+    Count the cyclomatic complexity of a function body.
+
+    Args:
+        body: The `block` of a function or method declaration, or None for
+            a declaration without a body
+
+    Returns:
+        1 plus the number of decision points; 1 for a body without
+        branches and for a function without a body
+    """
+    complexity = 1
+    stack = [body] if body is not None else []
+    while stack:
+        node = stack.pop()
+        if node.type in _BRANCH_NODES:
+            complexity += 1
+        elif node.type == "binary_expression":
+            operator = node.child_by_field_name("operator")
+            if operator is not None and operator.type in _BRANCH_OPERATORS:
+                complexity += 1
+        stack.extend(node.named_children)
+    return complexity
+
+
+def statement_count(node: Optional[Node]) -> int:
+    """
+    Count the logical lines of code under a node.
+
+    Args:
+        node: A function body, a whole file, or None for a declaration
+            without a body
+
+    Returns:
+        Number of statements and declarations, including those of nested
+        blocks and function literals
+    """
+    count = 0
+    stack = [node] if node is not None else []
+    while stack:
+        current = stack.pop()
+        if current.type in _STATEMENT_NODES:
+            count += 1
+        stack.extend(current.named_children)
+    return count
+
+
+def code_lines(node: Optional[Node], inner: bool = False) -> int:
+    """
+    Count the source lines under a node that hold code other than comments.
+
+    Every node starts at its first token and ends at its last, so the lines
+    a node starts and ends on hold code; lines inside a multi-line string
+    literal do too.
+
+    Args:
+        node: A function body, a whole file, or None
+        inner: Only count the node's children, leaving out lines that
+            only the node itself spans, such as the braces of a body when no
+            code shares their line
+
+    Returns:
+        Number of code lines
+    """
+    if node is None:
+        return 0
+    lines: set[int] = set()
+    stack = list(node.named_children) if inner else [node]
+    while stack:
+        current = stack.pop()
+        if current.type == "comment":
+            continue
+        start, end = current.start_point, current.end_point
+        if current.type in _STRING_NODES:
+            lines.update(range(start[0], end[0] + 1))
+        elif start != end:
+            lines.add(start[0])
+            # A node ending at the start of a line, like a file ending in a
+            # newline, ends on the line before
+            lines.add(end[0] if end[1] > 0 else end[0] - 1)
+        stack.extend(current.named_children)
+    return len(lines)
@@ -0,0 +1,360 @@
+"""
+Data models for symbol extraction.
+
+Defines the Symbol dataclass produced by the Go symbol extractor, the
+PackageSummary synthesized for each package, the ParseError recorded for
+files that do not parse cleanly, and the SymbolDiff describing changes
+between two extractions.
+"""
+
+from dataclasses import dataclass, field, fields as dataclass_fields, asdict
+from typing import Any
+
+# Every value of Symbol.kind
+SYMBOL_KINDS = ("func", "method", "struct", "interface", "type", "alias", "const", "var", "field", "test", "benchmark", "example")
+
+# Kinds of the functions `go test` runs, declared in `_test.go` files
+TEST_KINDS = ("test", "benchmark", "example")
+
+
+@dataclass
+class Symbol:
+    """
+    A declaration extracted from Go source.
+
+    Field names are part of the public API: they are the keys of `to_dict()`
+    and the JSON output, so they are only ever added to, never renamed.
+
+    Attributes:
+        name: Declared identifier (e.g. "Add", "Calculator")
+        kind: Symbol kind ("func", "method", "struct", "interface", "type", "alias",
+            "const", "var", "field", or for functions of `_test.go` files that
+            `go test` runs, "test", "benchmark", "example")
+        file: Path of the source file
+        line: Starting line number (1-indexed)
+        column: Starting column in characters (1-indexed)
+        end_line: Ending line number (1-indexed)
+        end_column: Column just past the last character (1-indexed, like go/token End)
+        name_line: Line of the declared identifier (1-indexed)
+        name_column: Column where the declared identifier starts (1-indexed); for
+            embedded fields, where the embedded type name starts
+        signature: Rendered declaration, e.g. "func Map[T, U any](s []T, f func(T) U) []U"
+        doc: Doc comment text with comment markers stripped
+        summary: First sentence of the doc, without a leading repeat of the name
+        receiver: Receiver type for methods (e.g. "*Calculator"), empty otherwise
+        pointer_receiver: Whether a method has a pointer receiver
+        receiver_mode: How a method receives its value: "pointer", "value",
+            or "" for symbols without a receiver
+        exported: Whether the identifier is exported (starts with an uppercase letter)
+        type: Type expression for fields, constants, and variables, the target of an
+            alias (e.g. "float64", "*Calculator"), or the function type of a function
+            or method without parameter names (e.g. "func(int, int) int"); empty
+            for untyped constants
+        value: Effective value of a constant, or a variable's initializer expression
+        tag: Struct tag contents for fields without quotes (e.g. 'json:"x"')
+        fields: Struct fields as "field" symbols; embedded fields have an empty name
+        methods: Interface method set, including methods promoted from embedded
+            interfaces; for other types, their methods when grouped
+        embeds: Embedded interface elements as written (e.g. "Adder", "io.Reader")
+        origin: For promoted interface methods, the interface that declares the
+            method; for methods promoted into a struct, the embedded type
+            that declares it
+        promotion_depth: For methods promoted into a struct, how many embedded
+            fields deep the declaring type is (1 for a direct embed), 0 otherwise
+        package: Import path of the containing package (set when walking a directory)
+        calls: Callees of a function or method body (when call extraction is enabled)
+        complexity: Cyclomatic complexity of a function or method (when metrics
+            are enabled), 0 otherwise
+        lines: Code lines of a function or method body, i.e. lines holding
+            code other than comments, without the braces' own lines (when
+            metrics are enabled)
+        loc: Logical lines of code of a function or method body, i.e. its
+            statements (when metrics are enabled)
+        implements: Interfaces in the analyzed set that the type satisfies
+        pointer_implements: Interfaces that only a pointer to the type satisfies,
+            because some of the methods have pointer receivers
+        source: Source text of the whole declaration, from its first token to
+            its last, without the doc comment (when source capture is enabled)
+        imports: Import paths the declaration references, e.g. ["fmt"] for
+            a function calling fmt.Printf; sorted
+        deprecated: Whether the doc has a paragraph starting "Deprecated:"
+        deprecation_note: Text of that paragraph after the marker, e.g.
+            "Use NewClient instead."
+        directives: `//go:` directive comments attached to the declaration,
+            as written (e.g. ["//go:embed schema.sql"]); they are not part
+            of `doc`
+        subject: For examples, the local name of the symbol documented, by
+            the go/doc naming convention (e.g. "Calculator.Add" for
+            ExampleCalculator_Add); empty for package examples and examples
+            of names the package does not declare
+    """
+    name: str
+    kind: str
+    file: str
+    line: int
+    signature: str
+    column: int = 0
+    end_line: int = 0
+    end_column: int = 0
+    name_line: int = 0
+    name_column: int = 0
+    doc: str = ""
+    summary: str = ""
+    receiver: str = ""
+    pointer_receiver: bool = False
+    receiver_mode: str = ""
+    exported: bool = False
+    type: str = ""
+    tag: str = ""
+    value: str = ""
+    fields: list["Symbol"] = field(default_factory=list)
+    methods: list["Symbol"] = field(default_factory=list)
+    embeds: list[str] = field(default_factory=list)
+    origin: str = ""
+    promotion_depth: int = 0
+    package: str = ""
+    calls: list[str] = field(default_factory=list)
+    complexity: int = 0
+    lines: int = 0
+    loc: int = 0
+    implements: list[str] = field(default_factory=list)
+    pointer_implements: list[str] = field(default_factory=list)
+    source: str = ""
+    imports: list[str] = field(default_factory=list)
+    deprecated: bool = False
+    deprecation_note: str = ""
+    directives: list[str] = field(default_factory=list)
+    subject: str = ""
+
+    @property
+    def receiver_type_name(self) -> str:
+        """Receiver type name without pointer or type arguments (e.g. "Stack" for "*Stack[T]")."""
+        return self.receiver.lstrip("*").split("[")[0]
+
+    @property
+    def local_name(self) -> str:
+        """Name within the package, qualified by receiver type for methods (e.g. "Calculator.Add")."""
+        receiver = self.receiver_type_name
+        return f"{receiver}.{self.name}" if receiver else self.name
+
+    @property
+    def qualified_name(self) -> str:
+        """Name qualified by package import path when known (e.g. "example.com/calc.Calculator.Add")."""
+        return f"{self.package}.{self.local_name}" if self.package else self.local_name
+
+    def to_dict(self) -> dict[str, Any]:
+        """Convert to a JSON-serializable dictionary."""
+        return asdict(self)
+
+    @classmethod
+    def from_dict(cls, data: dict[str, Any]) -> "Symbol":
+        """
+        Rebuild a symbol from `to_dict()` output.
+
+        Unknown keys are ignored so that data written by a newer version
+        can still be loaded.
+        """
+        known = {f.name for f in dataclass_fields(cls)}
+        values = {k: v for k, v in data.items() if k in known}
+        values["fields"] = [cls.from_dict(f) for f in values.get("fields", [])]
+        values["methods"] = [cls.from_dict(m) for m in values.get("methods", [])]
+        return cls(**values)
+
+
+@dataclass
+class PackageSummary:
+    """
+    Overview of one package: its clause, doc, and size.
+
+    Attributes:
+        name: Package name from the `package` clause (e.g. "calculator")
+        path: Import path, or "" for a single file
+        doc: Package doc comment, i.e. the comment attached to the `package`
+            clause without markers
+        files: Number of Go files in the package
+        kinds: Symbol count per kind, in SYMBOL_KINDS order, omitting kinds
+            with no symbols; methods grouped under their type are counted
+        imports: Import paths used by any of the package's symbols, sorted
+        lines: Code lines of all the package's files, leaving out blank and
+            comment-only lines (when metrics are enabled)
+        loc: Logical lines of code of all the package's files, i.e. their
+            statements and declarations (when metrics are enabled)
+        file_loc: File name to its `lines` and `loc` (when metrics are
+            enabled)
+        directives: `//go:` directives of the package's files that are not
+            attached to a declaration, such as `//go:build` lines and
+            standalone `//go:generate` commands, without repeats
+    """
+    name: str
+    path: str = ""
+    doc: str = ""
+    files: int = 0
+    kinds: dict[str, int] = field(default_factory=dict)
+    imports: list[str] = field(default_factory=list)
+    lines: int = 0
+    loc: int = 0
+    file_loc: dict[str, dict[str, int]] = field(default_factory=dict)
+    directives: list[str] = field(default_factory=list)
+
+    def to_dict(self) -> dict[str, Any]:
+        """Convert to a JSON-serializable dictionary."""
+        return asdict(self)
+
+
+@dataclass
+class ParseError:
+    """
+    A syntax error, or a file that could not be read.
+
+    Attributes:
+        file: Path of the source file
+        line: Line of the error (1-indexed), or 0 when the file could not
+            be read
+        column: Column in characters (1-indexed), or 0 with line
+        message: Description, e.g. 'unexpected "{"' or 'missing "}"'
+    """
+    file: str
+    line: int
+    column: int
+    message: str
+
+    def to_dict(self) -> dict[str, Any]:
+        """Convert to a JSON-serializable dictionary."""
+        return asdict(self)
+
+    def __str__(self) -> str:
+        """Render as `file:line:column: message`, like the go tool."""
+        if not self.line:
+            return f"{self.file}: {self.message}"
+        return f"{self.file}:{self.line}:{self.column}: {self.message}"
+
+
+@dataclass
+class Annotation:
+    """
+    A marker comment such as `// TODO(ana): handle overflow`.
+
+    Attributes:
+        marker: The marker word, e.g. "TODO" or "FIXME"
+        text: Comment text after the marker and its colon
+        file: Path of the source file
+        line: Line of the marker (1-indexed)
+        column: Column of the marker in characters (1-indexed)
+        owner: Name in parentheses after the marker, e.g. "ana"
+        symbol: Name of the enclosing or documented declaration, e.g.
+            "Calculator.Add", or "" at file level
+    """
+    marker: str
+    text: str
+    file: str
+    line: int
+    column: int
+    owner: str = ""
+    symbol: str = ""
+
+    def to_dict(self) -> dict[str, Any]:
+        """Convert to a JSON-serializable dictionary."""
+        return asdict(self)
+
+    def __str__(self) -> str:
+        """Render as `file:line: MARKER(owner): text`."""
+        owner = f"({self.owner})" if self.owner else ""
+        return f"{self.file}:{self.line}: {self.marker}{owner}: {self.text}"
+
+
+@dataclass
+class SymbolDiff:
+    """
+    Changes between two sets of symbols.
+
+    Attributes:
+        added: Symbols present only in the new set
+        removed: Symbols present only in the old set
+        modified: New versions of symbols whose declaration changed
+        previous: Old versions of the modified symbols, in the same order
+        deprecated: New versions of exported symbols that gained a
+            `Deprecated:` paragraph (only set when comparing APIs)
+    """
+    added: list[Symbol] = field(default_factory=list)
+    removed: list[Symbol] = field(default_factory=list)
+    modified: list[Symbol] = field(default_factory=list)
+    previous: list[Symbol] = field(default_factory=list)
+    deprecated: list[Symbol] = field(default_factory=list)
+
+    def extend(self, other: "SymbolDiff") -> None:
+        """Append another diff's changes to this one."""
+        self.added.extend(other.added)
+        self.removed.extend(other.removed)
+        self.modified.extend(other.modified)
+        self.previous.extend(other.previous)
+        self.deprecated.extend(other.deprecated)
+
+    def __bool__(self) -> bool:
+        """A diff is truthy when it contains any change."""
+        return bool(self.added or self.removed or self.modified or self.deprecated)
+
+
+@dataclass
+class MethodMismatch:
+    """
+    A method keeping a type from satisfying an interface.
+
+    Attributes:
+        name: Method name required by the interface
+        reason: "missing" when the type has no such method, "type" when its
+            function type differs, "pointer_receiver" when only the pointer
+            type has it, or "unexported" when the interface's unexported
+            method cannot be provided from another package
+        want: Function type the interface requires, e.g. "func(int) error"
+        have: Function type of the type's method, empty when missing
+    """
+    name: str
+    reason: str
+    want: str
+    have: str = ""
+
+    def __str__(self) -> str:
+        """Describe the mismatch as Go's type checker would."""
+        if self.reason == "missing":
+            return f"missing method {self.name}: want {self.want}"
+        if self.reason == "type":
+            return f"wrong type for method {self.name}: have {self.have}, want {self.want}"
+        if self.reason == "pointer_receiver":
+            return f"method {self.name} has pointer receiver"
+        return f"unexported method {self.name} of another package"
+
+    def to_dict(self) -> dict[str, Any]:
+        """Convert to a JSON-serializable dictionary."""
+        return asdict(self)
+
+
+@dataclass
+class ReceiverMix:
+    """
+    A type whose methods mix value and pointer receivers.
+
+    Attributes:
+        type: Qualified name of the receiver type, e.g.
+            "example.com/calc.Calculator"
+        file: File declaring the type, or its first method when the type
+            is not among the checked symbols
+        line: Line of that declaration (1-indexed)
+        pointer_methods: Methods with pointer receivers, in source order
+        value_methods: Methods with value receivers, in source order
+    """
+    type: str
+    file: str
+    line: int
+    pointer_methods: list[str] = field(default_factory=list)
+    value_methods: list[str] = field(default_factory=list)
+
+    def __str__(self) -> str:
+        """Describe the mix, naming each method under its receiver mode."""
+        return (
+            f"{self.file}:{self.line}: {self.type} mixes value and pointer receivers: "
+            f"pointer {', '.join(self.pointer_methods)}; value {', '.join(self.value_methods)}"
+        )
+
+    def to_dict(self) -> dict[str, Any]:
+        """Convert to a JSON-serializable dictionary."""
+        return asdict(self)
@@ -0,0 +1,424 @@
+"""
+Canonical forms of Go type expressions.
+
+Signatures are compared as text, but one type can be spelled several ways.
+normalize_type() rewrites a type expression so that spellings of the same
+type compare equal:
+
+- type names are qualified by the import path of their package: `geo.Point`
+  in an importer, `g.Point` under `import g ".../geo"`, `Point` under a dot
+  import of geo, and both `Point` and a redundant `geo.Point` within geo
+  itself all become `example.com/geo.Point`
+- names of aliases are replaced by the types they denote, so under
+  `type Reader = io.Reader`, `Reader` becomes `io.Reader`
+- the predeclared aliases are spelled one way: `interface{}` as `any`,
+  `byte` as `uint8`, and `rune` as `int32`
+
+Only type names change; parameter and field names, tags, and spacing are
+kept. TypeNormalizer applies this to extracted symbols, resolving names
+against the other symbols of the tree.
+"""
+
+import re
+from collections.abc import Mapping
+from dataclasses import dataclass, field
+from typing import Iterable, Iterator, Optional
+
+from tree_sitter import Node
+
+from .imports import assumed_package_name
+from .models import Symbol
+from .parsing import FRAGMENT_PREFIX, fragment_parser, node_text
+
+# Predeclared type names, which no import or alias shadows unless the
+# package declares the name itself
+PREDECLARED_TYPES = {
+    "any", "bool", "byte", "comparable", "complex64", "complex128", "error",
+    "float32", "float64", "int", "int8", "int16", "int32", "int64", "rune",
+    "string", "uint", "uint8", "uint16", "uint32", "uint64", "uintptr",
+}
+
+# Predeclared aliases and the types they denote
+_PREDECLARED_ALIASES = {"byte": "uint8", "rune": "int32"}
+
+# Symbol kinds that declare a type name
+_TYPE_KINDS = ("struct", "interface", "type", "alias")
+
+# Outside struct and interface bodies and func types, which may name their
+# parameters, every name in a type expression names a type, so those
+# expressions need no parsing
+_TYPE_NAME_RE = re.compile(r"[A-Za-z_]\w*(?:\.[A-Za-z_]\w*)?")
+_EMPTY_INTERFACE_RE = re.compile(r"\binterface\{\s*\}")
+_TYPE_KEYWORDS = {"chan", "func", "interface", "map", "struct"}
+
+
+@dataclass
+class Scope:
+    """
+    Where a type expression is written, which decides what its names mean.
+
+    Attributes:
+        package: Import path of the declaring package, "" for a lone file
+        imports: Name each import is referenced by (its assumed package name
+            unless renamed) to its import path
+        dot_imports: Import paths imported with `import . "path"`
+        type_params: Type parameters in scope, which are never qualified
+        name: Package name of the declaring package, which qualifies its
+            own names redundantly (defaults to the assumed package name)
+    """
+    package: str = ""
+    imports: dict[str, str] = field(default_factory=dict)
+    dot_imports: list[str] = field(default_factory=list)
+    type_params: set[str] = field(default_factory=set)
+    name: str = ""
+
+
+def normalize_type(
+    type_text: str,
+    scope: Optional[Scope] = None,
+    declared: Optional[Mapping[str, set[str]]] = None,
+    aliases: Optional[Mapping[str, str]] = None,
+) -> str:
+    """
+    Canonicalize a type expression.
+
+    Args:
+        type_text: A type, e.g. "func(geo.Point, interface{}) error"
+        scope: Package and imports the type is written in
+        declared: Import path to the type names its package declares, for
+            the packages that are known. A bare name the declaring package
+            does not declare, when it is known, comes from the dot import
+            declaring it, or from the only dot import.
+        aliases: Qualified alias name ("example.com/geo.Reader") to the
+            normalized type it denotes
+
+    Returns:
+        The type with canonical names, or the text as given if it does not
+        parse as a type
+    """
+    scope = scope or Scope()
+    plain = _EMPTY_INTERFACE_RE.sub("any", type_text)
+    if "{" not in plain and "func" not in plain:
+        return _TYPE_NAME_RE.sub(lambda match: _resolve_name(match.group(0), scope, declared, aliases), plain)
+    return _normalize(type_text, scope, declared, aliases, "type _ = ")
+
+
+def normalize_signature(
+    signature: str,
+    kind: str,
+    scope: Optional[Scope] = None,
+    declared: Optional[Mapping[str, set[str]]] = None,
+    aliases: Optional[Mapping[str, str]] = None,
+) -> str:
+    """
+    Canonicalize the types in a symbol's signature (see normalize_type()).
+
+    Declared names, parameter names, and values are kept, so two
+    signatures normalize the same when they differ only in how their types
+    are spelled.
+
+    Args:
+        signature: Rendered signature, e.g. "func Load(r io.Reader) error"
+        kind: Symbol kind; "field" signatures and interface methods such as
+            "Read(p []byte) (int, error)" are read as members
+
+    Returns:
+        The signature with canonical type names, or as given if it does
+        not parse
+    """
+    if kind == "field":
+        return _normalize(signature, scope, declared, aliases, "type _ struct {\n", "\n}")
+    if kind == "method" and not signature.startswith("func"):
+        return _normalize(signature, scope, declared, aliases, "type _ interface {\n", "\n}")
+    if kind in ("struct", "interface"):
+        # Signatures stop before the braces, e.g. "type Point struct"; the
+        # stand-in body keeps an interface from reading as `any`
+        return _normalize(signature, scope, declared, aliases, "", "{}" if kind == "struct" else "{ _() }")
+    return _normalize(signature, scope, declared, aliases)
+
+
+class TypeNormalizer:
+    """
+    Normalizes the types and signatures of extracted symbols.
+
+    Names resolve against the type declarations among the symbols given,
+    and their aliases are expanded. Symbols record the import paths they
+    use but not what each import is named, so imports are referenced by
+    their assumed package name; a qualifier naming no import is taken for
+    the only import the expression does not otherwise reference, and that
+    import for a dot import when bare names need one.
+    """
+
+    def __init__(self, symbols: Iterable[Symbol]):
+        """
+        Index the type declarations of symbols.
+
+        Args:
+            symbols: Symbols of one or more packages, such as a whole tree
+        """
+        self.declared: dict[str, set[str]] = {}
+        alias_symbols: dict[str, Symbol] = {}
+        for symbol in symbols:
+            if symbol.kind in _TYPE_KINDS:
+                self.declared.setdefault(symbol.package, set()).add(symbol.name)
+            if symbol.kind == "alias":
+                alias_symbols[_qualified(symbol.package, symbol.name)] = symbol
+        self.aliases = _Aliases(self, alias_symbols)
+        self._normalized: dict[tuple, str] = {}
+
+    def normalize(
+        self,
+        type_text: str,
+        package: str = "",
+        imports: Iterable[str] = (),
+        type_params: Iterable[str] = (),
+    ) -> str:
+        """
+        Canonicalize a type written in a package.
+
+        Args:
+            type_text: A type, e.g. a Symbol.type
+            package: Import path of the declaring package
+            imports: Import paths the type may reference
+            type_params: Type parameters in scope
+
+        Returns:
+            The type with canonical names
+        """
+        key = (type_text, package, tuple(imports), frozenset(type_params))
+        if key not in self._normalized:
+            scope = self._scope(type_text, package, key[2], key[3])
+            self._normalized[key] = normalize_type(type_text, scope, self.declared, self.aliases)
+        return self._normalized[key]
+
+    def symbol_type(self, symbol: Symbol, owner: Optional[Symbol] = None) -> str:
+        """
+        Canonicalize the type of a symbol, e.g. a method's function type.
+
+        Args:
+            symbol: Symbol with a `type`
+            owner: Type declaring the symbol, for a field or an interface
+                method, which take their package and imports from it
+        """
+        package = symbol.package or (owner.package if owner else "")
+        imports = symbol.imports or (owner.imports if owner else [])
+        type_params = type_parameters(symbol) | (type_parameters(owner) if owner else set())
+        return self.normalize(symbol.type, package, imports, type_params)
+
+    def signature(self, symbol: Symbol) -> str:
+        """Canonicalize the types in a symbol's signature (see normalize_signature())."""
+        scope = self._scope(symbol.signature, symbol.package, symbol.imports, type_parameters(symbol))
+        return normalize_signature(symbol.signature, symbol.kind, scope, self.declared, self.aliases)
+
+    def _scope(self, text: str, package: str, imports: Iterable[str], type_params: Iterable[str]) -> Scope:
+        """Guess the scope of an expression from the import paths it uses."""
+        qualifiers = set(re.findall(r"\b([A-Za-z_]\w*)\.[A-Za-z_]", text))
+        by_name = {assumed_package_name(path): path for path in imports}
+        unreferenced = [path for name, path in by_name.items() if name not in qualifiers]
+        unknown = qualifiers - set(by_name) - {assumed_package_name(package)}
+        if len(unreferenced) == 1 and len(unknown) == 1:
+            # A renamed import
+            by_name[unknown.pop()] = unreferenced.pop()
+        return Scope(package=package, imports=by_name, dot_imports=unreferenced, type_params=set(type_params))
+
+
+class _Aliases(Mapping):
+    """Qualified alias names to the normalized types they denote, expanded on first use."""
+
+    def __init__(self, normalizer: TypeNormalizer, symbols: dict[str, Symbol]):
+        self.normalizer = normalizer
+        self.symbols = symbols
+        self.expanded: dict[str, str] = {}
+        self.expanding: set[str] = set()
+
+    def __getitem__(self, name: str) -> str:
+        if name not in self.expanded:
+            if name in self.expanding:
+                # An alias cycle is invalid Go; stop at the name
+                raise KeyError(name)
+            alias = self.symbols[name]
+            self.expanding.add(name)
+            try:
+                self.expanded[name] = self.normalizer.normalize(alias.type, alias.package, alias.imports)
+            finally:
+                self.expanding.discard(name)
+        return self.expanded[name]
+
+    def __contains__(self, name: object) -> bool:
+        return name in self.symbols and name not in self.expanding
+
+    def __iter__(self) -> Iterator[str]:
+        return iter(self.symbols)
+
+    def __len__(self) -> int:
+        return len(self.symbols)
+
+
+def type_parameters(symbol: Optional[Symbol]) -> set[str]:
+    """
+    Get the type parameters a symbol declares, or uses on a generic receiver.
+
+    Args:
+        symbol: A generic function ("func Map[T, U any](...)"), type
+            ("type Stack[T comparable] struct"), or method of a generic
+            type (receiver "*Stack[T]")
+
+    Returns:
+        The parameter names, e.g. {"T", "U"}
+    """
+    if symbol is None:
+        return set()
+    if symbol.kind == "method" and symbol.receiver:
+        text = symbol.receiver
+        match = re.match(r"\*?\s*[A-Za-z_]\w*\[", text)
+    else:
+        text = symbol.signature
+        match = re.match(r"(?:func|type)\s+[A-Za-z_]\w*\[", text)
+    if not match:
+        return set()
+    params = _bracketed(text, match.end() - 1)
+    return {part.split()[0] for part in _split_top_level(params) if part.split()}
+
+
+def _normalize(
+    text: str,
+    scope: Optional[Scope],
+    declared: Optional[Mapping[str, set[str]]],
+    aliases: Optional[Mapping[str, str]],
+    before: str = "",
+    after: str = "",
+) -> str:
+    """Rewrite the type names in a declaration, parsed between `before` and `after`."""
+    scope = scope or Scope()
+    source = (FRAGMENT_PREFIX + before + text + after).encode("utf8")
+    root = fragment_parser().parse(source).root_node
+    if root.has_error:
+        return text
+
+    type_params = set(scope.type_params)
+    stack = [root]
+    while stack:
+        node = stack.pop()
+        if node.type == "type_parameter_declaration":
+            type_params.update(node_text(n, source) for n in node.children_by_field_name("name"))
+        stack.extend(node.children)
+
+    replacements: list[tuple[int, int, str]] = []
+    stack = [root]
+    while stack:
+        node = stack.pop()
+        replacement = None
+        if node.type == "qualified_type":
+            package = node.child_by_field_name("package")
+            name = node.child_by_field_name("name")
+            replacement = _resolve_qualified(node_text(package, source), node_text(name, source), scope, aliases)
+        elif node.type == "type_identifier" and not _is_declared_name(node):
+            name = node_text(node, source)
+            if name not in type_params:
+                replacement = _resolve_bare(name, scope, declared, aliases)
+        elif node.type == "interface_type" and not node.named_children:
+            replacement = "any"
+        if replacement is not None:
+            replacements.append((node.start_byte, node.end_byte, replacement))
+        else:
+            stack.extend(node.children)
+
+    result = source
+    for start, end, replacement in sorted(replacements, reverse=True):
+        result = result[:start] + replacement.encode("utf8") + result[end:]
+    start = len(FRAGMENT_PREFIX) + len(before.encode("utf8"))
+    end = len(result) - len(after.encode("utf8"))
+    return result[start:end].decode("utf8")
+
+
+def _resolve_name(
+    name: str,
+    scope: Scope,
+    declared: Optional[Mapping[str, set[str]]],
+    aliases: Optional[Mapping[str, str]],
+) -> str:
+    """Get the canonical spelling of a name matched by _TYPE_NAME_RE."""
+    if "." in name:
+        qualifier, _, local = name.partition(".")
+        return _resolve_qualified(qualifier, local, scope, aliases)
+    if name in _TYPE_KEYWORDS or name in scope.type_params:
+        return name
+    return _resolve_bare(name, scope, declared, aliases)
+
+
+def _resolve_qualified(qualifier: str, name: str, scope: Scope, aliases: Optional[Mapping[str, str]]) -> str:
+    """Get the canonical spelling of a qualified type name such as "geo.Point"."""
+    if qualifier in scope.imports:
+        qualified = f"{scope.imports[qualifier]}.{name}"
+    elif scope.package and qualifier == (scope.name or assumed_package_name(scope.package)):
+        qualified = f"{scope.package}.{name}"
+    else:
+        return f"{qualifier}.{name}"
+    return aliases[qualified] if aliases and qualified in aliases else qualified
+
+
+def _resolve_bare(
+    name: str,
+    scope: Scope,
+    declared: Optional[Mapping[str, set[str]]],
+    aliases: Optional[Mapping[str, str]],
+) -> str:
+    """Get the canonical spelling of an unqualified type name such as "Point"."""
+    local = declared.get(scope.package) if declared else None
+    if local is None or name not in local:
+        if name in PREDECLARED_TYPES:
+            return _PREDECLARED_ALIASES.get(name, name)
+        if local is not None and name[:1].isupper() and scope.dot_imports:
+            # Only a dot import can supply an exported name the package lacks
+            providers = [p for p in scope.dot_imports if declared and name in declared.get(p, ())]
+            if not providers and len(scope.dot_imports) == 1:
+                providers = scope.dot_imports
+            if len(providers) == 1:
+                qualified = f"{providers[0]}.{name}"
+                return aliases[qualified] if aliases and qualified in aliases else qualified
+    qualified = _qualified(scope.package, name)
+    return aliases[qualified] if aliases and qualified in aliases else qualified
+
+
+def _qualified(package: str, name: str) -> str:
+    """Qualify a name by its package's import path, if it has one."""
+    return f"{package}.{name}" if package else name
+
+
+def _is_declared_name(node: Node) -> bool:
+    """Check whether a type identifier is the name being declared, e.g. `T` in `type T int`."""
+    parent = node.parent
+    if parent is None or parent.type not in ("type_spec", "type_alias"):
+        return False
+    name = parent.child_by_field_name("name")
+    return name is not None and name.start_byte == node.start_byte
+
+
+def _bracketed(text: str, start: int) -> str:
+    """Get the text between the bracket at `start` and its closing bracket."""
+    depth = 0
+    for i in range(start, len(text)):
+        if text[i] in "[({":
+            depth += 1
+        elif text[i] in "])}":
+            depth -= 1
+            if depth == 0:
+                return text[start + 1:i]
+    return text[start + 1:]
+
+
+def _split_top_level(text: str) -> list[str]:
+    """Split a list on the commas outside any brackets."""
+    parts, depth, current = [], 0, ""
+    for char in text:
+        if char in "[({":
+            depth += 1
+        elif char in "])}":
+            depth -= 1
+        if char == "," and depth == 0:
+            parts.append(current)
+            current = ""
+        else:
+            current += char
+    parts.append(current)
+    return parts
@@ -0,0 +1,74 @@
+"""
+Canonical ordering for extracted symbols.
+
+Extraction yields symbols in source order; output is sorted into a stable
+order so repeated runs, and runs over reorganized files, diff cleanly.
+"""
+
+import posixpath
+from dataclasses import replace
+
+from .models import Symbol
+
+# Declaration kinds in output order; unknown kinds sort last
+KIND_ORDER = ["const", "var", "interface", "struct", "type", "alias", "func", "method", "test", "benchmark", "example"]
+
+_KIND_RANK = {kind: rank for rank, kind in enumerate(KIND_ORDER)}
+
+
+def sort_symbols(symbols: list[Symbol]) -> list[Symbol]:
+    """
+    Sort symbols by package, file path, kind, name, and start line.
+
+    Package comes before file path so that each package is contiguous:
+    by path alone, "a/b/b.go" of package a/b would sort between "a/a.go"
+    and "a/c.go" of package a, and output headed per package (text
+    `package` lines, Markdown sections) would repeat a. Packages are keyed
+    by directory, so the files of a directory, an external `foo_test`
+    package's included, still sort by path.
+
+    Methods are placed directly after their receiver type when the type is
+    declared in the same package directory, even if in a different file;
+    otherwise they sort as kind "method" within their own file. Methods
+    grouped under a type sort by name. Struct fields and interface methods
+    keep their declaration order.
+
+    Args:
+        symbols: Symbols in any order
+
+    Returns:
+        New list in canonical order
+    """
+    types = {
+        (s.package, posixpath.dirname(_posix(s.file)), s.name): s
+        for s in symbols
+        if s.kind not in ("func", "method")
+    }
+
+    def key(symbol: Symbol) -> tuple:
+        if symbol.kind == "method":
+            owner = types.get((symbol.package, posixpath.dirname(_posix(symbol.file)), symbol.receiver_type_name))
+            if owner is not None:
+                return (*_base_key(owner), 1, symbol.name, _posix(symbol.file), symbol.line)
+        return (*_base_key(symbol), 0, "", "", 0)
+
+    return [_sort_grouped_methods(s) for s in sorted(symbols, key=key)]
+
+
+def _sort_grouped_methods(symbol: Symbol) -> Symbol:
+    """Copy a type with its grouped methods sorted by name."""
+    if symbol.kind == "interface" or not symbol.methods:
+        return symbol
+    methods = sorted(symbol.methods, key=lambda m: (m.name, _posix(m.file), m.line))
+    return replace(symbol, methods=methods)
+
+
+def _base_key(symbol: Symbol) -> tuple:
+    """Sort key for a top-level declaration."""
+    rank = _KIND_RANK.get(symbol.kind, len(KIND_ORDER))
+    return (symbol.package, _posix(symbol.file), rank, symbol.name, symbol.line)
+
+
+def _posix(path: str) -> str:
+    """Normalize path separators so ordering is the same on every platform."""
+    return path.replace("\\", "/")
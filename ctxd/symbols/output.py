@@ -0,0 +1,138 @@
+"""
+Per-package output files.
+
+Writes the symbols of a directory walk as one file per package, named
+after its import path (example.com/shapes/geo -> example.com/shapes/geo.md),
+plus an index for formats that have one. Each file is written to a
+temporary file next to it and renamed into place, so a crash never leaves
+a partial file behind.
+"""
+
+import contextlib
+import os
+import posixpath
+import tempfile
+from pathlib import Path
+
+from .formatters import SymbolFormatter
+from .models import PackageSummary, Symbol
+
+# Base name of the index file, e.g. index.json
+INDEX_NAME = "index"
+
+
+def package_filename(import_path: str, summary: PackageSummary, extension: str) -> str:
+    """
+    Get the slash-separated file name a package is written to.
+
+    Args:
+        import_path: Import path of the package; "." for the walked
+            directory itself when there is no go.mod
+        summary: The package's summary, whose name is used for "."
+        extension: File name extension without the dot
+
+    Returns:
+        Relative file name, e.g. "example.com/shapes/geo.json"
+
+    Raises:
+        ValueError: If the import path has no usable name
+    """
+    parts = [part for part in posixpath.normpath(import_path).split("/") if part not in (".", "")]
+    if not parts:
+        parts = [summary.name]
+    if not parts[-1] or any(part == ".." for part in parts):
+        raise ValueError(f"Cannot name an output file for package {import_path!r}")
+    return f"{'/'.join(parts)}.{extension}"
+
+
+def write_packages(
+    out_dir: Path,
+    summaries: dict[str, PackageSummary],
+    symbols: list[Symbol],
+    formatter: SymbolFormatter,
+    with_summary: bool = False
+) -> list[Path]:
+    """
+    Write one file per package, and the formatter's index if it has one.
+
+    Args:
+        out_dir: Directory to write to; created with any missing parents
+        summaries: Import path to summary of every package to write, in order
+        symbols: Symbols of those packages, in output order
+        formatter: Format of the files
+        with_summary: Precede each package's symbols with its summary
+
+    Returns:
+        Paths written, the index last
+
+    Raises:
+        ValueError: If two packages would be written to the same file
+        OSError: If a file cannot be written
+    """
+    by_package: dict[str, list[Symbol]] = {path: [] for path in summaries}
+    for symbol in symbols:
+        by_package.setdefault(symbol.package, []).append(symbol)
+
+    files: dict[str, str] = {}
+    owners: dict[str, str] = {f"{INDEX_NAME}.{formatter.extension}": "the index"}
+    for import_path, summary in summaries.items():
+        name = package_filename(import_path, summary, formatter.extension)
+        if name in owners:
+            raise ValueError(f"Package {import_path} and {owners[name]} would both be written to {name}")
+        owners[name] = import_path
+        files[import_path] = name
+
+    written = []
+    for import_path, name in files.items():
+        package_symbols = by_package[import_path]
+        if with_summary:
+            text = formatter.format_packages({import_path: summaries[import_path]}, package_symbols)
+        else:
+            text = formatter.format(package_symbols)
+        written.append(write_atomic(out_dir / name, text + "\n"))
+
+    index = formatter.format_index(summaries, files)
+    if index:
+        written.append(write_atomic(out_dir / f"{INDEX_NAME}.{formatter.extension}", index + "\n"))
+    return written
+
+
+def write_atomic(path: Path, text: str) -> Path:
+    """
+    Replace a file's contents in one step.
+
+    The text goes to a temporary file in the same directory, which is then
+    renamed over the target, so readers see the old file or the new one.
+
+    Args:
+        path: File to write; missing parent directories are created
+        text: New contents
+
+    Returns:
+        The path written
+
+    Raises:
+        OSError: If the file cannot be written
+    """
+    path.parent.mkdir(parents=True, exist_ok=True)
+    fd, tmp_path = tempfile.mkstemp(dir=path.parent, prefix=f".{path.name}.", suffix=".tmp")
+    try:
+        with os.fdopen(fd, "w", encoding="utf-8") as f:
+            f.write(text)
+            f.flush()
+            os.fsync(f.fileno())
+        # mkstemp creates the file private to the user
+        os.chmod(tmp_path, 0o666 & ~_umask())
+        os.replace(tmp_path, path)
+    except BaseException:
+        with contextlib.suppress(OSError):
+            os.unlink(tmp_path)
+        raise
+    return path
+
+
+def _umask() -> int:
+    """Get the process umask, which can only be read by setting it."""
+    mask = os.umask(0)
+    os.umask(mask)
+    return mask
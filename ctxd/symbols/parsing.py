@@ -0,0 +1,31 @@
+"""
+Parsing of Go source fragments.
+
+Signatures, type expressions, and declarations taken out of their files are
+not valid Go on their own; they parse after a package clause,
+FRAGMENT_PREFIX, which callers subtract from byte offsets. Each process
+shares one parser for them.
+"""
+
+from typing import Optional
+
+from tree_sitter import Node, Parser
+
+from ..chunkers.treesitter import TreeSitterChunker
+
+FRAGMENT_PREFIX = "package p\n"
+
+_PARSER: Optional[Parser] = None
+
+
+def fragment_parser() -> Parser:
+    """Get the process's Go parser for fragments, created on first use."""
+    global _PARSER
+    if _PARSER is None:
+        _PARSER = Parser(TreeSitterChunker._get_language("go"))
+    return _PARSER
+
+
+def node_text(node: Optional[Node], source: bytes) -> str:
+    """Get the source text of a node, or "" for no node."""
+    return source[node.start_byte:node.end_byte].decode("utf8") if node is not None else ""
@@ -0,0 +1,103 @@
+"""
+File paths as output shows them.
+
+Extraction records each file as it was reached, e.g. "pkg/calc/calc.go"
+when walking "pkg", so the same tree prints differently depending on where
+it was scanned from. rewrite_paths() renders paths in one of three modes
+for output that is portable and stable in diffs:
+
+- "rel": relative to the scanned directory ("calc/calc.go" for "pkg")
+- "abs": absolute ("/home/ana/src/app/pkg/calc/calc.go")
+- "base": the file name alone ("calc.go")
+
+Every mode uses forward slashes, whatever the platform. Paths are only
+rewritten for display: extraction, the parse cache (keyed by contents),
+and features that read files back, such as --since, see the paths as
+walked.
+"""
+
+import ntpath
+import os
+import posixpath
+from dataclasses import replace
+from pathlib import Path
+from typing import Iterable, TypeVar
+
+from .models import Symbol
+
+PATH_MODES = ("rel", "abs", "base")
+
+# Symbols, ParseErrors, Annotations, ReceiverMixes: anything with a `file`
+T = TypeVar("T")
+
+
+def validate_path_mode(mode: str) -> None:
+    """
+    Check that a path mode is known.
+
+    Raises:
+        ValueError: If the mode is not one of PATH_MODES
+    """
+    if mode not in PATH_MODES:
+        raise ValueError(f"Invalid path mode: {mode!r} (expected one of: {', '.join(PATH_MODES)})")
+
+
+def display_path(path: str, root: Path, mode: str) -> str:
+    """
+    Render one file path.
+
+    Args:
+        path: Path as recorded by extraction
+        root: Directory that was scanned; for a single file, its directory
+        mode: One of PATH_MODES
+
+    Returns:
+        The path in the mode, with forward slashes; names that are not
+        paths on disk, such as "<stdin>", are only given forward slashes
+
+    Raises:
+        ValueError: If the mode is unknown
+    """
+    validate_path_mode(mode)
+    if path.startswith("<"):
+        return path.replace(ntpath.sep, posixpath.sep)
+    if mode == "base":
+        return ntpath.basename(path)
+    absolute = os.path.abspath(path)
+    if mode == "rel":
+        absolute = os.path.relpath(absolute, os.path.abspath(root))
+    return absolute.replace(ntpath.sep, posixpath.sep)
+
+
+def rewrite_paths(items: Iterable[T], root: Path, mode: str) -> list[T]:
+    """
+    Get copies of symbols, or of other records naming a `file`, with their
+    paths rendered by display_path().
+
+    Fields and methods of symbols are rewritten too.
+
+    Args:
+        items: Symbols, ParseErrors, Annotations, or ReceiverMixes
+        root: Directory that was scanned
+        mode: One of PATH_MODES
+
+    Returns:
+        The rewritten copies, in input order
+
+    Raises:
+        ValueError: If the mode is unknown
+    """
+    validate_path_mode(mode)
+    return [_rewrite(item, root, mode) for item in items]
+
+
+def _rewrite(item: T, root: Path, mode: str) -> T:
+    """Rewrite the path of one record and, for a symbol, those of its members."""
+    if isinstance(item, Symbol):
+        return replace(
+            item,
+            file=display_path(item.file, root, mode),
+            fields=[_rewrite(f, root, mode) for f in item.fields],
+            methods=[_rewrite(m, root, mode) for m in item.methods],
+        )
+    return replace(item, file=display_path(item.file, root, mode))
@@ -0,0 +1,269 @@
+"""
+Glob patterns for scoping directory walks.
+
+Patterns use doublestar syntax and are matched against slash-separated
+file paths relative to the walk root:
+- `*` matches any run of characters within a path segment
+- `?` matches one character within a segment
+- `**` as a whole segment matches zero or more segments
+  (`internal/**`, `**/*_gen.go`, `pkg/**/api.go`)
+- `[abc]`, `[a-z]`, `[!abc]` match one character from a set
+- `{a,b}` matches either alternative
+- `\\` escapes the next character
+
+Ignore files (`.ctxdignore`) list such patterns one per line, with the
+rules of .gitignore; see IgnoreRules.
+"""
+
+import re
+from dataclasses import dataclass
+from typing import Iterable
+
+
+def compile_glob(pattern: str) -> re.Pattern:
+    """
+    Translate a doublestar glob into a regular expression.
+
+    Args:
+        pattern: Glob pattern, e.g. "pkg/**/*.go"
+
+    Returns:
+        Compiled pattern to use with fullmatch()
+
+    Raises:
+        ValueError: If the pattern has unbalanced braces or brackets, or a
+            trailing escape
+    """
+    out = []
+    depth = 0  # open {...} groups
+    i = 0
+    n = len(pattern)
+    while i < n:
+        at_segment_start = i == 0 or pattern[i - 1] == "/"
+        if pattern == "**":
+            out.append(".*")
+            i = n
+        elif at_segment_start and pattern.startswith("**/", i):
+            out.append("(?:[^/]+/)*")
+            i += 3
+        elif pattern.startswith("/**", i) and i + 3 == n:
+            # "dir/**" matches dir itself and everything below it
+            out.append("(?:/.*)?")
+            i += 3
+        elif pattern.startswith("**", i):
+            # Not a whole segment: behaves like "*"
+            out.append("[^/]*")
+            i += 2
+        elif pattern[i] == "*":
+            out.append("[^/]*")
+            i += 1
+        elif pattern[i] == "?":
+            out.append("[^/]")
+            i += 1
+        elif pattern[i] == "[":
+            end = pattern.find("]", i + 2 if pattern[i + 1:i + 2] in ("!", "^") else i + 1)
+            if end < 0:
+                raise ValueError(f"Invalid glob pattern {pattern!r}: unclosed '['")
+            body = pattern[i + 1:end]
+            negate = body[:1] in ("!", "^")
+            if negate:
+                body = body[1:]
+            body = body.replace("\\", "\\\\")
+            out.append(f"[^/{body}]" if negate else f"[{body}]")
+            i = end + 1
+        elif pattern[i] == "{":
+            out.append("(?:")
+            depth += 1
+            i += 1
+        elif pattern[i] == "," and depth:
+            out.append("|")
+            i += 1
+        elif pattern[i] == "}" and depth:
+            out.append(")")
+            depth -= 1
+            i += 1
+        elif pattern[i] == "\\":
+            if i + 1 == n:
+                raise ValueError(f"Invalid glob pattern {pattern!r}: trailing '\\'")
+            out.append(re.escape(pattern[i + 1]))
+            i += 2
+        else:
+            out.append(re.escape(pattern[i]))
+            i += 1
+
+    if depth:
+        raise ValueError(f"Invalid glob pattern {pattern!r}: unclosed '{{'")
+    return re.compile("".join(out))
+
+
+class PathFilter:
+    """
+    Include and exclude globs for relative file paths.
+
+    A path is selected when it matches no exclude pattern and, if any
+    include patterns are given, at least one of them. Exclude wins when
+    both match.
+    """
+
+    def __init__(self, include: Iterable[str] = (), exclude: Iterable[str] = ()):
+        """
+        Compile the patterns.
+
+        Args:
+            include: Patterns a path must match (all paths when empty)
+            exclude: Patterns a path must not match
+
+        Raises:
+            ValueError: If a pattern is invalid
+        """
+        self.include = list(include)
+        self.exclude = list(exclude)
+        self._include = [compile_glob(p) for p in self.include]
+        self._exclude = [compile_glob(p) for p in self.exclude]
+
+    def matches(self, rel_path: str) -> bool:
+        """
+        Check whether a path is selected.
+
+        Args:
+            rel_path: Path relative to the walk root; backslashes are treated
+                as separators
+
+        Returns:
+            True if the path passes the filter
+        """
+        rel_path = rel_path.replace("\\", "/")
+        if any(p.fullmatch(rel_path) for p in self._exclude):
+            return False
+        return not self._include or any(p.fullmatch(rel_path) for p in self._include)
+
+    def __bool__(self) -> bool:
+        """Whether any pattern is set."""
+        return bool(self.include or self.exclude)
+
+    def __repr__(self) -> str:
+        """String representation."""
+        return f"PathFilter(include={self.include!r}, exclude={self.exclude!r})"
+
+
+@dataclass(frozen=True)
+class _IgnoreRule:
+    """One line of an ignore file."""
+    regex: re.Pattern
+    negate: bool
+    dir_only: bool
+    # Directory of the ignore file, relative to the top of the rules ("" or "a/b/")
+    base: str
+
+
+class IgnoreRules:
+    """
+    Rules of the ignore files between the top of a tree and a directory.
+
+    Each line of an ignore file is a glob relative to the file's directory,
+    following .gitignore:
+    - blank lines and lines starting with `#` are skipped
+    - `!pattern` re-includes what an earlier rule ignored
+    - a trailing `/` only matches directories
+    - a pattern with a `/` at its start or middle is anchored to the
+      file's directory; one without matches at any depth below it
+    - an ignored directory is skipped whole, so nothing below it can be
+      re-included; `dir/**` ignores only what is inside, so it can be
+
+    The last rule matching a path decides, and the rules of deeper files
+    come after those of their ancestors, so the nearest file wins.
+    Instances are immutable; with_file() returns extended rules.
+    """
+
+    def __init__(self, prefix: str = "", rules: tuple = ()):
+        """
+        Initialize rules.
+
+        Args:
+            prefix: Path of the walk root relative to the top of the rules,
+                "" or ending in "/", when ignore files above the root apply
+            rules: Parsed rules, outermost first
+        """
+        self.prefix = prefix
+        self.rules = rules
+
+    def with_file(self, content: str, rel_dir: str = "", source: str = "") -> "IgnoreRules":
+        """
+        Add the rules of an ignore file.
+
+        Args:
+            content: Text of the ignore file
+            rel_dir: Directory of the file relative to the walk root, ""
+                for the root itself; ".." segments reach the directories
+                above it up to the top
+            source: Name of the file, for error messages
+
+        Returns:
+            The extended rules
+
+        Raises:
+            ValueError: If a pattern is invalid
+        """
+        base = _join(self.prefix, rel_dir)
+        rules = []
+        for number, line in enumerate(content.splitlines(), 1):
+            line = line.strip()
+            if not line or line.startswith("#"):
+                continue
+            negate = line.startswith("!")
+            if negate:
+                line = line[1:]
+            dir_only = line.endswith("/")
+            line = line.rstrip("/")
+            if not line:
+                continue
+            anchored = "/" in line
+            line = line.lstrip("/")
+            if line.endswith("/**"):
+                # Everything inside, without the directory itself
+                line += "/*"
+            try:
+                regex = compile_glob(line if anchored else "**/" + line)
+            except ValueError as e:
+                raise ValueError(f"{source or 'ignore file'}:{number}: {e}") from None
+            rules.append(_IgnoreRule(regex, negate, dir_only, base))
+        return IgnoreRules(self.prefix, self.rules + tuple(rules))
+
+    def ignored(self, rel_path: str, is_dir: bool = False) -> bool:
+        """
+        Check whether a path is ignored by itself, not counting its parents.
+
+        Args:
+            rel_path: Path relative to the walk root
+            is_dir: Whether the path is a directory
+
+        Returns:
+            True if the last rule matching the path ignores it
+        """
+        path = self.prefix + rel_path.replace("\\", "/")
+        ignored = False
+        for rule in self.rules:
+            if (rule.dir_only and not is_dir) or not path.startswith(rule.base):
+                continue
+            if rule.regex.fullmatch(path[len(rule.base):]):
+                ignored = not rule.negate
+        return ignored
+
+    def __bool__(self) -> bool:
+        """Whether any rule is set."""
+        return bool(self.rules)
+
+    def __repr__(self) -> str:
+        """String representation."""
+        return f"IgnoreRules(prefix={self.prefix!r}, rules={len(self.rules)})"
+
+
+def _join(prefix: str, rel_dir: str) -> str:
+    """Join a rules prefix and a relative directory into a rule base ("" or ending in "/")."""
+    parts = [p for p in prefix.split("/") if p]
+    for part in rel_dir.replace("\\", "/").split("/"):
+        if part == "..":
+            parts.pop()
+        elif part and part != ".":
+            parts.append(part)
+    return "".join(f"{p}/" for p in parts)
@@ -0,0 +1,473 @@
+"""
+Cross-symbol resolution for extracted Go symbols.
+
+Resolves relationships that span declarations, such as interfaces that
+embed other interfaces declared elsewhere in the same file or package,
+methods and their receiver types, methods promoted through embedded struct
+fields, calls between functions, and the symbols examples document.
+"""
+
+from dataclasses import replace
+from typing import Optional
+
+from .imports import assumed_package_name
+from .models import TEST_KINDS, MethodMismatch, Symbol
+from .normalize import TypeNormalizer
+
+
+def flatten_interfaces(symbols: list[Symbol]) -> None:
+    """
+    Expand each interface's method set with methods from embedded interfaces.
+
+    Embedded names are resolved against the interfaces in `symbols`,
+    regardless of declaration order, and followed transitively, including
+    through local aliases (`type RW = ReadWriter`). Promoted methods are
+    tagged with the interface that declares them via `origin`.
+    Embeds that cannot be resolved locally (e.g. "io.Reader") are left in
+    `embeds` as written.
+
+    Safe to call repeatedly: previously promoted methods are recomputed.
+
+    Args:
+        symbols: Symbols to resolve in place
+    """
+    interfaces = {s.name: s for s in symbols if s.kind == "interface"}
+    # An alias denotes its target, so embedding it embeds the target
+    for alias in (s for s in symbols if s.kind == "alias"):
+        target = _resolve_alias(alias.type.split("[")[0], symbols)
+        if target in interfaces:
+            interfaces.setdefault(alias.name, interfaces[target])
+
+    for iface in (s for s in symbols if s.kind == "interface"):
+        declared = [m for m in iface.methods if not m.origin]
+        seen = {m.name for m in declared}
+        promoted = []
+
+        for embed in iface.embeds:
+            for method in _embedded_method_set(embed, interfaces, {iface.name}):
+                # Go allows the same method to arrive via several paths
+                if method.name not in seen:
+                    seen.add(method.name)
+                    promoted.append(method)
+
+        iface.methods = declared + promoted
+
+
+def _embedded_method_set(
+    embed: str,
+    interfaces: dict[str, Symbol],
+    visiting: set[str]
+) -> list[Symbol]:
+    """
+    Collect the full method set of an embedded interface.
+
+    Args:
+        embed: Embedded element as written (e.g. "Adder", "Container[T]")
+        interfaces: Interfaces available for resolution, by name
+        visiting: Interfaces on the current resolution path (cycle guard)
+
+    Returns:
+        Methods tagged with their declaring interface, or [] if unresolved
+    """
+    name = embed.split("[")[0]
+    parent = interfaces.get(name)
+    if parent is None or name in visiting or parent.name in visiting:
+        return []
+
+    visiting = visiting | {name, parent.name}
+    methods = [replace(m, origin=parent.name) for m in parent.methods if not m.origin]
+    for nested in parent.embeds:
+        methods.extend(_embedded_method_set(nested, interfaces, visiting))
+    return methods
+
+
+def find_implementations(symbols: list[Symbol]) -> None:
+    """
+    Record which interfaces each concrete type satisfies.
+
+    A type's method set is compared with every interface's flattened method
+    set by method name and function type (parameter names do not matter,
+    types and variadics do), with types normalized so that aliases and
+    other spellings of the same type match (see normalize.py). The method
+    set includes the methods promoted from embedded fields (see
+    promoted_methods()). Following Go's rules, value-receiver methods
+    belong to both `T` and `*T` while pointer-receiver methods belong only
+    to `*T`: interfaces satisfied by `T` go in `implements`, and those only
+    `*T` satisfies go in `pointer_implements`. Interfaces of another
+    package are named by qualified name, and never match when they have
+    unexported methods, which no outside type can provide.
+
+    Interfaces without methods, and interfaces whose method set is not fully
+    known (embedding "io.Reader" or a type-set element), are skipped.
+
+    Safe to call repeatedly: previous results are recomputed.
+
+    Args:
+        symbols: Symbols of one or more packages, resolved in place
+    """
+    by_package: dict[str, list[Symbol]] = {}
+    for symbol in symbols:
+        if symbol.kind in ("interface", "alias"):
+            by_package.setdefault(symbol.package, []).append(symbol)
+
+    interfaces = sorted(
+        (s for s in symbols if s.kind == "interface" and s.methods and _method_set_known(s, by_package[s.package])),
+        key=lambda s: (s.package, s.name),
+    )
+    types = TypeNormalizer(symbols)
+    required = {
+        id(iface): {m.name: _method_type(m, iface, types) for m in iface.methods}
+        for iface in interfaces
+    }
+
+    methods = _methods_by_type(symbols, by_package)
+    promoted = promoted_methods(symbols)
+    for concrete in (s for s in symbols if s.kind in ("struct", "type")):
+        method_set = _method_set(concrete, methods, promoted)
+        value_set = {m.name: _method_type(m, concrete, types) for m, pointer_only in method_set if not pointer_only}
+        pointer_set = {m.name: _method_type(m, concrete, types) for m, _ in method_set}
+
+        concrete.implements = []
+        concrete.pointer_implements = []
+        if not pointer_set:
+            continue
+        for iface in interfaces:
+            foreign = iface.package != concrete.package
+            if foreign and not all(m.exported for m in iface.methods):
+                continue
+            label = iface.qualified_name if foreign else iface.name
+            wanted = required[id(iface)].items()
+            if all(value_set.get(name) == t for name, t in wanted):
+                concrete.implements.append(label)
+            elif all(pointer_set.get(name) == t for name, t in wanted):
+                concrete.pointer_implements.append(label)
+
+
+def link_examples(symbols: list[Symbol]) -> None:
+    """
+    Record the symbol each example documents, by go/doc's naming rules.
+
+    The name after "Example" is looked up among the functions, types, and
+    methods of the example's package: ExampleF documents the function or
+    type F, and ExampleT_M the method M of T. A suffix starting with a
+    lowercase letter may follow, so ExampleT_M_second documents T.M and
+    ExampleT_second documents T. Example and Example_suffix are package
+    examples and get no subject, nor do examples of undeclared names.
+    ExampleT_m documents T, since an unexported method name is a suffix.
+
+    Safe to call repeatedly: previous results are recomputed.
+
+    Args:
+        symbols: Symbols of one or more packages, resolved in place
+    """
+    declared: dict[tuple[str, str], str] = {}
+    for symbol in symbols:
+        if symbol.kind == "method":
+            # A lowercase name after T_ is a suffix, so only exported methods have examples
+            if symbol.exported:
+                declared[(symbol.package, f"{symbol.receiver_type_name}_{symbol.name}")] = symbol.local_name
+        elif symbol.kind in ("func", "struct", "interface", "type", "alias"):
+            declared[(symbol.package, symbol.name)] = symbol.name
+
+    for example in (s for s in symbols if s.kind == "example"):
+        example.subject = _example_subject(example.name[len("Example"):], example.package, declared)
+
+
+def _example_subject(name: str, package: str, declared: dict[tuple[str, str], str]) -> str:
+    """Find what an example name (without "Example") documents, trying the longest prefix first."""
+    # Package examples start with "_"; no declared name does
+    end = len(name) if name[:1] != "_" else 0
+    while end > 0:
+        suffix = name[end + 1:]
+        if (end == len(name) or suffix[:1].islower()) and (package, name[:end]) in declared:
+            return declared[(package, name[:end])]
+        end = name.rfind("_", 0, end)
+    return ""
+
+
+def check_implements(concrete: Symbol, iface: Symbol, symbols: list[Symbol], pointer: bool = False) -> list[MethodMismatch]:
+    """
+    Explain whether a type satisfies an interface.
+
+    Uses the comparison of find_implementations(): methods match by name
+    and normalized function type, with local type names qualified by package, promoted
+    methods count, and pointer-receiver methods only belong to the pointer
+    type.
+
+    Args:
+        concrete: Struct or defined type
+        iface: Interface, with embedded interfaces flattened
+        symbols: Symbols of the tree, for the type's methods and aliases
+        pointer: Check the method set of `*T` instead of `T`
+
+    Returns:
+        Mismatches in the interface's method order, empty when the type
+        satisfies the interface
+
+    Raises:
+        ValueError: If the interface's method set is not fully known, such
+            as when it embeds an interface of another module
+    """
+    by_package: dict[str, list[Symbol]] = {}
+    for symbol in symbols:
+        if symbol.kind in ("interface", "alias"):
+            by_package.setdefault(symbol.package, []).append(symbol)
+    if not _method_set_known(iface, by_package.get(iface.package, [])):
+        embeds = ", ".join(iface.embeds)
+        raise ValueError(f"The method set of {iface.qualified_name} is not fully known (it embeds {embeds})")
+
+    method_set = _method_set(concrete, _methods_by_type(symbols, by_package), promoted_methods(symbols))
+    have = {m.name: m for m, _ in method_set}
+    pointer_only = {m.name for m, only in method_set if only}
+    foreign = iface.package != concrete.package
+    types = TypeNormalizer(symbols)
+
+    mismatches = []
+    for required in iface.methods:
+        want = _method_type(required, iface, types)
+        method = have.get(required.name)
+        if foreign and not required.exported:
+            mismatches.append(MethodMismatch(required.name, "unexported", required.type))
+        elif method is None:
+            mismatches.append(MethodMismatch(required.name, "missing", required.type))
+        elif _method_type(method, concrete, types) != want:
+            # Spell out packages when they are all that differs
+            shown = (required.type, method.type) if required.type != method.type \
+                else (want, _method_type(method, concrete, types))
+            mismatches.append(MethodMismatch(required.name, "type", shown[0], shown[1]))
+        elif method.name in pointer_only and not pointer:
+            mismatches.append(MethodMismatch(required.name, "pointer_receiver", required.type, method.type))
+    return mismatches
+
+
+def _methods_by_type(symbols: list[Symbol], by_package: dict[str, list[Symbol]]) -> dict[tuple[str, str], list[Symbol]]:
+    """Group methods by the (package, type name) of their receiver, following local aliases."""
+    methods: dict[tuple[str, str], list[Symbol]] = {}
+    for method in (s for s in symbols if s.kind == "method"):
+        owner = _resolve_alias(method.receiver_type_name, by_package.get(method.package, []))
+        methods.setdefault((method.package, owner), []).append(method)
+    return methods
+
+
+def promoted_methods(symbols: list[Symbol]) -> dict[tuple[str, str], list[tuple[Symbol, bool]]]:
+    """
+    Find the methods each struct gains from its embedded fields.
+
+    Embedded types are resolved within `symbols`, by name in the struct's
+    package or, for names like "calc.Calculator", in the imported package,
+    following local aliases, and are searched breadth first as Go selects
+    methods: a method or field at a shallower depth shadows deeper ones,
+    including the struct's own methods and fields at depth 0, and a name
+    found more than once at the same depth is ambiguous, so it is not
+    promoted. Embedded interfaces promote their method set, and embedded
+    types that are not in `symbols` promote nothing.
+
+    Promoted methods are copies with `origin` set to the embedded type
+    declaring them, as written in the first field that embeds it, and
+    `promotion_depth` to how many embedded fields deep it is. A method
+    with a pointer receiver promoted only through fields embedded by value
+    belongs to `*S` but not `S`; through any embedded pointer, to both.
+
+    Args:
+        symbols: Symbols of one or more packages
+
+    Returns:
+        For each struct with promoted methods, keyed by (package, name),
+        the methods in depth and field order, each with whether only
+        pointers to the struct have it
+    """
+    by_package: dict[str, list[Symbol]] = {}
+    for symbol in symbols:
+        if symbol.kind in ("interface", "alias"):
+            by_package.setdefault(symbol.package, []).append(symbol)
+    types = {(s.package, s.name): s for s in symbols if s.kind in ("struct", "type", "interface")}
+    methods = _methods_by_type(symbols, by_package)
+
+    result = {}
+    for outer in (s for s in symbols if s.kind == "struct"):
+        promoted = _promote(outer, types, methods, by_package)
+        if promoted:
+            result[(outer.package, outer.name)] = promoted
+    return result
+
+
+def _promote(
+    outer: Symbol,
+    types: dict[tuple[str, str], Symbol],
+    methods: dict[tuple[str, str], list[Symbol]],
+    by_package: dict[str, list[Symbol]],
+) -> list[tuple[Symbol, bool]]:
+    """Collect the methods promoted into one struct, breadth first by embedding depth."""
+    declared = methods.get((outer.package, outer.name), [])
+    shadowed = {m.name for m in declared} | {_field_name(f) for f in outer.fields}
+    # (embedding type, whether a pointer was embedded on the way to it); a
+    # type reached twice at one depth stays twice, so its names are ambiguous
+    level = [(outer, False)]
+    seen = {(outer.package, outer.name)}
+    promoted = []
+    depth = 0
+    while level:
+        depth += 1
+        found: dict[str, list[tuple[Symbol, bool]]] = {}
+        names: dict[str, int] = {}
+        next_level = []
+        reached = set()
+        for owner, via_pointer in level:
+            for embedded in (f for f in owner.fields if not f.name):
+                target = _embedded_type(embedded.type, owner, types, by_package)
+                # A type already reached at a shallower depth shadows itself here
+                if target is None or (target.package, target.name) in seen:
+                    continue
+                reached.add((target.package, target.name))
+                through_pointer = via_pointer or embedded.type.startswith("*")
+                origin = embedded.type.lstrip("*").split("[")[0]
+                if target.kind == "interface":
+                    members = target.methods
+                else:
+                    members = methods.get((target.package, target.name), [])
+                for method in members:
+                    copy = replace(method, origin=origin, promotion_depth=depth)
+                    found.setdefault(method.name, []).append((copy, method.pointer_receiver and not through_pointer))
+                for name in [m.name for m in members] + [_field_name(f) for f in target.fields]:
+                    names[name] = names.get(name, 0) + 1
+                next_level.append((target, through_pointer))
+        for name, candidates in found.items():
+            if name not in shadowed and names[name] == 1:
+                promoted.extend(candidates)
+        shadowed |= names.keys()
+        seen |= reached
+        level = next_level
+    return promoted
+
+
+def _embedded_type(
+    type_text: str,
+    owner: Symbol,
+    types: dict[tuple[str, str], Symbol],
+    by_package: dict[str, list[Symbol]],
+) -> Optional[Symbol]:
+    """Resolve an embedded field's type ("*Base", "calc.Calculator", "List[T]") to its declaration."""
+    name = type_text.lstrip("*").split("[")[0]
+    package = owner.package
+    if "." in name:
+        qualifier, _, name = name.partition(".")
+        paths = [path for path in owner.imports if assumed_package_name(path) == qualifier]
+        if not paths:
+            return None
+        package = paths[0]
+    return types.get((package, _resolve_alias(name, by_package.get(package, []))))
+
+
+def _field_name(field: Symbol) -> str:
+    """Get the name a field is selected by; embedded fields go by their type name."""
+    return field.name or field.type.lstrip("*").split("[")[0].split(".")[-1]
+
+
+def _method_set(
+    concrete: Symbol,
+    methods: dict[tuple[str, str], list[Symbol]],
+    promoted: dict[tuple[str, str], list[tuple[Symbol, bool]]],
+) -> list[tuple[Symbol, bool]]:
+    """Get a type's declared and promoted methods, each with whether only pointers to the type have it."""
+    key = (concrete.package, concrete.name)
+    declared = methods.get(key, []) + [m for m in concrete.methods if not m.promotion_depth]
+    return [(m, m.pointer_receiver) for m in declared] + promoted.get(key, [])
+
+
+def _method_set_known(iface: Symbol, package_symbols: list[Symbol], visiting: frozenset = frozenset()) -> bool:
+    """Check that every element an interface embeds resolves to an interface of its package."""
+    if iface.name in visiting:
+        return True
+    local = {s.name: s for s in package_symbols if s.kind == "interface"}
+    for embed in iface.embeds:
+        target = local.get(_resolve_alias(embed.split("[")[0], package_symbols))
+        if target is None or not _method_set_known(target, package_symbols, visiting | {iface.name}):
+            return False
+    return True
+
+
+def _method_type(method: Symbol, owner: Symbol, types: TypeNormalizer) -> str:
+    """Get a method's normalized function type; interface methods use the package and imports of their interface."""
+    return types.symbol_type(method, owner)
+
+
+def group_methods(symbols: list[Symbol]) -> list[Symbol]:
+    """
+    Nest methods under their receiver type's `methods`.
+
+    Receivers are resolved by type name within the same package, whether
+    value (`Calculator`) or pointer (`*Calculator`), following local aliases
+    to the type they denote. Methods whose receiver type is not among
+    `symbols` stay at the top level. Structs also list the methods promoted
+    from their embedded fields after their own (see promoted_methods()).
+
+    Args:
+        symbols: Symbols of one or more packages
+
+    Returns:
+        New list without the grouped methods; types that gained methods are
+        copies, so the input is not modified
+    """
+    types = {
+        (s.package, s.name): s
+        for s in symbols
+        if s.kind not in ("func", "method", "alias", "interface", *TEST_KINDS)
+    }
+    aliases = [s for s in symbols if s.kind == "alias"]
+
+    grouped: dict[tuple[str, str], list[Symbol]] = {}
+    result: list[Symbol] = []
+    for symbol in symbols:
+        if symbol.kind == "method":
+            package_symbols = [a for a in aliases if a.package == symbol.package]
+            owner = (symbol.package, _resolve_alias(symbol.receiver_type_name, package_symbols))
+            if owner in types:
+                grouped.setdefault(owner, []).append(symbol)
+                continue
+        result.append(symbol)
+
+    promoted = promoted_methods(symbols)
+    for i, symbol in enumerate(result):
+        key = (symbol.package, symbol.name)
+        methods = grouped.get(key, []) + [m for m, _ in promoted.get(key, [])]
+        if methods and types.get(key) is symbol:
+            result[i] = replace(symbol, methods=symbol.methods + methods)
+    return result
+
+
+def _resolve_alias(target: str, symbols: list[Symbol]) -> str:
+    """Follow a chain of local aliases to the name it finally denotes."""
+    aliases = {s.name: s.type for s in symbols if s.kind == "alias"}
+    seen: set[str] = set()
+    while target in aliases and target not in seen:
+        seen.add(target)
+        target = aliases[target]
+    return target
+
+
+def build_call_graph(symbols: list[Symbol]) -> dict[str, list[str]]:
+    """
+    Build a call graph adjacency list from extracted functions and methods.
+
+    Symbols must have been extracted with call extraction enabled. Callees
+    that name a function or method of the caller's package are qualified the
+    same way as callers; anything else (other packages, function values)
+    keeps its raw source text, e.g. "fmt.Printf". Tests, benchmarks, and
+    examples are callers too, showing what they exercise.
+
+    Args:
+        symbols: Extracted symbols, possibly spanning several packages
+
+    Returns:
+        Mapping of caller qualified name to callee names, in source order
+    """
+    callables = [s for s in symbols if s.kind in ("func", "method", *TEST_KINDS)]
+    known = {(s.package, s.local_name) for s in callables}
+
+    graph: dict[str, list[str]] = {}
+    for symbol in callables:
+        graph[symbol.qualified_name] = [
+            f"{symbol.package}.{callee}" if symbol.package and (symbol.package, callee) in known else callee
+            for callee in symbol.calls
+        ]
+    return graph
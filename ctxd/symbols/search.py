@@ -0,0 +1,188 @@
+"""
+Fuzzy symbol search.
+
+Matches a query against symbol names the way fzf does: the query's
+characters must appear in the name in order, ignoring case, and matches
+score higher when they are consecutive and start at word boundaries
+("Calculator.Add", "parse_url", "ParseURL" all start words at each
+capital, underscore, or dot). Exact names and names starting with the
+query rank above every other match. Doc comments can be searched too,
+ranking below all name matches.
+"""
+
+from dataclasses import dataclass, field
+from typing import Any, Optional
+
+from .models import Symbol
+
+# Points per matched character, and bonuses for where it falls
+SCORE_MATCH = 16
+BONUS_BOUNDARY = 8
+BONUS_CAMEL = 7
+BONUS_CONSECUTIVE = 4
+# The first query character counts its position bonus this many times
+FIRST_CHAR_MULTIPLIER = 2
+# Penalties for skipped characters between matches
+PENALTY_GAP_START = 3
+PENALTY_GAP_EXTENSION = 1
+
+# Added to names equal to, or starting with, the query
+BOOST_EXACT = 1000
+BOOST_PREFIX = 500
+
+
+@dataclass
+class Match:
+    """
+    A symbol matching a search query.
+
+    Attributes:
+        symbol: The matching symbol
+        score: Match quality, higher is better; only comparable between
+            matches of the same `matched` kind
+        matched: "name" when the query matched the symbol's local name,
+            "doc" when it only matched its doc comment
+        positions: Indexes of the matched characters in the local name
+            (empty for doc matches)
+    """
+    symbol: Symbol
+    score: int
+    matched: str = "name"
+    positions: list[int] = field(default_factory=list)
+
+    def to_dict(self) -> dict[str, Any]:
+        """Convert to the symbol's dictionary plus `score` and `matched`."""
+        data = self.symbol.to_dict()
+        data["score"] = self.score
+        data["matched"] = self.matched
+        return data
+
+
+def fuzzy_match(query: str, text: str) -> Optional[tuple[int, list[int]]]:
+    """
+    Match a query against text as a case-insensitive subsequence.
+
+    Finds the first occurrence of the whole query, then narrows it to the
+    shortest window ending there, as fzf's v1 algorithm does, so "add"
+    matches the "Add" of "Calculator.Add" rather than spreading over
+    "cAlculator.aDd".
+
+    Args:
+        query: Characters to find, in order
+        text: Text to search
+
+    Returns:
+        Score and matched positions in text, or None if text does not
+        contain the query's characters in order
+    """
+    if not query:
+        return None
+    pattern = query.lower()
+    lowered = text.lower()
+
+    # Forward: the earliest position at which the whole query has matched
+    position = 0
+    for char in pattern:
+        position = lowered.find(char, position)
+        if position < 0:
+            return None
+        position += 1
+    end = position
+
+    # Backward: the latest start from which the query still matches
+    positions = []
+    position = end
+    for char in reversed(pattern):
+        position = lowered.rfind(char, 0, position)
+        positions.append(position)
+    positions.reverse()
+
+    return _score(text, positions), positions
+
+
+def search(symbols: list[Symbol], query: str, in_doc: bool = False, limit: Optional[int] = None) -> list[Match]:
+    """
+    Rank symbols by how well their names match a query.
+
+    Names are local names ("Calculator.Add"), so a method can be found by
+    its type. Ties keep the order of the input, after preferring shorter
+    names.
+
+    Args:
+        symbols: Symbols to search; grouped methods are not searched
+        query: Text to look for; surrounding whitespace is ignored
+        in_doc: Also match symbols whose doc comment contains the query,
+            ignoring case; these rank after every name match
+        limit: Maximum number of matches, None for all
+
+    Returns:
+        Matches, best first
+    """
+    query = query.strip()
+    if not query:
+        return []
+    lowered = query.lower()
+
+    ranked = []
+    for order, symbol in enumerate(symbols):
+        match = _match_symbol(symbol, query, lowered, in_doc)
+        if match is not None:
+            key = (match.matched != "name", -match.score, len(symbol.local_name), order)
+            ranked.append((key, match))
+    ranked.sort(key=lambda entry: entry[0])
+    matches = [match for _, match in ranked]
+    return matches if limit is None else matches[:limit]
+
+
+def _match_symbol(symbol: Symbol, query: str, lowered: str, in_doc: bool) -> Optional[Match]:
+    """Match one symbol's name, falling back to its doc comment."""
+    result = fuzzy_match(query, symbol.local_name)
+    if result is not None:
+        score, positions = result
+        names = (symbol.name.lower(), symbol.local_name.lower())
+        if lowered in names:
+            score += BOOST_EXACT
+        elif any(name.startswith(lowered) for name in names):
+            score += BOOST_PREFIX
+        return Match(symbol, score, "name", positions)
+
+    if in_doc:
+        found = symbol.doc.lower().find(lowered)
+        if found >= 0:
+            # Scored like a name, so mentions starting a word rank higher
+            return Match(symbol, _score(symbol.doc, list(range(found, found + len(lowered)))), "doc")
+    return None
+
+
+def _score(text: str, positions: list[int]) -> int:
+    """Score matched positions in text."""
+    score = 0
+    previous = None
+    run_bonus = 0
+    for i, position in enumerate(positions):
+        bonus = _position_bonus(text, position)
+        if previous is not None and position == previous + 1:
+            # Runs keep the bonus of the character they started at
+            bonus = max(bonus, run_bonus, BONUS_CONSECUTIVE)
+        else:
+            if previous is not None:
+                gap = position - previous - 1
+                score -= PENALTY_GAP_START + PENALTY_GAP_EXTENSION * (gap - 1)
+            run_bonus = bonus
+        score += SCORE_MATCH + (bonus * FIRST_CHAR_MULTIPLIER if i == 0 else bonus)
+        previous = position
+    return score
+
+
+def _position_bonus(text: str, position: int) -> int:
+    """Get the bonus for matching the character at position: word starts score highest."""
+    if position == 0:
+        return BONUS_BOUNDARY
+    before, char = text[position - 1], text[position]
+    if not before.isalnum() and char.isalnum():
+        return BONUS_BOUNDARY
+    if before.islower() and char.isupper():
+        return BONUS_CAMEL
+    if not before.isdigit() and char.isdigit():
+        return BONUS_CAMEL
+    return 0
@@ -0,0 +1,524 @@
+"""
+HTTP API over the symbols of a Go package tree.
+
+Serves an in-memory Index as JSON for editors and browser tools:
+
+    GET /symbols?kind=func&exported=true&package=example.com/calc
+    GET /symbols/{name}
+    GET /packages
+    GET /search?q=calc&limit=20&doc=true
+    GET /events?snapshot=true (WebSocket)
+
+Symbols name their files relative to the served directory, with forward
+slashes. Responses allow cross-origin requests. The index is rebuilt
+atomically, so requests served during a re-index see either the old or the
+new tree.
+
+/events streams the changes found by each re-index as JSON text messages,
+one per symbol: `{"type": "added", "symbol": {...}}`, or "removed", or
+"modified" with the old version under `previous`. With snapshot=true the
+first message is `{"type": "snapshot", "symbols": [...]}` of the index the
+changes apply to. Each client buffers at most `max_buffered_events`
+messages; one falling further behind is disconnected with close code 1008,
+and can reconnect with snapshot=true to catch up.
+
+Given an IndexSnapshot, the server starts from the index its last run
+saved, parsing only the files changed since, and saves the index again on
+shutdown (see ctxd.symbols.snapshot).
+"""
+
+import json
+import logging
+import socket
+import threading
+from collections import deque
+from dataclasses import replace
+from http import HTTPStatus
+from http.server import BaseHTTPRequestHandler, ThreadingHTTPServer
+from pathlib import Path
+from typing import Any, Optional
+from urllib.parse import parse_qs, unquote, urlsplit
+
+from . import websocket
+from .api import Index, Options, extract_dir
+from .filters import filter_exported, filter_kinds, validate_kinds
+from .index import diff_symbols
+from .models import Symbol
+from .paths import rewrite_paths
+from .search import Match, search
+from .snapshot import IndexSnapshot
+
+logger = logging.getLogger(__name__)
+
+# Number of /search results without a limit parameter
+DEFAULT_SEARCH_LIMIT = 20
+
+# Messages an /events client may fall behind by before it is disconnected
+DEFAULT_MAX_BUFFERED_EVENTS = 1000
+
+
+def parse_addr(addr: str) -> tuple[str, int]:
+    """
+    Parse a listen address such as "localhost:8080" or ":8080".
+
+    As with Go's net/http, an empty host listens on all interfaces.
+
+    Raises:
+        ValueError: If the address has no valid port
+    """
+    host, sep, port = addr.rpartition(":")
+    if not sep or not port.isdigit() or int(port) > 65535:
+        raise ValueError(f"Invalid address: {addr!r} (expected HOST:PORT or :PORT)")
+    return host.strip("[]") or "0.0.0.0", int(port)
+
+
+class Subscription:
+    """
+    Queue of change events for one /events client.
+
+    Events are pushed by the re-indexing thread and taken by the client's
+    connection thread. A subscription is closed by the client
+    disconnecting, by the server shutting down, or by overflowing.
+    """
+
+    def __init__(self, limit: int):
+        """
+        Initialize an open subscription.
+
+        Args:
+            limit: Number of events that may be buffered
+        """
+        self.limit = limit
+        self.overflowed = False
+        self._events: deque[dict[str, Any]] = deque()
+        self._closed = False
+        self._condition = threading.Condition()
+
+    def push(self, event: dict[str, Any]) -> None:
+        """Buffer an event, closing the subscription if the buffer is full."""
+        with self._condition:
+            if self._closed:
+                return
+            if len(self._events) >= self.limit:
+                self.overflowed = True
+                self._closed = True
+                self._events.clear()
+            else:
+                self._events.append(event)
+            self._condition.notify_all()
+
+    def next(self, timeout: Optional[float] = None) -> Optional[dict[str, Any]]:
+        """
+        Wait for the next event.
+
+        Returns:
+            The event, or None once the subscription is closed and drained
+            (or when the timeout passes first)
+        """
+        with self._condition:
+            self._condition.wait_for(lambda: self._events or self._closed, timeout)
+            return self._events.popleft() if self._events else None
+
+    def close(self) -> None:
+        """Stop accepting events and wake the waiting reader."""
+        with self._condition:
+            self._closed = True
+            self._condition.notify_all()
+
+    @property
+    def closed(self) -> bool:
+        """Whether the subscription no longer accepts events."""
+        with self._condition:
+            return self._closed
+
+
+class SymbolServer:
+    """
+    Serves the symbols of a directory tree over HTTP.
+
+    Request handling is independent of the socket layer (see handle()), so
+    the API can be exercised without a listening server.
+    """
+
+    def __init__(
+        self,
+        root: Path,
+        options: Optional[Options] = None,
+        cors_origin: str = "*",
+        max_buffered_events: int = DEFAULT_MAX_BUFFERED_EVENTS,
+        snapshot: Optional[IndexSnapshot] = None,
+    ):
+        """
+        Initialize the server and build the index.
+
+        Args:
+            root: Directory to serve
+            options: Extraction settings for the index
+            cors_origin: Value of the Access-Control-Allow-Origin header
+            max_buffered_events: Events an /events client may fall behind by
+            snapshot: Snapshot to start from, loaded here, kept up to date by
+                each re-index, and saved on shutdown
+
+        Raises:
+            NotADirectoryError: If root is not a directory
+        """
+        self.root = Path(root)
+        self.options = options or Options()
+        self.cors_origin = cors_origin
+        self.max_buffered_events = max_buffered_events
+        self.snapshot = snapshot
+        if snapshot is not None:
+            snapshot.load()
+        self.index: Index = extract_dir(self.root, self.options, snapshot)
+        self._lock = threading.Lock()
+        self._subscriptions: set[Subscription] = set()
+        self._httpd: Optional[ThreadingHTTPServer] = None
+
+    def reload(self) -> None:
+        """
+        Re-extract the tree, swap in the new index, and send the changes
+        to /events subscribers.
+
+        If extraction fails, the previous index keeps being served.
+        """
+        try:
+            index = extract_dir(self.root, self.options, self.snapshot)
+        except Exception as e:
+            logger.error(f"Failed to re-index {self.root}, serving the previous index: {e}")
+            return
+        with self._lock:
+            events = change_events(self.index, index, self.root)
+            self.index = index
+            # Under the lock, so a new subscriber's snapshot is either before
+            # or after these events
+            for subscription in self._subscriptions:
+                for event in events:
+                    subscription.push(event)
+        logger.info(f"Re-indexed {self.root}: {len(index)} symbols in {len(index.packages)} packages")
+
+    def subscribe(self) -> tuple[Subscription, Index]:
+        """
+        Start receiving change events.
+
+        Returns:
+            The subscription, and the index its first event applies to
+        """
+        subscription = Subscription(self.max_buffered_events)
+        with self._lock:
+            self._subscriptions.add(subscription)
+            return subscription, self.index
+
+    def unsubscribe(self, subscription: Subscription) -> None:
+        """Stop sending events to a subscription and close it."""
+        subscription.close()
+        with self._lock:
+            self._subscriptions.discard(subscription)
+
+    def handle(self, method: str, target: str) -> tuple[int, Any]:
+        """
+        Answer a request.
+
+        Args:
+            method: HTTP method
+            target: Request target, e.g. "/symbols?kind=func"
+
+        Returns:
+            Status code and JSON-serializable body
+        """
+        if method != "GET":
+            return HTTPStatus.METHOD_NOT_ALLOWED, {"error": f"Method not allowed: {method}"}
+
+        url = urlsplit(target)
+        path = unquote(url.path).rstrip("/") or "/"
+        query = {key: values[-1] for key, values in parse_qs(url.query).items()}
+        with self._lock:
+            index = self.index
+
+        if path == "/symbols":
+            return self._list_symbols(index, query)
+        if path.startswith("/symbols/"):
+            return self._get_symbol(index, path[len("/symbols/"):], query)
+        if path == "/packages":
+            return HTTPStatus.OK, [summary.to_dict() for summary in index.summaries.values()]
+        if path == "/search":
+            return self._search(index, query)
+        if path == "/events":
+            return HTTPStatus.UPGRADE_REQUIRED, {"error": "/events is a WebSocket endpoint"}
+        return HTTPStatus.NOT_FOUND, {"error": f"Not found: {path}"}
+
+    def _list_symbols(self, index: Index, query: dict[str, str]) -> tuple[int, Any]:
+        """List symbols filtered by the `kind`, `exported`, and `package` parameters."""
+        symbols = index.symbols()
+        if "package" in query:
+            if query["package"] not in index.packages:
+                return HTTPStatus.NOT_FOUND, {"error": f"Unknown package: {query['package']}"}
+            symbols = index.package(query["package"])
+
+        if "kind" in query:
+            kinds = [k.strip() for k in query["kind"].split(",") if k.strip()]
+            try:
+                validate_kinds(kinds)
+            except ValueError as e:
+                return HTTPStatus.BAD_REQUEST, {"error": str(e)}
+            symbols = filter_kinds(symbols, kinds)
+
+        # Like --exported-only: false does not filter
+        exported = query.get("exported", "false").lower()
+        if exported not in ("true", "false"):
+            return HTTPStatus.BAD_REQUEST, {"error": f"Invalid exported: {query['exported']!r} (expected true or false)"}
+        if exported == "true":
+            symbols = filter_exported(symbols)
+
+        return HTTPStatus.OK, [symbol_dict(s, self.root) for s in symbols]
+
+    def _get_symbol(self, index: Index, name: str, query: dict[str, str]) -> tuple[int, Any]:
+        """
+        Look up one symbol by qualified name ("example.com/calc.Calculator.Add")
+        or by name within its package ("Calculator.Add").
+        """
+        matches = index.find(name, query.get("package"))
+        if not matches:
+            return HTTPStatus.NOT_FOUND, {"error": f"Unknown symbol: {name}"}
+        if len(matches) > 1:
+            return HTTPStatus.CONFLICT, {
+                "error": f"Ambiguous symbol: {name}",
+                "candidates": [s.qualified_name for s in matches],
+            }
+        return HTTPStatus.OK, with_span(matches[0], self.root)
+
+    def _search(self, index: Index, query: dict[str, str]) -> tuple[int, Any]:
+        """Fuzzy-match symbol names against `q`, and doc comments too with `doc=true`."""
+        text = query.get("q", "").strip()
+        if not text:
+            return HTTPStatus.BAD_REQUEST, {"error": "Missing query: q"}
+
+        limit = query.get("limit", str(DEFAULT_SEARCH_LIMIT))
+        if not limit.isdigit() or int(limit) < 1:
+            return HTTPStatus.BAD_REQUEST, {"error": f"Invalid limit: {limit!r} (expected a positive integer)"}
+        in_doc = query.get("doc", "false").lower()
+        if in_doc not in ("true", "false"):
+            return HTTPStatus.BAD_REQUEST, {"error": f"Invalid doc: {query['doc']!r} (expected true or false)"}
+
+        matches = search(index.symbols(), text, in_doc=in_doc == "true", limit=int(limit))
+        return HTTPStatus.OK, [match_dict(match, self.root) for match in matches]
+
+    def bind(self, addr: tuple[str, int]) -> None:
+        """
+        Open the listening socket.
+
+        Args:
+            addr: Host and port; port 0 picks a free port (see `address`)
+
+        Raises:
+            OSError: If the address cannot be bound
+        """
+        server = self
+
+        class Handler(_RequestHandler):
+            symbol_server = server
+
+        self._httpd = ThreadingHTTPServer(addr, Handler)
+        self._httpd.daemon_threads = True
+
+    def serve_forever(self) -> None:
+        """Serve requests on the bound socket until shutdown()."""
+        self._httpd.serve_forever()
+
+    @property
+    def address(self) -> tuple[str, int]:
+        """Get the host and port the server listens on."""
+        return self._httpd.server_address[:2] if self._httpd else ("", 0)
+
+    def shutdown(self) -> None:
+        """Stop serving, disconnect /events clients, close the socket, and save the snapshot."""
+        with self._lock:
+            subscriptions = list(self._subscriptions)
+        for subscription in subscriptions:
+            subscription.close()
+        if self._httpd:
+            self._httpd.shutdown()
+            self._httpd.server_close()
+            self._httpd = None
+        if self.snapshot is not None:
+            self.snapshot.save()
+
+
+class _RequestHandler(BaseHTTPRequestHandler):
+    """Adapts http.server requests to SymbolServer.handle()."""
+
+    symbol_server: SymbolServer
+
+    def do_GET(self) -> None:
+        """Serve a GET request, upgrading /events requests to a WebSocket."""
+        url = urlsplit(self.path)
+        if (unquote(url.path).rstrip("/") == "/events"
+                and self.headers.get("Upgrade", "").lower() == "websocket"):
+            self._stream_events({key: values[-1] for key, values in parse_qs(url.query).items()})
+            return
+        self._respond(*self.symbol_server.handle("GET", self.path))
+
+    def _stream_events(self, query: dict[str, str]) -> None:
+        """
+        Complete the WebSocket handshake and send change events until the
+        client disconnects or falls too far behind.
+
+        A second thread reads the client's frames, answering pings and
+        noticing close frames and dropped connections; both threads are
+        finished when this returns.
+        """
+        key = self.headers.get("Sec-WebSocket-Key", "")
+        if not websocket.is_valid_key(key) or self.headers.get("Sec-WebSocket-Version") != "13":
+            self._respond(HTTPStatus.BAD_REQUEST, {"error": "Invalid WebSocket handshake"})
+            return
+        snapshot = query.get("snapshot", "false").lower()
+        if snapshot not in ("true", "false"):
+            self._respond(HTTPStatus.BAD_REQUEST, {"error": f"Invalid snapshot: {query['snapshot']!r} (expected true or false)"})
+            return
+
+        server = self.symbol_server
+        subscription, index = server.subscribe()
+        self.close_connection = True
+        self._write_lock = threading.Lock()
+        self._client_closed = False
+        reader = threading.Thread(target=self._read_client_frames, args=(subscription,), daemon=True)
+        try:
+            self.send_response(HTTPStatus.SWITCHING_PROTOCOLS)
+            self.send_header("Upgrade", "websocket")
+            self.send_header("Connection", "Upgrade")
+            self.send_header("Sec-WebSocket-Accept", websocket.accept_key(key))
+            self.end_headers()
+            self.wfile.flush()
+            reader.start()
+
+            if snapshot == "true":
+                self._send_text({"type": "snapshot", "symbols": [symbol_dict(s, server.root) for s in index.symbols()]})
+            while True:
+                event = subscription.next()
+                if event is None:
+                    break
+                self._send_text(event)
+
+            if subscription.overflowed:
+                logger.info(f"Disconnected {self.address_string()}: more than {subscription.limit} events behind")
+                self._send(websocket.encode_close(websocket.CLOSE_POLICY_VIOLATION, "Too many buffered events"))
+            elif self._client_closed:
+                self._send(websocket.encode_close(websocket.CLOSE_NORMAL))
+            else:
+                # The server is shutting down
+                self._send(websocket.encode_close(websocket.CLOSE_GOING_AWAY))
+        except OSError as e:
+            logger.debug(f"Lost /events client {self.address_string()}: {e}")
+        finally:
+            server.unsubscribe(subscription)
+            # Unblocks the reader thread if the client has not closed
+            try:
+                self.connection.shutdown(socket.SHUT_RDWR)
+            except OSError:
+                pass
+            if reader.is_alive():
+                reader.join()
+
+    def _read_client_frames(self, subscription: Subscription) -> None:
+        """Read client frames until the connection closes, then close the subscription."""
+        try:
+            while not subscription.closed:
+                opcode, payload = websocket.read_frame(self.rfile)
+                if opcode == websocket.OP_CLOSE:
+                    self._client_closed = True
+                    break
+                if opcode == websocket.OP_PING:
+                    self._send(websocket.encode_frame(websocket.OP_PONG, payload))
+        except websocket.ProtocolError as e:
+            logger.debug(f"Closing /events client {self.address_string()}: {e}")
+        except (EOFError, OSError):
+            pass
+        finally:
+            subscription.close()
+
+    def _send_text(self, message: dict[str, Any]) -> None:
+        """Send a JSON text message."""
+        self._send(websocket.encode_frame(websocket.OP_TEXT, json.dumps(message).encode("utf-8")))
+
+    def _send(self, frame: bytes) -> None:
+        """Write a frame; the connection and reader threads both send."""
+        with self._write_lock:
+            self.wfile.write(frame)
+            self.wfile.flush()
+
+    def _reject(self) -> None:
+        """Answer a method other than GET with 405."""
+        self._respond(*self.symbol_server.handle(self.command, self.path))
+
+    do_POST = do_PUT = do_PATCH = do_DELETE = _reject
+
+    def do_OPTIONS(self) -> None:
+        """Answer a CORS preflight request."""
+        self.send_response(HTTPStatus.NO_CONTENT)
+        self._send_cors_headers()
+        self.send_header("Access-Control-Allow-Methods", "GET, OPTIONS")
+        self.send_header("Access-Control-Allow-Headers", "Content-Type")
+        self.send_header("Content-Length", "0")
+        self.end_headers()
+
+    def _respond(self, status: int, body: Any) -> None:
+        """Send a JSON response."""
+        data = json.dumps(body, indent=2).encode("utf-8")
+        self.send_response(status)
+        self.send_header("Content-Type", "application/json; charset=utf-8")
+        self.send_header("Content-Length", str(len(data)))
+        self._send_cors_headers()
+        if status == HTTPStatus.METHOD_NOT_ALLOWED:
+            self.send_header("Allow", "GET, OPTIONS")
+        self.end_headers()
+        self.wfile.write(data)
+
+    def _send_cors_headers(self) -> None:
+        """Allow browser tools on other origins to read responses."""
+        self.send_header("Access-Control-Allow-Origin", self.symbol_server.cors_origin)
+
+    def log_message(self, format: str, *args: Any) -> None:
+        """Log requests through logging instead of writing to stderr."""
+        logger.debug(f"{self.address_string()} {format % args}")
+
+
+def change_events(old: Index, new: Index, root: Path) -> list[dict[str, Any]]:
+    """
+    Describe the changes between two indexes as /events messages.
+
+    Packages are compared by import path, in sorted order, and symbols by
+    name within their package as diff_symbols() does. File paths are shown
+    relative to root, the served directory.
+
+    Returns:
+        One message per added, removed, or modified symbol
+    """
+    events = []
+    for path in sorted(set(old.packages) | set(new.packages)):
+        diff = diff_symbols(old.package(path), new.package(path))
+        events.extend({"type": "added", "symbol": symbol_dict(s, root)} for s in diff.added)
+        events.extend({"type": "removed", "symbol": symbol_dict(s, root)} for s in diff.removed)
+        events.extend(
+            {"type": "modified", "symbol": symbol_dict(s, root), "previous": symbol_dict(p, root)}
+            for s, p in zip(diff.modified, diff.previous)
+        )
+    return events
+
+
+def symbol_dict(symbol: Symbol, root: Path) -> dict[str, Any]:
+    """Convert a symbol to a dictionary, with its file paths as `--path-mode rel` shows them relative to root."""
+    return rewrite_paths([symbol], root, "rel")[0].to_dict()
+
+
+def match_dict(match: Match, root: Path) -> dict[str, Any]:
+    """Convert a search match to a dictionary, with its file paths as symbol_dict() shows them."""
+    return replace(match, symbol=rewrite_paths([match.symbol], root, "rel")[0]).to_dict()
+
+
+def with_span(symbol: Symbol, root: Path) -> dict[str, Any]:
+    """Convert a symbol to a dictionary, as symbol_dict() does, with its source range under `span`."""
+    data = symbol_dict(symbol, root)
+    data["span"] = {
+        "start": {"line": symbol.line, "column": symbol.column},
+        "end": {"line": symbol.end_line, "column": symbol.end_column},
+    }
+    return data
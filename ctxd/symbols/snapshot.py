@@ -0,0 +1,260 @@
+"""
+On-disk snapshot of a resolved index, so that a server restarts warm.
+
+A snapshot holds the resolved Index of a tree, the content hash of each
+file it was built from, and the unresolved symbols of each file. When a
+snapshot is reused by extract_dir():
+
+- if every file is unchanged, the saved index is served as it is, without
+  parsing or resolving anything
+- otherwise only the changed files are parsed, and the relationships
+  between files (embedded and implemented interfaces) are resolved again
+  from the saved symbols of the rest
+
+A snapshot file is a header line naming the snapshot format, the cache
+schema, and the ctxd version, followed by zlib-compressed JSON. A file
+written under another header, or that cannot be read, is discarded and
+the tree is extracted cold; so is a snapshot of another tree or of other
+options.
+"""
+
+import contextlib
+import hashlib
+import json
+import logging
+import os
+import tempfile
+import zlib
+from dataclasses import fields
+from pathlib import Path
+from typing import TYPE_CHECKING, Any, Optional
+
+from .cache import SCHEMA_VERSION, SymbolCache, default_cache_dir, set_file
+from .fs import Traversable
+from .models import PackageSummary, ParseError, Symbol
+
+if TYPE_CHECKING:
+    from .api import Index, Options
+
+logger = logging.getLogger(__name__)
+
+# Bump whenever the layout of snapshot files changes
+SNAPSHOT_FORMAT = 1
+
+# Options that do not change the index, so do not invalidate a snapshot
+_IGNORED_OPTIONS = ("cache", "cache_dir", "jobs", "format", "max_tokens", "transformers", "path_mode")
+
+
+def default_snapshot_path(root: Path) -> Path:
+    """Get where the snapshot of a directory is kept: $XDG_CACHE_HOME/ctxd/index/<hash of its path>."""
+    digest = hashlib.sha256(str(Path(root).resolve()).encode("utf-8")).hexdigest()
+    return default_cache_dir().parent / "index" / f"{digest[:32]}.snapshot"
+
+
+def clear_snapshots() -> int:
+    """
+    Delete the snapshots kept at default_snapshot_path().
+
+    Returns:
+        Number of snapshots removed
+    """
+    directory = default_snapshot_path(Path(".")).parent
+    if not directory.exists():
+        return 0
+    count = 0
+    for snapshot in directory.glob("*.snapshot"):
+        snapshot.unlink()
+        count += 1
+    return count
+
+
+class IndexSnapshot(SymbolCache):
+    """
+    The last index of a tree and the per-file symbols it was resolved from.
+
+    As a SymbolCache, it serves the symbols of unchanged files from memory
+    and falls back to `cache`, if given. Failures to read or write the
+    snapshot file are logged; the snapshot never makes extraction fail.
+    """
+
+    def __init__(self, path: Path, cache: Optional[SymbolCache] = None):
+        """
+        Initialize an empty snapshot; see load().
+
+        Args:
+            path: Snapshot file, e.g. default_snapshot_path(root)
+            cache: Cache to look files up in when they are not in the snapshot
+        """
+        super().__init__(Path(path).parent)
+        self.path = Path(path)
+        self.cache = cache
+        # Cache key to the serialized symbols of a file
+        self.entries: dict[str, list[dict[str, Any]]] = {}
+        # Root, options, file hashes, and index of the last record()
+        self.state: Optional[dict[str, Any]] = None
+        self._used: set[str] = set()
+        self._hashes: dict[str, str] = {}
+
+    def load(self) -> bool:
+        """
+        Read the snapshot file, replacing what is in memory.
+
+        Returns:
+            Whether a usable snapshot was read; a missing, unreadable, or
+            outdated one leaves the snapshot empty
+        """
+        self.entries, self.state = {}, None
+        try:
+            with open(self.path, "rb") as f:
+                header = f.readline()
+                if header != _header():
+                    logger.debug(f"Discarding snapshot {self.path} written by another version: {header[:80]!r}")
+                    return False
+                data = json.loads(zlib.decompress(f.read()))
+            self.entries, self.state = data["entries"], data["state"]
+        except FileNotFoundError:
+            return False
+        except (OSError, ValueError, TypeError, KeyError, zlib.error) as e:
+            logger.debug(f"Discarding unreadable snapshot {self.path}: {e}")
+            self.entries, self.state = {}, None
+            return False
+        logger.debug(f"Loaded snapshot {self.path} of {len(self.entries)} files")
+        return True
+
+    def save(self) -> None:
+        """Write the snapshot file, if anything was recorded."""
+        if self.state is None:
+            return
+        try:
+            self.path.parent.mkdir(parents=True, exist_ok=True)
+            # Write then rename so that a crash never leaves a partial snapshot
+            fd, tmp_path = tempfile.mkstemp(dir=self.path.parent, suffix=".tmp")
+            try:
+                with os.fdopen(fd, "wb") as f:
+                    f.write(_header())
+                    f.write(zlib.compress(json.dumps({"entries": self.entries, "state": self.state}).encode("utf-8")))
+                os.replace(tmp_path, self.path)
+            except BaseException:
+                with contextlib.suppress(OSError):
+                    os.unlink(tmp_path)
+                raise
+        except OSError as e:
+            logger.warning(f"Failed to write snapshot {self.path}: {e}")
+            return
+        logger.debug(f"Saved snapshot {self.path} of {len(self.entries)} files")
+
+    def lookup(self, root: Traversable, files: list[Traversable], options: "Options") -> Optional["Index"]:
+        """
+        Get the saved index if it is still that of the tree.
+
+        Args:
+            root: Directory being extracted
+            files: Its Go files, as found by find_go_files()
+            options: Extraction settings
+
+        Returns:
+            A copy of the saved index, or None if a file was added,
+            removed, or changed, or the root or options differ
+        """
+        from .api import Index
+
+        self._used = set()
+        self._hashes = {}
+        for file_path in files:
+            try:
+                with file_path.open("rb") as f:
+                    self._hashes[str(file_path)] = hashlib.sha256(f.read()).hexdigest()
+            except OSError:
+                # Reported when the file is extracted
+                continue
+
+        state = self.state
+        if (state is None or options.transformers
+                or state["root"] != str(root) or state["options"] != _settings(options) or state["files"] != self._hashes):
+            return None
+        index = state["index"]
+        return Index(
+            root=root,
+            packages={path: [Symbol.from_dict(d) for d in symbols] for path, symbols in index["packages"].items()},
+            summaries={path: PackageSummary(**d) for path, d in index["summaries"].items()},
+            errors=[ParseError(**d) for d in index["errors"]],
+        )
+
+    def record(self, index: "Index", options: "Options") -> None:
+        """
+        Remember a freshly extracted index, dropping the symbols of files
+        that are no longer in the tree.
+
+        Args:
+            index: Index extracted from the files given to lookup()
+            options: Settings it was extracted with
+        """
+        self.entries = {key: self.entries[key] for key in self._used if key in self.entries}
+        self.state = {
+            "root": str(index.root),
+            "options": _settings(options),
+            "files": self._hashes,
+            "index": {
+                "packages": {path: [s.to_dict() for s in symbols] for path, symbols in index.packages.items()},
+                "summaries": {path: summary.to_dict() for path, summary in index.summaries.items()},
+                "errors": [e.to_dict() for e in index.errors],
+            },
+        }
+
+    def get(self, key: str, path: str) -> Optional[list[Symbol]]:
+        """Load the symbols of a file from the snapshot, or else from the fallback cache."""
+        entry = self.entries.get(key)
+        if entry is None and self.cache is not None:
+            symbols = self.cache.get(key, path)
+            if symbols is not None:
+                self.entries[key] = [s.to_dict() for s in symbols]
+                self._used.add(key)
+            return symbols
+        if entry is None:
+            self.misses += 1
+            return None
+
+        self.hits += 1
+        self._used.add(key)
+        symbols = [Symbol.from_dict(d) for d in entry]
+        for symbol in symbols:
+            set_file(symbol, path)
+        return symbols
+
+    def put(self, key: str, symbols: list[Symbol]) -> None:
+        """Store the symbols of a parsed file in the snapshot and the fallback cache."""
+        self.entries[key] = [s.to_dict() for s in symbols]
+        self._used.add(key)
+        if self.cache is not None:
+            self.cache.put(key, symbols)
+
+    def clear(self) -> int:
+        """
+        Forget the snapshot and delete its file; the fallback cache is kept.
+
+        Returns:
+            Number of files the snapshot held
+        """
+        count = len(self.entries)
+        self.entries, self.state = {}, None
+        try:
+            self.path.unlink()
+        except FileNotFoundError:
+            pass
+        return count
+
+    def __repr__(self) -> str:
+        """String representation."""
+        return f"IndexSnapshot(path={str(self.path)!r}, files={len(self.entries)}, hits={self.hits}, misses={self.misses})"
+
+
+def _header() -> bytes:
+    """Get the first line of snapshot files written by this version."""
+    from .. import __version__
+
+    return f"ctxd-snapshot {SNAPSHOT_FORMAT} schema={SCHEMA_VERSION} ctxd={__version__}\n".encode("utf-8")
+
+
+def _settings(options: "Options") -> dict[str, Any]:
+    """Get the options that shape an index, as JSON-compatible values."""
+    return json.loads(json.dumps({f.name: getattr(options, f.name) for f in fields(options) if f.name not in _IGNORED_OPTIONS}))
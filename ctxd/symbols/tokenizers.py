@@ -0,0 +1,114 @@
+"""
+Token counters for budgeting symbol output.
+
+The budget logic only depends on the Tokenizer interface, so callers can
+supply their own counter. The heuristic is dependency-free; the tiktoken
+counter needs the optional `tiktoken` package.
+"""
+
+import logging
+from abc import ABC, abstractmethod
+
+logger = logging.getLogger(__name__)
+
+
+class Tokenizer(ABC):
+    """Abstract base class for token counters."""
+
+    @abstractmethod
+    def count(self, text: str) -> int:
+        """
+        Count the tokens in text.
+
+        Args:
+            text: Text to measure
+
+        Returns:
+            Number of tokens
+        """
+        pass
+
+
+class HeuristicTokenizer(Tokenizer):
+    """
+    Estimates tokens from length, assuming about four characters per token.
+
+    Close enough for English prose and code with GPT-style vocabularies,
+    without loading a vocabulary.
+    """
+
+    def __init__(self, chars_per_token: int = 4):
+        """
+        Initialize the heuristic.
+
+        Args:
+            chars_per_token: Average characters per token
+        """
+        self.chars_per_token = chars_per_token
+
+    def count(self, text: str) -> int:
+        """Estimate tokens, rounding up."""
+        return -(-len(text) // self.chars_per_token)
+
+
+class TiktokenTokenizer(Tokenizer):
+    """
+    Counts tokens exactly with a tiktoken encoding.
+
+    Requires the optional `tiktoken` package (`pip install ctxd[tokenizers]`).
+    """
+
+    def __init__(self, encoding: str = "cl100k_base"):
+        """
+        Load a tiktoken encoding.
+
+        Args:
+            encoding: tiktoken encoding name
+
+        Raises:
+            ImportError: If tiktoken is not installed
+        """
+        try:
+            import tiktoken
+        except ImportError as e:
+            raise ImportError(
+                "The tiktoken tokenizer requires the 'tiktoken' package. "
+                "Install it with: pip install ctxd[tokenizers]"
+            ) from e
+
+        self.encoding = tiktoken.get_encoding(encoding)
+        logger.debug(f"Loaded tiktoken encoding {encoding}")
+
+    def count(self, text: str) -> int:
+        """Count tokens with the loaded encoding."""
+        return len(self.encoding.encode(text, disallowed_special=()))
+
+
+# Registry of available tokenizers (name -> tokenizer class)
+TOKENIZERS: dict[str, type[Tokenizer]] = {
+    "heuristic": HeuristicTokenizer,
+    "tiktoken": TiktokenTokenizer,
+}
+
+
+def get_tokenizer(name: str) -> Tokenizer:
+    """
+    Create a tokenizer by name.
+
+    Args:
+        name: Tokenizer name (e.g. "heuristic", "tiktoken")
+
+    Returns:
+        Tokenizer instance
+
+    Raises:
+        ValueError: If the tokenizer is not registered
+        ImportError: If the tokenizer's optional dependency is missing
+    """
+    tokenizer_cls = TOKENIZERS.get(name)
+    if tokenizer_cls is None:
+        raise ValueError(
+            f"Unsupported tokenizer: {name}. "
+            f"Supported: {list(TOKENIZERS.keys())}"
+        )
+    return tokenizer_cls()
@@ -0,0 +1,123 @@
+"""
+Symbol transformers for post-processing extraction results.
+
+Embedders pass a chain of Transformers in Options.transformers to rewrite
+or drop symbols before they are output, for example to redact internal
+names, inject annotations into docs, or drop whole packages. The chain
+runs after kind and name filtering and before method grouping and
+canonical ordering:
+
+- each symbol goes through the transformers in list order, and a
+  transformer only sees what the ones before it kept
+- symbols reach a transformer in extraction order, one package (or, when
+  streaming, one file) at a time; do not rely on seeing the whole tree
+- the result is sorted afterwards by the names transformers gave, so
+  renaming a symbol can move it
+- transformers see each top-level symbol once; fields and methods are
+  part of their type and are rewritten through it
+
+Redactor is the built-in transformer.
+"""
+
+import re
+from abc import ABC, abstractmethod
+from dataclasses import replace
+from typing import Iterable, Optional
+
+from .models import Symbol
+
+# Symbol attributes that Redactor rewrites: text, then lists of text
+_TEXT_ATTRIBUTES = ("name", "signature", "doc", "summary", "receiver", "type", "value", "tag", "origin", "package", "source", "deprecation_note", "subject")
+_LIST_ATTRIBUTES = ("embeds", "calls", "implements", "pointer_implements", "imports")
+
+
+class Transformer(ABC):
+    """Abstract base class for symbol transformers."""
+
+    @abstractmethod
+    def transform(self, symbol: Symbol) -> Optional[Symbol]:
+        """
+        Rewrite or drop a symbol.
+
+        Args:
+            symbol: Symbol to transform; changing it in place is allowed
+
+        Returns:
+            The symbol to keep, the same object or a replacement, or None
+            to drop it
+        """
+        pass
+
+
+class Redactor(Transformer):
+    """
+    Replaces every match of a regular expression in a symbol's text, so
+    internal names stay out of the output.
+
+    The name, signature, doc, types, values, package, source, and the
+    names it lists (calls, embeds, implements, imports) are rewritten, in
+    the symbol and its fields and methods. Files and positions are kept.
+    """
+
+    def __init__(self, pattern: str, replacement: str = "REDACTED"):
+        """
+        Initialize the redactor.
+
+        Args:
+            pattern: Regular expression of text to redact, e.g. "internal\\w*"
+            replacement: Text put in place of each match
+
+        Raises:
+            ValueError: If the pattern is not a valid regular expression
+        """
+        try:
+            self.pattern = re.compile(pattern)
+        except re.error as e:
+            raise ValueError(f"Invalid redact pattern {pattern!r}: {e}") from e
+        self.replacement = replacement
+
+    def transform(self, symbol: Symbol) -> Optional[Symbol]:
+        """Get a copy of the symbol with its matches replaced."""
+        changes: dict = {name: self._redact(getattr(symbol, name)) for name in _TEXT_ATTRIBUTES}
+        changes.update({name: [self._redact(item) for item in getattr(symbol, name)] for name in _LIST_ATTRIBUTES})
+        changes["fields"] = [self.transform(f) for f in symbol.fields]
+        changes["methods"] = [self.transform(m) for m in symbol.methods]
+        return replace(symbol, **changes)
+
+    def _redact(self, text: str) -> str:
+        """Replace the matches in one piece of text."""
+        return self.pattern.sub(lambda _: self.replacement, text) if text else text
+
+
+def apply_transformers(symbols: Iterable[Symbol], transformers: Iterable[Transformer]) -> list[Symbol]:
+    """
+    Run symbols through a chain of transformers.
+
+    Args:
+        symbols: Symbols in the order transformers should see them
+        transformers: Transformers to apply, first to last
+
+    Returns:
+        The symbols that every transformer kept, as the last one returned
+        them, in input order
+
+    Raises:
+        TypeError: If a transformer returns something other than a Symbol
+            or None
+    """
+    chain = list(transformers)
+    transformed = []
+    for symbol in symbols:
+        for transformer in chain:
+            result = transformer.transform(symbol)
+            if result is None:
+                break
+            if not isinstance(result, Symbol):
+                raise TypeError(
+                    f"{type(transformer).__name__}.transform() returned {type(result).__name__}, "
+                    "expected a Symbol or None"
+                )
+            symbol = result
+        else:
+            transformed.append(symbol)
+    return transformed
@@ -0,0 +1,138 @@
+"""
+Unused unexported declarations.
+
+An unexported function, type, constant, or variable that nothing else in
+its package names is dead code: no other package can reach it either. The
+check is conservative, so it may miss dead code but does not flag live
+code:
+
+- a name counts as used wherever it appears as an identifier outside its
+  own declaration, even where a local of the same name shadows it
+- a string literal holding just the name also counts, since names can be
+  looked up reflectively (`MethodByName("helper")`, template functions)
+- declarations marked with a `//go:` directive (`//go:embed`,
+  `//go:noinline`) or named by `//go:linkname` or cgo's `//export` are
+  never flagged, nor are `init`, `main`, and names starting with `_`
+- every Go file of the package's directory is searched, including test
+  files and files that build constraints leave out for the target
+"""
+
+import re
+
+from tree_sitter import Node
+
+from .models import Symbol
+
+# Kinds of package-level declarations that are checked
+UNUSED_KINDS = ("func", "struct", "interface", "type", "alias", "const", "var")
+
+# Functions the runtime calls itself
+_ENTRY_POINTS = {"init", "main"}
+
+_IDENTIFIER_RE = re.compile(r"[A-Za-z_]\w*")
+_STRING_NODES = {"raw_string_literal", "interpreted_string_literal"}
+# Directives that name the declaration they keep alive, e.g. "//go:linkname local runtime.name"
+_NAMING_DIRECTIVE_RE = re.compile(r"^//(?:go:linkname|export)\s+([A-Za-z_]\w*)")
+
+
+def referenced_names(root: Node, source: bytes) -> set[str]:
+    """
+    Collect the names a file uses, for find_unused().
+
+    Args:
+        root: Root node of the parsed file
+        source: The file's source, which node offsets index
+
+    Returns:
+        Identifiers appearing outside their own package-level declaration,
+        identifier-like string literal contents, and the names of
+        declarations kept alive by directives
+    """
+    names: set[str] = set()
+    # Name to the byte ranges of its package-level declarations
+    declared: dict[str, list[tuple[int, int]]] = {}
+    comments: list[Node] = []
+    for node in root.children:
+        if node.type == "comment":
+            text = _text(node, source)
+            match = _NAMING_DIRECTIVE_RE.match(text)
+            if match:
+                names.add(match.group(1))
+            comments.append(node)
+            continue
+        marked = any(_text(c, source).startswith("//go:") for c in _attached(comments, node))
+        comments = []
+        for name, span in _declared_names(node, source):
+            declared.setdefault(name, []).append(span)
+            if marked:
+                names.add(name)
+
+    stack = [root]
+    while stack:
+        node = stack.pop()
+        if node.type in ("identifier", "type_identifier"):
+            name = _text(node, source)
+            if not any(start <= node.start_byte < end for start, end in declared.get(name, ())):
+                names.add(name)
+        elif node.type in _STRING_NODES:
+            content = _text(node, source)[1:-1]
+            if _IDENTIFIER_RE.fullmatch(content):
+                names.add(content)
+        stack.extend(node.children)
+    return names
+
+
+def find_unused(symbols: list[Symbol], referenced: set[str]) -> list[Symbol]:
+    """
+    Pick the unexported declarations of a package that it never references.
+
+    Args:
+        symbols: Symbols of one package; methods and fields are not checked
+        referenced: Names collected by referenced_names() from every file
+            of the package
+
+    Returns:
+        Unused symbols, in input order
+    """
+    return [
+        s for s in symbols
+        if s.kind in UNUSED_KINDS
+        and not s.exported
+        and s.name not in _ENTRY_POINTS
+        and not s.name.startswith("_")
+        and s.name not in referenced
+    ]
+
+
+def _declared_names(node: Node, source: bytes) -> list[tuple[str, tuple[int, int]]]:
+    """Get the names a package-level declaration declares, each with the span it is declared by."""
+    if node.type == "function_declaration":
+        name = node.child_by_field_name("name")
+        return [(_text(name, source), (node.start_byte, node.end_byte))] if name else []
+    declared = []
+    if node.type in ("type_declaration", "const_declaration", "var_declaration"):
+        for spec in node.named_children:
+            if spec.type in ("type_spec", "type_alias"):
+                name = spec.child_by_field_name("name")
+                declared.append((_text(name, source), (spec.start_byte, spec.end_byte)))
+            elif spec.type in ("const_spec", "var_spec"):
+                for name in spec.children_by_field_name("name"):
+                    declared.append((_text(name, source), (spec.start_byte, spec.end_byte)))
+    return declared
+
+
+def _attached(comments: list[Node], node: Node) -> list[Node]:
+    """Get the trailing run of comments that ends on the line before a node."""
+    attached: list[Node] = []
+    row = node.start_point[0]
+    for comment in reversed(comments):
+        if comment.end_point[0] != row - 1:
+            break
+        attached.append(comment)
+        row = comment.start_point[0]
+    return attached
+
+
+def _text(node: Node, source: bytes) -> str:
+    """Get the source text of a node."""
+    return source[node.start_byte:node.end_byte].decode("utf8", errors="replace")
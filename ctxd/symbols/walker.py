@@ -0,0 +1,882 @@
+"""
+Package tree walking for Go symbol extraction.
+
+Finds the Go files under a root directory the way the go tool would see
+them, aggregates their symbols by package import path, and summarizes each
+package. Trees are read through the Traversable protocol (see fs.py), so
+the root may be a pathlib.Path, a zipfile.Path, or a MapFS.
+"""
+
+import concurrent.futures
+import contextlib
+import itertools
+import logging
+import os
+import posixpath
+import re
+from functools import partial
+from pathlib import Path, PurePath
+from typing import Iterator, Optional, Union
+
+from .cache import SymbolCache
+from .constraints import BuildContext
+from .extractor import DIRECTIVE_RE, GoSymbolExtractor, GoSyntaxError
+from .filters import filter_exported
+from .fs import Traversable
+from .models import SYMBOL_KINDS, PackageSummary, ParseError, Symbol
+from .patterns import IgnoreRules, PathFilter
+from .resolve import find_implementations, flatten_interfaces, link_examples
+
+logger = logging.getLogger(__name__)
+
+# Directories the go tool never treats as part of the package tree
+EXCLUDED_DIRS = {"vendor", "testdata"}
+
+# Files listing paths for walks to skip (see patterns.IgnoreRules)
+IGNORE_FILENAME = ".ctxdignore"
+
+# Fewer files than this are parsed in-process, faster than workers start
+PARALLEL_MIN_FILES = 32
+
+_MODULE_RE = re.compile(r'^module\s+"?([^"\s]+)"?', re.MULTILINE)
+_PACKAGE_RE = re.compile(r"^package\s+(\w+)")
+# Lines declaring a single symbol, which the comments directly above belong to
+_DECLARATION_RE = re.compile(r"^(?:func\b|(?:type|var|const)\s+[^\s(])")
+
+
+def find_go_files(
+    root: Traversable,
+    recursive: bool = True,
+    include_tests: bool = False,
+    include: Optional[list[str]] = None,
+    exclude: Optional[list[str]] = None,
+    context: Optional[BuildContext] = None,
+    depth: Optional[int] = None
+) -> list[Traversable]:
+    """
+    Find the Go source files under a directory.
+
+    Skips vendor and testdata directories, directories starting with "." or
+    "_", paths ignored by `.ctxdignore` files, files whose build
+    constraints exclude the target platform (including `//go:build
+    ignore`), and `_test.go` files unless requested. Symlinks to
+    directories are not followed.
+
+    The `.ctxdignore` files of root and the directories below it apply, and
+    for a directory on disk inside a Go module, those of its parents up to
+    the module root too.
+
+    Args:
+        root: Directory to search, e.g. a Path or a MapFS
+        recursive: Descend into subdirectories
+        include_tests: Include `_test.go` files
+        include: Glob patterns (see patterns.py) that paths relative to
+            root must match; all files when empty
+        exclude: Glob patterns of relative paths to skip, taking precedence
+            over include
+        context: Target platform of build constraints (defaults to
+            BuildContext.default(), the go tool's)
+        depth: When recursive, the number of directory levels below root
+            to descend into: 0 for root's own files, 1 for those of its
+            subdirectories too, and so on; None for no limit
+
+    Returns:
+        Go files below root, of the same type as root, sorted by path
+
+    Raises:
+        ValueError: If a pattern or an ignore file line is invalid, or
+            $GOOS or $GOARCH is unknown
+    """
+    paths = PathFilter(include or (), exclude or ())
+    context = context or BuildContext.default()
+    files = []
+    for rel_path, file_path in _walk(root, recursive, depth, ignore=_ancestor_ignore_rules(root)):
+        if paths and not paths.matches(rel_path):
+            continue
+        if is_go_source(file_path, include_tests=include_tests, context=context):
+            files.append((rel_path.split("/"), file_path))
+
+    # Component-wise, so "a/b.go" sorts before "a.go" as with sorted Paths
+    return [file_path for _, file_path in sorted(files, key=lambda f: f[0])]
+
+
+def _walk(
+    directory: Traversable,
+    recursive: bool,
+    depth: Optional[int] = None,
+    prefix: str = "",
+    ignore: Optional[IgnoreRules] = None
+) -> Iterator[tuple[str, Traversable]]:
+    """
+    Yield (relative path, entry) for the non-directory entries of a tree,
+    descending at most `depth` levels and skipping ignored paths.
+
+    Like os.walk, symlinks to directories are not followed and unreadable
+    directories are skipped.
+    """
+    try:
+        entries = sorted(directory.iterdir(), key=lambda e: e.name)
+    except OSError as e:
+        logger.debug(f"Skipping unreadable directory {directory}: {e}")
+        return
+    ignore = _with_ignore_file(directory, prefix.rstrip("/"), ignore or IgnoreRules())
+
+    for entry in entries:
+        rel_path = prefix + entry.name
+        if isinstance(entry, Path) and entry.is_symlink() and entry.is_dir():
+            continue
+        if entry.is_dir():
+            if (recursive and (depth is None or depth > 0) and not is_excluded_dir(entry.name)
+                    and not ignore.ignored(rel_path, is_dir=True)):
+                yield from _walk(entry, recursive, None if depth is None else depth - 1, rel_path + "/", ignore)
+        elif not ignore.ignored(rel_path):
+            yield rel_path, entry
+
+
+def is_ignored(root: Traversable, rel_path: str) -> bool:
+    """
+    Check whether the `.ctxdignore` files of a tree skip a file or one of
+    its directories, as find_go_files() would.
+
+    Args:
+        root: Directory that is walked
+        rel_path: Slash-separated path of the file relative to root
+
+    Returns:
+        True if the file is ignored
+
+    Raises:
+        ValueError: If an ignore file line is invalid
+    """
+    ignore = _ancestor_ignore_rules(root)
+    parts = rel_path.split("/")
+    directory = root
+    for i, part in enumerate(parts):
+        ignore = _with_ignore_file(directory, "/".join(parts[:i]), ignore)
+        if ignore.ignored("/".join(parts[:i + 1]), is_dir=i < len(parts) - 1):
+            return True
+        directory = directory / part
+    return False
+
+
+def _with_ignore_file(directory: Traversable, rel_dir: str, ignore: IgnoreRules) -> IgnoreRules:
+    """Add the rules of a directory's ignore file, if it has a readable one."""
+    ignore_file = directory / IGNORE_FILENAME
+    if not ignore_file.is_file():
+        return ignore
+    try:
+        content = _read_text(ignore_file)
+    except OSError as e:
+        logger.debug(f"Cannot read {ignore_file}: {e}")
+        return ignore
+    return ignore.with_file(content, rel_dir, source=str(ignore_file))
+
+
+def _ancestor_ignore_rules(root: Traversable) -> IgnoreRules:
+    """Get the rules of the ignore files above a directory on disk, up to its module root."""
+    module = find_module(root) if isinstance(root, Path) else None
+    if module is None:
+        return IgnoreRules()
+    module_root = module[1]
+    rel_parts = root.resolve().relative_to(module_root).parts
+    ignore = IgnoreRules(prefix="".join(f"{part}/" for part in rel_parts))
+    for i in range(len(rel_parts)):
+        up = "/".join([".."] * (len(rel_parts) - i))
+        ignore = _with_ignore_file(module_root.joinpath(*rel_parts[:i]), up, ignore)
+    return ignore
+
+
+def is_excluded_dir(name: str) -> bool:
+    """Check whether a directory name is outside the go tool's package tree."""
+    return name in EXCLUDED_DIRS or name.startswith((".", "_"))
+
+
+def is_go_source(file_path: Traversable, include_tests: bool = False, context: Optional[BuildContext] = None) -> bool:
+    """
+    Check whether a file is a Go source file that would be built.
+
+    Args:
+        file_path: File to check
+        include_tests: Accept `_test.go` files
+        context: Target platform of build constraints (defaults to
+            BuildContext.default())
+
+    Returns:
+        True for `.go` files not hidden, not tests (unless requested), and
+        whose file name and build constraints allow the target platform
+    """
+    filename = file_path.name
+    if not filename.endswith(".go") or filename.startswith((".", "_")):
+        return False
+    if filename.endswith("_test.go") and not include_tests:
+        return False
+    context = context or BuildContext.default()
+    if not context.matches_filename(filename):
+        logger.debug(f"Skipping {file_path}: not built for {context.goos}/{context.goarch}")
+        return False
+    if not context.matches_source(_read_header(file_path)):
+        logger.debug(f"Skipping {file_path}: excluded by build constraints")
+        return False
+    return True
+
+
+def extract_packages(
+    root: Traversable,
+    recursive: bool = True,
+    include_tests: bool = False,
+    exported_only: bool = False,
+    calls: bool = False,
+    cache: Optional[SymbolCache] = None,
+    include: Optional[list[str]] = None,
+    exclude: Optional[list[str]] = None,
+    files: Optional[list[Traversable]] = None,
+    strict: bool = False,
+    errors: Optional[list[ParseError]] = None,
+    metrics: bool = False,
+    include_source: bool = False,
+    context: Optional[BuildContext] = None,
+    jobs: Optional[int] = None,
+    depth: Optional[int] = None,
+    comments: bool = True,
+    skip_bodies: bool = False
+) -> dict[str, list[Symbol]]:
+    """
+    Extract symbols from every Go file under a directory, grouped by package.
+
+    Packages are keyed by import path, derived from the nearest go.mod at or
+    above `root` (within the tree for trees other than a Path). Without a
+    go.mod, the directory path relative to `root` is used instead ("." for
+    the root itself). Embedded interfaces are resolved
+    across all files of a package, and implemented interfaces across all
+    packages of the tree.
+
+    A file with syntax errors contributes the declarations that could be
+    recovered, and an unreadable file is skipped; either way the walk goes
+    on and the problem is appended to `errors`.
+
+    Files are parsed by a pool of `jobs` worker processes, each with its
+    own extractor, and their results are merged in file order, so the
+    output and errors are the same as those of a serial walk.
+
+    Args:
+        root: Directory to walk, e.g. a Path or a MapFS
+        recursive: Descend into subdirectories
+        include_tests: Include `_test.go` files
+        exported_only: Drop unexported symbols after resolution
+        calls: Record the callees of each function and method body
+        cache: Reuse the symbols of files whose contents are unchanged
+        include: Glob patterns of relative file paths to extract
+        exclude: Glob patterns of relative file paths to skip
+        files: Files under root to extract, as returned by find_go_files();
+            the walk options are ignored when given
+        strict: Stop at the first file with syntax errors or that cannot
+            be read
+        errors: List to append syntax and read errors to
+        metrics: Record the cyclomatic complexity and lines of code of
+            each function and method body
+        include_source: Record the source text of each declaration
+        context: Target platform of build constraints (defaults to
+            BuildContext.default())
+        jobs: Number of files parsed at once (defaults to the number of
+            CPUs); trees of fewer than PARALLEL_MIN_FILES files to parse
+            are always parsed in-process
+        depth: Directory levels below root to descend into when recursive
+            (see find_go_files); None for no limit
+        comments: Record doc comments and directives
+        skip_bodies: Do not look inside function and method bodies, which
+            turns off calls and metrics (see GoSymbolExtractor)
+
+    Returns:
+        Mapping of import path to symbols, ordered by import path and then
+        by file name and source position
+
+    Raises:
+        GoSyntaxError: In strict mode, for a file with syntax errors
+        OSError: In strict mode, for a file that cannot be read
+    """
+    root = Path(root) if isinstance(root, str) else root
+    module = _module_of(root)
+    extractor = GoSymbolExtractor(
+        calls=calls,
+        cache=cache,
+        strict=strict,
+        metrics=metrics,
+        include_source=include_source,
+        comments=comments,
+        skip_bodies=skip_bodies,
+    )
+
+    packages: dict[str, list[Symbol]] = {}
+    if files is None:
+        files = find_go_files(
+            root,
+            recursive=recursive,
+            include_tests=include_tests,
+            include=include,
+            exclude=exclude,
+            context=context,
+            depth=depth,
+        )
+    results = _extract_files(extractor, files, jobs or os.cpu_count() or 1)
+    for file_path, symbols in zip(files, results):
+        if symbols is None:
+            continue
+        import_path = _import_path(_relative_dir(file_path, root), module)
+        for symbol in symbols:
+            symbol.package = import_path
+        packages.setdefault(import_path, []).extend(symbols)
+
+    for symbols in packages.values():
+        # Interfaces may embed ones declared in sibling files
+        flatten_interfaces(symbols)
+    # Types may implement interfaces of other packages in the tree
+    find_implementations([s for symbols in packages.values() for s in symbols])
+    # Examples may document declarations of sibling files
+    link_examples([s for symbols in packages.values() for s in symbols])
+    if errors is not None:
+        errors.extend(extractor.errors)
+
+    if exported_only:
+        packages = {path: filter_exported(symbols) for path, symbols in packages.items()}
+
+    logger.debug(f"Extracted {len(packages)} packages from {root}")
+    return dict(sorted(packages.items()))
+
+
+def stream_packages(
+    root: Traversable,
+    files: list[Traversable],
+    exported_only: bool = False,
+    calls: bool = False,
+    cache: Optional[SymbolCache] = None,
+    strict: bool = False,
+    errors: Optional[list[ParseError]] = None,
+    metrics: bool = False,
+    include_source: bool = False,
+    jobs: Optional[int] = None,
+    comments: bool = True,
+    skip_bodies: bool = False
+) -> Iterator[tuple[str, list[Symbol]]]:
+    """
+    Extract the symbols of files under a directory one file at a time.
+
+    Unlike extract_packages(), nothing is held back until the walk ends:
+    each file's symbols are yielded as soon as it is parsed, so embedded
+    interfaces and implemented interfaces are only resolved within the
+    file. Files are yielded in the order given when parsed in-process;
+    worker processes yield them in the order they finish.
+
+    Args:
+        root: Directory the files were found under
+        files: Files to extract, as returned by find_go_files()
+        errors: List to append syntax and read errors to, as each file
+            finishes
+
+    The remaining arguments are those of extract_packages().
+
+    Yields:
+        Import path of each file's package and the file's symbols, in
+        source order; unreadable files are skipped
+
+    Raises:
+        GoSyntaxError: In strict mode, for a file with syntax errors
+        OSError: In strict mode, for a file that cannot be read
+    """
+    root = Path(root) if isinstance(root, str) else root
+    module = _module_of(root)
+    extractor = GoSymbolExtractor(
+        calls=calls,
+        cache=cache,
+        strict=strict,
+        metrics=metrics,
+        include_source=include_source,
+        comments=comments,
+        skip_bodies=skip_bodies,
+    )
+    for i, symbols in _iter_files(extractor, files, jobs or os.cpu_count() or 1, ordered=False):
+        if errors is not None:
+            errors.extend(extractor.errors)
+        extractor.errors = []
+        if symbols is None:
+            continue
+        import_path = _import_path(_relative_dir(files[i], root), module)
+        for symbol in symbols:
+            symbol.package = import_path
+        yield import_path, filter_exported(symbols) if exported_only else symbols
+
+
+def _extract_files(extractor: GoSymbolExtractor, files: list[Traversable], jobs: int) -> list[Optional[list[Symbol]]]:
+    """
+    Extract the symbols of files, in parallel when there are enough of them.
+
+    Returns:
+        Symbols of each file, or None for a file that cannot be read
+    """
+    return [symbols for _, symbols in _iter_files(extractor, files, jobs)]
+
+
+def _iter_files(
+    extractor: GoSymbolExtractor,
+    files: list[Traversable],
+    jobs: int,
+    ordered: bool = True
+) -> Iterator[tuple[int, Optional[list[Symbol]]]]:
+    """
+    Extract the symbols of files as they are parsed, in parallel when there
+    are enough of them.
+
+    Files are read and looked up in the extractor's cache here; the rest
+    are parsed by worker processes. Errors are recorded on the extractor,
+    and in strict mode raised, as each file is yielded.
+
+    Args:
+        ordered: Yield files in the order given; otherwise unreadable and
+            cached files come first, then parsed files as workers finish
+
+    Yields:
+        Position of each file in `files` and its symbols, or None for a
+        file that cannot be read
+    """
+    contents: list[Union[str, OSError]] = []
+    for file_path in files:
+        try:
+            contents.append(_read_text(file_path))
+        except OSError as e:
+            contents.append(e)
+
+    cached: dict[int, list[Symbol]] = {}
+    keys: dict[int, str] = {}
+    pending = []
+    for i, (file_path, content) in enumerate(zip(files, contents)):
+        if isinstance(content, OSError):
+            continue
+        if extractor.cache is not None:
+            keys[i] = extractor.cache_key(content)
+            symbols = extractor.cache.get(keys[i], str(file_path))
+            if symbols is not None:
+                cached[i] = symbols
+                continue
+        pending.append(i)
+
+    settings = {
+        "calls": extractor.calls,
+        "metrics": extractor.metrics,
+        "include_source": extractor.include_source,
+        "comments": extractor.comments,
+        "skip_bodies": extractor.skip_bodies,
+    }
+    jobs = min(jobs, len(pending))
+    parse_jobs = [(contents[i], str(files[i])) for i in pending]
+    with contextlib.ExitStack() as stack:
+        if jobs > 1 and len(pending) >= PARALLEL_MIN_FILES:
+            logger.debug(f"Parsing {len(pending)} files with {jobs} workers")
+            executor = stack.enter_context(concurrent.futures.ProcessPoolExecutor(
+                max_workers=jobs, initializer=_init_worker, initargs=(settings,)
+            ))
+            if ordered:
+                # map() yields results in submission order, whatever order workers finish in
+                parsed = zip(pending, executor.map(_parse_in_worker, parse_jobs, chunksize=max(1, len(pending) // (jobs * 4))))
+            else:
+                futures = {executor.submit(_parse_in_worker, job): i for i, job in zip(pending, parse_jobs)}
+                parsed = ((futures[future], future.result()) for future in concurrent.futures.as_completed(futures))
+        else:
+            # map() is lazy, so each file is parsed as it is reached
+            parsed = zip(pending, map(partial(_parse, GoSymbolExtractor(**settings)), parse_jobs))
+
+        queued = set(pending)
+        if ordered:
+            finished = ((i, next(parsed)[1] if i in queued else None) for i in range(len(files)))
+        else:
+            finished = itertools.chain(((i, None) for i in range(len(files)) if i not in queued), parsed)
+        for i, result in finished:
+            yield i, _finish_file(extractor, files[i], contents[i], cached.get(i), result, keys.get(i))
+
+
+def _finish_file(
+    extractor: GoSymbolExtractor,
+    file_path: Traversable,
+    content: Union[str, OSError],
+    cached: Optional[list[Symbol]],
+    parsed: Optional[tuple[list[Symbol], list[ParseError]]],
+    key: Optional[str]
+) -> Optional[list[Symbol]]:
+    """Record the errors of a file read, looked up, or parsed by _iter_files(), and cache what was parsed."""
+    if isinstance(content, OSError):
+        if extractor.strict:
+            raise content
+        logger.debug(f"Skipping {file_path}: {content}")
+        extractor.errors.append(ParseError(file=str(file_path), line=0, column=0, message=content.strerror or str(content)))
+        return None
+    if cached is not None:
+        return cached
+    symbols, file_errors = parsed
+    if file_errors:
+        if extractor.strict:
+            raise GoSyntaxError(file_errors[0])
+        extractor.errors.extend(file_errors)
+    elif key is not None:
+        # Files with errors are re-parsed so that their errors are reported again
+        extractor.cache.put(key, symbols)
+    return symbols
+
+
+# Extractor of a worker process, created by _init_worker
+_worker_extractor: Optional[GoSymbolExtractor] = None
+
+
+def _init_worker(settings: dict[str, bool]) -> None:
+    """Create the extractor of a worker process; tree-sitter parsers are not shared."""
+    global _worker_extractor
+    _worker_extractor = GoSymbolExtractor(**settings)
+
+
+def _parse_in_worker(job: tuple[str, str]) -> tuple[list[Symbol], list[ParseError]]:
+    """Parse a file's contents in a worker process."""
+    return _parse(_worker_extractor, job)
+
+
+def _parse(extractor: GoSymbolExtractor, job: tuple[str, str]) -> tuple[list[Symbol], list[ParseError]]:
+    """Extract the symbols of a (content, path) pair, with its syntax errors."""
+    content, path = job
+    extractor.errors = []
+    symbols = extractor.extract(content, path)
+    return symbols, extractor.errors
+
+
+def summarize_packages(
+    root: Traversable,
+    packages: dict[str, list[Symbol]],
+    files: list[Traversable],
+    metrics: bool = False,
+    comments: bool = True
+) -> dict[str, PackageSummary]:
+    """
+    Summarize every package of a tree.
+
+    Args:
+        root: Directory that was walked
+        packages: Output of extract_packages(), possibly filtered
+        files: Files the packages were extracted from
+        metrics: Count the lines of code of each package's files
+        comments: Read package docs and file-level directives
+
+    Returns:
+        Mapping of import path to summary, in the order of `packages`
+    """
+    root = Path(root) if isinstance(root, str) else root
+    module = _module_of(root)
+    files_by_package: dict[str, list[Traversable]] = {}
+    for file_path in files:
+        files_by_package.setdefault(_import_path(_relative_dir(file_path, root), module), []).append(file_path)
+
+    return {
+        path: summarize_package(path, files_by_package.get(path, []), symbols, metrics, comments)
+        for path, symbols in packages.items()
+    }
+
+
+def summarize_package(
+    import_path: str,
+    files: list[Traversable],
+    symbols: list[Symbol],
+    metrics: bool = False,
+    comments: bool = True
+) -> PackageSummary:
+    """
+    Summarize one package.
+
+    The name and doc are read from the `package` clauses of its files. The
+    doc comes from the first file that has one (conventionally doc.go), and
+    the name from the first file outside an external `_test` package.
+    File-level directives are gathered from every file, in the same order.
+
+    Lines of code are counted over whole files, so unlike the kind counts
+    they do not depend on which symbols were kept.
+
+    Args:
+        import_path: Import path of the package ("" for a single file)
+        files: The package's Go files
+        symbols: The package's symbols, counted by kind and with their
+            imports aggregated
+        metrics: Count the code lines and statements of each file
+        comments: Read the doc and file-level directives; without them
+            both are left empty
+
+    Returns:
+        The package summary
+    """
+    name = doc = ""
+    directives: list[str] = []
+    for file_path in sorted(files, key=lambda f: (f.name != "doc.go", f.name)):
+        try:
+            content = _read_text(file_path)
+        except OSError as e:
+            logger.debug(f"Cannot read {file_path}: {e}")
+            continue
+        clause_name, clause_doc = parse_package_clause(content)
+        if not name or (name.endswith("_test") and not clause_name.endswith("_test")):
+            name = clause_name
+        if comments:
+            doc = doc or clause_doc
+            directives.extend(d for d in file_directives(content) if d not in directives)
+
+    summary = PackageSummary(
+        name=name,
+        path=import_path,
+        doc=doc,
+        files=len(files),
+        kinds=count_kinds(symbols),
+        imports=collect_imports(symbols),
+        directives=directives,
+    )
+    if metrics:
+        count_package_lines(summary, files)
+    return summary
+
+
+def count_package_lines(summary: PackageSummary, files: list[Traversable]) -> None:
+    """
+    Set the lines and loc of a summary, and of each of its files, by name.
+
+    Unreadable files are left out.
+    """
+    extractor = GoSymbolExtractor()
+    for file_path in sorted(files, key=lambda f: f.name):
+        try:
+            content = _read_text(file_path)
+        except OSError as e:
+            logger.debug(f"Cannot read {file_path}: {e}")
+            continue
+        lines, loc = extractor.count_lines(content)
+        summary.file_loc[file_path.name] = {"lines": lines, "loc": loc}
+        summary.lines += lines
+        summary.loc += loc
+
+
+def count_kinds(symbols: list[Symbol]) -> dict[str, int]:
+    """
+    Count symbols per kind, including methods grouped under their type.
+
+    Args:
+        symbols: Symbols to count
+
+    Returns:
+        Kind to count in SYMBOL_KINDS order, omitting kinds with no symbols
+    """
+    counts = {kind: 0 for kind in SYMBOL_KINDS}
+    for symbol in symbols:
+        counts[symbol.kind] += 1
+        if symbol.kind != "interface":
+            # Methods nested by group_methods, but not those promoted from embedded types
+            counts["method"] += sum(1 for m in symbol.methods if not m.promotion_depth)
+    return {kind: count for kind, count in counts.items() if count}
+
+
+def collect_imports(symbols: list[Symbol]) -> list[str]:
+    """
+    Aggregate the imports used by symbols, including methods grouped under their type.
+
+    Args:
+        symbols: Symbols whose imports to collect
+
+    Returns:
+        Unique import paths, sorted
+    """
+    imports: set[str] = set()
+    for symbol in symbols:
+        imports.update(symbol.imports)
+        if symbol.kind != "interface":
+            for method in (m for m in symbol.methods if not m.promotion_depth):
+                imports.update(method.imports)
+    return sorted(imports)
+
+
+def read_package_clause(file_path: Traversable) -> tuple[str, str]:
+    """
+    Read the package name and doc comment of a Go file.
+
+    Only the file header is scanned, without parsing the rest of the file.
+    As with go/doc, the doc comment is the comment group directly above the
+    `package` clause, and `//go:` directives inside it are dropped.
+
+    Args:
+        file_path: Go file to read
+
+    Returns:
+        (package name, doc comment), with "" for an unreadable file or a
+        missing clause
+    """
+    try:
+        content = _read_text(file_path)
+    except OSError as e:
+        logger.debug(f"Cannot read {file_path}: {e}")
+        return "", ""
+    return parse_package_clause(content)
+
+
+def parse_package_clause(content: str) -> tuple[str, str]:
+    """
+    Get the package name and doc comment of Go source (see read_package_clause()).
+
+    Args:
+        content: Go source code
+
+    Returns:
+        (package name, doc comment), with "" for a missing clause
+    """
+    comment: list[str] = []
+    block: Optional[list[str]] = None
+    for line in content.splitlines():
+        stripped = line.strip()
+        if block is not None:
+            block.append(line)
+            if "*/" in line:
+                comment.append(GoSymbolExtractor._strip_comment("\n".join(block).strip()))
+                block = None
+        elif not stripped:
+            comment = []
+        elif stripped.startswith("//"):
+            if not stripped.startswith("//go:"):
+                comment.append(GoSymbolExtractor._strip_comment(stripped))
+        elif stripped.startswith("/*"):
+            if "*/" in stripped:
+                comment.append(GoSymbolExtractor._strip_comment(stripped))
+            else:
+                block = [stripped]
+        else:
+            match = _PACKAGE_RE.match(stripped)
+            return (match.group(1), "\n".join(comment)) if match else ("", "")
+    return "", ""
+
+
+def file_directives(content: str) -> list[str]:
+    """
+    Get the `//go:` directives of Go source that belong to the file rather
+    than to a declaration.
+
+    Directives start their line. Those in the comment block directly above
+    a declaration of one symbol (`func`, or `type`, `var`, or `const`
+    without parentheses) are the symbol's, as the extractor records them;
+    the rest, such as `//go:build` lines and standalone `//go:generate`
+    commands, are the file's.
+
+    Args:
+        content: Go source code
+
+    Returns:
+        The directives as written, in source order
+    """
+    directives: list[str] = []
+    # Directives of the comment block being read, until it is known what follows it
+    pending: list[str] = []
+    in_block = False
+    for line in content.splitlines():
+        stripped = line.strip()
+        if in_block:
+            in_block = "*/" not in line
+        elif stripped.startswith("//"):
+            if DIRECTIVE_RE.match(line):
+                pending.append(line.rstrip())
+        elif stripped.startswith("/*"):
+            in_block = "*/" not in stripped
+        else:
+            if not _DECLARATION_RE.match(line):
+                directives.extend(pending)
+            pending = []
+    directives.extend(pending)
+    return directives
+
+
+def find_module(start: Path) -> Optional[tuple[str, Path]]:
+    """
+    Find the Go module containing a directory.
+
+    Args:
+        start: Directory to search from, walking up to the filesystem root
+
+    Returns:
+        (module path, module root directory), or None if there is no go.mod
+    """
+    start = Path(start).resolve()
+    for directory in [start, *start.parents]:
+        go_mod = directory / "go.mod"
+        if not go_mod.is_file():
+            continue
+        try:
+            match = _MODULE_RE.search(go_mod.read_text(encoding="utf-8", errors="ignore"))
+        except OSError as e:
+            logger.debug(f"Cannot read {go_mod}: {e}")
+            return None
+        return (match.group(1), directory) if match else None
+    return None
+
+
+def _module_of(root: Traversable) -> Optional[tuple[str, str]]:
+    """
+    Find the module of a walked tree.
+
+    Returns:
+        (module path, root directory relative to the module root, "." for
+        the module root itself), or None if there is no go.mod
+    """
+    if isinstance(root, Path):
+        module = find_module(root)
+        if module is None:
+            return None
+        module_path, module_root = module
+        return module_path, root.resolve().relative_to(module_root).as_posix()
+
+    # Other trees cannot be resolved; walk up through their parents
+    directory, rel_parts = root, []
+    while True:
+        go_mod = directory / "go.mod"
+        if go_mod.is_file():
+            try:
+                match = _MODULE_RE.search(_read_text(go_mod))
+            except OSError as e:
+                logger.debug(f"Cannot read {go_mod}: {e}")
+                return None
+            return (match.group(1), "/".join(reversed(rel_parts)) or ".") if match else None
+        parent = getattr(directory, "parent", directory)
+        if str(parent) == str(directory):
+            return None
+        rel_parts.append(directory.name)
+        directory = parent
+
+
+def _relative_dir(file_path: Traversable, root: Traversable) -> str:
+    """Get the slash-separated directory of a file relative to the root ("." for the root)."""
+    if isinstance(file_path, PurePath) and isinstance(root, PurePath):
+        return file_path.parent.relative_to(root).as_posix()
+    return posixpath.relpath(str(file_path.parent), str(root))
+
+
+def _import_path(rel_dir: str, module: Optional[tuple[str, str]]) -> str:
+    """Get the import path for a package directory relative to the walked root."""
+    if module is None:
+        return rel_dir
+
+    module_path, root_dir = module
+    rel = posixpath.normpath(posixpath.join(root_dir, rel_dir))
+    return module_path if rel == "." else f"{module_path}/{rel}"
+
+
+def _read_text(file_path: Traversable) -> str:
+    """Read a Go file as the extractor does, ignoring undecodable bytes."""
+    with file_path.open("r", encoding="utf-8", errors="ignore") as f:
+        return f.read()
+
+
+def _read_header(file_path: Traversable) -> str:
+    """Read a file up to its package clause, where build constraints must appear."""
+    lines = []
+    try:
+        with file_path.open("r", encoding="utf-8", errors="ignore") as f:
+            for line in f:
+                if _PACKAGE_RE.match(line):
+                    break
+                lines.append(line)
+    except OSError:
+        # Unreadable files are reported when they are extracted
+        return ""
+    return "".join(lines)
@@ -0,0 +1,354 @@
+"""
+Watch mode for Go symbol extraction.
+
+Monitors a package tree, re-extracts only the Go files that change, and
+reports the resulting symbol diffs.
+"""
+
+import logging
+import threading
+import time
+from pathlib import Path
+from typing import Optional, Callable
+from watchdog.observers import Observer
+from watchdog.events import FileSystemEventHandler, FileSystemEvent
+
+from .constraints import BuildContext
+from .index import SymbolIndex
+from .models import SymbolDiff
+from .patterns import PathFilter
+from .walker import find_go_files, is_excluded_dir, is_go_source, is_ignored
+
+logger = logging.getLogger(__name__)
+
+
+class GoChangeHandler(FileSystemEventHandler):
+    """
+    Event handler for Go file changes.
+
+    Coalesces bursts of events (e.g. an editor writing a temp file and
+    renaming it on save) so each file is re-parsed once per burst.
+    """
+
+    def __init__(
+        self,
+        index: SymbolIndex,
+        root: Path,
+        recursive: bool = True,
+        include_tests: bool = False,
+        debounce_seconds: float = 0.1,
+        on_diff: Optional[Callable[[SymbolDiff], None]] = None,
+        include: Optional[list[str]] = None,
+        exclude: Optional[list[str]] = None,
+        on_change: Optional[Callable[[], None]] = None,
+        context: Optional[BuildContext] = None,
+        depth: Optional[int] = None
+    ):
+        """
+        Initialize the change handler.
+
+        Args:
+            index: Index to update as files change
+            root: Directory being watched
+            recursive: Whether subdirectories are part of the tree
+            include_tests: Track `_test.go` files
+            debounce_seconds: Quiet period before pending changes are processed
+            on_diff: Optional callback with the symbol changes of each batch
+            include: Glob patterns of relative file paths to track
+            exclude: Glob patterns of relative file paths to ignore
+            on_change: Optional callback after each processed batch, also
+                when no symbol changed (e.g. only line numbers moved)
+            context: Target platform of build constraints (defaults to
+                BuildContext.default())
+            depth: Directory levels below the root that are part of the
+                tree when recursive; None for no limit
+        """
+        super().__init__()
+        self.index = index
+        self.root = Path(root)
+        self.recursive = recursive
+        self.include_tests = include_tests
+        self.paths = PathFilter(include or (), exclude or ())
+        self.debounce_seconds = debounce_seconds
+        self.on_diff = on_diff
+        self.on_change = on_change
+        self.context = context or BuildContext.default()
+        self.depth = depth
+
+        # Events arrive on the observer thread; processing runs on the caller's
+        self._lock = threading.Lock()
+        self._pending_changes: dict[str, str] = {}  # path -> event_type
+        self._last_event_time = 0.0
+
+    def on_modified(self, event: FileSystemEvent) -> None:
+        """Handle file modification events."""
+        if event.is_directory:
+            return
+        self._queue_change(event.src_path, "modified")
+
+    def on_created(self, event: FileSystemEvent) -> None:
+        """Handle file creation events."""
+        if event.is_directory:
+            return
+        self._queue_change(event.src_path, "created")
+
+    def on_deleted(self, event: FileSystemEvent) -> None:
+        """Handle file deletion events."""
+        if event.is_directory:
+            return
+        self._queue_change(event.src_path, "deleted")
+
+    def on_moved(self, event: FileSystemEvent) -> None:
+        """Handle file move events."""
+        if event.is_directory:
+            return
+        # Treat move as delete + create
+        self._queue_change(event.src_path, "deleted")
+        if hasattr(event, "dest_path"):
+            self._queue_change(event.dest_path, "created")
+
+    def _queue_change(self, path: str, event_type: str) -> None:
+        """
+        Queue a file change for processing.
+
+        Args:
+            path: File path that changed
+            event_type: Type of event (modified, created, deleted)
+        """
+        file_path = self._relative_to_root(Path(path))
+        if file_path is None or not self._is_watched(file_path):
+            return
+
+        with self._lock:
+            self._pending_changes[str(file_path)] = event_type
+            self._last_event_time = time.time()
+
+        logger.debug(f"Queued {event_type} event for {file_path}")
+
+    def _relative_to_root(self, file_path: Path) -> Optional[Path]:
+        """
+        Rewrite an event path relative to the root as it was given.
+
+        Keeps index keys identical to the paths found by the initial walk,
+        whether the observer reports absolute or relative paths.
+        """
+        try:
+            rel = file_path.resolve().relative_to(self.root.resolve())
+        except ValueError:
+            return None
+        return self.root / rel
+
+    def _is_watched(self, file_path: Path) -> bool:
+        """Check whether a path under the root is a Go file in the watched tree."""
+        if not file_path.name.endswith(".go"):
+            return False
+        rel_dirs = file_path.parent.relative_to(self.root).parts
+        if rel_dirs and not self.recursive:
+            return False
+        if self.depth is not None and len(rel_dirs) > self.depth:
+            return False
+        if any(is_excluded_dir(d) for d in rel_dirs):
+            return False
+        rel_path = file_path.relative_to(self.root).as_posix()
+        if not self.paths.matches(rel_path):
+            return False
+        try:
+            return not is_ignored(self.root, rel_path)
+        except ValueError as e:
+            logger.warning(f"Invalid ignore file: {e}")
+            return True
+
+    def process_pending_changes(self, force: bool = False) -> SymbolDiff:
+        """
+        Re-extract pending files if the debounce period has elapsed.
+
+        This should be called periodically (e.g., in a loop).
+
+        Args:
+            force: Process immediately, ignoring the debounce period
+
+        Returns:
+            Combined symbol changes of the processed files
+        """
+        with self._lock:
+            if not self._pending_changes:
+                return SymbolDiff()
+            if not force and time.time() - self._last_event_time < self.debounce_seconds:
+                return SymbolDiff()
+            pending, self._pending_changes = self._pending_changes, {}
+
+        logger.info(f"Processing {len(pending)} pending changes")
+
+        diff = SymbolDiff()
+        for path, event_type in sorted(pending.items()):
+            file_path = Path(path)
+            try:
+                # A file can be rewritten with build constraints that exclude
+                # it or fail the filter after a rename; either way it leaves
+                # the index
+                if event_type == "deleted" or not file_path.exists() \
+                        or not is_go_source(file_path, include_tests=self.include_tests, context=self.context):
+                    diff.extend(self.index.remove_file(file_path))
+                else:
+                    diff.extend(self.index.update_file(file_path))
+            except Exception as e:
+                logger.error(f"Failed to process change for {path}: {e}")
+
+        if diff and self.on_diff:
+            self.on_diff(diff)
+        if self.on_change:
+            self.on_change()
+        return diff
+
+
+class SymbolWatcher:
+    """
+    Watches a Go package tree and keeps a SymbolIndex up to date.
+
+    Uses watchdog to detect file system events; only changed files are
+    re-parsed.
+    """
+
+    def __init__(self, index: Optional[SymbolIndex] = None, debounce_seconds: float = 0.1):
+        """
+        Initialize the watcher.
+
+        Args:
+            index: Index to maintain (defaults to a new one)
+            debounce_seconds: Quiet period used to coalesce rapid writes
+        """
+        self.index = index or SymbolIndex()
+        self.debounce_seconds = debounce_seconds
+        self.observer: Optional[Observer] = None
+        self.handler: Optional[GoChangeHandler] = None
+        self._running = False
+
+    def build(
+        self,
+        path: Path,
+        recursive: bool = True,
+        include_tests: bool = False,
+        include: Optional[list[str]] = None,
+        exclude: Optional[list[str]] = None,
+        context: Optional[BuildContext] = None,
+        depth: Optional[int] = None
+    ) -> None:
+        """
+        Populate the index with every Go file currently in the tree.
+
+        Args:
+            path: Directory to index
+            recursive: Include subdirectories
+            include_tests: Include `_test.go` files
+            include: Glob patterns of relative file paths to index
+            exclude: Glob patterns of relative file paths to skip
+            context: Target platform of build constraints
+            depth: Directory levels below path to index when recursive
+        """
+        files = find_go_files(
+            Path(path),
+            recursive=recursive,
+            include_tests=include_tests,
+            include=include,
+            exclude=exclude,
+            context=context,
+            depth=depth,
+        )
+        for file_path in files:
+            self.index.update_file(file_path)
+        logger.info(f"Indexed symbols from {len(self.index)} files")
+
+    def start(
+        self,
+        path: Path,
+        recursive: bool = True,
+        include_tests: bool = False,
+        on_diff: Optional[Callable[[SymbolDiff], None]] = None,
+        include: Optional[list[str]] = None,
+        exclude: Optional[list[str]] = None,
+        on_change: Optional[Callable[[], None]] = None,
+        context: Optional[BuildContext] = None,
+        depth: Optional[int] = None
+    ) -> None:
+        """
+        Watch a directory until interrupted.
+
+        Blocks the calling thread. On Ctrl+C, pending changes are flushed
+        through `on_diff` before the observer shuts down.
+
+        Args:
+            path: Directory to watch
+            recursive: Watch subdirectories
+            include_tests: Track `_test.go` files
+            on_diff: Optional callback with the symbol changes of each batch
+            include: Glob patterns of relative file paths to track
+            exclude: Glob patterns of relative file paths to ignore
+            on_change: Optional callback after each processed batch
+            context: Target platform of build constraints
+            depth: Directory levels below path to watch when recursive
+        """
+        if self._running:
+            logger.warning("Watcher is already running")
+            return
+
+        path = Path(path)
+        if not path.is_dir():
+            raise ValueError(f"Path is not a directory: {path}")
+
+        logger.info(f"Starting symbol watcher for {path}")
+
+        self.handler = GoChangeHandler(
+            self.index,
+            path,
+            recursive=recursive,
+            include_tests=include_tests,
+            debounce_seconds=self.debounce_seconds,
+            on_diff=on_diff,
+            include=include,
+            exclude=exclude,
+            on_change=on_change,
+            context=context,
+            depth=depth
+        )
+        self.observer = Observer()
+        self.observer.schedule(self.handler, str(path), recursive=recursive)
+
+        self.observer.start()
+        self._running = True
+
+        try:
+            while self._running:
+                time.sleep(0.05)
+                self.handler.process_pending_changes()
+        except KeyboardInterrupt:
+            self.stop()
+
+    def stop(self) -> None:
+        """Flush pending changes and stop the watcher."""
+        if not self._running:
+            return
+
+        logger.info("Stopping symbol watcher")
+        self._running = False
+
+        if self.observer:
+            self.observer.stop()
+            self.observer.join()
+            self.observer = None
+
+        # Events that arrived inside the debounce window are still applied
+        if self.handler:
+            self.handler.process_pending_changes(force=True)
+            self.handler = None
+
+        logger.info("Symbol watcher stopped")
+
+    @property
+    def is_running(self) -> bool:
+        """Check if the watcher is currently running."""
+        return self._running
+
+    def __repr__(self) -> str:
+        """String representation."""
+        status = "running" if self._running else "stopped"
+        return f"SymbolWatcher({status}, files={len(self.index)})"
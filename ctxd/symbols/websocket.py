@@ -0,0 +1,114 @@
+"""
+Server side of the WebSocket protocol (RFC 6455), as far as /events needs it.
+
+Covers the opening handshake, unmasked server frames, and reading the
+masked frames clients send. Messages are small JSON texts, so fragmented
+messages and extensions such as permessage-deflate are not supported.
+"""
+
+import base64
+import hashlib
+import struct
+from typing import BinaryIO
+
+# Appended to Sec-WebSocket-Key before hashing, from RFC 6455
+HANDSHAKE_GUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+OP_CONTINUATION = 0x0
+OP_TEXT = 0x1
+OP_BINARY = 0x2
+OP_CLOSE = 0x8
+OP_PING = 0x9
+OP_PONG = 0xA
+
+CLOSE_NORMAL = 1000
+CLOSE_GOING_AWAY = 1001
+CLOSE_PROTOCOL_ERROR = 1002
+CLOSE_POLICY_VIOLATION = 1008
+CLOSE_TOO_BIG = 1009
+
+# Largest frame accepted from a client; clients only send control frames
+MAX_CLIENT_PAYLOAD = 64 * 1024
+
+
+class ProtocolError(ValueError):
+    """Raised for a frame that breaks the protocol; close_code says how to close."""
+
+    def __init__(self, message: str, close_code: int = CLOSE_PROTOCOL_ERROR):
+        super().__init__(message)
+        self.close_code = close_code
+
+
+def accept_key(key: str) -> str:
+    """Get the Sec-WebSocket-Accept value answering a Sec-WebSocket-Key."""
+    digest = hashlib.sha1((key.strip() + HANDSHAKE_GUID).encode("ascii")).digest()
+    return base64.b64encode(digest).decode("ascii")
+
+
+def is_valid_key(key: str) -> bool:
+    """Check that a Sec-WebSocket-Key is 16 base64-encoded bytes."""
+    try:
+        return len(base64.b64decode(key.strip(), validate=True)) == 16
+    except ValueError:
+        return False
+
+
+def encode_frame(opcode: int, payload: bytes = b"") -> bytes:
+    """Encode one final, unmasked frame, as servers send them."""
+    length = len(payload)
+    if length < 126:
+        header = struct.pack("!BB", 0x80 | opcode, length)
+    elif length < 1 << 16:
+        header = struct.pack("!BBH", 0x80 | opcode, 126, length)
+    else:
+        header = struct.pack("!BBQ", 0x80 | opcode, 127, length)
+    return header + payload
+
+
+def encode_close(code: int, reason: str = "") -> bytes:
+    """Encode a close frame with a status code and reason."""
+    return encode_frame(OP_CLOSE, struct.pack("!H", code) + reason.encode("utf-8")[:123])
+
+
+def read_frame(stream: BinaryIO) -> tuple[int, bytes]:
+    """
+    Read one frame from a client.
+
+    Args:
+        stream: Buffered socket stream
+
+    Returns:
+        Opcode and unmasked payload
+
+    Raises:
+        EOFError: If the connection closed
+        ProtocolError: If the frame is unmasked, fragmented, or too large
+    """
+    first, second = _read_exactly(stream, 2)
+    opcode = first & 0x0F
+    if not first & 0x80 or opcode == OP_CONTINUATION:
+        raise ProtocolError("Fragmented messages are not supported")
+    if not second & 0x80:
+        raise ProtocolError("Client frames must be masked")
+
+    length = second & 0x7F
+    if length == 126:
+        (length,) = struct.unpack("!H", _read_exactly(stream, 2))
+    elif length == 127:
+        (length,) = struct.unpack("!Q", _read_exactly(stream, 8))
+    if length > MAX_CLIENT_PAYLOAD:
+        raise ProtocolError(f"Frame of {length} bytes is too large", CLOSE_TOO_BIG)
+    if opcode >= OP_CLOSE and length > 125:
+        raise ProtocolError("Control frames must not exceed 125 bytes")
+
+    mask = _read_exactly(stream, 4)
+    payload = _read_exactly(stream, length)
+    return opcode, bytes(b ^ mask[i % 4] for i, b in enumerate(payload))
+
+
+def _read_exactly(stream: BinaryIO, size: int) -> bytes:
+    """Read size bytes, raising EOFError if the stream ends first."""
+    data = stream.read(size)
+    if len(data) < size:
+        raise EOFError("Connection closed")
+    return data
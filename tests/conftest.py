@@ -2,7 +2,8 @@
 Pytest fixtures for ctxd tests.
 
 Provides reusable test fixtures for temporary directories, sample files,
-mock components, and test data.
+mock components, and test data, and the write() helper that test modules
+import to lay out source trees.
 """
 
 import pytest
@@ -15,6 +16,13 @@ from ctxd.store import VectorStore
 from ctxd.indexer import Indexer
 
 
+def write(root: Path, rel_path: str, content: str) -> None:
+    """Write a file under root, creating parent directories."""
+    file_path = root / rel_path
+    file_path.parent.mkdir(parents=True, exist_ok=True)
+    file_path.write_text(content)
+
+
 @pytest.fixture
 def temp_dir():
     """Create a temporary directory for testing."""
@@ -0,0 +1,25 @@
+package aliases
+
+import "io"
+
+// Celsius is a defined type with its own method set
+type Celsius float64
+
+// Temperature is an alias; it is the same type as Celsius
+type Temperature = Celsius
+
+// Reader re-exports io.Reader
+type Reader = io.Reader
+
+type (
+	// ID is a defined type
+	ID int
+	// Key aliases ID
+	Key = ID
+)
+
+// Set is a generic defined type
+type Set[T comparable] map[T]struct{}
+
+// String formats a Celsius value
+func (c Celsius) String() string { return "" }
@@ -0,0 +1,30 @@
+// Package annotations has marker comments for annotation tests.
+//
+// TODO: write a package doc
+package annotations
+
+// FIXME(ana): overflow on large inputs
+func Add(a, b int) int {
+	s := "TODO: not a marker"
+	// HACK works around issue 12
+	return a + b // XXX: check
+}
+
+type Stack[T any] struct{ items []T }
+
+// Push adds an item.
+func (s *Stack[T]) Push(v T) {
+	/* BUG: never grows
+	 * TODO(bob) shrink too */
+	_ = v
+}
+
+const (
+	// TODOS is not a marker
+	A = 1 // TODO: rename
+)
+
+// this mentions TODO in prose
+var x = `
+// TODO: inside a raw string
+`
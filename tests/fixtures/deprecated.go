@@ -0,0 +1,37 @@
+// Package legacy has deprecated declarations for deprecation tests.
+package legacy
+
+// Connect opens a connection.
+//
+// Deprecated: Use Dial instead, which supports
+// timeouts.
+func Connect(addr string) error {
+	return nil
+}
+
+// Dial opens a connection with a timeout.
+func Dial(addr string, timeout int) error {
+	return nil
+}
+
+// Options configures a client.
+type Options struct {
+	Addr string
+
+	// Deprecated: Set Addr instead.
+	Host string
+}
+
+// Client talks to a server.
+//
+// Deprecated: Clients are created by Dial.
+type Client interface {
+	// Send sends a message.
+	//
+	// Deprecated: Use SendContext.
+	Send(msg string) error
+}
+
+// DeprecatedMode is not deprecated: its doc only mentions the word.
+// See the Deprecated: paragraph convention in go.dev/doc/comment.
+const DeprecatedMode = 1
@@ -0,0 +1,32 @@
+//go:build linux || darwin
+
+// Package assets embeds and generates its data.
+package assets
+
+import _ "embed"
+
+//go:generate stringer -type=Mode
+
+// Mode selects how assets are served.
+type Mode int
+
+// schema holds the bundled schema.
+//
+//go:embed schema.sql
+var schema string
+
+// Hash hashes its input.
+//go:noinline
+func Hash(data []byte) uint64 {
+	return 0
+}
+
+// go:generate is only mentioned here, with a space.
+func Plain() {}
+
+var (
+	//go:embed banner.txt
+	banner string
+)
+
+//go:generate go run gen.go
@@ -0,0 +1,52 @@
+package generics
+
+// Number is a constraint satisfied by numeric types.
+type Number interface {
+	~int | ~int64 | ~float64
+}
+
+// Map applies f to every element of s.
+func Map[T, U any](s []T, f func(T) U) []U {
+	out := make([]U, 0, len(s))
+	for _, v := range s {
+		out = append(out, f(v))
+	}
+	return out
+}
+
+// Sum adds all values together.
+func Sum[T ~int | ~string](values ...T) T {
+	var total T
+	for _, v := range values {
+		total += v
+	}
+	return total
+}
+
+// Keys returns the keys of m in unspecified order.
+func Keys[M ~map[K]V, K comparable, V any](m M) []K {
+	keys := make([]K, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// Stack is a LIFO container.
+type Stack[T comparable] struct {
+	items []T
+}
+
+// Push adds an item to the top of the stack.
+func (s *Stack[T]) Push(item T) {
+	s.items = append(s.items, item)
+}
+
+// Pair holds two values of different types.
+type Pair[K comparable, V any] struct {
+	Key   K
+	Value V
+}
+
+// List is a generic slice type.
+type List[T any] []T
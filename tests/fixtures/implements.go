@@ -0,0 +1,65 @@
+package shapes
+
+import "fmt"
+
+// Shape is implemented by value receivers
+type Shape interface {
+	Area() float64
+	Perimeter() float64
+}
+
+// Scaler needs a pointer receiver
+type Scaler interface {
+	Scale(factor float64)
+}
+
+// MutableShape combines Shape and Scaler
+type MutableShape interface {
+	Shape
+	Scaler
+}
+
+// Formatter has a variadic method
+type Formatter interface {
+	Format(format string, args ...interface{}) string
+}
+
+// Streamer embeds an interface from another package
+type Streamer interface {
+	fmt.Stringer
+	Stream() []byte
+}
+
+// Empty is satisfied by everything, so it is not reported
+type Empty interface{}
+
+// Rect is a rectangle
+type Rect struct {
+	W, H float64
+}
+
+func (r Rect) Area() float64      { return r.W * r.H }
+func (r Rect) Perimeter() float64 { return 2 * (r.W + r.H) }
+func (r *Rect) Scale(f float64)   { r.W *= f; r.H *= f }
+
+// Circle only has value methods, with a mismatched Perimeter
+type Circle struct{ R float64 }
+
+func (c Circle) Area() float64 { return 3.14 * c.R * c.R }
+func (c Circle) Perimeter() int { return 0 }
+
+// Printer formats with a different parameter name
+type Printer struct{}
+
+func (p Printer) Format(layout string, values ...interface{}) string { return "" }
+
+// Joiner takes a slice instead of variadic arguments
+type Joiner struct{}
+
+func (j Joiner) Format(format string, args []interface{}) string { return "" }
+
+// Celsius is a defined non-struct type
+type Celsius float64
+
+func (c Celsius) Area() float64      { return 0 }
+func (c Celsius) Perimeter() float64 { return 0 }
@@ -0,0 +1,44 @@
+// Package report renders reports.
+package report
+
+import (
+	"fmt"
+	str "strings"
+	_ "embed"
+	. "math"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Report is a rendered report.
+type Report struct {
+	Doc  yaml.Node
+	Body string
+}
+
+// Title is the default title.
+var Title = str.ToUpper("report")
+
+// Limit bounds report sizes.
+const Limit = 10
+
+// Render formats a report.
+func Render(r Report) string {
+	return fmt.Sprintf("%s: %v", Title, r.Doc)
+}
+
+// Scale grows a value by the square root of two.
+func Scale(v float64) float64 {
+	return v * Sqrt(2)
+}
+
+// Exit stops the program.
+func (r Report) Exit() {
+	os.Exit(Limit)
+}
+
+// Empty has no dependencies.
+func Empty() Report {
+	return Report{Body: ""}
+}
@@ -0,0 +1,49 @@
+package inline
+
+import "io"
+
+// Base is embedded by an inline struct.
+type Base struct{}
+
+// Config has fields of anonymous types.
+type Config struct {
+	// Server is an inline struct spanning several lines.
+	Server struct {
+		Host, Addr string `json:"host"`
+		Port       int    // listening port
+		*Base
+		io.Reader
+	}
+	Hooks   map[string]func(name string, args ...any) error
+	Events  <-chan struct{}
+	Limits  [4]struct{ Min, Max int }
+	Handler interface {
+		Handle(ctx any) (n int, err error)
+		io.Closer
+	}
+}
+
+// Walk takes a callback of a function type.
+func Walk(root string, visit func(path string, info struct {
+	Size int64
+	Dir  bool
+}) error) error {
+	return nil
+}
+
+// Options is a defined type of an inline struct type.
+type Options = struct {
+	Verbose bool
+}
+
+// Defaults has an inline type and no initializer.
+var Defaults map[string]struct {
+	Value string
+	Set   bool
+}
+
+// Number is a type set spanning lines.
+type Number interface {
+	~int |
+		~float64
+}
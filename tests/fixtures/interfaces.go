@@ -0,0 +1,41 @@
+package shapes
+
+import "io"
+
+// ReadWriteCloser is declared before the interfaces it embeds
+type ReadWriteCloser interface {
+	ReadWriter
+	Closer
+}
+
+// ReadWriter combines Reader and Writer
+type ReadWriter interface {
+	Reader
+	Writer
+}
+
+type Reader interface {
+	// Read reads into p
+	Read(p []byte) (n int, err error)
+}
+
+type Writer interface {
+	Write(p []byte) (n int, err error)
+}
+
+type Closer interface {
+	Close() error
+}
+
+// Flusher embeds an interface from another package
+type Flusher interface {
+	io.Writer
+	Flush() error
+	reset()
+}
+
+// Overlap reaches Close through two embedded interfaces
+type Overlap interface {
+	Closer
+	ReadWriteCloser
+}
@@ -0,0 +1,6 @@
+// +build plan9 windows,386
+
+package platform
+
+// Legacy is only built for plan9 and 32-bit Windows.
+func Legacy() {}
@@ -0,0 +1,8 @@
+package platform
+
+const name = "darwin"
+
+// Open opens a file on Apple silicon.
+func Open(path string) (int, error) {
+	return 0, nil
+}
@@ -0,0 +1,8 @@
+package platform
+
+const name = "linux"
+
+// Open opens a file with open(2).
+func Open(path string) (int, error) {
+	return 0, nil
+}
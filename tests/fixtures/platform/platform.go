@@ -0,0 +1,7 @@
+// Package platform has a different Open on each operating system.
+package platform
+
+// Name reports the platform.
+func Name() string {
+	return name
+}
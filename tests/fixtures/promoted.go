@@ -0,0 +1,48 @@
+package calculator
+
+// Accumulator is met by Calculator's pointer-receiver methods
+type Accumulator interface {
+	Add(n int)
+	Subtract(n int)
+}
+
+// Valuer is met by Calculator's value-receiver method
+type Valuer interface {
+	GetValue() int
+}
+
+// Counter embeds a Calculator by value, so its pointer-receiver methods
+// are only promoted to *Counter
+type Counter struct {
+	Calculator
+	count int
+}
+
+// SharedCounter embeds a pointer, so every method is promoted to the value
+type SharedCounter struct {
+	*Calculator
+}
+
+// Labeled promotes Calculator's methods two levels deep, except the
+// GetValue it declares itself
+type Labeled struct {
+	Counter
+	label string
+}
+
+// GetValue shadows the promoted Calculator.GetValue
+func (l Labeled) GetValue() int {
+	return 0
+}
+
+// Screen declares its own Display
+type Screen struct{}
+
+// Display shows nothing
+func (s Screen) Display() {}
+
+// Panel embeds two types with a Display at the same depth, so neither is promoted
+type Panel struct {
+	Calculator
+	Screen
+}
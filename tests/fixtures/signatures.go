@@ -0,0 +1,74 @@
+package signatures
+
+import (
+	"context"
+	"io"
+)
+
+// Printf has a variadic parameter and named results.
+func Printf(format string, args ...interface{}) (n int, err error) {
+	return 0, nil
+}
+
+// Clamp groups parameters that share a type.
+func Clamp(x, lo, hi int) int {
+	return min(max(x, lo), hi)
+}
+
+// Pair has unnamed parameters and results.
+func Pair(int, string) (int, error) {
+	return 0, nil
+}
+
+// Count has a single named result.
+func Count(r io.Reader) (total int) {
+	return 0
+}
+
+// Join has a variadic slice parameter.
+func Join(sep string, parts ...[]string) string {
+	return ""
+}
+
+// Apply takes and returns function types.
+func Apply(f func(a, b int) (int, error), g func(...int)) func() error {
+	return nil
+}
+
+// Ignore has blank parameter names.
+func Ignore(_ int, _, label string) {}
+
+// Pipe has directional channel parameters.
+func Pipe(in <-chan int, out chan<- []int) {}
+
+// Open is split over several lines with comments between parameters.
+func Open(
+	ctx context.Context, // request scope
+	name string,
+	/* flags */ flag int,
+	opts ...Option,
+) (
+	f *File, // nil on error
+	err error,
+) {
+	return nil, nil
+}
+
+// File is an open file.
+type File struct{}
+
+// Option configures Open.
+type Option func(*File)
+
+// ReadAt is a method with named results.
+func (f *File) ReadAt(p []byte, off int64) (n int, err error) {
+	return 0, nil
+}
+
+// Stream has parameter lists with comments.
+type Stream interface {
+	Read(p []byte /* buffer */) (n int, err error)
+	Write(
+		p []byte, // data to write
+	) (int, error)
+}
@@ -0,0 +1,26 @@
+package shapes
+
+import "io"
+
+// Base holds fields shared by all shapes.
+type Base struct {
+	ID string `json:"id"`
+}
+
+// Point is a 2D point with serialization tags.
+type Point struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y" yaml:"y"`
+}
+
+// Shape embeds other types.
+type Shape struct {
+	Base
+	*Point
+	io.Reader
+
+	// Name is the display name.
+	Name  string
+	w, h  int
+	label string "legacy"
+}
@@ -0,0 +1,57 @@
+package values
+
+import "time"
+
+// Weekday is a day of the week
+type Weekday int
+
+// Days of the week
+const (
+	// Sunday starts the week
+	Sunday Weekday = iota
+	Monday
+	Tuesday
+	_
+	Thursday
+)
+
+// ByteSize counts bytes
+type ByteSize uint64
+
+const (
+	_           = iota
+	KB ByteSize = 1 << (10 * iota)
+	MB
+	GB
+)
+
+// Pi is an untyped constant
+const Pi = 3.14159
+
+const (
+	Greeting       = "hello"
+	Timeout        = 5 * time.Second
+	big, small     = 1 << 62, -1
+	Mask           = ^uint8(0)
+)
+
+// Config holds settings
+type Config struct{ Name string }
+
+var (
+	// Count is inferred as int
+	Count = 0
+	Ratio = 0.5
+	Label = `raw`
+	Enabled = true
+	Default = Config{Name: "x"}
+	Current = &Config{}
+	Started time.Time
+	x, y int = 1, 2
+	Handler = func(n int) error { return nil }
+)
+
+// ErrMissing reports a missing value
+var ErrMissing = newError("missing")
+
+func newError(msg string) error { return nil }
@@ -0,0 +1,147 @@
+"""
+Unit tests for TODO/FIXME marker extraction.
+
+Tests AnnotationScanner against tests/fixtures/annotations.go, directory
+scans through extract_annotations, and the formatters' annotation output.
+"""
+
+import json
+import pytest
+from pathlib import Path
+from ctxd.symbols import (
+    Annotation,
+    AnnotationScanner,
+    JsonFormatter,
+    MarkdownFormatter,
+    Options,
+    TextFormatter,
+    extract_annotations,
+)
+
+FIXTURES = Path(__file__).parent / "fixtures"
+
+
+@pytest.fixture
+def annotations():
+    """Annotations of the fixture file with the default markers."""
+    return AnnotationScanner().scan_file(FIXTURES / "annotations.go")
+
+
+class TestAnnotationScanner:
+    """Tests for finding markers in comments."""
+
+    def test_markers(self, annotations):
+        """Each marker comment is found once, in source order."""
+        assert [(a.line, a.marker, a.text) for a in annotations] == [
+            (3, "TODO", "write a package doc"),
+            (6, "FIXME", "overflow on large inputs"),
+            (9, "HACK", "works around issue 12"),
+            (10, "XXX", "check"),
+            (17, "BUG", "never grows"),
+            (18, "TODO", "shrink too"),
+            (24, "TODO", "rename"),
+        ]
+
+    def test_string_literals_ignored(self, annotations):
+        """Markers inside interpreted and raw string literals are not comments."""
+        assert all("not a marker" not in a.text and "raw string" not in a.text for a in annotations)
+
+    def test_prose_ignored(self, annotations):
+        """Only markers starting a comment line count, as whole words."""
+        texts = [a.text for a in annotations]
+        assert not any("prose" in t for t in texts)
+        assert not any("TODOS" in t for t in texts)
+
+    def test_owner(self, annotations):
+        """A parenthesized name after the marker is its owner."""
+        owners = {a.line: a.owner for a in annotations if a.owner}
+        assert owners == {6: "ana", 18: "bob"}
+
+    def test_columns(self, annotations):
+        """Columns point at the marker, in characters."""
+        columns = {a.line: a.column for a in annotations}
+        assert columns[6] == 4
+        assert columns[9] == 5
+        assert columns[18] == 5
+
+    def test_symbols(self, annotations):
+        """Annotations name the declaration they are in, trail, or document."""
+        assert {a.line: a.symbol for a in annotations} == {
+            3: "",
+            6: "Add",
+            9: "Add",
+            10: "Add",
+            17: "Stack.Push",
+            18: "Stack.Push",
+            24: "A",
+        }
+
+    def test_custom_markers(self):
+        """Only the configured markers are reported."""
+        found = AnnotationScanner(["HACK", "BUG"]).scan_file(FIXTURES / "annotations.go")
+        assert [a.marker for a in found] == ["HACK", "BUG"]
+
+    def test_invalid_markers(self):
+        """Markers must be single words."""
+        with pytest.raises(ValueError, match="Invalid marker"):
+            AnnotationScanner(["TO DO"])
+        with pytest.raises(ValueError, match="At least one marker"):
+            AnnotationScanner([])
+
+
+class TestExtractAnnotations:
+    """Tests for the library entry point."""
+
+    def test_file(self):
+        """A single file is scanned."""
+        found = extract_annotations(FIXTURES / "annotations.go", Options(markers=["FIXME"]))
+        assert [(a.file, a.line) for a in found] == [(str(FIXTURES / "annotations.go"), 6)]
+
+    def test_directory(self, tmp_path):
+        """A directory's files are scanned in file order, honoring exclude."""
+        (tmp_path / "a.go").write_text("package a\n\n// TODO: first\nfunc A() {}\n")
+        (tmp_path / "b.go").write_text("package a\n\n// TODO: second\nfunc B() {}\n")
+        (tmp_path / "c_gen.go").write_text("package a\n\n// TODO: generated\nfunc C() {}\n")
+
+        found = extract_annotations(tmp_path, Options(exclude=["*_gen.go"]))
+
+        assert [(Path(a.file).name, a.text, a.symbol) for a in found] == [
+            ("a.go", "first", "A"),
+            ("b.go", "second", "B"),
+        ]
+
+
+class TestFormatAnnotations:
+    """Tests for annotation output in each format."""
+
+    ANNOTATIONS = [
+        Annotation(marker="TODO", text="handle | pipes", file="a.go", line=3, column=4, owner="ana", symbol="Add"),
+        Annotation(marker="FIXME", text="leak", file="a.go", line=9, column=2),
+    ]
+
+    def test_text(self):
+        """Text output has one line per annotation with its symbol."""
+        assert TextFormatter().format_annotations(self.ANNOTATIONS).splitlines() == [
+            "a.go:3: TODO(ana): handle | pipes  // in Add",
+            "a.go:9: FIXME: leak",
+        ]
+
+    def test_json(self):
+        """JSON output is an array of annotation objects."""
+        data = json.loads(JsonFormatter().format_annotations(self.ANNOTATIONS))
+        assert data[0] == {
+            "marker": "TODO",
+            "text": "handle | pipes",
+            "file": "a.go",
+            "line": 3,
+            "column": 4,
+            "owner": "ana",
+            "symbol": "Add",
+        }
+
+    def test_markdown(self):
+        """Markdown output is a table with escaped cells."""
+        lines = MarkdownFormatter().format_annotations(self.ANNOTATIONS).splitlines()
+        assert lines[0] == "| Location | Symbol | Marker | Text |"
+        assert lines[2] == "| `a.go:3` | `Add` | TODO(ana) | handle \\| pipes |"
+        assert MarkdownFormatter().format_annotations([]) == ""
@@ -0,0 +1,258 @@
+"""
+Unit tests for the symbol extraction library API.
+
+Tests extract_file, extract_source, extract_dir, stream_dir, extract_context, Options, and Index as downstream code
+would use them.
+"""
+
+import pytest
+from pathlib import Path
+import ctxd
+from ctxd.symbols import Index, Options, Symbol, extract_context, extract_dir, extract_file, extract_source, stream_dir
+from ctxd.symbols import api
+from conftest import write
+
+FIXTURES = Path(__file__).parent / "fixtures"
+
+
+@pytest.fixture
+def module_tree(tmp_path):
+    """A small Go module with a root and a nested package."""
+    write(tmp_path, "go.mod", "module example.com/shapes\n\ngo 1.22\n")
+    write(tmp_path, "shapes.go", "package shapes\n\nfunc Area() int { return Scale() }\n\nfunc Scale() int { return 1 }\n")
+    write(tmp_path, "geo/point.go", "package geo\n\ntype Point struct{}\n\nfunc (p Point) X() int { return 0 }\n\nfunc origin() Point { return Point{} }\n")
+    return tmp_path
+
+
+class TestExample:
+    """Usage example: extracting the sample fixture."""
+
+    def test_example_extract_sample(self):
+        """Extract a file's exported API and list its methods by receiver."""
+        symbols = ctxd.extract_file(FIXTURES / "sample.go", ctxd.Options(exported_only=True, group_methods=True))
+
+        calculator = next(s for s in symbols if s.name == "Calculator")
+        assert calculator.kind == "struct"
+        assert calculator.fields == []  # value and name are unexported
+        assert [m.name for m in calculator.methods] == ["Add", "Display", "GetValue", "Subtract"]
+        assert calculator.methods[0].signature == "func (c *Calculator) Add(n int)"
+
+        functions = [s.name for s in symbols if s.kind == "func"]
+        assert functions == ["Add", "Multiply", "NewCalculator"]
+        assert symbols[0].to_dict()["name"] == "Adder"
+
+
+class TestExtractFile:
+    """Tests for single-file extraction."""
+
+    def test_defaults(self):
+        """Without options, all symbols are returned in canonical order."""
+        symbols = extract_file(FIXTURES / "sample.go")
+
+        assert all(isinstance(s, Symbol) for s in symbols)
+        assert [s.name for s in symbols if s.kind == "method"] == ["Add", "Display", "GetValue", "Subtract"]
+        assert [f.name for f in next(s for s in symbols if s.name == "Calculator").fields] == ["value", "name"]
+        assert symbols == extract_file(str(FIXTURES / "sample.go"))
+
+    def test_missing_file(self, tmp_path):
+        """A missing file raises instead of returning nothing."""
+        with pytest.raises(FileNotFoundError):
+            extract_file(tmp_path / "missing.go")
+
+
+class TestExtractSource:
+    """Tests for extracting source held in memory."""
+
+    def test_matches_file(self):
+        """Source extracts like the file it came from, under the given name."""
+        content = (FIXTURES / "sample.go").read_text()
+        from_file = extract_file(FIXTURES / "sample.go")
+        from_source = extract_source(content, "calculator.go")
+
+        assert [(s.name, s.line) for s in from_source] == [(s.name, s.line) for s in from_file]
+        assert {s.file for s in from_source} == {"calculator.go"}
+
+    def test_default_name(self):
+        """Without a name the file is reported as <stdin>, with lines relative to the content."""
+        symbols = extract_source("package a\n\nfunc One() {}\n\nfunc two() {}\n", options=Options(exported_only=True))
+
+        assert [(s.name, s.file, s.line) for s in symbols] == [("One", "<stdin>", 3)]
+
+    def test_errors(self):
+        """Syntax errors are appended to the given list under the file name."""
+        errors = []
+        extract_source("package a\n\nfunc B( {\n", "b.go", errors=errors)
+
+        assert errors and errors[0].file == "b.go"
+
+
+class TestExtractDir:
+    """Tests for directory extraction into an Index."""
+
+    def test_packages(self, module_tree):
+        """Packages are keyed by import path; recursion is opt-in."""
+        assert list(extract_dir(module_tree).packages) == ["example.com/shapes"]
+
+        index = extract_dir(module_tree, Options(recursive=True))
+        assert isinstance(index, Index)
+        assert list(index.packages) == ["example.com/shapes", "example.com/shapes/geo"]
+        assert [s.name for s in index.package("example.com/shapes/geo")] == ["Point", "X", "origin"]
+        assert index.package("example.com/missing") == []
+        assert len(index) == 5
+        assert [s.name for s in index] == [s.name for s in index.symbols()]
+
+    def test_options(self, module_tree):
+        """Options are applied per package."""
+        index = extract_dir(module_tree, Options(recursive=True, exported_only=True, group_methods=True))
+        geo = index.package("example.com/shapes/geo")
+
+        assert [s.name for s in geo] == ["Point"]
+        assert [m.name for m in geo[0].methods] == ["X"]
+
+    def test_kinds(self, module_tree):
+        """Kind filtering runs before methods are grouped."""
+        index = extract_dir(module_tree, Options(recursive=True, kinds=["method"], group_methods=True))
+
+        assert [s.local_name for s in index] == ["Point.X"]
+        with pytest.raises(ValueError, match="Unknown symbol kind"):
+            extract_dir(module_tree, Options(kinds=["function"]))
+
+    def test_names(self, module_tree):
+        """Name patterns apply to every package, after kind filtering."""
+        index = extract_dir(module_tree, Options(recursive=True, kinds=["struct", "method"], name="^[PX]", name_exclude="X"))
+
+        assert [s.local_name for s in index] == ["Point"]
+        with pytest.raises(ValueError, match="Invalid name pattern"):
+            extract_dir(module_tree, Options(name="*"))
+
+    def test_call_graph(self, module_tree):
+        """The index builds the call graph when calls are recorded."""
+        index = extract_dir(module_tree, Options(calls=True))
+
+        assert index.call_graph()["example.com/shapes.Area"] == ["example.com/shapes.Scale"]
+
+    def test_summaries(self, module_tree):
+        """Each package is summarized with the counts of its extracted symbols."""
+        index = extract_dir(module_tree, Options(recursive=True, exported_only=True))
+
+        assert list(index.summaries) == list(index.packages)
+        geo = index.summaries["example.com/shapes/geo"]
+        assert (geo.name, geo.files, geo.kinds) == ("geo", 1, {"method": 1, "struct": 1})
+
+    def test_errors(self, module_tree):
+        """Syntax errors are collected on the index."""
+        write(module_tree, "broken.go", "package shapes\n\nfunc Broken( {\n")
+        index = extract_dir(module_tree)
+
+        assert {e.file for e in index.errors} == {str(module_tree / "broken.go")}
+        assert "Area" in [s.name for s in index]
+
+    def test_jobs(self, module_tree):
+        """jobs must be at least 1."""
+        assert len(extract_dir(module_tree, Options(recursive=True, jobs=2))) == len(extract_dir(module_tree, Options(recursive=True)))
+        with pytest.raises(ValueError, match="Invalid jobs"):
+            extract_dir(module_tree, Options(jobs=0))
+
+    def test_not_a_directory(self, tmp_path):
+        """A file or missing path is rejected."""
+        with pytest.raises(NotADirectoryError):
+            extract_dir(tmp_path / "missing")
+
+
+class TestParserModes:
+    """Tests for the comments and skip_bodies options."""
+
+    def test_no_comments(self, tmp_path):
+        """Package docs and directives are left out along with symbol docs."""
+        write(tmp_path, "a.go", "//go:build linux\n\n// Package a does things.\npackage a\n\n// Run runs.\nfunc Run() {}\n")
+
+        index = extract_dir(tmp_path, Options(comments=False, goos="linux"))
+
+        assert index.symbols()[0].doc == ""
+        assert (index.summaries["."].doc, index.summaries["."].directives) == ("", [])
+
+    def test_skip_bodies_turns_off_metrics(self, module_tree, monkeypatch):
+        """Calls and metrics are turned off with a warning instead of reported as empty."""
+        warnings = []
+        monkeypatch.setattr(api.logger, "warning", warnings.append)
+
+        index = extract_dir(module_tree, Options(calls=True, metrics=True, skip_bodies=True))
+
+        assert warnings == ["Function bodies are skipped, so calls and metrics will not be recorded"]
+        assert index.summaries["example.com/shapes"].lines == 0
+        assert [s.signature for s in index.symbols()] == ["func Area() int", "func Scale() int"]
+
+    def test_context_reads_bodies(self, module_tree):
+        """Context slices follow calls even when bodies are skipped otherwise."""
+        context = extract_context(module_tree, "Area", Options(skip_bodies=True))
+
+        assert [s.name for s in context] == ["Scale", "Area"]
+
+
+class TestStreamDir:
+    """Tests for streaming the symbols of a directory file by file."""
+
+    def test_matches_extract_dir(self, module_tree):
+        """A serial stream yields the symbols of extract_dir, file by file in walk order."""
+        options = Options(recursive=True, exported_only=True, jobs=1)
+        streamed = [s.qualified_name for s in stream_dir(module_tree, options)]
+
+        assert streamed == ["example.com/shapes/geo.Point", "example.com/shapes/geo.Point.X", "example.com/shapes.Area", "example.com/shapes.Scale"]
+        assert sorted(streamed) == sorted(s.qualified_name for s in extract_dir(module_tree, options))
+
+    def test_lazy(self, module_tree):
+        """Symbols are yielded before later files are parsed."""
+        write(module_tree, "whole.go", "package shapes\n\nfunc Whole( {\n")
+        errors = []
+        stream = stream_dir(module_tree, Options(recursive=True, jobs=1), errors=errors)
+
+        assert next(stream).name == "Point"
+        assert errors == []
+        list(stream)
+        assert [e.file for e in errors] == [str(module_tree / "whole.go")]
+
+    def test_filters(self, module_tree):
+        """Kind and name filters apply to each file."""
+        streamed = stream_dir(module_tree, Options(recursive=True, kinds=["func"], name_exclude="^A"))
+
+        assert sorted(s.name for s in streamed) == ["Scale", "origin"]
+
+    def test_group_methods(self, module_tree):
+        """Methods cannot be grouped, since their types may be in other files."""
+        with pytest.raises(ValueError, match="cannot be grouped"):
+            list(stream_dir(module_tree, Options(group_methods=True)))
+
+
+class TestExtractContext:
+    """Tests for gathering a symbol and its dependencies."""
+
+    def test_constructor(self):
+        """NewCalculator at depth 1 pulls in the Calculator struct, before itself."""
+        context = extract_context(FIXTURES / "sample.go", "NewCalculator")
+
+        assert [(s.kind, s.name) for s in context] == [("struct", "Calculator"), ("func", "NewCalculator")]
+        assert all(s.source == "" and s.calls == [] for s in context)
+
+    def test_across_packages(self, module_tree):
+        """Qualified names resolve into other packages of the tree."""
+        write(module_tree, "shapes.go", 'package shapes\n\nimport "example.com/shapes/geo"\n\nfunc Area(p geo.Point) int { return p.X() }\n')
+
+        context = extract_context(module_tree, "example.com/shapes.Area", Options(recursive=True, include_source=True))
+
+        assert [s.qualified_name for s in context] == ["example.com/shapes/geo.Point", "example.com/shapes.Area"]
+        assert context[-1].source.startswith("func Area")
+
+    def test_filters_do_not_apply(self, module_tree):
+        """Kind and name filters do not hide dependencies."""
+        context = extract_context(module_tree, "Area", Options(kinds=["struct"], name="^X"))
+
+        assert [s.name for s in context] == ["Scale", "Area"]
+
+    def test_unknown_and_ambiguous(self, module_tree):
+        """A name must denote exactly one symbol."""
+        write(module_tree, "geo/line.go", "package geo\n\nfunc Scale() {}\n")
+
+        with pytest.raises(ValueError, match="Unknown symbol"):
+            extract_context(module_tree, "Missing")
+        with pytest.raises(ValueError, match="Ambiguous symbol: Scale"):
+            extract_context(module_tree, "Scale", Options(recursive=True))
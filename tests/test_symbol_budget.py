@@ -0,0 +1,169 @@
+"""
+Unit tests for token budgeting.
+
+Tests the tokenizers and fit_to_budget tier ordering, doc stripping, and
+the final fit.
+"""
+
+import pytest
+from dataclasses import replace
+from ctxd.symbols import GoSymbolExtractor, TextFormatter, Tokenizer, sort_symbols
+from ctxd.symbols.budget import DEFAULT_PRIORITY, fit_to_budget
+from ctxd.symbols.tokenizers import HeuristicTokenizer, TOKENIZERS, get_tokenizer
+
+CONTENT = """package calc
+
+// Calculator holds a running total
+type Calculator struct{ Total int }
+
+// Add adds n to the total
+func (c *Calculator) Add(n int) { c.Total += n }
+
+// New creates a calculator
+func New() *Calculator { return &Calculator{} }
+
+// helper is internal
+func helper() {}
+"""
+
+
+@pytest.fixture
+def symbols():
+    """Sorted symbols from the inline calculator source."""
+    return sort_symbols(GoSymbolExtractor().extract(CONTENT, "calc.go"))
+
+
+def tokens(symbols) -> int:
+    """Token estimate of the text rendering."""
+    return HeuristicTokenizer().count(TextFormatter().format(symbols))
+
+
+class LineTokenizer(Tokenizer):
+    """Stub tokenizer counting each line as one token."""
+
+    def count(self, text: str) -> int:
+        return len(text.splitlines())
+
+
+class TestTokenizers:
+    """Tests for the tokenizer interface and registry."""
+
+    def test_heuristic_four_chars_per_token(self):
+        """Length is divided by four, rounding up."""
+        tokenizer = HeuristicTokenizer()
+
+        assert tokenizer.count("") == 0
+        assert tokenizer.count("abcd") == 1
+        assert tokenizer.count("abcde") == 2
+
+    def test_heuristic_ratio(self):
+        """The characters-per-token ratio is configurable."""
+        assert HeuristicTokenizer(chars_per_token=2).count("abcde") == 3
+
+    def test_registry(self):
+        """Registered names resolve to tokenizer instances."""
+        assert isinstance(get_tokenizer("heuristic"), HeuristicTokenizer)
+        assert "tiktoken" in TOKENIZERS
+
+    def test_unknown_tokenizer(self):
+        """Unknown names raise ValueError."""
+        with pytest.raises(ValueError, match="Unsupported tokenizer"):
+            get_tokenizer("sentencepiece")
+
+
+class TestFitToBudget:
+    """Tests for budget trimming."""
+
+    def test_under_budget_unchanged(self, symbols):
+        """Output that already fits is returned as is."""
+        kept, omitted = fit_to_budget(symbols, 10_000, TextFormatter())
+
+        assert kept == symbols
+        assert omitted == 0
+
+    def test_result_fits(self, symbols):
+        """Trimmed output never exceeds the budget."""
+        for budget in range(1, tokens(symbols)):
+            kept, omitted = fit_to_budget(symbols, budget, TextFormatter())
+
+            assert tokens(kept) <= budget
+            assert omitted == len(symbols) - len(kept)
+
+    def test_exported_types_first(self, symbols):
+        """A tight budget keeps the exported type and its methods, without docs."""
+        bare_type = [replace(s, doc="", summary="") for s in symbols if s.kind != "func"]
+        kept, omitted = fit_to_budget(symbols, tokens(bare_type), TextFormatter())
+
+        assert [s.name for s in kept] == ["Calculator", "Add"]
+        assert all(s.doc == "" for s in kept)
+        assert omitted == 2
+
+    def test_docs_before_unexported(self, symbols):
+        """Docs of kept symbols are restored before unexported symbols are added."""
+        full_exported = [s for s in symbols if s.name != "helper"]
+        kept, omitted = fit_to_budget(symbols, tokens(full_exported), TextFormatter())
+
+        assert [s.name for s in kept] == ["Calculator", "Add", "New"]
+        assert kept[0].doc == "Calculator holds a running total"
+        assert omitted == 1
+
+    def test_custom_priority(self, symbols):
+        """Reordering tiers changes what survives."""
+        helper = [s for s in symbols if s.name == "helper"]
+        kept, _ = fit_to_budget(
+            symbols, tokens(helper), TextFormatter(),
+            priority=["unexported", "exported_types", "exported_functions", "docs"],
+        )
+
+        assert [s.name for s in kept] == ["helper"]
+
+    def test_missing_tier_excluded(self, symbols):
+        """Tiers left out of the priority are never included."""
+        kept, omitted = fit_to_budget(symbols, tokens(symbols) - 1, TextFormatter(), priority=["exported_functions"])
+
+        assert [s.name for s in kept] == ["New"]
+        assert kept[0].doc == ""
+        assert omitted == 3
+
+    def test_methods_on_unexported_types(self):
+        """Exported methods of unexported types count as unexported."""
+        content = "package p\n\ntype impl struct{}\n\nfunc (i impl) Run() {}\n\nfunc Go() {}\n"
+        symbols = sort_symbols(GoSymbolExtractor().extract(content, "p.go"))
+        go_only = [s for s in symbols if s.name == "Go"]
+
+        kept, _ = fit_to_budget(symbols, tokens(go_only), TextFormatter())
+
+        assert [s.name for s in kept] == ["Go"]
+
+    def test_unknown_priority(self, symbols):
+        """Unknown tier names are rejected."""
+        with pytest.raises(ValueError, match="Unknown budget priority"):
+            fit_to_budget(symbols, 1, TextFormatter(), priority=["exported_types", "everything"])
+
+    def test_input_not_mutated(self, symbols):
+        """Stripping docs copies symbols instead of editing them."""
+        fit_to_budget(symbols, 5, TextFormatter())
+
+        assert symbols[0].doc == "Calculator holds a running total"
+
+    def test_stub_tokenizer_changes_cutoff(self, symbols):
+        """Swapping the tokenizer changes where the budget cuts off."""
+        # 12 heuristic tokens only fit the bare type line; 12 lines fit everything
+        heuristic_kept, _ = fit_to_budget(symbols, 12, TextFormatter())
+        stub_kept, stub_omitted = fit_to_budget(symbols, 12, TextFormatter(), tokenizer=LineTokenizer())
+
+        assert len(heuristic_kept) < len(stub_kept)
+        assert stub_kept == symbols
+        assert stub_omitted == 0
+
+    def test_stub_tokenizer_tight_budget(self, symbols):
+        """The stub's budget is measured in its own units."""
+        kept, omitted = fit_to_budget(symbols, 4, TextFormatter(), tokenizer=LineTokenizer())
+
+        assert LineTokenizer().count(TextFormatter().format(kept)) <= 4
+        assert [s.name for s in kept] == ["Calculator", "Add", "New"]
+        assert omitted == 1
+
+    def test_default_priority(self):
+        """The default favors the exported API surface."""
+        assert DEFAULT_PRIORITY == ["exported_types", "exported_functions", "docs", "unexported"]
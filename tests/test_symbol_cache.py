@@ -0,0 +1,140 @@
+"""
+Unit tests for the on-disk symbol cache.
+
+Tests SymbolCache keys and entries, and cached extraction through
+GoSymbolExtractor and extract_dir.
+"""
+
+import shutil
+import pytest
+from pathlib import Path
+from ctxd.symbols import GoSymbolExtractor, Options, Symbol, extract_dir
+from ctxd.symbols.cache import SymbolCache, default_cache_dir
+
+FIXTURES = Path(__file__).parent / "fixtures"
+
+
+@pytest.fixture
+def cache(tmp_path):
+    """A cache rooted in a temporary directory."""
+    return SymbolCache(tmp_path / "cache")
+
+
+class TestSymbolCache:
+    """Tests for cache keys and entries."""
+
+    def test_default_dir_follows_xdg(self, monkeypatch, tmp_path):
+        """The default location honors XDG_CACHE_HOME."""
+        monkeypatch.setenv("XDG_CACHE_HOME", str(tmp_path))
+        assert default_cache_dir() == tmp_path / "ctxd" / "symbols"
+
+        monkeypatch.delenv("XDG_CACHE_HOME")
+        assert default_cache_dir() == Path.home() / ".cache" / "ctxd" / "symbols"
+
+    def test_key_covers_content_and_settings(self, cache):
+        """Keys change with contents and settings, and are stable otherwise."""
+        key = cache.key("package a", "calls=False")
+
+        assert key == cache.key("package a", "calls=False")
+        assert key != cache.key("package b", "calls=False")
+        assert key != cache.key("package a", "calls=True")
+
+    def test_key_covers_version(self, cache, monkeypatch):
+        """Upgrading ctxd invalidates every entry."""
+        import ctxd
+
+        key = cache.key("package a")
+        monkeypatch.setattr(ctxd, "__version__", "999.0.0")
+        assert cache.key("package a") != key
+
+    def test_round_trip(self, cache):
+        """Stored symbols load back equal, nested members included."""
+        field = Symbol(name="X", kind="field", file="a.go", line=3, signature="X int", type="int")
+        struct = Symbol(name="P", kind="struct", file="a.go", line=2, signature="type P struct", fields=[field])
+
+        cache.put("ab12", [struct])
+        assert cache.get("ab12", "a.go") == [struct]
+        assert (cache.hits, cache.misses) == (1, 0)
+
+    def test_loaded_symbols_take_new_path(self, cache):
+        """Identical contents cached from another path report the current one."""
+        field = Symbol(name="X", kind="field", file="old.go", line=3, signature="X int")
+        cache.put("ab12", [Symbol(name="P", kind="struct", file="old.go", line=2, signature="type P struct", fields=[field])])
+
+        loaded = cache.get("ab12", "new.go")
+        assert loaded[0].file == "new.go"
+        assert loaded[0].fields[0].file == "new.go"
+
+    def test_corrupt_entry_is_a_miss(self, cache):
+        """Unreadable entries are ignored rather than raised."""
+        cache.put("ab12", [])
+        (cache.directory / "ab" / "ab12.json").write_text("{not json")
+
+        assert cache.get("ab12", "a.go") is None
+        assert cache.get("cd34", "a.go") is None
+        assert cache.misses == 2
+
+    def test_clear(self, cache):
+        """Clearing removes all entries and reports how many."""
+        cache.put("ab12", [])
+        cache.put("cd34", [])
+
+        assert cache.clear() == 2
+        assert not cache.directory.exists()
+        assert cache.clear() == 0
+
+
+class TestCachedExtraction:
+    """Tests for extraction through the cache."""
+
+    def test_unchanged_file_is_not_reparsed(self, cache, monkeypatch):
+        """A second extraction of the same contents comes from the cache."""
+        extractor = GoSymbolExtractor(cache=cache)
+        first = extractor.extract_file(FIXTURES / "sample.go")
+
+        monkeypatch.setattr(extractor, "extract", lambda *args: pytest.fail("file was re-parsed"))
+        assert extractor.extract_file(FIXTURES / "sample.go") == first
+        assert cache.hits == 1
+
+    def test_changed_file_is_reparsed(self, cache, tmp_path):
+        """Editing a file produces a new entry."""
+        source = tmp_path / "a.go"
+        source.write_text("package a\n\nfunc One() {}\n")
+        extractor = GoSymbolExtractor(cache=cache)
+        extractor.extract_file(source)
+
+        source.write_text("package a\n\nfunc Two() {}\n")
+        assert [s.name for s in extractor.extract_file(source)] == ["Two"]
+        assert cache.misses == 2
+
+    def test_settings_are_not_shared(self, cache):
+        """Entries written with exported_only are not reused without it."""
+        exported = GoSymbolExtractor(exported_only=True, cache=cache).extract_file(FIXTURES / "sample.go")
+        everything = GoSymbolExtractor(cache=cache).extract_file(FIXTURES / "sample.go")
+
+        def calculator_fields(symbols):
+            return [f.name for s in symbols if s.name == "Calculator" for f in s.fields]
+
+        assert calculator_fields(exported) == []
+        assert calculator_fields(everything) == ["value", "name"]
+
+    def test_files_with_errors_are_not_cached(self, cache, tmp_path):
+        """Broken files are re-parsed, so their errors are reported every run."""
+        source = tmp_path / "a.go"
+        source.write_text("package a\n\nfunc B( {\n")
+
+        for _ in range(2):
+            extractor = GoSymbolExtractor(cache=cache)
+            extractor.extract_file(source)
+            assert extractor.errors
+        assert cache.hits == 0
+
+    def test_extract_dir_matches_uncached(self, tmp_path):
+        """Cold and warm cached runs give the same result as an uncached one."""
+        tree = tmp_path / "tree"
+        shutil.copytree(FIXTURES, tree)
+        options = Options(recursive=True, cache=True, cache_dir=tmp_path / "cache")
+
+        uncached = extract_dir(tree, Options(recursive=True)).symbols()
+        assert extract_dir(tree, options).symbols() == uncached
+        assert extract_dir(tree, options).symbols() == uncached
@@ -0,0 +1,197 @@
+"""
+Unit tests for narrowing symbols to lines changed since a git revision.
+
+Tests hunk header parsing, span overlap, filter_changed, and changed_since
+against throwaway repositories.
+"""
+
+import subprocess
+import pytest
+from ctxd.symbols import GoSymbolExtractor
+from ctxd.symbols.changes import ChangeSet, ChangesError, Hunk, changed_since, parse_diff
+from ctxd.symbols.filters import filter_changed
+
+CALC = """package calc
+
+// Add adds.
+func Add(a, b int) int {
+\treturn a + b
+}
+
+// Sub subtracts.
+func Sub(a, b int) int {
+\treturn a - b
+}
+
+type Calculator struct {
+\tvalue int
+}
+"""
+
+
+def git(repo, *args):
+    """Run git in a test repository."""
+    subprocess.run(["git", *args], cwd=repo, check=True, capture_output=True)
+
+
+@pytest.fixture
+def repo(tmp_path):
+    """A repository whose first commit has calc.go."""
+    git(tmp_path, "init")
+    git(tmp_path, "config", "user.email", "test@test.com")
+    git(tmp_path, "config", "user.name", "Test User")
+    (tmp_path / "calc.go").write_text(CALC)
+    git(tmp_path, "add", ".")
+    git(tmp_path, "commit", "-m", "Initial commit")
+    return tmp_path
+
+
+def names(symbols):
+    """Names of symbols, in order."""
+    return [s.name for s in symbols]
+
+
+class TestParseDiff:
+    """Tests for reading hunk headers."""
+
+    def test_hunks(self):
+        """The new-file side of each header is read; a missing count means one line."""
+        diff = (
+            "diff --git a/pkg/calc.go b/pkg/calc.go\n"
+            "--- a/pkg/calc.go\n"
+            "+++ b/pkg/calc.go\n"
+            "@@ -5 +5 @@ func Add(a, b int) int {\n"
+            "-\treturn a + b\n"
+            "+\treturn b + a\n"
+            "@@ -20,3 +20,0 @@ func Sub(a, b int) int {\n"
+            "diff --git a/new.go b/new.go\n"
+            "new file mode 100644\n"
+            "--- /dev/null\n"
+            "+++ b/new.go\n"
+            "@@ -0,0 +1,4 @@\n"
+        )
+
+        assert parse_diff(diff) == {
+            "pkg/calc.go": [Hunk(5, 1), Hunk(20, 0)],
+            "new.go": [Hunk(1, 4)],
+        }
+
+    def test_deleted_and_quoted_files(self):
+        """Deleted files are left out, and C-quoted names are unquoted."""
+        diff = (
+            "--- a/gone.go\n"
+            "+++ /dev/null\n"
+            "@@ -1,3 +0,0 @@\n"
+            '--- "a/tab\\there.go"\n'
+            '+++ "b/tab\\there.go"\n'
+            "@@ -2 +2 @@\n"
+        )
+
+        assert parse_diff(diff) == {"tab\there.go": [Hunk(2, 1)]}
+
+
+class TestHunk:
+    """Tests for span overlap."""
+
+    def test_overlaps(self):
+        """Changed lines overlap spans that share a line with them."""
+        hunk = Hunk(10, 3)
+
+        assert hunk.overlaps(12, 20)
+        assert hunk.overlaps(1, 10)
+        assert not hunk.overlaps(13, 20)
+        assert not hunk.overlaps(1, 9)
+
+    def test_deletion(self):
+        """Removed lines overlap only spans that enclose both of their neighbours."""
+        hunk = Hunk(10, 0)
+
+        assert hunk.overlaps(8, 12)
+        assert not hunk.overlaps(11, 12)
+        assert not hunk.overlaps(5, 10)
+
+
+class TestFilterChanged:
+    """Tests for filter_changed."""
+
+    def test_spans(self, tmp_path):
+        """Only declarations overlapping a hunk are kept."""
+        path = tmp_path / "calc.go"
+        path.write_text(CALC)
+        symbols = GoSymbolExtractor().extract_file(path)
+        changes = ChangeSet(hunks={path.resolve(): [Hunk(10, 1)]})
+
+        assert names(filter_changed(symbols, changes)) == ["Sub"]
+
+    def test_new_file(self, tmp_path):
+        """Every declaration of a new file is kept."""
+        path = tmp_path / "calc.go"
+        path.write_text(CALC)
+        symbols = GoSymbolExtractor().extract_file(path)
+
+        assert names(filter_changed(symbols, ChangeSet(new_files={path.resolve()}))) == ["Add", "Sub", "Calculator"]
+
+    def test_grouped_methods(self, tmp_path):
+        """A type is kept for a changed method, with only the changed methods."""
+        from ctxd.symbols import group_methods
+
+        path = tmp_path / "stack.go"
+        path.write_text(
+            "package stack\n\ntype Stack struct{}\n\n"
+            "func (s *Stack) Push() {}\n\nfunc (s *Stack) Pop() {}\n"
+        )
+        symbols = group_methods(GoSymbolExtractor().extract_file(path))
+        changes = ChangeSet(hunks={path.resolve(): [Hunk(7, 1)]})
+
+        [stack] = filter_changed(symbols, changes)
+        assert stack.name == "Stack"
+        assert names(stack.methods) == ["Pop"]
+
+
+class TestChangedSince:
+    """Tests for changed_since."""
+
+    def test_working_tree_changes(self, repo):
+        """Committed, unstaged, and untracked changes all count."""
+        (repo / "calc.go").write_text(CALC.replace("a - b", "b - a"))
+        (repo / "extra.go").write_text("package calc\n\nfunc Mul() {}\n")
+        path = repo / "calc.go"
+        symbols = GoSymbolExtractor().extract_file(path) + GoSymbolExtractor().extract_file(repo / "extra.go")
+
+        changes = changed_since(repo, "HEAD")
+
+        assert changes.hunks == {path.resolve(): [Hunk(10, 1)]}
+        assert names(filter_changed(symbols, changes)) == ["Sub", "Mul"]
+
+    @pytest.mark.parametrize("setting", ["diff.mnemonicPrefix", "diff.noprefix"])
+    def test_diff_prefix_config(self, repo, setting):
+        """Prefix settings in the user's git config do not change the paths."""
+        git(repo, "config", setting, "true")
+        (repo / "calc.go").write_text(CALC.replace("a - b", "b - a"))
+
+        changes = changed_since(repo, "HEAD")
+
+        assert changes.hunks == {(repo / "calc.go").resolve(): [Hunk(10, 1)]}
+
+    def test_committed_changes(self, repo):
+        """Commits after the revision count."""
+        (repo / "calc.go").write_text(CALC.replace("value int", "value int\n\tname  string"))
+        git(repo, "commit", "-am", "Add name")
+
+        changes = changed_since(repo / "calc.go", "HEAD~1")
+        symbols = GoSymbolExtractor().extract_file(repo / "calc.go")
+
+        assert names(filter_changed(symbols, changes)) == ["Calculator"]
+
+    def test_unknown_revision(self, repo):
+        """An unknown revision is an error."""
+        with pytest.raises(ChangesError, match="Unknown git revision: nope"):
+            changed_since(repo, "nope")
+
+    def test_not_a_repository(self, tmp_path, monkeypatch):
+        """A directory outside any repository is an error."""
+        # Keep git from finding a repository above tmp_path
+        monkeypatch.setenv("GIT_CEILING_DIRECTORIES", str(tmp_path.parent))
+
+        with pytest.raises(ChangesError, match="Not a git repository"):
+            changed_since(tmp_path, "HEAD")
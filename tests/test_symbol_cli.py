@@ -0,0 +1,74 @@
+"""
+Unit tests for the symbol commands of the CLI.
+
+Tests `ctxd symbols`, `ctxd find`, `ctxd diff`, and `ctxd verify-implements`
+end to end through click's test runner.
+"""
+
+from click.testing import CliRunner
+from ctxd.cli import main
+from conftest import write
+
+
+RUNNER_FILES = {
+    "go.mod": "module example.com/m\n\ngo 1.22\n",
+    "a/a.go": "package a\n\ntype Runner interface {\n\tRun(cb func(n int) error) error\n}\n",
+    "b/b.go": "package b\n\ntype Impl struct{}\n\nfunc (Impl) Run(cb func(n int) error) error { return nil }\n",
+}
+
+
+class TestVerifyImplements:
+    """Tests for `ctxd verify-implements`."""
+
+    def test_func_parameter_names(self, tmp_path):
+        """Named parameters of func-typed parameters do not make a mismatch."""
+        for rel_path, content in RUNNER_FILES.items():
+            write(tmp_path, rel_path, content)
+
+        result = CliRunner().invoke(main, [
+            "verify-implements", "example.com/m/b.Impl", "example.com/m/a.Runner", str(tmp_path), "--no-cache",
+        ])
+
+        assert result.exit_code == 0, result.output
+        assert "PASS: example.com/m/b.Impl implements example.com/m/a.Runner" in result.output
+
+
+class TestConfigDefaults:
+    """Tests for `.ctxd.yaml` settings that do not apply to a run."""
+
+    def test_max_tokens_with_unused(self, tmp_path):
+        """A configured budget does not conflict with --unused."""
+        write(tmp_path, "a.go", "package a\n\nfunc helper() {}\n\nfunc Run() {}\n")
+        config = tmp_path / ".ctxd.yaml"
+        config.write_text("max_tokens: 4000\n")
+
+        result = CliRunner().invoke(main, ["symbols", str(tmp_path), "--unused", "--config", str(config), "--no-cache"])
+
+        assert result.exit_code == 0, result.output
+        assert "func helper()" in result.output
+
+
+class TestPathMode:
+    """Tests for --path-mode outside `ctxd symbols`."""
+
+    def test_find(self, tmp_path):
+        """find shows files relative to PATH however it was spelled."""
+        write(tmp_path, "pkg/calc/calc.go", "package calc\n\nfunc Add(a, b int) int { return a + b }\n")
+
+        result = CliRunner().invoke(main, ["find", "add", str(tmp_path / "pkg" / ".." / "pkg"), "--no-cache"])
+        base = CliRunner().invoke(main, ["find", "add", str(tmp_path / "pkg"), "--path-mode", "base", "--no-cache"])
+
+        assert result.exit_code == 0, result.output
+        assert result.output == "calc/calc.go:3: func Add(a, b int) int\n"
+        assert base.output == "calc.go:3: func Add(a, b int) int\n"
+
+    def test_diff(self, tmp_path):
+        """diff shows removed symbols relative to OLD and the rest relative to NEW."""
+        write(tmp_path, "v1/calc/calc.go", "package calc\n\nfunc Add(a, b int) int { return a + b }\n")
+        write(tmp_path, "v2/calc/calc.go", "package calc\n\nfunc Sub(a, b int) int { return a - b }\n")
+
+        result = CliRunner().invoke(main, ["diff", str(tmp_path / "v1"), str(tmp_path / "v2"), "-r", "--no-cache"])
+
+        assert result.exit_code == 1
+        assert "- calc/calc.go:3: func Add(a, b int) int" in result.output
+        assert "+ calc/calc.go:3: func Sub(a, b int) int" in result.output
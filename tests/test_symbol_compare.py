@@ -0,0 +1,219 @@
+"""
+Unit tests for API comparison between package trees.
+
+Tests diff_packages and breaking_changes against two versions of a small
+module, and the formatters' rendering of per-package diffs.
+"""
+
+import json
+import pytest
+from ctxd.symbols import extract_packages, extract_source, get_formatter
+from ctxd.symbols.compare import breaking_changes, diff_packages
+from conftest import write
+
+OLD = """package calc
+
+// Calculator adds.
+type Calculator struct {
+	Value int
+	Name  string
+	hidden int
+}
+
+func (c *Calculator) Add(n int) {}
+
+func Multiply(x, y int) int { return x * y }
+
+func helper() {}
+
+type Adder interface {
+	Add(n int)
+}
+"""
+
+NEW = """package calc
+
+// Calculator adds numbers.
+type Calculator struct {
+	Value int64
+	hidden string
+}
+
+func (c *Calculator) Add(n int) int { return 0 }
+
+func Divide(x, y int) int { return x / y }
+
+type Adder interface {
+	Add(n int)
+	Reset()
+}
+"""
+
+
+OLD_DEPRECATED = """package calc
+
+// Sum adds.
+func Sum(a, b int) int { return a + b }
+
+// Deprecated: Use Sum.
+func legacy() {}
+
+type Point struct {
+	X int
+	Y int
+}
+"""
+
+NEW_DEPRECATED = """package calc
+
+// Sum adds.
+//
+// Deprecated: Use Add.
+func Sum(a, b int) int { return a + b }
+
+// Deprecated: Use Sum.
+func legacy() {}
+
+type Point struct {
+	X int
+	// Deprecated: Use X.
+	Y int
+}
+
+// Deprecated: Born deprecated.
+func Old() {}
+"""
+
+
+@pytest.fixture
+def trees(tmp_path):
+    """Package trees of two versions of a module, keyed "old" and "new"."""
+    for name, source in (("old", OLD), ("new", NEW)):
+        write(tmp_path / name, "go.mod", "module example.com/calc\n")
+        write(tmp_path / name, "calc.go", source)
+        write(tmp_path / name, "geo/point.go", "package geo\n\ntype Point struct{}\n")
+    return {name: extract_packages(tmp_path / name) for name in ("old", "new")}
+
+
+class TestDiffPackages:
+    """Tests for comparing two package trees."""
+
+    def test_identical(self, trees):
+        """A tree compared with itself has no changes."""
+        assert diff_packages(trees["old"], trees["old"]) == {}
+
+    def test_changes(self, trees):
+        """Members are compared one by one, and doc edits are ignored."""
+        diffs = diff_packages(trees["old"], trees["new"])
+        assert list(diffs) == ["example.com/calc"]
+        diff = diffs["example.com/calc"]
+
+        assert [s.local_name for s in diff.removed] == ["Calculator.Name", "Multiply", "helper"]
+        assert [s.local_name for s in diff.added] == ["Divide", "Adder.Reset"]
+        assert [(p.signature, s.signature) for s, p in zip(diff.modified, diff.previous)] == [
+            ("Value int", "Value int64"),
+            ("hidden int", "hidden string"),
+            ("func (c *Calculator) Add(n int)", "func (c *Calculator) Add(n int) int"),
+        ]
+
+    def test_added_and_removed_packages(self, trees):
+        """Packages present on one side only are all additions or removals."""
+        new = dict(trees["old"])
+        geo = new.pop("example.com/calc/geo")
+        diffs = diff_packages(trees["old"], new)
+
+        assert [s.name for s in diffs["example.com/calc/geo"].removed] == [s.name for s in geo]
+        assert diff_packages(new, trees["old"])["example.com/calc/geo"].added == geo
+
+    def test_breaking_changes(self, trees):
+        """Only removals and signature changes of exported symbols break importers."""
+        diff = diff_packages(trees["old"], trees["new"])["example.com/calc"]
+
+        assert [s.local_name for s in breaking_changes(diff)] == [
+            "Calculator.Name", "Multiply", "Calculator.Value", "Calculator.Add",
+        ]
+
+    def test_additions_are_not_breaking(self, trees):
+        """Adding symbols, including interface methods, is not reported as breaking."""
+        calc = trees["old"]["example.com/calc"]
+        without_adder = {"example.com/calc": [s for s in calc if s.name != "Adder"]}
+        diff = diff_packages(without_adder, {"example.com/calc": calc})["example.com/calc"]
+
+        assert [s.local_name for s in diff.added] == ["Adder", "Adder.Add"]
+        assert breaking_changes(diff) == []
+
+    def test_respelled_types(self):
+        """Signatures that only spell the same types differently are not modified."""
+        old = extract_source(
+            "package calc\n\nimport \"io\"\n\ntype Source = io.Reader\n\n"
+            "type Box struct {\n\tData interface{}\n}\n\nfunc Load(r io.Reader, b []byte) interface{} { return nil }\n",
+            "calc.go",
+        )
+        new = extract_source(
+            "package calc\n\nimport \"io\"\n\ntype Source = io.Reader\n\n"
+            "type Box struct {\n\tData any\n}\n\nfunc Load(r Source, b []uint8) any { return nil }\n\nfunc Size(n int) {}\n",
+            "calc.go",
+        )
+        diff = diff_packages({"example.com/calc": old}, {"example.com/calc": new})["example.com/calc"]
+
+        assert diff.modified == []
+        assert [s.name for s in diff.added] == ["Size"]
+
+
+class TestNewlyDeprecated:
+    """Tests for reporting symbols that became deprecated."""
+
+    @pytest.fixture
+    def diff(self):
+        """Changes between the two versions of the deprecation example."""
+        old, new = (
+            {"example.com/calc": extract_source(source, "calc.go")}
+            for source in (OLD_DEPRECATED, NEW_DEPRECATED)
+        )
+        return diff_packages(old, new)["example.com/calc"]
+
+    def test_deprecated_between_versions(self, diff):
+        """Exported symbols and members that gain a Deprecated: paragraph are listed."""
+        assert [s.local_name for s in diff.deprecated] == ["Point.Y", "Sum"]
+        assert diff.deprecated[1].deprecation_note == "Use Add."
+        # Born deprecated is an addition, and deprecation is not breaking
+        assert [s.name for s in diff.added] == ["Old"]
+        assert diff.modified == [] and breaking_changes(diff) == []
+
+    def test_text(self, diff):
+        """Text output marks deprecations with ! and their note."""
+        lines = get_formatter("text").format_package_diffs({"example.com/calc": diff}).splitlines()
+
+        assert lines[-2:] == ["! calc.go:6: func Sum(a, b int) int", "    deprecated: Use Add."]
+
+
+class TestFormatPackageDiffs:
+    """Tests for rendering per-package diffs."""
+
+    def test_text(self, trees):
+        """Text output groups changes under package headers with old signatures."""
+        output = get_formatter("text").format_package_diffs(diff_packages(trees["old"], trees["new"]))
+        lines = output.splitlines()
+
+        assert lines[0] == "package example.com/calc"
+        assert lines[1].startswith("- ") and lines[1].endswith("calc.go:6: Name string")
+        assert "    was: func (c *Calculator) Add(n int)" in lines
+
+    def test_json(self, trees):
+        """JSON output maps import paths to change arrays."""
+        data = json.loads(get_formatter("json").format_package_diffs(diff_packages(trees["old"], trees["new"])))
+
+        assert set(data["example.com/calc"]) == {"added", "removed", "modified", "previous", "deprecated"}
+        assert data["example.com/calc"]["previous"][2]["signature"] == "func (c *Calculator) Add(n int)"
+
+    def test_markdown(self, trees):
+        """Markdown output is a diff block per package."""
+        output = get_formatter("markdown").format_package_diffs(diff_packages(trees["old"], trees["new"]))
+
+        assert output.startswith("## package example.com/calc\n\n```diff\n- Name string\n")
+        assert "- func (c *Calculator) Add(n int)\n+ func (c *Calculator) Add(n int) int" in output
+
+    def test_empty(self):
+        """No changes render as nothing, or an empty JSON object."""
+        assert get_formatter("text").format_package_diffs({}) == ""
+        assert get_formatter("json").format_package_diffs({}) == "{}"
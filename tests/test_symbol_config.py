@@ -0,0 +1,152 @@
+"""
+Unit tests for `.ctxd.yaml` config files.
+
+Tests config lookup and loading of Options with validation of every setting.
+"""
+
+import pytest
+from ctxd.symbols import ConfigError, Options, find_config, load_options
+
+
+def write_config(directory, text):
+    """Write a config file into a directory and return its path."""
+    path = directory / ".ctxd.yaml"
+    path.write_text(text)
+    return path
+
+
+class TestFindConfig:
+    """Tests for config file lookup."""
+
+    def test_walks_up(self, tmp_path):
+        """The nearest file in a parent directory is found."""
+        path = write_config(tmp_path, "recursive: true\n")
+        nested = tmp_path / "pkg" / "sub"
+        nested.mkdir(parents=True)
+
+        assert find_config(nested) == path.resolve()
+
+    def test_nearest_wins(self, tmp_path):
+        """A file closer to the start directory shadows one further up."""
+        write_config(tmp_path, "recursive: true\n")
+        nested = tmp_path / "pkg"
+        nested.mkdir()
+        path = write_config(nested, "strict: true\n")
+
+        assert find_config(nested) == path.resolve()
+
+    def test_missing(self, tmp_path, monkeypatch):
+        """None is returned when no directory has a file."""
+        monkeypatch.setattr("ctxd.symbols.config.CONFIG_FILENAME", ".ctxd-test-missing.yaml")
+        assert find_config(tmp_path) is None
+
+
+class TestLoadOptions:
+    """Tests for loading Options from a file."""
+
+    def test_no_file_gives_defaults(self, tmp_path, monkeypatch):
+        """Without a file every option keeps its default."""
+        monkeypatch.setattr("ctxd.symbols.config.CONFIG_FILENAME", ".ctxd-test-missing.yaml")
+        assert load_options(start=tmp_path) == Options()
+
+    def test_settings(self, tmp_path):
+        """Every supported key is loaded into Options."""
+        write_config(tmp_path, (
+            "format: markdown\n"
+            "exported_only: true\n"
+            "recursive: true\n"
+            "depth: 2\n"
+            "include_tests: true\n"
+            "group_methods: true\n"
+            "strict: true\n"
+            "include: ['pkg/**']\n"
+            "exclude: ['**/*_gen.go']\n"
+            "kinds: [func, interface]\n"
+            "name: ^New\n"
+            "name_exclude: Mock\n"
+            "max_tokens: 4000\n"
+            "markers: [TODO, NOTE]\n"
+            "metrics: true\n"
+            "complexity_threshold: 15\n"
+            "include_source: true\n"
+            "goos: windows\n"
+            "goarch: arm64\n"
+            "jobs: 4\n"
+        ))
+
+        options = load_options(start=tmp_path)
+
+        assert options == Options(
+            format="markdown",
+            exported_only=True,
+            recursive=True,
+            depth=2,
+            include_tests=True,
+            group_methods=True,
+            strict=True,
+            include=["pkg/**"],
+            exclude=["**/*_gen.go"],
+            kinds=["func", "interface"],
+            name="^New",
+            name_exclude="Mock",
+            max_tokens=4000,
+            markers=["TODO", "NOTE"],
+            metrics=True,
+            complexity_threshold=15,
+            include_source=True,
+            goos="windows",
+            goarch="arm64",
+            jobs=4,
+        )
+
+    def test_explicit_path(self, tmp_path):
+        """An explicit path is read regardless of its name."""
+        path = tmp_path / "symbols.yaml"
+        path.write_text("exported_only: true\n")
+
+        assert load_options(path).exported_only is True
+
+    def test_empty_file(self, tmp_path):
+        """An empty file gives the defaults."""
+        write_config(tmp_path, "")
+        assert load_options(start=tmp_path) == Options()
+
+    def test_kinds_string(self, tmp_path):
+        """Kinds may be written comma-separated, like --kind."""
+        write_config(tmp_path, "kinds: func, method\n")
+        assert load_options(start=tmp_path).kinds == ["func", "method"]
+
+    def test_missing_explicit_path(self, tmp_path):
+        """A missing explicit path is an error."""
+        with pytest.raises(ConfigError, match="Cannot read config file"):
+            load_options(tmp_path / "absent.yaml")
+
+    @pytest.mark.parametrize("text, message", [
+        ("format: [markdown\n", "Invalid YAML"),
+        ("- recursive\n", "expected a mapping"),
+        ("recursve: true\n", "Unknown setting 'recursve'"),
+        ("calls: true\n", "Unknown setting 'calls'"),
+        ("cache: false\n", "Unknown setting 'cache'"),
+        ("recursive: yes please\n", "Invalid recursive"),
+        ("exclude: vendor/**\n", "Invalid exclude"),
+        ("kinds: [func, widget]\n", "Invalid kinds.*widget"),
+        ("kinds: []\n", "expected at least one kind"),
+        ("format: html\n", "Invalid format.*'html'"),
+        ("max_tokens: 0\n", "Invalid max_tokens"),
+        ("max_tokens: true\n", "Invalid max_tokens"),
+        ("markers: [TODO, NOT A WORD]\n", "Invalid markers"),
+        ("complexity_threshold: 0\n", "Invalid complexity_threshold"),
+        ("goos: plan10\n", "Invalid goos.*'plan10'"),
+        ("goarch: 64\n", "Invalid goarch"),
+        ("jobs: 0\n", "Invalid jobs"),
+        ("depth: -1\n", "Invalid depth"),
+        ("name: '(New'\n", "Invalid name.*pattern"),
+        ("name_exclude: 3\n", "Invalid name_exclude"),
+    ])
+    def test_invalid(self, tmp_path, text, message):
+        """Invalid files and settings raise ConfigError naming the problem."""
+        path = write_config(tmp_path, text)
+
+        with pytest.raises(ConfigError, match=message) as excinfo:
+            load_options(path)
+        assert str(path) in str(excinfo.value)
@@ -0,0 +1,172 @@
+"""
+Unit tests for Go build constraints.
+
+Tests file name suffixes, //go:build and // +build lines, the default build
+context, and platform selection when walking the fixtures/platform tree.
+"""
+
+import pytest
+from pathlib import Path
+from ctxd.symbols import MapFS, Options, extract_dir, find_go_files
+from ctxd.symbols.constraints import BuildContext, ConstraintError, parse_expr
+
+FIXTURES = Path(__file__).parent / "fixtures"
+
+LINUX = BuildContext("linux", "amd64", cgo=True)
+WINDOWS = BuildContext("windows", "amd64")
+DARWIN = BuildContext("darwin", "arm64")
+
+
+def symbols_for(context: BuildContext) -> list[tuple[str, str]]:
+    """Names and files of the platform fixture's symbols for a target."""
+    index = extract_dir(FIXTURES / "platform", Options(goos=context.goos, goarch=context.goarch))
+    return [(s.name, Path(s.file).name) for s in index.symbols()]
+
+
+class TestMatchesTag:
+    """Tests for BuildContext.matches_tag."""
+
+    def test_platform_tags(self):
+        """GOOS, GOARCH, unix, gc, and release tags are satisfied."""
+        for tag in ("linux", "amd64", "unix", "gc", "go1.21", "cgo"):
+            assert LINUX.matches_tag(tag), tag
+        for tag in ("windows", "arm64", "ignore", "integration"):
+            assert not LINUX.matches_tag(tag), tag
+
+    def test_implied_os(self):
+        """android satisfies linux, and windows is not unix."""
+        assert BuildContext("android", "arm64").matches_tag("linux")
+        assert not WINDOWS.matches_tag("unix")
+        assert not WINDOWS.matches_tag("cgo")
+
+
+class TestMatchesFilename:
+    """Tests for GOOS and GOARCH file name suffixes."""
+
+    @pytest.mark.parametrize("name,expected", [
+        ("file_linux.go", True),
+        ("file_windows.go", False),
+        ("file_amd64.go", True),
+        ("file_arm64.go", False),
+        ("file_linux_amd64.go", True),
+        ("file_linux_arm64.go", False),
+        ("file_windows_test.go", False),
+        ("file_linux_test.go", True),
+        ("linux.go", True),
+        ("windows.go", True),
+        ("file_unix.go", True),
+        ("file_windows_foo.go", True),
+    ])
+    def test_suffixes(self, name, expected):
+        """Only the last one or two parts after the first underscore count."""
+        assert LINUX.matches_filename(name) == expected
+
+
+class TestMatchesSource:
+    """Tests for constraint lines in file headers."""
+
+    @pytest.mark.parametrize("line,expected", [
+        ("//go:build linux", True),
+        ("//go:build !linux", False),
+        ("//go:build linux && (arm64 || amd64)", True),
+        ("//go:build windows || (linux && !cgo)", False),
+        ("//go:build ignore", False),
+        ("//go:build !windows && go1.18", True),
+        ("// +build linux darwin", True),
+        ("// +build windows,amd64 !linux", False),
+        ("// +build !windows", True),
+    ])
+    def test_expressions(self, line, expected):
+        """Both constraint syntaxes are evaluated."""
+        assert LINUX.matches_source(f"{line}\n\npackage p\n") == expected
+
+    def test_go_build_wins(self):
+        """// +build lines are ignored when a //go:build line is present."""
+        assert LINUX.matches_source("//go:build linux\n// +build windows\n\npackage p\n")
+
+    def test_comments_only_in_header(self):
+        """Constraints after the first code line are ignored."""
+        assert LINUX.matches_source("package p\n\n//go:build windows\n")
+        assert LINUX.matches_source("/* header */\nvar x = 1\n//go:build windows\n")
+
+    def test_plus_build_in_doc_comment(self):
+        """// +build lines must be followed by a blank line to count."""
+        assert LINUX.matches_source("// Package p.\n// +build windows\npackage p\n")
+        assert not LINUX.matches_source("/* Copyright */\n\n// +build windows\n\npackage p\n")
+
+    def test_invalid_constraint_kept(self):
+        """Files with an unparsable constraint are kept."""
+        assert LINUX.matches_source("//go:build linux &&\n\npackage p\n")
+
+
+class TestParseExpr:
+    """Tests for parse_expr."""
+
+    def test_precedence(self):
+        """&& binds tighter than ||, and ! tighter than both."""
+        expr = parse_expr("a || b && !c")
+
+        assert expr(lambda tag: tag == "a")
+        assert expr(lambda tag: tag == "b")
+        assert not expr(lambda tag: tag in ("b", "c"))
+
+    @pytest.mark.parametrize("text", ["", "linux &&", "(linux", "linux)", "linux windows", "a & b", "!"])
+    def test_malformed(self, text):
+        """Malformed expressions are errors."""
+        with pytest.raises(ConstraintError):
+            parse_expr(text)
+
+
+class TestDefault:
+    """Tests for BuildContext.default."""
+
+    def test_environment(self, monkeypatch):
+        """$GOOS, $GOARCH, and $CGO_ENABLED are honored, and arguments win."""
+        monkeypatch.setenv("GOOS", "plan9")
+        monkeypatch.setenv("GOARCH", "386")
+        monkeypatch.setenv("CGO_ENABLED", "1")
+
+        assert BuildContext.default() == BuildContext("plan9", "386", cgo=True)
+        assert BuildContext.default(goos="windows") == BuildContext("windows", "386", cgo=True)
+
+    def test_unknown(self, monkeypatch):
+        """Unknown values are errors."""
+        monkeypatch.delenv("GOOS", raising=False)
+        with pytest.raises(ValueError, match="Unknown GOOS"):
+            BuildContext.default(goos="plan10")
+        with pytest.raises(ValueError, match="Unknown GOARCH"):
+            BuildContext.default(goos="linux", goarch="z80")
+
+
+class TestPlatformSelection:
+    """Tests for walking a tree with files for several platforms."""
+
+    def test_linux(self):
+        """Only the linux variant of Open is extracted, with unix-only files."""
+        assert symbols_for(LINUX) == [
+            ("name", "open_linux.go"),
+            ("Open", "open_linux.go"),
+            ("Pipe", "pipe.go"),
+            ("Name", "platform.go"),
+        ]
+
+    def test_windows(self):
+        """The windows variant replaces the linux one, and unix files drop out."""
+        assert symbols_for(WINDOWS) == [
+            ("name", "open_windows.go"),
+            ("Open", "open_windows.go"),
+            ("Name", "platform.go"),
+        ]
+
+    def test_os_and_arch(self):
+        """A GOOS_GOARCH suffix needs both to match, and +build options are ORed."""
+        assert [f for _, f in symbols_for(DARWIN)] == ["open_darwin_arm64.go", "open_darwin_arm64.go", "pipe.go", "platform.go"]
+        assert [f for _, f in symbols_for(BuildContext("windows", "386"))] == [
+            "legacy.go", "open_windows.go", "open_windows.go", "platform.go",
+        ]
+
+    def test_find_go_files_context(self):
+        """find_go_files takes the context directly."""
+        tree = MapFS({"a_windows.go": "package a\n", "a_linux.go": "package a\n"})
+
+        assert [f.name for f in find_go_files(tree, context=WINDOWS)] == ["a_windows.go"]
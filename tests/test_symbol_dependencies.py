@@ -0,0 +1,137 @@
+"""
+Unit tests for gathering context slices.
+
+Tests reference collection, resolution within and across packages, depth
+limits, and dependency ordering.
+"""
+
+import pytest
+from ctxd.symbols import GoSymbolExtractor, gather_context
+from ctxd.symbols.dependencies import referenced_names
+
+SOURCE = """package calc
+
+import "fmt"
+
+type Value int
+
+type Calculator struct {
+	value Value
+	log   *Log
+}
+
+type Log struct {
+	entries []string
+}
+
+type Stack[T any] struct {
+	items []T
+}
+
+func NewCalculator(initial Value) *Calculator {
+	return &Calculator{value: initial}
+}
+
+func (c *Calculator) Add(n Value) {
+	c.record("add")
+	fmt.Println(n)
+}
+
+func (c *Calculator) record(entry string) {
+	c.log = new(Log)
+}
+
+func Reset() *Calculator {
+	calc := NewCalculator(0)
+	return calc
+}
+
+func Push[T any](s *Stack[T], item T) {}
+"""
+
+
+@pytest.fixture
+def symbols():
+    """Extract SOURCE with calls and source text."""
+    return GoSymbolExtractor(calls=True, include_source=True).extract(SOURCE, "calc.go")
+
+
+def find(symbols, name):
+    """Get the symbol with a local name."""
+    return next(s for s in symbols if s.local_name == name)
+
+
+def context_names(symbols, name, depth=1):
+    """Gather the context of a symbol as local names."""
+    return [s.local_name for s in gather_context(symbols, find(symbols, name), depth)]
+
+
+class TestReferencedNames:
+    """Tests for the names a declaration uses."""
+
+    def test_signature_and_body(self, symbols):
+        """Parameter, result, and constructed types come before callees."""
+        assert referenced_names(find(symbols, "Calculator.Add")) == ["Calculator", "Value", "Calculator.record", "fmt.Println"]
+
+    def test_new(self, symbols):
+        """The type given to new() counts."""
+        assert "Log" in referenced_names(find(symbols, "Calculator.record"))
+
+    def test_own_name_and_type_parameters(self, symbols):
+        """A type's own name, type parameters, and predeclared types are left out."""
+        assert referenced_names(find(symbols, "Stack")) == []
+        assert referenced_names(find(symbols, "Push")) == ["Stack"]
+
+
+class TestGatherContext:
+    """Tests for gather_context."""
+
+    def test_constructor(self, symbols):
+        """A constructor pulls in the struct it returns, before itself."""
+        assert context_names(symbols, "NewCalculator") == ["Value", "Calculator", "NewCalculator"]
+
+    def test_depth_zero(self, symbols):
+        """Depth 0 gathers just the symbol."""
+        assert context_names(symbols, "Reset", depth=0) == ["Reset"]
+
+    def test_depth(self, symbols):
+        """Each level follows the references of the one before."""
+        assert context_names(symbols, "Reset") == ["Calculator", "NewCalculator", "Reset"]
+        assert context_names(symbols, "Reset", depth=2) == ["Value", "Log", "Calculator", "NewCalculator", "Reset"]
+
+    def test_receiver_calls(self, symbols):
+        """Calls through the receiver resolve to methods; unknown packages are skipped."""
+        assert context_names(symbols, "Calculator.Add") == ["Value", "Calculator", "Calculator.record", "Calculator.Add"]
+
+    def test_cycles(self, symbols):
+        """Mutual references are each listed once."""
+        cycle = GoSymbolExtractor(calls=True, include_source=True).extract(
+            "package p\n\ntype A struct{ b *B }\n\ntype B struct{ a *A }\n", "p.go",
+        )
+
+        assert context_names(cycle, "A", depth=5) == ["B", "A"]
+
+    def test_qualified_names(self):
+        """Qualified names resolve through the imports of the declaration."""
+        extractor = GoSymbolExtractor(calls=True, include_source=True)
+        geo = extractor.extract("package geo\n\ntype Point struct{}\n\nfunc Distance(a, b Point) int { return 0 }\n", "geo/geo.go")
+        shapes = extractor.extract(
+            'package shapes\n\nimport "example.com/app/geo"\n\n'
+            "func Span(p geo.Point) int { return geo.Distance(p, p) }\n",
+            "shapes.go",
+        )
+        for symbol in geo:
+            symbol.package = "example.com/app/geo"
+        for symbol in shapes:
+            symbol.package = "example.com/app"
+
+        context = gather_context(geo + shapes, shapes[0], depth=2)
+
+        assert [s.qualified_name for s in context] == [
+            "example.com/app/geo.Point", "example.com/app/geo.Distance", "example.com/app.Span",
+        ]
+
+    def test_invalid_depth(self, symbols):
+        """Negative depths are rejected."""
+        with pytest.raises(ValueError, match="Invalid depth"):
+            gather_context(symbols, symbols[0], depth=-1)
@@ -0,0 +1,235 @@
+"""
+Unit tests for symbol filters.
+
+Tests filter_exported and the extractor's exported_only option, filter_kinds,
+and NameFilter.
+"""
+
+import pytest
+from pathlib import Path
+from ctxd.symbols import GoSymbolExtractor
+from ctxd.symbols.filters import NameFilter, filter_exported, filter_kinds, validate_kinds
+
+FIXTURES = Path(__file__).parent / "fixtures"
+
+
+@pytest.fixture
+def sample_symbols():
+    """Symbols extracted from the sample Go fixture."""
+    return GoSymbolExtractor().extract_file(FIXTURES / "sample.go")
+
+
+class TestFilterExported:
+    """Tests for exported-only filtering."""
+
+    def test_sample_public_surface(self, sample_symbols):
+        """Exported functions, types, methods, and interfaces are kept."""
+        names = {s.name for s in filter_exported(sample_symbols)}
+
+        assert {"Add", "Multiply", "Calculator", "GetValue", "Adder", "MathOperator"} <= names
+
+    def test_unexported_fields_dropped(self, sample_symbols):
+        """Unexported struct fields are removed recursively."""
+        symbols = {s.name: s for s in filter_exported(sample_symbols)}
+
+        assert symbols["Calculator"].fields == []
+        assert [f.name for f in symbols["Point"].fields] == ["X", "Y"]
+
+    def test_input_not_mutated(self, sample_symbols):
+        """Filtering returns copies rather than editing the input."""
+        filter_exported(sample_symbols)
+        calculator = next(s for s in sample_symbols if s.name == "Calculator")
+
+        assert [f.name for f in calculator.fields] == ["value", "name"]
+
+    def test_unexported_interface_methods_dropped(self):
+        """Lowercase interface methods are removed from the method set."""
+        symbols = {s.name: s for s in filter_exported(
+            GoSymbolExtractor().extract_file(FIXTURES / "interfaces.go")
+        )}
+
+        assert [m.name for m in symbols["Flusher"].methods] == ["Flush"]
+
+    def test_unexported_example_subject(self):
+        """Examples keep their subject only when it is exported."""
+        content = (
+            "package calc\n\n"
+            "type Calculator struct{}\n\n"
+            "type cache struct{}\n\n"
+            "func (c *cache) Get() {}\n\n"
+            "func ExampleCalculator() {}\n\n"
+            "func Examplecache_Get() {}\n\n"
+            "func Example_cache() {}\n"
+        )
+        symbols = GoSymbolExtractor().extract(content, "calc_test.go")
+        # Not an example: a lowercase letter follows the prefix
+        assert {s.name: s.kind for s in symbols}["Examplecache_Get"] == "func"
+
+        subjects = {s.name: s.subject for s in filter_exported(symbols) if s.kind == "example"}
+
+        assert subjects == {"ExampleCalculator": "Calculator", "Example_cache": ""}
+
+    def test_unexported_declarations_dropped(self):
+        """Lowercase functions, types, and methods are removed."""
+        content = """package main
+
+func helper() {}
+
+type cache struct{}
+
+func (c *Counter) reset() {}
+
+type Counter struct{}
+"""
+        symbols = filter_exported(GoSymbolExtractor().extract(content, "test.go"))
+
+        assert [s.name for s in symbols] == ["Counter"]
+
+    def test_methods_on_unexported_type_dropped(self):
+        """Capitalized methods on unexported types are not public API."""
+        content = """package main
+
+type cache struct{}
+
+func (c *cache) Get(key string) string { return "" }
+
+func (c cache) Len() int { return 0 }
+"""
+        symbols = filter_exported(GoSymbolExtractor().extract(content, "test.go"))
+
+        assert symbols == []
+
+    def test_methods_on_generic_exported_type_kept(self):
+        """Receivers with type arguments resolve to their base type name."""
+        content = """package main
+
+type Stack[T any] struct{}
+
+func (s *Stack[T]) Push(item T) {}
+"""
+        symbols = filter_exported(GoSymbolExtractor().extract(content, "test.go"))
+
+        assert [s.name for s in symbols] == ["Stack", "Push"]
+
+    def test_extractor_option(self):
+        """exported_only on the extractor applies the same filter."""
+        symbols = GoSymbolExtractor(exported_only=True).extract_file(FIXTURES / "sample.go")
+        calculator = next(s for s in symbols if s.name == "Calculator")
+
+        assert all(s.exported for s in symbols)
+        assert calculator.fields == []
+
+
+    def test_unexported_interfaces_dropped_from_implements(self):
+        """Exported types do not advertise unexported interfaces."""
+        content = """package main
+
+type closer interface { Close() error }
+
+type Closer interface { Close() error }
+
+type File struct{}
+
+func (f File) Close() error { return nil }
+"""
+        symbols = GoSymbolExtractor(exported_only=True).extract(content, "test.go")
+        file = next(s for s in symbols if s.name == "File")
+
+        assert file.implements == ["Closer"]
+
+
+class TestFilterKinds:
+    """Tests for filtering by symbol kind."""
+
+    def test_interfaces_only(self, sample_symbols):
+        """Only interfaces of the sample are kept."""
+        names = [s.name for s in filter_kinds(sample_symbols, ["interface"])]
+
+        assert names == ["Adder", "Multiplier", "MathOperator"]
+
+    def test_multiple_kinds(self, sample_symbols):
+        """Several kinds can be selected at once."""
+        kinds = {s.kind for s in filter_kinds(sample_symbols, ["func", "method"])}
+
+        assert kinds == {"func", "method"}
+
+    def test_structs_keep_fields(self, sample_symbols):
+        """Fields stay nested in their struct when structs are selected."""
+        result = filter_kinds(sample_symbols, ["struct", "field"])
+
+        assert [s.name for s in result] == ["Calculator", "Point"]
+        assert [f.name for f in result[1].fields] == ["X", "Y"]
+
+    def test_fields_lifted(self, sample_symbols):
+        """Fields alone are listed with their struct as receiver."""
+        result = filter_kinds(sample_symbols, ["field"])
+
+        assert [s.local_name for s in result] == ["Calculator.value", "Calculator.name", "Point.X", "Point.Y"]
+        assert all(s.kind == "field" for s in result)
+
+    def test_composes_with_exported(self, sample_symbols):
+        """Kind filtering applies on top of exported-only filtering."""
+        result = filter_kinds(filter_exported(sample_symbols), ["field"])
+
+        assert [s.local_name for s in result] == ["Point.X", "Point.Y"]
+
+    def test_does_not_mutate_input(self, sample_symbols):
+        """The input and its fields are left untouched."""
+        filter_kinds(sample_symbols, ["field"])
+        point = next(s for s in sample_symbols if s.name == "Point")
+
+        assert point.fields[0].receiver == ""
+
+    def test_unknown_kind_rejected(self, sample_symbols):
+        """Unknown kind names raise with the list of valid ones."""
+        with pytest.raises(ValueError, match="Unknown symbol kind: funct, iface"):
+            filter_kinds(sample_symbols, ["funct", "interface", "iface"])
+        with pytest.raises(ValueError, match="expected one of: func, method"):
+            validate_kinds(["functions"])
+
+    def test_const_and_var(self):
+        """Constants and variables are kinds of their own."""
+        symbols = GoSymbolExtractor().extract_file(FIXTURES / "values.go")
+
+        assert {s.kind for s in filter_kinds(symbols, ["const"])} == {"const"}
+        assert "Count" in {s.name for s in filter_kinds(symbols, ["var"])}
+
+
+class TestNameFilter:
+    """Tests for name pattern filtering."""
+
+    def test_prefix(self, sample_symbols):
+        """Anchored patterns only match where they say."""
+        assert [s.name for s in NameFilter("^New").filter(sample_symbols)] == ["NewCalculator"]
+
+    def test_not_anchored(self, sample_symbols):
+        """Unanchored patterns match anywhere in the name, methods by their own name."""
+        names = {s.name for s in NameFilter("Value").filter(sample_symbols)}
+
+        assert "GetValue" in names and "initialValue" not in names
+
+    def test_exclude_wins(self, sample_symbols):
+        """Names matching the exclude pattern are dropped even when included."""
+        names = NameFilter("Add", exclude="^Add$").filter(sample_symbols)
+
+        assert [s.name for s in names] == ["Adder"]
+
+    def test_composes_with_kinds(self, sample_symbols):
+        """Name filtering narrows other filters' results."""
+        symbols = NameFilter("^Ma").filter(filter_kinds(sample_symbols, ["interface"]))
+
+        assert [s.name for s in symbols] == ["MathOperator"]
+
+    def test_empty(self, sample_symbols):
+        """A filter without patterns keeps everything and is falsy."""
+        names = NameFilter()
+
+        assert not names
+        assert names.filter(sample_symbols) == sample_symbols
+
+    def test_invalid(self):
+        """Invalid patterns are rejected when the filter is built."""
+        with pytest.raises(ValueError, match=r"Invalid name pattern '\(New'"):
+            NameFilter("(New")
+        with pytest.raises(ValueError, match="Invalid name exclude pattern"):
+            NameFilter(exclude="[")
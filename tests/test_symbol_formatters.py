@@ -0,0 +1,627 @@
+"""
+Unit tests for symbol output formatters.
+
+Tests TextFormatter, JsonFormatter, JsonlFormatter, MarkdownFormatter,
+LspFormatter, DotFormatter, package summary rendering, and the formatter registry.
+"""
+
+import json
+import shutil
+import subprocess
+import pytest
+from dataclasses import replace
+from pathlib import Path
+from ctxd.symbols import GoSymbolExtractor, MapFS, Options, PackageSummary, extract_fs, Symbol, SymbolDiff, TextFormatter, JsonFormatter, JsonlFormatter, get_formatter
+from ctxd.symbols.formatters import SymbolFormatter, MarkdownFormatter, LspFormatter, DotFormatter, FORMATTERS
+
+FIXTURES = Path(__file__).parent / "fixtures"
+
+
+@pytest.fixture
+def sample_symbols():
+    """Symbols extracted from the sample Go fixture."""
+    return GoSymbolExtractor().extract_file(FIXTURES / "sample.go")
+
+
+class TestTextFormatter:
+    """Tests for the default human-readable format."""
+
+    def test_signature_lines(self, sample_symbols):
+        """Each symbol renders as file:line: signature."""
+        output = TextFormatter().format(sample_symbols)
+        lines = output.splitlines()
+
+        assert lines[0].endswith("sample.go:6: func Add(a, b int) int")
+        assert lines[1] == "    // Add adds two integers and returns the result"
+
+    def test_interface_method_set(self, sample_symbols):
+        """Interfaces list their embeds and promoted methods with their origin."""
+        operator = next(s for s in sample_symbols if s.name == "MathOperator")
+        lines = TextFormatter().format([operator]).splitlines()
+
+        assert lines[2:] == [
+            "    Adder",
+            "    Multiplier",
+            "    Add(a, b int) int  // from Adder",
+            "    Multiply(x, y int) int  // from Multiplier",
+        ]
+
+    def test_package_headers(self):
+        """Symbols from a directory walk are grouped under package headers."""
+        symbols = [
+            Symbol(name="A", kind="func", file="a.go", line=3, signature="func A()", package="example.com/a"),
+            Symbol(name="B", kind="func", file="a.go", line=5, signature="func B()", package="example.com/a"),
+            Symbol(name="C", kind="func", file="c/c.go", line=3, signature="func C()", package="example.com/a/c"),
+        ]
+
+        assert TextFormatter().format(symbols).splitlines() == [
+            "package example.com/a",
+            "a.go:3: func A()",
+            "a.go:5: func B()",
+            "",
+            "package example.com/a/c",
+            "c/c.go:3: func C()",
+        ]
+
+    def test_empty(self):
+        """No symbols renders as empty output."""
+        assert TextFormatter().format([]) == ""
+
+    def test_color(self):
+        """With color on, names, receivers, and doc comments are highlighted."""
+        symbols = [
+            Symbol(name="Point", kind="struct", file="p.go", line=3, signature="type Point struct", doc="Point is a point."),
+            Symbol(name="Scale", kind="method", file="p.go", line=7, signature="func (p *Point) Scale(f float64) Point", receiver="Point"),
+            Symbol(name="Origin", kind="const", file="p.go", line=9, signature="const Origin = 0"),
+        ]
+
+        lines = TextFormatter(color=True).format(symbols).splitlines()
+
+        assert lines == [
+            "p.go:3: type \033[36mPoint\033[0m struct",
+            "    \033[2m// Point is a point.\033[0m",
+            "p.go:7: func \033[33m(p *Point)\033[0m \033[32mScale\033[0m(f float64) Point",
+            "p.go:9: const Origin = 0",
+        ]
+
+    def test_no_color_by_default(self, sample_symbols):
+        """Output has no escape codes unless color is requested."""
+        assert "\033[" not in TextFormatter().format(sample_symbols)
+
+    def test_source(self):
+        """Captured source is shown below the doc in place of the member list."""
+        field = Symbol(name="X", kind="field", file="p.go", line=4, signature="X int", source="X int")
+        point = Symbol(
+            name="Point", kind="struct", file="p.go", line=3, signature="type Point struct",
+            doc="Point is a point.", fields=[field], source="type Point struct {\n\tX int\n}",
+        )
+
+        assert TextFormatter().format([point]).splitlines() == [
+            "p.go:3: type Point struct",
+            "    // Point is a point.",
+            "    type Point struct {",
+            "    \tX int",
+            "    }",
+        ]
+
+    def test_complexity(self):
+        """Complexity is shown under functions and flagged above the threshold."""
+        symbols = [
+            Symbol(name="Parse", kind="func", file="p.go", line=3, signature="func Parse(s string) int", complexity=12),
+            Symbol(name="Zero", kind="func", file="p.go", line=9, signature="func Zero() int", complexity=1),
+        ]
+
+        assert TextFormatter(complexity_threshold=10).format(symbols).splitlines() == [
+            "p.go:3: func Parse(s string) int",
+            "    // complexity 12 (over 10)",
+            "p.go:9: func Zero() int",
+            "    // complexity 1",
+        ]
+
+    def test_deprecated(self):
+        """Deprecated symbols and members are tagged after their signature."""
+        host = Symbol(name="Host", kind="field", file="o.go", line=5, signature="Host string", deprecated=True)
+        symbols = [
+            Symbol(name="Options", kind="struct", file="o.go", line=3, signature="type Options struct", fields=[host]),
+            Symbol(name="Connect", kind="func", file="o.go", line=9, signature="func Connect()", deprecated=True),
+        ]
+
+        assert TextFormatter().format(symbols).splitlines() == [
+            "o.go:3: type Options struct",
+            "    Host string [deprecated]",
+            "o.go:9: func Connect() [deprecated]",
+        ]
+
+    def test_example_subject(self):
+        """Examples name the symbol they document."""
+        example = Symbol(name="ExampleAdd", kind="example", file="a_test.go", line=5, signature="func ExampleAdd()", subject="Add")
+
+        assert TextFormatter().format([example]).splitlines() == [
+            "a_test.go:5: func ExampleAdd()",
+            "    // example of Add",
+        ]
+
+
+class TestJsonFormatter:
+    """Tests for the JSON format."""
+
+    def test_top_level_array(self, sample_symbols):
+        """Output is a JSON array with one object per symbol."""
+        data = json.loads(JsonFormatter().format(sample_symbols))
+
+        assert isinstance(data, list)
+        assert len(data) == len(sample_symbols)
+        assert set(data[0]) >= {"name", "kind", "receiver", "signature", "doc", "file", "line"}
+
+    def test_method_receiver(self, sample_symbols):
+        """Methods carry their receiver type and the method kind."""
+        data = json.loads(JsonFormatter().format(sample_symbols))
+        method_add = next(d for d in data if d["name"] == "Add" and d["kind"] == "method")
+
+        assert method_add["receiver"] == "*Calculator"
+        assert method_add["signature"] == "func (c *Calculator) Add(n int)"
+
+    def test_function_has_empty_receiver(self, sample_symbols):
+        """Free functions have an empty receiver."""
+        data = json.loads(JsonFormatter().format(sample_symbols))
+        func_add = next(d for d in data if d["name"] == "Add" and d["kind"] == "func")
+
+        assert func_add["receiver"] == ""
+        assert func_add["line"] == 6
+
+    def test_empty(self):
+        """No symbols renders as an empty array."""
+        assert json.loads(JsonFormatter().format([])) == []
+
+
+class TestJsonlFormatter:
+    """Tests for the JSON Lines format."""
+
+    def test_one_object_per_line(self, sample_symbols):
+        """Each symbol is a compact JSON object on a line of its own."""
+        lines = JsonlFormatter().format(sample_symbols).split("\n")
+
+        assert len(lines) == len(sample_symbols)
+        assert [json.loads(line) for line in lines] == json.loads(JsonFormatter().format(sample_symbols))
+        assert lines[0] == JsonlFormatter().format_symbol(sample_symbols[0])
+
+    def test_packages(self, sample_symbols):
+        """Package summaries precede the symbols as objects of kind package."""
+        summaries = {"example.com/calc": PackageSummary(name="calc", path="example.com/calc", files=1)}
+        lines = JsonlFormatter().format_packages(summaries, sample_symbols[:1]).split("\n")
+
+        assert json.loads(lines[0])["kind"] == "package"
+        assert json.loads(lines[0])["path"] == "example.com/calc"
+        assert json.loads(lines[1])["name"] == sample_symbols[0].name
+
+    def test_diff(self, sample_symbols):
+        """Changes are symbol objects with a change, modified ones with the previous version."""
+        old = replace(sample_symbols[1], signature="func Old()")
+        diff = SymbolDiff(added=[sample_symbols[0]], modified=[sample_symbols[1]], previous=[old])
+        lines = [json.loads(line) for line in JsonlFormatter().format_package_diffs({"": diff}).split("\n")]
+
+        assert [line["change"] for line in lines] == ["added", "modified"]
+        assert lines[1]["previous"]["signature"] == "func Old()"
+
+    def test_calls(self):
+        """The call graph is a line per caller."""
+        output = JsonlFormatter().format_calls({"p.A": ["p.B"], "p.B": []})
+
+        assert [json.loads(line) for line in output.split("\n")] == [
+            {"caller": "p.A", "callees": ["p.B"]},
+            {"caller": "p.B", "callees": []},
+        ]
+
+    def test_empty(self):
+        """No symbols renders as no lines."""
+        assert JsonlFormatter().format([]) == ""
+
+
+class TestMarkdownFormatter:
+    """Tests for the Markdown API reference format."""
+
+    def test_methods_grouped_under_type(self, sample_symbols):
+        """Methods appear under their receiver type, after its field table."""
+        output = MarkdownFormatter().format(sample_symbols)
+        calculator = output[output.index("### Calculator"):output.index("### NewCalculator")]
+
+        headings = [line for line in calculator.splitlines() if line.startswith("#")]
+        assert headings == [
+            "### Calculator",
+            "#### Calculator.Add",
+            "#### Calculator.Subtract",
+            "#### Calculator.GetValue",
+            "#### Calculator.Display",
+        ]
+        assert "| `value` | `int` |  |  |" in calculator
+
+    def test_function_code_block(self, sample_symbols):
+        """Functions render as a Go code block followed by the doc comment."""
+        output = MarkdownFormatter().format(sample_symbols)
+
+        assert output.startswith("## ")
+        assert "### Add\n\n```go\nfunc Add(a, b int) int\n```\n\nAdd adds two integers and returns the result" in output
+
+    def test_test_functions(self):
+        """Tests and examples render like functions, with the subject of an example."""
+        symbols = [
+            Symbol(name="TestAdd", kind="test", file="a_test.go", line=3, signature="func TestAdd(t *testing.T)"),
+            Symbol(name="ExampleAdd", kind="example", file="a_test.go", line=5, signature="func ExampleAdd()", subject="Add"),
+        ]
+        output = MarkdownFormatter().format(symbols)
+
+        assert "### TestAdd\n\n```go\nfunc TestAdd(t *testing.T)\n```" in output
+        assert "### ExampleAdd\n\n```go\nfunc ExampleAdd()\n```\n\nExample of: `Add`" in output
+
+    def test_interface_method_set(self, sample_symbols):
+        """Interfaces show their flattened method set as valid Go."""
+        output = MarkdownFormatter().format(sample_symbols)
+
+        assert (
+            "```go\ntype MathOperator interface {\n"
+            "\tAdder\n\tMultiplier\n"
+            "\t// Add(a, b int) int (from Adder)\n"
+            "\t// Multiply(x, y int) int (from Multiplier)\n}\n```"
+        ) in output
+
+    def test_package_sections(self):
+        """Each package gets its own section, and fields tables escape pipes."""
+        tag_field = Symbol(name="Mode", kind="field", file="a.go", line=4, signature="", type="int", tag='enum:"a|b"')
+        symbols = [
+            Symbol(name="Config", kind="struct", file="a.go", line=3, signature="type Config struct",
+                   package="example.com/a", fields=[tag_field]),
+            Symbol(name="Run", kind="func", file="b/b.go", line=3, signature="func Run()", package="example.com/a/b"),
+        ]
+        output = MarkdownFormatter().format(symbols)
+
+        assert [line for line in output.splitlines() if line.startswith("## ")] == [
+            "## package example.com/a",
+            "## package example.com/a/b",
+        ]
+        assert '| `Mode` | `int` | `enum:"a\\|b"` |  |' in output
+
+    def test_orphan_methods(self):
+        """Methods on a type declared elsewhere get their own subsection."""
+        method = Symbol(name="Close", kind="method", file="a.go", line=3,
+                        signature="func (c *Conn) Close() error", receiver="*Conn")
+        output = MarkdownFormatter().format([method])
+
+        assert "### Conn\n\n#### Conn.Close" in output
+
+    def test_deterministic(self, sample_symbols):
+        """Rendering the same symbols twice gives identical output."""
+        assert MarkdownFormatter().format(sample_symbols) == MarkdownFormatter().format(sample_symbols)
+
+
+class TestLspFormatter:
+    """Tests for LSP DocumentSymbol output."""
+
+    def by_name(self, document: list[dict]) -> dict[str, dict]:
+        return {d["name"]: d for d in document}
+
+    def test_kinds(self, sample_symbols):
+        """Symbol kinds map to LSP SymbolKind integers."""
+        document = self.by_name(json.loads(LspFormatter().format(sample_symbols)))
+
+        assert document["Add"]["kind"] == 12
+        assert document["Adder"]["kind"] == 11
+        assert document["Calculator"]["kind"] == 23
+        assert document["Adder"]["children"][0]["kind"] == 6
+        assert document["Point"]["children"][0]["kind"] == 8
+
+    def test_test_kinds(self):
+        """Tests, benchmarks, and examples are functions."""
+        symbols = [
+            Symbol(name=name, kind=kind, file="a_test.go", line=line, signature=f"func {name}()")
+            for line, (name, kind) in enumerate([("TestA", "test"), ("BenchmarkA", "benchmark"), ("ExampleA", "example")], 1)
+        ]
+
+        assert [d["kind"] for d in json.loads(LspFormatter().format(symbols))] == [12, 12, 12]
+
+    def test_zero_based_ranges(self, sample_symbols):
+        """Ranges are converted to 0-based lines and characters."""
+        document = self.by_name(json.loads(LspFormatter().format(sample_symbols)))
+        add = document["Add"]
+
+        # `func Add(a, b int) int {` spans lines 6-8 of the fixture
+        assert add["range"] == {"start": {"line": 5, "character": 0}, "end": {"line": 7, "character": 1}}
+        assert add["selectionRange"] == {"start": {"line": 5, "character": 5}, "end": {"line": 5, "character": 8}}
+        assert add["detail"] == "func Add(a, b int) int"
+
+    def test_methods_and_fields_nested(self, sample_symbols):
+        """Methods and fields are children of their type."""
+        document = self.by_name(json.loads(LspFormatter().format(sample_symbols)))
+        children = [c["name"] for c in document["Calculator"]["children"]]
+
+        assert children == ["value", "name", "Add", "Subtract", "GetValue", "Display"]
+        assert "(*Calculator).Add" not in document
+        point_x = document["Point"]["children"][0]
+        assert point_x["detail"] == "float64"
+        assert point_x["selectionRange"]["start"] == {"line": 66, "character": 1}
+
+    def test_selection_within_range(self, sample_symbols):
+        """Every selectionRange lies inside its range, as the spec requires."""
+        def check(symbol):
+            start, end = symbol["range"]["start"], symbol["range"]["end"]
+            sel = symbol["selectionRange"]
+            assert (start["line"], start["character"]) <= (sel["start"]["line"], sel["start"]["character"])
+            assert (sel["end"]["line"], sel["end"]["character"]) <= (end["line"], end["character"])
+            for child in symbol.get("children", []):
+                check(child)
+
+        for symbol in json.loads(LspFormatter().format(sample_symbols)):
+            check(symbol)
+
+    def test_orphan_method_named_with_receiver(self):
+        """Methods whose type is not in the file stay top-level, gopls style."""
+        method = Symbol(
+            name="Close", kind="method", file="a.go", line=3, column=1, end_line=3, end_column=30,
+            name_line=3, name_column=17, signature="func (f *File) Close() error", receiver="*File",
+        )
+        document = json.loads(LspFormatter().format([method]))
+
+        assert document[0]["name"] == "(*File).Close"
+        assert document[0]["selectionRange"]["end"]["character"] == 21
+
+    def test_deprecated_tag(self):
+        """Deprecated symbols carry the Deprecated SymbolTag."""
+        symbols = [
+            Symbol(name="Connect", kind="func", file="a.go", line=1, signature="func Connect()", deprecated=True),
+            Symbol(name="Dial", kind="func", file="a.go", line=3, signature="func Dial()"),
+        ]
+        document = self.by_name(json.loads(LspFormatter().format(symbols)))
+
+        assert document["Connect"]["tags"] == [1]
+        assert "tags" not in document["Dial"]
+
+    def test_directory_keyed_by_file(self):
+        """Symbols from a directory walk are grouped per file."""
+        symbols = [
+            Symbol(name="A", kind="func", file="a.go", line=1, signature="func A()", package="m"),
+            Symbol(name="B", kind="func", file="b.go", line=1, signature="func B()", package="m"),
+        ]
+        output = json.loads(LspFormatter().format(symbols))
+
+        assert list(output) == ["a.go", "b.go"]
+        assert output["b.go"][0]["name"] == "B"
+
+
+class TestDotFormatter:
+    """Tests for the Graphviz format."""
+
+    def test_sample(self, sample_symbols):
+        """Types are boxes listing their methods; embedded interfaces are edges."""
+        assert DotFormatter().format(sample_symbols).splitlines() == [
+            "digraph symbols {",
+            "    rankdir=LR;",
+            '    node [shape=box, fontname="Helvetica"];',
+            '    "Calculator" [label="Calculator\\n\\nAdd(n int)\\lSubtract(n int)\\lGetValue() int\\lDisplay()\\l"];',
+            '    "Adder" [label="Adder\\n\\nAdd(a, b int) int\\l", style=dashed];',
+            '    "Multiplier" [label="Multiplier\\n\\nMultiply(x, y int) int\\l", style=dashed];',
+            '    "MathOperator" [label="MathOperator", style=dashed];',
+            '    "Point" [label="Point"];',
+            '    "MathOperator" -> "Adder" [arrowhead=empty, label="embeds"];',
+            '    "MathOperator" -> "Multiplier" [arrowhead=empty, label="embeds"];',
+            "}",
+        ]
+
+    def test_packages(self):
+        """Packages are clusters, nodes are qualified, and implements edges cross packages."""
+        index = extract_fs(MapFS({
+            "go.mod": "module example.com/zoo\n",
+            "zoo.go": "package zoo\n\ntype Namer interface{ Name() string }\n",
+            "cat/cat.go": (
+                "package cat\n\nimport \"example.com/zoo\"\n\n"
+                "type Cat struct{ zoo.Namer }\n\nfunc (c *Cat) Name() string { return \"\" }\n"
+            ),
+        }), options=Options(recursive=True))
+        output = DotFormatter().format(index.symbols())
+
+        assert '    subgraph "cluster_example.com/zoo/cat" {' in output
+        assert '        "example.com/zoo/cat.Cat" [label="Cat\\n\\nName() string\\l"];' in output
+        assert (
+            '    "example.com/zoo/cat.Cat" -> "example.com/zoo.Namer" [arrowhead=empty, label="embeds"];'
+        ) in output
+        assert (
+            '    "example.com/zoo/cat.Cat" -> "example.com/zoo.Namer" [arrowhead=empty, style=dashed, label="*"];'
+        ) in output
+
+    def test_external_and_generic_types(self):
+        """Generic, pointer, and outside types keep valid, stable IDs."""
+        content = (
+            "package p\n\nimport \"io\"\n\n"
+            "type Node[T any] struct{ *Node[T]; io.Reader }\n\n"
+            "type Number interface{ ~int | ~float64 }\n"
+        )
+        output = DotFormatter().format(GoSymbolExtractor().extract(content, "p.go"))
+
+        assert '    "Node" [label="Node[T any]"];' in output
+        assert '    "Node" -> "Node" [arrowhead=empty, label="embeds"];' in output
+        assert '    "Node" -> "io.Reader" [arrowhead=empty, label="embeds"];' in output
+        assert '    "io.Reader" [style=dotted];' in output
+        # Type sets are not edges
+        assert '"Number" ->' not in output
+
+    def test_escaping(self):
+        """Quotes and backslashes in names and labels are escaped."""
+        symbol = Symbol(name='Odd"Name\\', kind="struct", file="a.go", line=1, signature='type Odd"Name\\ struct')
+
+        assert '    "Odd\\"Name\\\\" [label="Odd\\"Name\\\\"];' in DotFormatter().format([symbol])
+
+    def test_calls(self):
+        """Call graphs render as caller -> callee edges."""
+        output = DotFormatter().format_calls({"m.Add": ["m.sum", "fmt.Println"], "m.sum": []})
+
+        assert output.splitlines()[3:] == [
+            '    "m.Add";',
+            '    "m.Add" -> "m.sum";',
+            '    "m.Add" -> "fmt.Println";',
+            '    "m.sum";',
+            "}",
+        ]
+
+    @pytest.mark.skipif(shutil.which("dot") is None, reason="Graphviz is not installed")
+    def test_renders(self, sample_symbols):
+        """Graphviz accepts the output."""
+        result = subprocess.run(["dot", "-Tsvg"], input=DotFormatter().format(sample_symbols), capture_output=True, text=True)
+
+        assert result.returncode == 0, result.stderr
+        assert "<svg" in result.stdout
+
+
+class TestFormatPackages:
+    """Tests for output preceded by package summaries."""
+
+    @pytest.fixture
+    def summaries(self):
+        """A documented package summary."""
+        return {"m/calc": PackageSummary(
+            name="calc",
+            path="m/calc",
+            doc="Package calc adds.\nIt is small.",
+            files=2,
+            kinds={"func": 1, "method": 2},
+            imports=["fmt"],
+        )}
+
+    @pytest.fixture
+    def symbols(self):
+        """A function of the summarized package."""
+        return [Symbol(name="Add", kind="func", file="calc/add.go", line=3, signature="func Add()", package="m/calc")]
+
+    def test_text(self, summaries, symbols):
+        """Text output starts with a comment block per package."""
+        output = TextFormatter().format_packages(summaries, symbols)
+
+        assert output.splitlines()[:4] == [
+            "// package calc (m/calc): 2 files, 1 func, 2 methods",
+            "//   Package calc adds.",
+            "//   It is small.",
+            "",
+        ]
+        assert output.endswith(TextFormatter().format(symbols))
+
+    def test_json(self, summaries, symbols):
+        """JSON output becomes an object of packages and symbols."""
+        output = json.loads(JsonFormatter().format_packages(summaries, symbols))
+
+        assert output["packages"] == [{
+            "name": "calc",
+            "path": "m/calc",
+            "doc": "Package calc adds.\nIt is small.",
+            "files": 2,
+            "kinds": {"func": 1, "method": 2},
+            "imports": ["fmt"],
+            "lines": 0,
+            "loc": 0,
+            "file_loc": {},
+            "directives": [],
+        }]
+        assert [s["name"] for s in output["symbols"]] == ["Add"]
+
+    def test_lsp(self, summaries, symbols):
+        """LSP output keeps its DocumentSymbols under `symbols`."""
+        output = json.loads(LspFormatter().format_packages(summaries, symbols))
+
+        assert output["packages"][0]["name"] == "calc"
+        assert output["symbols"]["calc/add.go"][0]["name"] == "Add"
+
+    def test_markdown(self, summaries, symbols):
+        """Markdown sections are headed by the package name, then its size and doc."""
+        output = MarkdownFormatter().format_packages(summaries, symbols)
+
+        assert output.startswith(
+            "## package calc\n\n"
+            "`m/calc` · 2 files, 1 func, 2 methods\n\n"
+            "Package calc adds.\nIt is small.\n\n"
+            "### Add"
+        )
+
+    def test_markdown_single_file(self, sample_symbols):
+        """A single file's section is named after its package clause."""
+        summary = PackageSummary(name="calculator", files=1, kinds={"func": 3})
+        output = MarkdownFormatter().format_packages({"": summary}, sample_symbols)
+
+        assert output.startswith("## package calculator\n\n1 file, 3 funcs\n\n### Add\n")
+
+
+class TestCounts:
+    """Tests for symbol tallies (--count)."""
+
+    @pytest.fixture
+    def summaries(self):
+        """Two packages, the second without symbols."""
+        return {
+            "m/calc": PackageSummary(name="calc", path="m/calc", files=2, kinds={"func": 2, "method": 4, "struct": 1}),
+            "m/shapes": PackageSummary(name="shapes", path="m/shapes", files=1, kinds={"func": 1, "interface": 3}),
+            "m/empty": PackageSummary(name="empty", path="m/empty", files=1),
+        }
+
+    def test_text(self, summaries):
+        """One line per package with symbols, then the total by kind."""
+        assert TextFormatter().format_counts(summaries).splitlines() == [
+            "m/calc: 2 func, 4 method, 1 struct",
+            "m/shapes: 1 func, 3 interface",
+            "total: 11 symbols (3 func, 4 method, 1 struct, 3 interface)",
+        ]
+
+    def test_single_file(self, sample_symbols):
+        """A single file's tallies are labeled with its package name."""
+        summary = PackageSummary(name="calculator", files=1, kinds={"func": 3, "method": 4, "struct": 2, "interface": 3})
+
+        assert TextFormatter().format_counts({"": summary}).splitlines()[0] == "calculator: 3 func, 4 method, 2 struct, 3 interface"
+
+    def test_no_symbols(self):
+        """Empty results still report a total."""
+        assert TextFormatter().format_counts({"": PackageSummary(name="calc")}) == "total: 0 symbols"
+
+    def test_json(self, summaries):
+        """JSON tallies are an object of the total, kinds, and packages."""
+        output = json.loads(JsonFormatter().format_counts(summaries))
+
+        assert output == {
+            "total": 11,
+            "kinds": {"func": 3, "method": 4, "struct": 1, "interface": 3},
+            "packages": [
+                {"path": "m/calc", "name": "calc", "total": 7, "kinds": {"func": 2, "method": 4, "struct": 1}},
+                {"path": "m/shapes", "name": "shapes", "total": 4, "kinds": {"func": 1, "interface": 3}},
+            ],
+        }
+
+    def test_filtered_symbols(self):
+        """Tallies count what the filters leave, via the summaries of a walk."""
+        index = extract_fs(MapFS({
+            "go.mod": "module m\n",
+            "calc.go": "package calc\n\nfunc Add() {}\n\nfunc sub() {}\n\ntype Calc struct{}\n",
+        }), options=Options(exported_only=True, kinds=["func"]))
+
+        assert TextFormatter().format_counts(index.summaries) == "m: 1 func\ntotal: 1 symbol (1 func)"
+
+
+class TestFormatterRegistry:
+    """Tests for get_formatter and the FORMATTERS registry."""
+
+    def test_known_formats(self):
+        """Registered names resolve to formatter instances."""
+        assert isinstance(get_formatter("text"), TextFormatter)
+        assert isinstance(get_formatter("json"), JsonFormatter)
+        assert isinstance(get_formatter("jsonl"), JsonlFormatter)
+        assert isinstance(get_formatter("markdown"), MarkdownFormatter)
+        assert isinstance(get_formatter("lsp"), LspFormatter)
+        assert isinstance(get_formatter("dot"), DotFormatter)
+
+    def test_unknown_format(self):
+        """Unknown format names raise ValueError."""
+        with pytest.raises(ValueError, match="Unsupported format"):
+            get_formatter("yaml")
+
+    def test_custom_formatter(self, monkeypatch):
+        """New formats can be registered without touching the CLI."""
+        class NamesFormatter(SymbolFormatter):
+            def format(self, symbols: list[Symbol]) -> str:
+                return ",".join(s.name for s in symbols)
+
+        monkeypatch.setitem(FORMATTERS, "names", NamesFormatter)
+        symbol = Symbol(name="Add", kind="func", file="a.go", line=1, signature="func Add()")
+
+        assert get_formatter("names").format([symbol]) == "Add"
@@ -0,0 +1,104 @@
+"""
+Unit tests for walking file trees other than OS directories.
+
+Tests MapFS as a Traversable, and extract_fs over in-memory trees and zip
+archives.
+"""
+
+import zipfile
+import pytest
+from ctxd.symbols import MapFS, Options, extract_dir, extract_fs
+
+FILES = {
+    "go.mod": "module example.com/shapes\n\ngo 1.22\n",
+    "geo/point.go": "// Package geo has points.\npackage geo\n\n// Point is a point.\ntype Point struct{}\n",
+    "geo/polar/polar.go": "package polar\n\nfunc Convert() {}\n",
+    "shapes.go": b"package shapes\n\nfunc Area() int { return 0 }\n",
+}
+
+
+class TestMapFS:
+    """Tests for the in-memory tree."""
+
+    def test_directories_are_implicit(self):
+        """Parents of files are directories; files are not."""
+        fsys = MapFS(FILES)
+
+        assert fsys.is_dir() and not fsys.is_file()
+        assert (fsys / "geo").is_dir()
+        assert (fsys / "geo" / "point.go").is_file()
+        assert not (fsys / "geo" / "missing.go").is_file()
+        assert not (fsys / "geo" / "missing.go").is_dir()
+
+    def test_iterdir(self):
+        """Children are listed once each, sorted by name."""
+        assert [p.name for p in MapFS(FILES).iterdir()] == ["geo", "go.mod", "shapes.go"]
+        assert [str(p) for p in (MapFS(FILES) / "geo").iterdir()] == ["geo/point.go", "geo/polar"]
+
+        with pytest.raises(NotADirectoryError):
+            list((MapFS(FILES) / "go.mod").iterdir())
+
+    def test_paths_normalized(self):
+        """Keys and joined paths are cleaned, and the root is its own parent."""
+        fsys = MapFS({"./geo//point.go": "package geo\n"})
+
+        assert fsys.joinpath("geo", "point.go").read_text() == "package geo\n"
+        assert fsys / "geo" / ".." / "geo" == fsys / "geo"
+        assert (fsys / "geo").parent == fsys
+        assert fsys.parent == fsys
+        assert str(fsys) == "."
+
+    def test_open(self):
+        """Text and bytes contents open in either mode; writes are refused."""
+        fsys = MapFS(FILES)
+
+        assert (fsys / "shapes.go").read_text().startswith("package shapes")
+        assert (fsys / "go.mod").read_bytes() == b"module example.com/shapes\n\ngo 1.22\n"
+        with pytest.raises(FileNotFoundError):
+            (fsys / "nope.go").open()
+        with pytest.raises(ValueError, match="read-only"):
+            (fsys / "go.mod").open("w")
+
+
+class TestExtractFS:
+    """Tests for extraction from non-OS trees."""
+
+    def test_map_fs(self):
+        """Packages are keyed by the tree's go.mod and files by their tree path."""
+        index = extract_fs(MapFS(FILES), options=Options(recursive=True))
+
+        assert list(index.packages) == [
+            "example.com/shapes",
+            "example.com/shapes/geo",
+            "example.com/shapes/geo/polar",
+        ]
+        point = index.package("example.com/shapes/geo")[0]
+        assert (point.name, point.file, point.doc) == ("Point", "geo/point.go", "Point is a point.")
+        assert index.summaries["example.com/shapes/geo"].doc == "Package geo has points."
+
+    def test_subdirectory(self):
+        """Walking a directory of the tree still finds the module above it."""
+        index = extract_fs(MapFS(FILES), "geo", Options(recursive=True))
+
+        assert list(index.packages) == ["example.com/shapes/geo", "example.com/shapes/geo/polar"]
+
+    def test_not_a_directory(self):
+        """A root that is not a directory of the tree is an error."""
+        with pytest.raises(NotADirectoryError):
+            extract_fs(MapFS(FILES), "geo/point.go")
+
+    def test_zip_archive(self, tmp_path):
+        """zipfile.Path trees are walked without extracting the archive."""
+        zip_path = tmp_path / "shapes.zip"
+        with zipfile.ZipFile(zip_path, "w") as archive:
+            for name, data in FILES.items():
+                archive.writestr(f"shapes/{name}", data)
+
+        with zipfile.ZipFile(zip_path) as archive:
+            index = extract_dir(zipfile.Path(archive, "shapes/"), Options(recursive=True))
+
+            assert list(index.packages) == [
+                "example.com/shapes",
+                "example.com/shapes/geo",
+                "example.com/shapes/geo/polar",
+            ]
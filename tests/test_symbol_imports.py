@@ -0,0 +1,85 @@
+"""
+Unit tests for import usage per symbol.
+
+Tests package name assumptions for import paths, and the imports recorded
+on symbols of tests/fixtures/imports.go and on package summaries.
+"""
+
+import pytest
+from pathlib import Path
+from ctxd.symbols import GoSymbolExtractor, MapFS, Options, extract_fs
+from ctxd.symbols.imports import assumed_package_name
+
+FIXTURES = Path(__file__).parent / "fixtures"
+
+
+@pytest.fixture
+def imports():
+    """Symbol name to imports for the imports fixture."""
+    return {s.name: s.imports for s in GoSymbolExtractor().extract_file(FIXTURES / "imports.go")}
+
+
+class TestAssumedPackageName:
+    """Tests for guessing the name an import is referenced by."""
+
+    @pytest.mark.parametrize("path, name", [
+        ("fmt", "fmt"),
+        ("net/http", "http"),
+        ("github.com/stretchr/testify/v2", "testify"),
+        ("gopkg.in/yaml.v3", "yaml"),
+        ("github.com/mattn/go-sqlite3", "sqlite3"),
+        ("github.com/x/go-toml.v1", "toml"),
+    ])
+    def test_names(self, path, name):
+        """The last path element is used, without version or go- decoration."""
+        assert assumed_package_name(path) == name
+
+
+class TestSymbolImports:
+    """Tests for the imports attached to symbols."""
+
+    def test_qualified_references(self, imports):
+        """Selector calls and qualified types resolve to their import paths."""
+        assert imports["Render"] == ["fmt"]
+        assert imports["Exit"] == ["os"]
+        assert imports["Report"] == ["gopkg.in/yaml.v3"]
+
+    def test_alias(self, imports):
+        """Aliased imports are resolved by their alias."""
+        assert imports["Title"] == ["strings"]
+
+    def test_dot_import(self, imports):
+        """An unqualified call of a name the file does not declare uses the dot import."""
+        assert imports["Scale"] == ["math"]
+
+    def test_unused_and_blank(self, imports):
+        """Symbols without package references and blank imports list nothing."""
+        assert imports["Empty"] == []
+        assert imports["Limit"] == []
+        assert not any("embed" in used for used in imports.values())
+
+    def test_sample_display(self):
+        """Display in the sample fixture uses fmt."""
+        symbols = {s.name: s for s in GoSymbolExtractor().extract_file(FIXTURES / "sample.go")}
+        assert symbols["Display"].imports == ["fmt"]
+
+    def test_type_parameters_not_dot_imports(self):
+        """Type parameters are not mistaken for names from a dot import."""
+        content = 'package main\n\nimport . "math"\n\nfunc Id[T any](v T) T { return v }\n'
+        assert GoSymbolExtractor().extract(content, "test.go")[0].imports == []
+
+
+class TestPackageImports:
+    """Tests for the aggregated import list of a package."""
+
+    def test_aggregated(self):
+        """A package lists the imports of all its symbols once, sorted."""
+        index = extract_fs(MapFS({
+            "go.mod": "module example.com/report\n",
+            "report.go": (FIXTURES / "imports.go").read_text(),
+            "extra.go": 'package report\n\nimport "fmt"\n\nfunc Print() { fmt.Println() }\n',
+        }), options=Options())
+
+        assert index.summaries["example.com/report"].imports == [
+            "fmt", "gopkg.in/yaml.v3", "math", "os", "strings",
+        ]
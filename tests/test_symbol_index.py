@@ -0,0 +1,250 @@
+"""
+Unit tests for the incremental symbol index and watch mode.
+
+Tests diff_symbols, SymbolIndex, and GoChangeHandler event coalescing.
+"""
+
+import json
+import pytest
+from pathlib import Path
+from types import SimpleNamespace
+from ctxd.symbols import GoSymbolExtractor, SymbolIndex, diff_symbols, get_formatter
+from ctxd.symbols.watcher import GoChangeHandler
+
+SOURCE = """package calc
+
+// Add adds two integers
+func Add(a, b int) int { return a + b }
+
+type Calculator struct{}
+
+func (c *Calculator) Add(n int) {}
+"""
+
+
+def extract(content: str):
+    """Extract symbols from inline Go source."""
+    return GoSymbolExtractor().extract(content, "calc.go")
+
+
+def event(path: Path, dest: Path = None):
+    """Build a minimal watchdog-like file event."""
+    return SimpleNamespace(src_path=str(path), dest_path=str(dest), is_directory=False)
+
+
+class TestDiffSymbols:
+    """Tests for comparing two extractions."""
+
+    def test_no_changes(self):
+        """Identical extractions produce an empty diff."""
+        assert not diff_symbols(extract(SOURCE), extract(SOURCE))
+
+    def test_added_and_removed(self):
+        """New and deleted declarations are reported."""
+        new = SOURCE.replace("type Calculator struct{}", "type Calc struct{}")
+        diff = diff_symbols(extract(SOURCE), extract(new))
+
+        assert [s.name for s in diff.added] == ["Calc"]
+        assert [s.name for s in diff.removed] == ["Calculator"]
+        assert diff.modified == []
+
+    def test_modified_signature(self):
+        """A changed signature counts as modified, keyed with the receiver."""
+        new = SOURCE.replace("Add(n int)", "Add(n int) error")
+        diff = diff_symbols(extract(SOURCE), extract(new))
+
+        assert [(s.name, s.kind) for s in diff.modified] == [("Add", "method")]
+        assert diff.added == diff.removed == []
+
+    def test_moved_is_not_modified(self):
+        """Shifting a declaration to another line is not a change."""
+        new = SOURCE.replace("package calc\n", "package calc\n\n\n")
+
+        assert not diff_symbols(extract(SOURCE), extract(new))
+
+    def test_doc_change_is_modified(self):
+        """Editing a doc comment counts as modified."""
+        new = SOURCE.replace("adds two integers", "sums two integers")
+        diff = diff_symbols(extract(SOURCE), extract(new))
+
+        assert [s.signature for s in diff.modified] == ["func Add(a, b int) int"]
+
+    def test_previous_versions(self):
+        """Old versions of modified symbols are kept alongside the new ones."""
+        new = SOURCE.replace("Add(n int)", "Add(n int) error")
+        diff = diff_symbols(extract(SOURCE), extract(new))
+
+        assert [s.signature for s in diff.previous] == ["func (c *Calculator) Add(n int)"]
+
+    def test_signatures_only(self):
+        """Doc edits are ignored when only signatures are compared."""
+        new = SOURCE.replace("adds two integers", "sums two integers")
+
+        assert not diff_symbols(extract(SOURCE), extract(new), signatures_only=True)
+
+
+class TestSymbolIndex:
+    """Tests for the per-file symbol index."""
+
+    def test_update_reports_diff(self, tmp_path):
+        """Updating a file returns what changed since the last update."""
+        go_file = tmp_path / "calc.go"
+        go_file.write_text(SOURCE)
+        index = SymbolIndex()
+
+        first = index.update_file(go_file)
+        go_file.write_text(SOURCE + "\nfunc Sub(a, b int) int { return a - b }\n")
+        second = index.update_file(go_file)
+
+        assert len(first.added) == 3
+        assert [s.name for s in second.added] == ["Sub"]
+        assert len(index.symbols()) == 4
+
+    def test_remove_file(self, tmp_path):
+        """Removing a file reports all of its symbols as removed."""
+        go_file = tmp_path / "calc.go"
+        go_file.write_text(SOURCE)
+        index = SymbolIndex()
+        index.update_file(go_file)
+
+        diff = index.remove_file(go_file)
+
+        assert len(diff.removed) == 3
+        assert len(index) == 0
+        assert index.symbols() == []
+
+    def test_remove_unknown_file(self, tmp_path):
+        """Removing a file that was never indexed is a no-op."""
+        assert not SymbolIndex().remove_file(tmp_path / "missing.go")
+
+
+class TestGoChangeHandler:
+    """Tests for event filtering and coalescing."""
+
+    @pytest.fixture
+    def tree(self, tmp_path):
+        """A watched tree with one indexed file."""
+        (tmp_path / "calc.go").write_text(SOURCE)
+        index = SymbolIndex()
+        index.update_file(tmp_path / "calc.go")
+        return tmp_path, index
+
+    def test_burst_coalesced(self, tree):
+        """Several writes to one file inside the window are processed once."""
+        root, index = tree
+        diffs = []
+        handler = GoChangeHandler(index, root, debounce_seconds=60, on_diff=diffs.append)
+
+        (root / "calc.go").write_text(SOURCE + "\nfunc Sub() {}\n")
+        handler.on_modified(event(root / "calc.go"))
+        handler.on_modified(event(root / "calc.go"))
+
+        assert not handler.process_pending_changes()
+        diff = handler.process_pending_changes(force=True)
+
+        assert [s.name for s in diff.added] == ["Sub"]
+        assert diffs == [diff]
+
+    def test_change_callback_without_diff(self, tree):
+        """on_change runs for every processed batch, even when only lines moved."""
+        root, index = tree
+        changes = []
+        handler = GoChangeHandler(index, root, debounce_seconds=0, on_change=lambda: changes.append(True))
+
+        (root / "calc.go").write_text("\n" + SOURCE)
+        handler.on_modified(event(root / "calc.go"))
+
+        assert not handler.process_pending_changes()
+        assert changes == [True]
+        assert not handler.process_pending_changes()
+        assert changes == [True]
+
+    def test_deleted_file(self, tree):
+        """Deleting a file removes its symbols."""
+        root, index = tree
+        handler = GoChangeHandler(index, root, debounce_seconds=0)
+
+        (root / "calc.go").unlink()
+        handler.on_deleted(event(root / "calc.go"))
+        diff = handler.process_pending_changes()
+
+        assert len(diff.removed) == 3
+        assert len(index) == 0
+
+    def test_moved_file(self, tree):
+        """A rename is handled as a delete plus a create."""
+        root, index = tree
+        handler = GoChangeHandler(index, root, debounce_seconds=0)
+
+        (root / "calc.go").rename(root / "math.go")
+        handler.on_moved(event(root / "calc.go", root / "math.go"))
+        diff = handler.process_pending_changes()
+
+        assert index.files == [str(root / "math.go")]
+        assert len(diff.removed) == 3 and len(diff.added) == 3
+
+    def test_ignored_paths(self, tree):
+        """Non-Go files, tests, and excluded or outside directories are ignored."""
+        root, index = tree
+        handler = GoChangeHandler(index, root, debounce_seconds=0)
+        (root / "vendor").mkdir()
+        for name in ["notes.txt", "calc_test.go", "vendor/dep.go"]:
+            (root / name).write_text("package calc\n\nfunc Extra() {}\n")
+            handler.on_created(event(root / name))
+        handler.on_created(event(root.parent / "elsewhere.go"))
+
+        assert not handler.process_pending_changes()
+        assert len(index) == 1
+
+    def test_pattern_scoped(self, tree):
+        """Files outside the include/exclude patterns are ignored."""
+        root, index = tree
+        handler = GoChangeHandler(index, root, debounce_seconds=0, include=["pkg/**"], exclude=["**/*_gen.go"])
+        (root / "pkg").mkdir()
+        for name in ["main.go", "pkg/api.go", "pkg/api_gen.go"]:
+            (root / name).write_text("package calc\n\nfunc Extra() {}\n")
+            handler.on_created(event(root / name))
+
+        handler.process_pending_changes()
+        assert index.files == [str(root / "calc.go"), str(root / "pkg" / "api.go")]
+
+    def test_depth_scoped(self, tree):
+        """Files deeper than the depth limit are ignored."""
+        root, index = tree
+        handler = GoChangeHandler(index, root, debounce_seconds=0, depth=1)
+        (root / "pkg" / "inner").mkdir(parents=True)
+        for name in ["pkg/api.go", "pkg/inner/deep.go"]:
+            (root / name).write_text("package calc\n\nfunc Extra() {}\n")
+            handler.on_created(event(root / name))
+
+        handler.process_pending_changes()
+        assert index.files == [str(root / "calc.go"), str(root / "pkg" / "api.go")]
+
+    def test_directory_events_ignored(self, tree):
+        """Directory events never reach the index."""
+        root, index = tree
+        handler = GoChangeHandler(index, root, debounce_seconds=0)
+        handler.on_created(SimpleNamespace(src_path=str(root / "pkg.go"), is_directory=True))
+
+        assert not handler.process_pending_changes()
+
+
+class TestDiffFormatting:
+    """Tests for rendering symbol diffs."""
+
+    def test_text_markers(self):
+        """Text diffs prefix each symbol with +, -, or ~."""
+        new = SOURCE.replace("Add(n int)", "Add(n int) error").replace("type Calculator struct{}", "type Calculator struct{}\n\ntype Mode int")
+        output = get_formatter("text").format_diff(diff_symbols(extract(SOURCE), extract(new)))
+
+        assert output.splitlines() == [
+            "+ calc.go:8: type Mode int",
+            "~ calc.go:10: func (c *Calculator) Add(n int) error",
+        ]
+
+    def test_json_object(self):
+        """JSON diffs are an object of added, removed, and modified arrays."""
+        data = json.loads(get_formatter("json").format_diff(diff_symbols(extract(SOURCE), [])))
+
+        assert set(data) == {"added", "removed", "modified"}
+        assert len(data["removed"]) == 3
@@ -0,0 +1,109 @@
+"""
+Unit tests for advisory checks.
+
+Tests the receiver mode of methods in each output format, and detection
+of types mixing value and pointer receivers.
+"""
+
+import json
+import pytest
+from pathlib import Path
+from ctxd.symbols import GoSymbolExtractor, check_receivers, get_formatter, group_methods
+
+FIXTURES = Path(__file__).parent / "fixtures"
+
+
+@pytest.fixture
+def extractor():
+    """Create a Go symbol extractor."""
+    return GoSymbolExtractor()
+
+
+class TestReceiverMode:
+    """Tests for each method's receiver mode."""
+
+    def test_modes(self, extractor):
+        """Methods report pointer or value receivers; functions report none."""
+        symbols = {s.local_name: s for s in extractor.extract_file(FIXTURES / "sample.go")}
+
+        assert symbols["Calculator.Add"].receiver_mode == "pointer"
+        assert symbols["Calculator.GetValue"].receiver_mode == "value"
+        assert symbols["NewCalculator"].receiver_mode == ""
+
+    def test_generic_receiver(self, extractor):
+        """A pointer to an instantiated generic type is a pointer receiver."""
+        symbols = extractor.extract("package p\n\ntype List[T any] struct{}\n\nfunc (l *List[T]) Push(v T) {}\n", "p.go")
+
+        assert symbols[1].receiver_mode == "pointer"
+
+    def test_formatted(self, extractor):
+        """JSON, JSON Lines, LSP, and text output show the mode of each method."""
+        symbols = extractor.extract_file(FIXTURES / "sample.go")
+        by_name = {s["name"]: s for s in json.loads(get_formatter("json").format(symbols))}
+        lines = [json.loads(line) for line in get_formatter("jsonl").format(symbols).splitlines()]
+        documents = {d["name"]: d for d in json.loads(get_formatter("lsp").format(symbols))}
+        text = get_formatter("text").format(symbols)
+
+        assert (by_name["GetValue"]["receiver_mode"], by_name["NewCalculator"]["receiver_mode"]) == ("value", "")
+        assert {line["name"]: line["receiver_mode"] for line in lines}["Subtract"] == "pointer"
+        children = {c["name"]: c for c in documents["Calculator"]["children"]}
+        assert children["Add"]["receiverMode"] == "pointer"
+        assert "receiverMode" not in children["value"]
+        assert "func (c Calculator) GetValue() int  // value receiver\n" in text
+
+    def test_grouped_and_promoted(self, extractor):
+        """Grouped methods are marked in text output, before where they were promoted from."""
+        source = (
+            "package p\n\ntype Base struct{}\n\nfunc (b *Base) Reset() {}\n\n"
+            "type Counter struct {\n\tBase\n}\n\nfunc (c Counter) Count() int { return 0 }\n"
+        )
+        symbols = group_methods(extractor.extract(source, "p.go"))
+
+        text = get_formatter("text").format(symbols)
+
+        assert "    func (c Counter) Count() int  // value receiver\n" in text
+        assert "    func (b *Base) Reset()  // pointer receiver, from Base" in text
+
+
+class TestCheckReceivers:
+    """Tests for check_receivers."""
+
+    def test_mixed(self, extractor):
+        """A type with both modes is reported with each method under its mode."""
+        mixes = check_receivers(extractor.extract_file(FIXTURES / "sample.go"))
+
+        assert len(mixes) == 1
+        assert (mixes[0].type, mixes[0].line) == ("Calculator", 16)
+        assert mixes[0].pointer_methods == ["Add", "Subtract", "Display"]
+        assert mixes[0].value_methods == ["GetValue"]
+        assert str(mixes[0]).endswith(
+            "Calculator mixes value and pointer receivers: pointer Add, Subtract, Display; value GetValue"
+        )
+
+    def test_consistent(self, extractor):
+        """Types using one mode, and promoted methods, are not reported."""
+        content = (
+            "package p\n\ntype Base struct{}\n\nfunc (b Base) Name() string { return \"\" }\n\n"
+            "type Wrapper struct{ Base }\n\nfunc (w *Wrapper) Set() {}\n"
+        )
+        symbols = extractor.extract(content, "p.go")
+
+        assert check_receivers(symbols) == []
+        assert check_receivers(group_methods(symbols)) == []
+
+    def test_grouped(self, extractor):
+        """Methods grouped under their type are checked."""
+        mixes = check_receivers(group_methods(extractor.extract_file(FIXTURES / "sample.go")))
+
+        assert [m.type for m in mixes] == ["Calculator"]
+
+    def test_type_elsewhere(self, extractor):
+        """Without the type's declaration, the first method locates the mix."""
+        content = "package p\n\nfunc (t T) A() {}\n\nfunc (t *T) B() {}\n"
+        symbols = extractor.extract(content, "p.go")
+        for symbol in symbols:
+            symbol.package = "example.com/p"
+
+        mixes = check_receivers(symbols)
+
+        assert [(m.type, m.line) for m in mixes] == [("example.com/p.T", 3)]
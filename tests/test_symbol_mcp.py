@@ -0,0 +1,129 @@
+"""
+Unit tests for the MCP tools over Go symbols.
+
+Tests SymbolTools directly, without the MCP transport, and the tool
+registration when the mcp package is installed.
+"""
+
+import asyncio
+import pytest
+from ctxd.symbols import Options, extract_dir
+from ctxd.symbols.mcp_server import SymbolTools, create_server
+from ctxd.symbols.search import search
+from conftest import write
+
+
+@pytest.fixture
+def tools(tmp_path):
+    """Tools over a module with two packages."""
+    write(tmp_path, "go.mod", "module example.com/calc\n")
+    write(tmp_path, "calc.go", (
+        "package calc\n\n"
+        "// Calculator adds numbers.\ntype Calculator struct{}\n\n"
+        "// Add adds n.\nfunc (c *Calculator) Add(n int) {}\n\n"
+        "// Parse reads a calculation.\nfunc Parse(s string) error { return nil }\n\n"
+        "func add(a, b int) int { return a + b }\n"
+    ))
+    write(tmp_path, "geo/point.go", (
+        "package geo\n\n// Point is a point to add to.\ntype Point struct{}\n\n"
+        "// Parse reads a point.\nfunc Parse(s string) Point { return Point{} }\n"
+    ))
+    return SymbolTools(tmp_path, cache=False)
+
+
+class TestListSymbols:
+    """Tests for list_symbols."""
+
+    def test_all_packages(self, tools):
+        """Every package is walked by default, with summaries."""
+        result = tools.list_symbols()
+
+        assert [p["path"] for p in result["packages"]] == ["example.com/calc", "example.com/calc/geo"]
+        assert "Point" in [s["name"] for s in result["symbols"]]
+        assert result["errors"] == []
+
+    def test_relative_paths(self, tools):
+        """Files are relative to the root, whichever directory is listed."""
+        result = tools.list_symbols(path="geo")
+
+        assert {s["file"] for s in result["symbols"]} == {"geo/point.go"}
+        assert tools.get_symbol("Calculator.Add")["file"] == "calc.go"
+
+    def test_options(self, tools):
+        """Extraction settings narrow the result as the CLI flags do."""
+        result = tools.list_symbols(package="example.com/calc", exported_only=True, kinds=["func"])
+
+        assert [s["name"] for s in result["symbols"]] == ["Parse"]
+
+    def test_errors(self, tools):
+        """Unknown kinds and packages, and paths outside the root, are errors."""
+        assert "Unknown symbol kind" in tools.list_symbols(kinds=["class"])["error"]
+        assert tools.list_symbols(package="example.com/nope") == {"error": "Unknown package: example.com/nope"}
+        assert "outside" in tools.list_symbols(path="..")["error"]
+        assert tools.list_symbols(path="missing") == {"error": "Not a directory: missing"}
+
+
+class TestGetSymbol:
+    """Tests for get_symbol."""
+
+    def test_local_name(self, tools):
+        """A unique local name resolves, with its span."""
+        result = tools.get_symbol("Calculator.Add")
+
+        assert result["receiver"] == "*Calculator"
+        assert result["span"]["start"] == {"line": 7, "column": 1}
+
+    def test_ambiguous(self, tools):
+        """A name declared in several packages lists the candidates."""
+        result = tools.get_symbol("Parse")
+
+        assert result["error"] == "Ambiguous symbol: Parse"
+        assert result["candidates"] == ["example.com/calc.Parse", "example.com/calc/geo.Parse"]
+
+    def test_qualified_and_source(self, tools):
+        """Qualified names pick one package, and settings such as include_source apply."""
+        result = tools.get_symbol("example.com/calc.Parse", include_source=True)
+
+        assert result["source"] == "func Parse(s string) error { return nil }"
+        assert tools.get_symbol("Nope") == {"error": "Unknown symbol: Nope"}
+
+
+class TestSearchSymbols:
+    """Tests for search_symbols."""
+
+    def test_ranking(self, tools):
+        """Results are ranked by search(), as `ctxd find --in-doc` ranks them."""
+        result = tools.search_symbols("add")
+
+        # The func and method named add, shorter local name first, then docs
+        assert [(s["name"], s["matched"]) for s in result["symbols"]] == [
+            ("add", "name"), ("Add", "name"), ("Point", "doc"), ("Calculator", "doc"),
+        ]
+        # Fuzzy, through the local name of the method
+        assert [s["name"] for s in tools.search_symbols("calcadd")["symbols"]] == ["Add"]
+
+    def test_same_as_find(self, tools):
+        """The tool returns the matches of search() over the same symbols."""
+        index = extract_dir(tools.root, Options(recursive=True, cache=False))
+        expected = [(m.symbol.name, m.symbol.line, m.score) for m in search(index.symbols(), "calc", in_doc=True)]
+
+        assert [(s["name"], s["line"], s["score"]) for s in tools.search_symbols("calc")["symbols"]] == expected
+
+    def test_limit(self, tools):
+        """Results are cut at the limit, which must be positive."""
+        assert tools.search_symbols("add", limit=2)["count"] == 2
+        assert "Invalid limit" in tools.search_symbols("add", limit=0)["error"]
+        assert tools.search_symbols("  ") == {"error": "Empty query"}
+
+
+class TestServer:
+    """Tests for registering the tools with FastMCP."""
+
+    def test_tools_registered(self, tools):
+        """The three tools are listed with their parameters."""
+        pytest.importorskip("mcp")
+        server = create_server(tools)
+
+        listed = {tool.name: tool for tool in asyncio.run(server.list_tools())}
+        assert set(listed) == {"list_symbols", "get_symbol", "search_symbols"}
+        assert "exported_only" in listed["list_symbols"].inputSchema["properties"]
@@ -0,0 +1,172 @@
+"""
+Unit tests for function metrics.
+
+Tests cyclomatic_complexity and the line counts against hand-counted
+function bodies, and the metrics recorded on extracted symbols and package
+summaries.
+"""
+
+import pytest
+from pathlib import Path
+from ctxd.symbols import GoSymbolExtractor, Options, extract_dir, extract_file
+from ctxd.symbols.formatters import TextFormatter, describe_counts
+from ctxd.symbols.metrics import code_lines, cyclomatic_complexity, statement_count
+
+FIXTURES = Path(__file__).parent / "fixtures"
+
+
+def complexity(body: str) -> int:
+    """Count the complexity of a function with the given body statements."""
+    source = f"package a\n\nfunc f(x int, ch chan int) {{\n{body}\n}}\n"
+    tree = GoSymbolExtractor().parser.parse(source.encode("utf-8"))
+    function = tree.root_node.named_children[1]
+    return cyclomatic_complexity(function.child_by_field_name("body"))
+
+
+def line_counts(body: str) -> tuple[int, int]:
+    """Count the code lines and statements of a function with the given body."""
+    source = f"package a\n\nfunc f(x int) {{\n{body}\n}}\n"
+    tree = GoSymbolExtractor().parser.parse(source.encode("utf-8"))
+    body_node = tree.root_node.named_children[1].child_by_field_name("body")
+    return code_lines(body_node, inner=True), statement_count(body_node)
+
+
+class TestCyclomaticComplexity:
+    """Tests for counting decision points."""
+
+    @pytest.mark.parametrize("body, expected", [
+        ("return", 1),
+        ("if x > 0 { return }", 2),
+        # else adds no path, else if adds one
+        ("if x > 0 { return } else { x++ }", 2),
+        ("if x > 0 { return } else if x < 0 { return }", 3),
+        ("for i := 0; i < x; i++ {}", 2),
+        ("for range ch {}", 2),
+        ("if x > 0 && x < 10 || x == 20 { return }", 4),
+        # Each case clause counts once, however many values it lists; default does not
+        ("switch x {\ncase 1, 2:\ncase 3:\ndefault:\n}", 3),
+        ("switch any(x).(type) {\ncase int:\ncase string:\n}", 3),
+        ("select {\ncase <-ch:\ncase ch <- 1:\ndefault:\n}", 3),
+        # Function literals count toward the enclosing function
+        ("go func() {\nif x > 0 { return }\n}()", 2),
+    ])
+    def test_examples(self, body, expected):
+        """Decision points are counted as gocyclo counts them."""
+        assert complexity(body) == expected
+
+    def test_no_body(self):
+        """A function without a body, e.g. implemented in assembly, has complexity 1."""
+        assert cyclomatic_complexity(None) == 1
+
+
+class TestLinesOfCode:
+    """Tests for counting code lines and statements."""
+
+    @pytest.mark.parametrize("body, expected", [
+        ("", (0, 0)),
+        ("return", (1, 1)),
+        # Blank and comment-only lines are not code
+        ("x++\n\n// note\n/* block\ncomment */\nx--", (2, 2)),
+        # A statement split over lines is one logical line
+        ("fmt.Println(\n\tx,\n\tx,\n)", (4, 1)),
+        ("if x > 0 { return }", (1, 2)),
+        ("if x > 0 {\n\treturn\n} else {\n\tx++\n}", (5, 3)),
+        ("var (\n\ta = 1\n\tb = 2\n)\n_, _ = a, b", (5, 2)),
+        # Statements of function literals count toward the enclosing function
+        ("go func() {\n\tx++\n}()", (3, 2)),
+        ("s := `one\n\ntwo`", (3, 1)),
+        ("loop:\n\tfor {\n\t\tbreak loop\n\t}", (4, 2)),
+    ])
+    def test_examples(self, body, expected):
+        """Code lines skip blanks and comments; statements ignore formatting."""
+        assert line_counts(body) == expected
+
+    def test_one_line_body(self):
+        """Code sharing a line with the braces counts."""
+        tree = GoSymbolExtractor().parser.parse(b"package a\n\nfunc f() int { return 1 }\n")
+        body = tree.root_node.named_children[1].child_by_field_name("body")
+
+        assert (code_lines(body, inner=True), statement_count(body)) == (1, 1)
+
+    def test_no_body(self):
+        """A function without a body has no lines."""
+        assert (code_lines(None), statement_count(None)) == (0, 0)
+
+    def test_file(self):
+        """A file counts every code line, and each declaration along with the statements."""
+        content = (FIXTURES / "sample.go").read_text()
+
+        # 7 functions and methods of one statement each, 5 types, and an import
+        assert GoSymbolExtractor().count_lines(content) == (44, 20)
+        assert GoSymbolExtractor().count_lines(content + "\n\n// trailing\n") == (44, 20)
+
+
+class TestExtractedComplexity:
+    """Tests for complexity on extracted symbols."""
+
+    def test_sample(self):
+        """The sample's straight-line functions and methods all have complexity 1."""
+        symbols = extract_file(FIXTURES / "sample.go", Options(metrics=True))
+        functions = [s for s in symbols if s.kind in ("func", "method")]
+
+        assert len(functions) == 7
+        assert {s.complexity for s in functions} == {1}
+        assert all(s.complexity == 0 for s in symbols if s.kind not in ("func", "method"))
+
+    def test_off_by_default(self):
+        """Without metrics, complexity is left at 0."""
+        symbols = extract_file(FIXTURES / "sample.go")
+        assert {s.complexity for s in symbols} == {0}
+
+
+class TestExtractedLines:
+    """Tests for lines of code on extracted symbols and package summaries."""
+
+    def test_sample(self):
+        """The sample's functions are one statement each, one of them spread over four lines."""
+        symbols = extract_file(FIXTURES / "sample.go", Options(metrics=True))
+        counts = {s.local_name: (s.lines, s.loc) for s in symbols if s.kind in ("func", "method")}
+
+        assert counts == {
+            "Add": (1, 1),
+            "Multiply": (1, 1),
+            "NewCalculator": (4, 1),
+            "Calculator.Add": (1, 1),
+            "Calculator.Subtract": (1, 1),
+            "Calculator.GetValue": (1, 1),
+            "Calculator.Display": (1, 1),
+        }
+        assert all((s.lines, s.loc) == (0, 0) for s in symbols if s.kind not in ("func", "method"))
+
+    def test_off_by_default(self):
+        """Without metrics, no lines are counted."""
+        symbols = extract_file(FIXTURES / "sample.go")
+        assert {(s.lines, s.loc) for s in symbols} == {(0, 0)}
+
+    def test_package_totals(self, tmp_path):
+        """Summaries total every file of the package, whatever symbols are kept."""
+        (tmp_path / "sample.go").write_text((FIXTURES / "sample.go").read_text())
+        (tmp_path / "extra.go").write_text("package calculator\n\nfunc half(x int) int {\n\tx /= 2\n\treturn x\n}\n")
+
+        summary = extract_dir(tmp_path, Options(metrics=True, exported_only=True)).summaries["."]
+
+        assert summary.file_loc == {"extra.go": {"lines": 5, "loc": 3}, "sample.go": {"lines": 44, "loc": 20}}
+        assert (summary.lines, summary.loc) == (49, 23)
+        assert describe_counts(summary).endswith(", 49 lines of code, 23 statements")
+
+    def test_package_totals_off_by_default(self, tmp_path):
+        """Without metrics, summaries have no line counts."""
+        (tmp_path / "sample.go").write_text((FIXTURES / "sample.go").read_text())
+
+        summary = extract_dir(tmp_path).summaries["."]
+
+        assert (summary.lines, summary.loc, summary.file_loc) == (0, 0, {})
+
+    def test_text_output(self):
+        """Text output follows the complexity with the lines of code."""
+        symbols = extract_file(FIXTURES / "sample.go", Options(metrics=True))
+
+        output = TextFormatter().format(symbols)
+
+        assert "    // complexity 1, 4 lines, 1 statement" in output.splitlines()
+        assert "    // complexity 1, 1 line, 1 statement" in output.splitlines()
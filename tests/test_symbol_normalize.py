@@ -0,0 +1,175 @@
+"""
+Unit tests for type expression normalization.
+
+Tests normalize_type and normalize_signature against pairs of equivalent
+spellings, and TypeNormalizer over extracted symbols.
+"""
+
+import pytest
+from ctxd.symbols import MapFS, extract_packages, extract_source
+from ctxd.symbols.normalize import Scope, TypeNormalizer, normalize_signature, normalize_type, type_parameters
+
+GEO = Scope(package="example.com/geo")
+APP = Scope(
+    package="example.com/app",
+    imports={"geo": "example.com/geo", "g": "example.com/geo", "io": "io"},
+    dot_imports=["example.com/shapes"],
+)
+DECLARED = {
+    "example.com/app": {"Local"},
+    "example.com/geo": {"Point", "Reader"},
+    "example.com/shapes": {"Circle"},
+}
+ALIASES = {"example.com/geo.Reader": "io.Reader"}
+
+
+def same(left: tuple[str, Scope], right: tuple[str, Scope]) -> bool:
+    """Check that two type expressions, each in its scope, normalize the same."""
+    return normalize_type(left[0], left[1], DECLARED, ALIASES) == normalize_type(right[0], right[1], DECLARED, ALIASES)
+
+
+class TestNormalizeType:
+    """Tests for equivalent spellings of one type."""
+
+    @pytest.mark.parametrize("left, right", [
+        # Qualified by an importer, renamed, and written inside the package
+        (("func(geo.Point) error", APP), ("func(Point) error", GEO)),
+        (("[]g.Point", APP), ("[]geo.Point", APP)),
+        # A redundant qualifier inside the declaring package
+        (("map[string]geo.Point", GEO), ("map[string]Point", GEO)),
+        # A dot import
+        (("*Circle", APP), ("*shapes.Circle", Scope(package="example.com/x", imports={"shapes": "example.com/shapes"}))),
+        # An alias and its target
+        (("func(Reader)", GEO), ("func(io.Reader)", APP)),
+        (("chan<- geo.Reader", APP), ("chan<- io.Reader", APP)),
+        # Predeclared aliases
+        (("func(interface{}) interface{ }", APP), ("func(any) any", APP)),
+        (("map[byte][]rune", APP), ("map[uint8][]int32", APP)),
+        (("struct{ Data interface{}; Raw []byte }", APP), ("struct{ Data any; Raw []uint8 }", APP)),
+        # Parameter names of func types are not type names
+        (("func(func(n int) error) error", APP), ("func(func(n int) error) error", GEO)),
+        (("func(cb func(p geo.Point)) error", APP), ("func(cb func(p Point)) error", GEO)),
+    ])
+    def test_equivalent(self, left, right):
+        """Spellings of the same type normalize the same."""
+        assert same(left, right)
+
+    @pytest.mark.parametrize("left, right", [
+        # The same name in different packages
+        (("func(Point)", GEO), ("func(Point)", Scope(package="example.com/other"))),
+        (("Local", APP), ("Local", GEO)),
+        # Field names are kept
+        (("struct{ X int }", APP), ("struct{ Y int }", APP)),
+        (("interface{ Read() }", APP), ("any", APP)),
+        (("[]int", APP), ("[]int64", APP)),
+    ])
+    def test_different(self, left, right):
+        """Different types stay different."""
+        assert not same(left, right)
+
+    def test_canonical_form(self):
+        """Names are qualified by import path; predeclared and type parameter names are not."""
+        scope = Scope(package="example.com/app", imports={"geo": "example.com/geo"}, type_params={"T"})
+
+        assert normalize_type("func(geo.Point, []T, Local, interface{}) error", scope) == \
+            "func(example.com/geo.Point, []T, example.com/app.Local, any) error"
+
+    def test_func_parameter_names(self):
+        """Parameter names in func types are kept as written."""
+        assert normalize_type("func(func(n int) error) error", APP) == "func(func(n int) error) error"
+
+    def test_predeclared_names_shadowed(self):
+        """A package's own type named like a predeclared one is not the predeclared type."""
+        declared = {"example.com/app": {"byte"}}
+
+        assert normalize_type("[]byte", APP, declared) == "[]example.com/app.byte"
+
+    def test_unknown_qualifiers_kept(self):
+        """Qualifiers that name no import are kept as written."""
+        assert normalize_type("yaml.Node", APP) == "yaml.Node"
+
+    def test_ambiguous_dot_imports(self):
+        """Without knowing which dot import declares a name, it stays local."""
+        scope = Scope(package="example.com/app", dot_imports=["strings", "bytes"])
+
+        assert normalize_type("*Builder", scope, {"example.com/app": set()}) == "*example.com/app.Builder"
+        assert normalize_type("*Builder", Scope(package="example.com/app", dot_imports=["strings"]), {"example.com/app": set()}) == \
+            "*strings.Builder"
+
+    def test_unparsable(self):
+        """Text that is not a type is returned as given."""
+        assert normalize_type("map[string", APP) == "map[string"
+
+
+class TestNormalizeSignature:
+    """Tests for normalizing the types in a signature."""
+
+    def test_function(self):
+        """Parameter names stay, types are normalized."""
+        assert normalize_signature("func Load(r geo.Reader, data []byte) interface{}", "func", APP, DECLARED, ALIASES) == \
+            "func Load(r io.Reader, data []uint8) any"
+
+    def test_generic(self):
+        """Type parameters, declared or on a receiver, are not qualified."""
+        scope = Scope(package="example.com/app")
+
+        assert normalize_signature("func Map[T, U any](s []T, f func(T) U) []U", "func", scope) == \
+            "func Map[T, U any](s []T, f func(T) U) []U"
+        assert normalize_signature("type Set[K comparable] struct", "struct", scope) == "type Set[K comparable] struct"
+
+    def test_members(self):
+        """Fields and interface methods are read as members of their type."""
+        assert normalize_signature("Data interface{} `json:\"data\"`", "field", APP) == "Data any `json:\"data\"`"
+        assert normalize_signature("Write(p []byte) (n int, err error)", "method", APP) == "Write(p []uint8) (n int, err error)"
+        assert normalize_signature("type Handler interface", "interface", APP) == "type Handler interface"
+
+    def test_type_parameters(self):
+        """Type parameters are read from signatures and generic receivers."""
+        symbols = extract_source(
+            "package p\n\ntype Stack[T any, C interface{ ~[]T }] struct{}\n\n"
+            "func (s *Stack[T, C]) Push(v T) {}\n\nfunc Map[T, U any](x T) U { var u U; return u }\n",
+            "p.go",
+        )
+
+        assert [type_parameters(s) for s in symbols] == [{"T", "C"}, {"T", "C"}, {"T", "U"}]
+
+
+class TestTypeNormalizer:
+    """Tests for normalizing extracted symbols against their tree."""
+
+    @pytest.fixture
+    def packages(self):
+        """A module whose packages spell the same types differently."""
+        return extract_packages(MapFS({
+            "go.mod": "module example.com/app\n",
+            "geo/geo.go": (
+                "package geo\n\nimport \"io\"\n\ntype Point struct{}\n\ntype Source = io.Reader\n\n"
+                "type Mover interface {\n\tMove(p Point, src Source) interface{}\n}\n"
+            ),
+            "app/app.go": (
+                "package app\n\nimport (\n\t\"io\"\n\n\t. \"example.com/app/geo\"\n)\n\n"
+                "type Car struct{}\n\nfunc (c *Car) Move(p Point, src io.Reader) any { return nil }\n"
+            ),
+        }))
+
+    def test_across_packages(self, packages):
+        """A method written with a dot import and an alias target matches the interface's spelling."""
+        symbols = [s for package in packages.values() for s in package]
+        types = TypeNormalizer(symbols)
+        mover = next(s for s in symbols if s.name == "Mover")
+        move = next(s for s in symbols if s.kind == "method" and s.name == "Move")
+
+        assert types.symbol_type(move) == types.symbol_type(mover.methods[0], mover) == \
+            "func(example.com/app/geo.Point, io.Reader) any"
+
+    def test_implements(self, packages):
+        """Implementations are found across the respellings."""
+        car = next(s for s in packages["example.com/app/app"] if s.name == "Car")
+
+        assert car.pointer_implements == ["example.com/app/geo.Mover"]
+
+    def test_alias_cycles(self):
+        """Invalid alias cycles stop instead of recursing forever."""
+        symbols = extract_source("package p\n\ntype A = B\n\ntype B = A\n\nfunc F(a A) {}\n", "p.go")
+
+        assert TypeNormalizer(symbols).symbol_type(symbols[-1]) in ("func(A)", "func(B)")
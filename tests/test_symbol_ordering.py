@@ -0,0 +1,116 @@
+"""
+Unit tests for canonical symbol ordering.
+
+Tests sort_symbols and that repeated extractions render identically.
+"""
+
+import random
+import pytest
+from pathlib import Path
+from ctxd.symbols import GoSymbolExtractor, Options, Symbol, extract_dir, get_formatter, sort_symbols
+from ctxd.symbols.formatters import FORMATTERS
+from conftest import write
+
+FIXTURES = Path(__file__).parent / "fixtures"
+
+
+def make(name: str, kind: str, file: str = "a.go", line: int = 1, receiver: str = "", package: str = "") -> Symbol:
+    """Build a minimal symbol."""
+    return Symbol(name=name, kind=kind, file=file, line=line, signature=name, receiver=receiver, package=package)
+
+
+class TestSortSymbols:
+    """Tests for the canonical sort."""
+
+    def test_sample_order(self):
+        """The sample sorts by kind, then name, with methods after their type."""
+        symbols = sort_symbols(GoSymbolExtractor().extract_file(FIXTURES / "sample.go"))
+
+        assert [(s.kind, s.name) for s in symbols] == [
+            ("interface", "Adder"),
+            ("interface", "MathOperator"),
+            ("interface", "Multiplier"),
+            ("struct", "Calculator"),
+            ("method", "Add"),
+            ("method", "Display"),
+            ("method", "GetValue"),
+            ("method", "Subtract"),
+            ("struct", "Point"),
+            ("func", "Add"),
+            ("func", "Multiply"),
+            ("func", "NewCalculator"),
+        ]
+
+    def test_input_order_irrelevant(self):
+        """Any permutation of the input sorts to the same order."""
+        symbols = GoSymbolExtractor().extract_file(FIXTURES / "sample.go")
+        shuffled = symbols[:]
+        random.Random(7).shuffle(shuffled)
+
+        assert sort_symbols(shuffled) == sort_symbols(symbols)
+
+    def test_file_before_kind(self):
+        """File path is the primary key within a package."""
+        symbols = sort_symbols([make("A", "struct", "b.go"), make("Z", "func", "a.go")])
+
+        assert [s.name for s in symbols] == ["Z", "A"]
+
+    def test_methods_follow_type_across_files(self):
+        """Methods in a sibling file sort right after their receiver type."""
+        symbols = sort_symbols([
+            make("Close", "method", "pkg/conn_close.go", receiver="*Conn"),
+            make("Conn", "struct", "pkg/conn.go"),
+            make("Dial", "func", "pkg/conn.go"),
+        ])
+
+        assert [s.name for s in symbols] == ["Conn", "Close", "Dial"]
+
+    def test_orphan_methods_sort_as_methods(self):
+        """Methods whose type is elsewhere sort last in their own file."""
+        symbols = sort_symbols([
+            make("Close", "method", "a.go", receiver="*Conn"),
+            make("Dial", "func", "a.go"),
+            make("Conn", "struct", "other/conn.go"),
+        ])
+
+        assert [s.name for s in symbols] == ["Dial", "Close", "Conn"]
+
+    def test_line_tiebreak(self):
+        """Symbols that are otherwise equal sort by start line."""
+        symbols = sort_symbols([make("init", "func", line=9), make("init", "func", line=3)])
+
+        assert [s.line for s in symbols] == [3, 9]
+
+    def test_packages_stay_contiguous(self):
+        """Symbols of one package are never split by another package's files."""
+        symbols = sort_symbols([
+            make("C", "func", "a/c.go", package="m/a"),
+            make("B", "func", "a/b/b.go", package="m/a/b"),
+            make("A", "func", "a/a.go", package="m/a"),
+        ])
+
+        assert [s.package for s in symbols] == ["m/a", "m/a", "m/a/b"]
+
+    def test_external_test_package(self, tmp_path):
+        """Files of an external test package sort by path among those of the package in its directory."""
+        write(tmp_path, "go.mod", "module m\n")
+        write(tmp_path, "foo/a.go", "package foo\n\nfunc A() {}\n")
+        write(tmp_path, "foo/b_test.go", "package foo_test\n\nfunc B() {}\n")
+        write(tmp_path, "foo/c.go", "package foo\n\nfunc C() {}\n")
+
+        symbols = sort_symbols(extract_dir(tmp_path, Options(recursive=True, include_tests=True, cache=False)).symbols())
+
+        assert [Path(s.file).name for s in symbols] == ["a.go", "b_test.go", "c.go"]
+
+
+class TestDeterministicOutput:
+    """Tests that rendering is reproducible."""
+
+    @pytest.mark.parametrize("format_name", sorted(FORMATTERS))
+    def test_byte_identical(self, format_name):
+        """Parsing the sample twice renders byte-identical output."""
+        def render() -> bytes:
+            symbols = sort_symbols(GoSymbolExtractor().extract_file(FIXTURES / "sample.go"))
+            return get_formatter(format_name).format(symbols).encode("utf-8")
+
+        assert render() == render()
@@ -0,0 +1,113 @@
+"""
+Unit tests for per-package output files.
+
+Tests file naming, the JSON index, and atomic replacement of existing files.
+"""
+
+import json
+import pytest
+from ctxd.symbols import JsonFormatter, MapFS, MarkdownFormatter, Options, PackageSummary, TextFormatter, extract_fs
+from ctxd.symbols.output import package_filename, write_atomic, write_packages
+
+FILES = {
+    "go.mod": "module example.com/shapes\n",
+    "shapes.go": "package shapes\n\nfunc Area() int { return 0 }\n",
+    "geo/point.go": "// Package geo has points.\npackage geo\n\n// Point is a point.\ntype Point struct{}\n",
+}
+
+
+@pytest.fixture
+def index():
+    """Index of a module with two packages."""
+    return extract_fs(MapFS(FILES), options=Options(recursive=True))
+
+
+class TestPackageFilename:
+    """Tests for naming package files."""
+
+    def test_import_path(self):
+        """Import paths become nested file names."""
+        summary = PackageSummary(name="geo")
+
+        assert package_filename("example.com/shapes/geo", summary, "md") == "example.com/shapes/geo.md"
+
+    def test_walked_directory(self):
+        """The walked directory of a tree without go.mod is named after its package."""
+        assert package_filename(".", PackageSummary(name="shapes"), "json") == "shapes.json"
+
+    def test_escaping_path(self):
+        """Paths leaving the output directory are refused."""
+        with pytest.raises(ValueError, match="Cannot name"):
+            package_filename("../shapes", PackageSummary(name="shapes"), "json")
+
+
+class TestWritePackages:
+    """Tests for write_packages."""
+
+    def test_markdown(self, tmp_path, index):
+        """Each package gets its own file, in nested directories, and no index."""
+        written = write_packages(tmp_path / "out", index.summaries, index.symbols(), MarkdownFormatter())
+
+        assert [p.relative_to(tmp_path / "out").as_posix() for p in written] == [
+            "example.com/shapes.md",
+            "example.com/shapes/geo.md",
+        ]
+        geo = (tmp_path / "out" / "example.com" / "shapes" / "geo.md").read_text()
+        assert "### Point" in geo
+        assert "Area" not in geo
+
+    def test_json_index(self, tmp_path, index):
+        """JSON output adds an index of the package summaries and their files."""
+        write_packages(tmp_path, index.summaries, index.symbols(), JsonFormatter())
+
+        packages = json.loads((tmp_path / "index.json").read_text())["packages"]
+        assert [(p["path"], p["file"]) for p in packages] == [
+            ("example.com/shapes", "example.com/shapes.json"),
+            ("example.com/shapes/geo", "example.com/shapes/geo.json"),
+        ]
+        geo = json.loads((tmp_path / "example.com" / "shapes" / "geo.json").read_text())
+        assert [s["name"] for s in geo] == ["Point"]
+
+    def test_with_summary(self, tmp_path, index):
+        """With summaries, each file starts with its own package's summary only."""
+        write_packages(tmp_path, index.summaries, index.symbols(), TextFormatter(), with_summary=True)
+
+        geo = (tmp_path / "example.com" / "shapes" / "geo.txt").read_text()
+        assert geo.startswith("// package geo (example.com/shapes/geo): 1 file, 1 struct\n//   Package geo has points.")
+
+    def test_index_collision(self, tmp_path):
+        """A package that would overwrite the index is an error."""
+        summaries = {"index": PackageSummary(name="index", path="index")}
+
+        with pytest.raises(ValueError, match="would both be written to index.json"):
+            write_packages(tmp_path, summaries, [], JsonFormatter())
+
+
+class TestWriteAtomic:
+    """Tests for write_atomic."""
+
+    def test_replaces_without_leftovers(self, tmp_path):
+        """An existing file is replaced and no temporary file remains."""
+        path = tmp_path / "a" / "b.json"
+        write_atomic(path, "old")
+        write_atomic(path, "new")
+
+        assert path.read_text() == "new"
+        assert [p.name for p in path.parent.iterdir()] == ["b.json"]
+
+    def test_failed_write_keeps_old_file(self, tmp_path, monkeypatch):
+        """When the rename fails, the old contents stay and the temporary file is removed."""
+        import os
+
+        path = tmp_path / "b.json"
+        path.write_text("old")
+
+        def fail(src, dst):
+            raise OSError("disk full")
+
+        monkeypatch.setattr(os, "replace", fail)
+        with pytest.raises(OSError, match="disk full"):
+            write_atomic(path, "new")
+
+        assert path.read_text() == "old"
+        assert [p.name for p in tmp_path.iterdir()] == ["b.json"]
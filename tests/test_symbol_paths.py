@@ -0,0 +1,113 @@
+"""
+Unit tests for output file paths.
+
+Tests each path mode, separator normalization, rewriting of symbols and
+their members, and validation of modes.
+"""
+
+import os
+import pytest
+from pathlib import Path
+from ctxd.symbols import ConfigError, Options, ParseError, extract_dir, extract_source, load_options, rewrite_paths
+from ctxd.symbols.paths import display_path
+
+FIXTURES = Path(__file__).parent / "fixtures"
+
+
+class TestDisplayPath:
+    """Tests for rendering one path."""
+
+    @pytest.mark.parametrize("mode,expected", [
+        ("rel", "calc/calc.go"),
+        ("abs", os.path.abspath("pkg/calc/calc.go")),
+        ("base", "calc.go"),
+    ])
+    def test_modes(self, mode, expected):
+        """Each mode renders a walked path its own way."""
+        assert display_path("pkg/calc/calc.go", Path("pkg"), mode) == expected
+
+    def test_rel_from_absolute(self, tmp_path):
+        """A path walked from an absolute root is made relative to it."""
+        assert display_path(str(tmp_path / "a" / "b.go"), tmp_path, "rel") == "a/b.go"
+
+    def test_rel_mixed_forms(self, tmp_path, monkeypatch):
+        """A relative root and absolute paths, or the reverse, give the same result."""
+        monkeypatch.chdir(tmp_path)
+        assert display_path(str(tmp_path / "pkg" / "a.go"), Path("pkg"), "rel") == "a.go"
+        assert display_path("pkg/a.go", tmp_path / "pkg", "rel") == "a.go"
+
+    @pytest.mark.parametrize("mode", ["rel", "base"])
+    def test_backslashes(self, mode):
+        """Windows separators become forward slashes."""
+        path = display_path("pkg\\calc\\calc.go", Path("."), mode)
+        assert "\\" not in path
+        assert path.endswith("calc.go")
+
+    @pytest.mark.parametrize("mode", ["rel", "abs", "base"])
+    def test_stdin(self, mode):
+        """Names that are not paths on disk are kept."""
+        assert display_path("<stdin>", Path("pkg"), mode) == "<stdin>"
+
+    def test_invalid_mode(self):
+        """An unknown mode is an error."""
+        with pytest.raises(ValueError, match="Invalid path mode"):
+            display_path("a.go", Path("."), "relative")
+
+
+class TestRewritePaths:
+    """Tests for rewriting the paths of symbols and other records."""
+
+    def test_symbols_and_members(self):
+        """Symbols are copied with their fields and methods rewritten, and left unchanged."""
+        symbols = extract_dir(FIXTURES, Options()).symbols()
+
+        rewritten = rewrite_paths(symbols, FIXTURES, "abs")
+
+        assert [s.name for s in rewritten] == [s.name for s in symbols]
+        members = [m for s in rewritten for m in s.fields + s.methods]
+        assert members
+        assert all(m.file == os.path.abspath(m.file) for s in rewritten for m in [s] + s.fields + s.methods)
+        assert all(s.file == str(FIXTURES / Path(s.file).name) for s in symbols)
+
+    def test_same_from_anywhere(self, monkeypatch):
+        """rel output does not depend on the working directory."""
+        absolute = rewrite_paths(extract_dir(FIXTURES, Options()).symbols(), FIXTURES, "rel")
+        monkeypatch.chdir(FIXTURES.parent)
+        relative = rewrite_paths(extract_dir(Path("fixtures"), Options()).symbols(), Path("fixtures"), "rel")
+
+        assert [s.file for s in relative] == [s.file for s in absolute]
+        assert all("/" not in s.file for s in relative)
+
+    def test_other_records(self):
+        """Records other than symbols are rewritten by their `file`."""
+        error = ParseError(file="pkg/bad.go", line=3, column=1, message="unexpected")
+
+        assert rewrite_paths([error], Path("pkg"), "base") == [ParseError(file="bad.go", line=3, column=1, message="unexpected")]
+
+    def test_stdin_source(self):
+        """Source read from stdin keeps its file name."""
+        symbols = extract_source("package p\n\nfunc F() {}\n", "<stdin>")
+
+        assert [s.file for s in rewrite_paths(symbols, Path("."), "abs")] == ["<stdin>"]
+
+    def test_invalid_mode(self):
+        """An unknown mode is an error even with nothing to rewrite."""
+        with pytest.raises(ValueError, match="Invalid path mode"):
+            rewrite_paths([], Path("."), "full")
+
+
+class TestConfig:
+    """Tests for path_mode in config files."""
+
+    def test_loaded(self, tmp_path):
+        """path_mode is read from the config file."""
+        (tmp_path / ".ctxd.yaml").write_text("path_mode: abs\n")
+
+        assert load_options(start=tmp_path).path_mode == "abs"
+
+    def test_invalid(self, tmp_path):
+        """An unknown mode is rejected."""
+        (tmp_path / ".ctxd.yaml").write_text("path_mode: full\n")
+
+        with pytest.raises(ConfigError, match="Invalid path_mode"):
+            load_options(start=tmp_path)
@@ -0,0 +1,297 @@
+"""
+Unit tests for include/exclude glob patterns.
+
+Tests compile_glob, PathFilter, IgnoreRules, and pattern-scoped and
+ignore-file directory walks.
+"""
+
+import pytest
+from ctxd.symbols import MapFS, extract_packages, find_go_files
+from ctxd.symbols.patterns import IgnoreRules, PathFilter, compile_glob
+from ctxd.symbols.walker import is_ignored
+
+
+NESTED_FILES = {
+    "go.mod": "module example.com/app\n\ngo 1.22\n",
+    "main.go": "package main\n\nfunc main() {}\n",
+    "pkg/api/api.go": "package api\n\nfunc Serve() {}\n",
+    "pkg/api/api_gen.go": "package api\n\nfunc Generated() {}\n",
+    "pkg/api/v2/api.go": "package v2\n\nfunc ServeV2() {}\n",
+    "pkg/store/store.go": "package store\n\nfunc Open() {}\n",
+    "internal/auth/auth.go": "package auth\n\nfunc Check() {}\n",
+    "internal/auth/token/token.go": "package token\n\nfunc Issue() {}\n",
+}
+
+
+@pytest.fixture
+def nested_tree():
+    """A module with public, internal, and generated code at several depths."""
+    return MapFS(NESTED_FILES)
+
+
+def with_files(extra: dict[str, str]) -> MapFS:
+    """The nested tree plus more files, such as ignore files."""
+    return MapFS({**NESTED_FILES, **extra})
+
+
+def relative(files: list[MapFS]) -> list[str]:
+    """Render tree paths for comparison."""
+    return [str(p) for p in files]
+
+
+class TestCompileGlob:
+    """Tests for doublestar glob translation."""
+
+    @pytest.mark.parametrize("pattern,path", [
+        ("*.go", "main.go"),
+        ("pkg/*/api.go", "pkg/api/api.go"),
+        ("pkg/**/*.go", "pkg/api.go"),
+        ("pkg/**/*.go", "pkg/api/v2/api.go"),
+        ("**/*_gen.go", "api_gen.go"),
+        ("**/*_gen.go", "pkg/api/api_gen.go"),
+        ("internal/**", "internal"),
+        ("internal/**", "internal/auth/token/token.go"),
+        ("**", "a/b/c.go"),
+        ("pkg/{api,store}/*.go", "pkg/store/store.go"),
+        ("file?.go", "file1.go"),
+        ("[a-c]*.go", "b.go"),
+        ("[!a]*.go", "b.go"),
+        ("\\*.go", "*.go"),
+    ])
+    def test_matches(self, pattern, path):
+        """Patterns match the paths doublestar would."""
+        assert compile_glob(pattern).fullmatch(path)
+
+    @pytest.mark.parametrize("pattern,path", [
+        ("*.go", "pkg/main.go"),
+        ("pkg/*/api.go", "pkg/api/v2/api.go"),
+        ("internal/**", "internals/x.go"),
+        ("**/*_gen.go", "pkg/api/api.go"),
+        ("pkg/{api,store}/*.go", "pkg/cache/cache.go"),
+        ("file?.go", "file10.go"),
+        ("file?.go", "file/.go"),
+        ("[!a]*.go", "a.go"),
+        ("\\*.go", "x.go"),
+    ])
+    def test_rejects(self, pattern, path):
+        """Single stars and classes stay within one path segment."""
+        assert not compile_glob(pattern).fullmatch(path)
+
+    @pytest.mark.parametrize("pattern", ["pkg/{api", "[abc", "abc\\"])
+    def test_invalid(self, pattern):
+        """Malformed patterns raise a clear error."""
+        with pytest.raises(ValueError, match="Invalid glob pattern"):
+            compile_glob(pattern)
+
+
+class TestPathFilter:
+    """Tests for combining include and exclude patterns."""
+
+    def test_empty_selects_everything(self):
+        """Without patterns every path passes."""
+        paths = PathFilter()
+
+        assert not paths
+        assert paths.matches("any/file.go")
+
+    def test_exclude_wins(self):
+        """A path matching both an include and an exclude is excluded."""
+        paths = PathFilter(include=["pkg/**"], exclude=["**/*_gen.go"])
+
+        assert paths.matches("pkg/api/api.go")
+        assert not paths.matches("pkg/api/api_gen.go")
+        assert not paths.matches("main.go")
+
+    def test_backslashes_normalized(self):
+        """Windows-style separators match slash patterns."""
+        assert PathFilter(include=["pkg/**"]).matches("pkg\\api\\api.go")
+
+
+class TestScopedWalk:
+    """Tests for include/exclude on directory walks."""
+
+    def test_exclude_directory(self, nested_tree):
+        """Excluding a subtree drops every nested package in it."""
+        files = find_go_files(nested_tree, exclude=["internal/**"])
+
+        assert relative(files) == [
+            "main.go",
+            "pkg/api/api.go",
+            "pkg/api/api_gen.go",
+            "pkg/api/v2/api.go",
+            "pkg/store/store.go",
+        ]
+
+    def test_include_nested(self, nested_tree):
+        """Include patterns with ** match at every depth below the prefix."""
+        files = find_go_files(nested_tree, include=["pkg/**/*.go"])
+
+        assert relative(files) == [
+            "pkg/api/api.go",
+            "pkg/api/api_gen.go",
+            "pkg/api/v2/api.go",
+            "pkg/store/store.go",
+        ]
+
+    def test_overlapping_patterns(self, nested_tree):
+        """Several includes union, and excludes win over them."""
+        files = find_go_files(
+            nested_tree,
+            include=["pkg/api/**", "internal/**"],
+            exclude=["**/*_gen.go", "internal/auth/token/**"],
+        )
+
+        assert relative(files) == [
+            "internal/auth/auth.go",
+            "pkg/api/api.go",
+            "pkg/api/v2/api.go",
+        ]
+
+    def test_non_recursive_scope(self, nested_tree):
+        """Patterns only narrow the files the walk would find anyway."""
+        assert find_go_files(nested_tree, recursive=False, include=["**"]) == [nested_tree / "main.go"]
+
+    def test_extract_packages(self, nested_tree):
+        """Package extraction honors the patterns."""
+        packages = extract_packages(nested_tree, include=["pkg/**"], exclude=["pkg/api/v2/**"])
+
+        assert list(packages) == ["example.com/app/pkg/api", "example.com/app/pkg/store"]
+
+
+class TestIgnoreRules:
+    """Tests for .gitignore-style rules."""
+
+    def test_unanchored_matches_any_depth(self):
+        """A pattern without a slash matches names at every depth."""
+        rules = IgnoreRules().with_file("*_gen.go\n")
+
+        assert rules.ignored("api_gen.go")
+        assert rules.ignored("pkg/api/api_gen.go")
+        assert not rules.ignored("pkg/api/api.go")
+
+    def test_anchored(self):
+        """A pattern with a slash matches relative to the file's directory."""
+        rules = IgnoreRules().with_file("/gen\npkg/*.go\n")
+
+        assert rules.ignored("gen", is_dir=True)
+        assert not rules.ignored("pkg/gen", is_dir=True)
+        assert rules.ignored("pkg/a.go")
+        assert not rules.ignored("pkg/sub/a.go")
+
+    def test_negation(self):
+        """A later `!` rule re-includes what an earlier one ignored."""
+        rules = IgnoreRules().with_file("# generated code\n\n*_gen.go\n!keep_gen.go\n")
+
+        assert rules.ignored("api_gen.go")
+        assert not rules.ignored("keep_gen.go")
+        assert not rules.ignored("pkg/keep_gen.go")
+
+    def test_dir_only(self):
+        """A trailing slash only matches directories."""
+        rules = IgnoreRules().with_file("build/\n")
+
+        assert rules.ignored("build", is_dir=True)
+        assert not rules.ignored("build")
+
+    def test_nested_file_rules(self):
+        """Rules of a nested file only apply below it, and win over its parent's."""
+        rules = IgnoreRules().with_file("*.pb.go\n").with_file("!api.pb.go\nlocal.go\n", "pkg")
+
+        assert rules.ignored("api.pb.go")
+        assert not rules.ignored("pkg/api.pb.go")
+        assert rules.ignored("pkg/sub/local.go")
+        assert not rules.ignored("local.go")
+
+    def test_parent_rules(self):
+        """Files above the walk root match against paths from their own directory."""
+        rules = IgnoreRules(prefix="pkg/api/").with_file("/pkg/api/v2/\n", "../..")
+
+        assert rules.ignored("v2", is_dir=True)
+        assert not rules.ignored("v3", is_dir=True)
+
+    def test_invalid_pattern(self):
+        """Invalid patterns report the file and line."""
+        with pytest.raises(ValueError, match="^.ctxdignore:3: "):
+            IgnoreRules().with_file("a.go\n\n[a-\n", source=".ctxdignore")
+
+
+class TestIgnoreFiles:
+    """Tests for .ctxdignore files in directory walks."""
+
+    def test_nested_ignore_files(self):
+        """The nearest ignore file wins, and only applies below its directory."""
+        tree = with_files({
+            ".ctxdignore": "*_gen.go\ntoken/\n",
+            "pkg/api/.ctxdignore": "!api_gen.go\nv2/\n",
+        })
+
+        assert relative(find_go_files(tree)) == [
+            "internal/auth/auth.go",
+            "main.go",
+            "pkg/api/api.go",
+            "pkg/api/api_gen.go",
+            "pkg/store/store.go",
+        ]
+
+    def test_directory_contents_reincluded(self):
+        """`dir/**` ignores a directory's contents, so negations can re-include some."""
+        tree = with_files({
+            ".ctxdignore": "internal/**\n!internal/auth/\n!internal/auth/auth.go\n",
+        })
+
+        files = relative(find_go_files(tree))
+
+        assert "internal/auth/auth.go" in files
+        assert "internal/auth/token/token.go" not in files
+
+    def test_ignored_directory_stays_ignored(self):
+        """Nothing below an ignored directory can be re-included."""
+        tree = with_files({
+            ".ctxdignore": "internal/\n!internal/auth/auth.go\n",
+        })
+
+        assert not any(f.startswith("internal/") for f in relative(find_go_files(tree)))
+
+    def test_composes_with_exclude(self):
+        """--exclude and the built-in exclusions still apply; negations cannot undo them."""
+        tree = with_files({
+            ".ctxdignore": "pkg/store/\n!vendor/\n",
+            "vendor/dep/dep.go": "package dep\n",
+        })
+
+        files = find_go_files(tree, exclude=["internal/**", "**/*_gen.go"])
+
+        assert relative(files) == ["main.go", "pkg/api/api.go", "pkg/api/v2/api.go"]
+
+    def test_parent_ignore_files(self, tmp_path):
+        """Walking a subdirectory applies the ignore files up to the module root."""
+        (tmp_path / "go.mod").write_text("module example.com/app\n")
+        (tmp_path / ".ctxdignore").write_text("/pkg/api/old.go\n*_gen.go\n")
+        for name in ("api.go", "old.go", "api_gen.go"):
+            (tmp_path / "pkg" / "api").mkdir(parents=True, exist_ok=True)
+            (tmp_path / "pkg" / "api" / name).write_text("package api\n")
+
+        files = find_go_files(tmp_path / "pkg" / "api")
+
+        assert [p.name for p in files] == ["api.go"]
+
+    def test_is_ignored(self):
+        """is_ignored() agrees with the walk, checking parent directories too."""
+        tree = with_files({
+            ".ctxdignore": "internal/\n",
+            "pkg/.ctxdignore": "*_gen.go\n",
+        })
+
+        assert is_ignored(tree, "internal/auth/auth.go")
+        assert is_ignored(tree, "pkg/api/api_gen.go")
+        assert not is_ignored(tree, "pkg/api/api.go")
+        assert not is_ignored(tree, "main.go")
+
+    def test_invalid_ignore_file(self):
+        """An invalid ignore file fails the walk with its path and line."""
+        tree = with_files({
+            "pkg/.ctxdignore": "[\n",
+        })
+
+        with pytest.raises(ValueError, match=".ctxdignore:1: "):
+            find_go_files(tree)
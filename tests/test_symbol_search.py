@@ -0,0 +1,98 @@
+"""
+Unit tests for fuzzy symbol search.
+
+Tests subsequence matching, scoring of word starts and runs, and the
+ranking of name and doc matches.
+"""
+
+from ctxd.symbols import Symbol
+from ctxd.symbols.search import fuzzy_match, search
+
+
+def symbol(name: str, receiver: str = "", doc: str = "") -> Symbol:
+    """A function, or a method of receiver, with a doc comment."""
+    return Symbol(
+        name=name,
+        kind="method" if receiver else "func",
+        signature=f"func {name}()",
+        file="calc.go",
+        line=1,
+        receiver=receiver,
+        doc=doc,
+    )
+
+
+def names(matches):
+    """Local names of matches, in order."""
+    return [m.symbol.local_name for m in matches]
+
+
+class TestFuzzyMatch:
+    """Tests for fuzzy_match."""
+
+    def test_subsequence(self):
+        """Characters must appear in order, ignoring case."""
+        assert fuzzy_match("cadd", "Calculator.Add")[1] == [3, 11, 12, 13]
+        assert fuzzy_match("ADD", "Calculator.Add") is not None
+        assert fuzzy_match("dda", "Calculator.Add") is None
+        assert fuzzy_match("", "Add") is None
+
+    def test_shortest_window(self):
+        """The match is narrowed to the last start that still matches."""
+        assert fuzzy_match("add", "Calculator.Add")[1] == [11, 12, 13]
+
+    def test_word_starts_score_higher(self):
+        """Matches at word starts beat matches inside words."""
+        camel, _ = fuzzy_match("pu", "ParseURL")
+        inside, _ = fuzzy_match("pu", "parseurl")
+        assert camel > inside
+
+    def test_runs_score_higher(self):
+        """Consecutive matches beat scattered ones."""
+        run, _ = fuzzy_match("sum", "Summary")
+        scattered, _ = fuzzy_match("sum", "SetupMain")
+        assert run > scattered
+
+
+class TestSearch:
+    """Tests for search."""
+
+    def test_exact_and_prefix_first(self):
+        """Exact names, then prefixes, outrank better-scattered matches."""
+        symbols = [symbol("ReadAll"), symbol("Read", "File"), symbol("Reader"), symbol("Read")]
+
+        assert names(search(symbols, "read")) == ["Read", "File.Read", "Reader", "ReadAll"]
+
+    def test_method_by_type(self):
+        """Methods match their local name, so the type narrows the query."""
+        symbols = [symbol("Add"), symbol("Add", "Calculator")]
+
+        assert names(search(symbols, "calcadd")) == ["Calculator.Add"]
+
+    def test_doc_matches_last(self):
+        """Doc matches are only found with in_doc, and rank after names."""
+        symbols = [symbol("Reset", doc="Reset clears the total."), symbol("Total"), symbol("Sum", doc="Sum returns the total.")]
+
+        assert names(search(symbols, "total")) == ["Total"]
+        matches = search(symbols, "total", in_doc=True)
+        assert names(matches) == ["Total", "Sum", "Reset"]
+        assert [m.matched for m in matches] == ["name", "doc", "doc"]
+
+    def test_limit(self):
+        """Only the best matches are kept."""
+        symbols = [symbol("Addx"), symbol("Add"), symbol("Adder")]
+
+        assert names(search(symbols, "add", limit=2)) == ["Add", "Addx"]
+
+    def test_blank_query(self):
+        """A blank query matches nothing."""
+        assert search([symbol("Add")], "  ") == []
+
+    def test_to_dict(self):
+        """Matches serialize as their symbol plus score and matched field."""
+        [match] = search([symbol("Add")], "add")
+
+        data = match.to_dict()
+        assert data["name"] == "Add"
+        assert data["matched"] == "name"
+        assert data["score"] == match.score
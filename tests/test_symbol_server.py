@@ -0,0 +1,382 @@
+"""
+Unit tests for the symbols HTTP API.
+
+Tests SymbolServer request handling against a small module tree, change
+events, and round trips over a real socket for headers, CORS, and /events.
+"""
+
+import base64
+import json
+import os
+import socket
+import struct
+import threading
+import urllib.error
+import urllib.request
+import pytest
+from ctxd.symbols import Options
+from ctxd.symbols import websocket
+from ctxd.symbols.server import Subscription, SymbolServer, parse_addr
+from conftest import write
+
+
+@pytest.fixture
+def server(tmp_path):
+    """A server over a module with two packages that both declare New."""
+    write(tmp_path, "go.mod", "module example.com/shapes\n\ngo 1.22\n")
+    write(tmp_path, "shapes.go", "// Package shapes draws shapes.\npackage shapes\n\n// New makes a shape.\nfunc New() int { return 0 }\n\nfunc scale() int { return 1 }\n")
+    write(tmp_path, "geo/point.go", "package geo\n\n// Point is a point.\ntype Point struct{}\n\nfunc (p Point) X() int { return 0 }\n\nfunc New() Point { return Point{} }\n")
+    return SymbolServer(tmp_path, Options(recursive=True))
+
+
+class TestParseAddr:
+    """Tests for listen address parsing."""
+
+    def test_host_and_port(self):
+        """HOST:PORT is split; an empty host means all interfaces."""
+        assert parse_addr("localhost:8080") == ("localhost", 8080)
+        assert parse_addr(":8080") == ("0.0.0.0", 8080)
+        assert parse_addr("[::1]:9000") == ("::1", 9000)
+
+    @pytest.mark.parametrize("addr", ["8080", "localhost", "localhost:http", ":70000"])
+    def test_invalid(self, addr):
+        """Addresses without a numeric port are rejected."""
+        with pytest.raises(ValueError, match="Invalid address"):
+            parse_addr(addr)
+
+
+class TestSymbolServer:
+    """Tests for the API endpoints."""
+
+    def test_list_symbols(self, server):
+        """/symbols lists every symbol of the tree."""
+        status, body = server.handle("GET", "/symbols")
+
+        assert status == 200
+        assert [s["name"] for s in body] == ["New", "scale", "Point", "X", "New"]
+
+    def test_relative_paths(self, server):
+        """Files are relative to the served directory in every endpoint."""
+        _, listed = server.handle("GET", "/symbols?package=example.com/shapes/geo")
+        _, found = server.handle("GET", "/symbols/Point.X")
+        _, matches = server.handle("GET", "/search?q=point")
+
+        assert {s["file"] for s in listed} == {"geo/point.go"}
+        assert found["file"] == "geo/point.go"
+        assert matches[0]["file"] == "geo/point.go"
+
+    def test_filters(self, server):
+        """kind, exported, and package narrow the list."""
+        _, body = server.handle("GET", "/symbols?kind=func&exported=true")
+        assert [(s["package"], s["name"]) for s in body] == [("example.com/shapes", "New"), ("example.com/shapes/geo", "New")]
+
+        _, body = server.handle("GET", "/symbols?package=example.com/shapes/geo&kind=method")
+        assert [s["name"] for s in body] == ["X"]
+
+    @pytest.mark.parametrize("target, message", [
+        ("/symbols?kind=widget", "Unknown symbol kind"),
+        ("/symbols?exported=maybe", "Invalid exported"),
+    ])
+    def test_bad_filters(self, server, target, message):
+        """Invalid filter values are a 400."""
+        status, body = server.handle("GET", target)
+        assert status == 400
+        assert message in body["error"]
+
+    def test_unknown_package(self, server):
+        """Filtering on a package that is not in the tree is a 404."""
+        status, body = server.handle("GET", "/symbols?package=example.com/nope")
+        assert status == 404
+
+    def test_get_symbol(self, server):
+        """/symbols/{name} returns one symbol with its doc and span."""
+        status, body = server.handle("GET", "/symbols/Point.X")
+
+        assert status == 200
+        assert body["signature"] == "func (p Point) X() int"
+        assert body["span"] == {"start": {"line": 6, "column": 1}, "end": {"line": 6, "column": 36}}
+
+    def test_get_symbol_qualified(self, server):
+        """Qualified names, whose import paths contain slashes, are resolved."""
+        status, body = server.handle("GET", "/symbols/example.com/shapes/geo.New")
+
+        assert status == 200
+        assert body["signature"] == "func New() Point"
+
+    def test_ambiguous_symbol(self, server):
+        """A name declared in several packages is a 409 listing the candidates."""
+        status, body = server.handle("GET", "/symbols/New")
+
+        assert status == 409
+        assert body["candidates"] == ["example.com/shapes.New", "example.com/shapes/geo.New"]
+
+        status, body = server.handle("GET", "/symbols/New?package=example.com/shapes")
+        assert status == 200
+        assert body["doc"] == "New makes a shape."
+
+    def test_unknown_symbol(self, server):
+        """Unknown symbols and paths are a 404."""
+        assert server.handle("GET", "/symbols/Missing")[0] == 404
+        assert server.handle("GET", "/nothing")[0] == 404
+
+    def test_method_not_allowed(self, server):
+        """Only GET is served."""
+        assert server.handle("POST", "/symbols")[0] == 405
+
+    def test_packages(self, server):
+        """/packages lists package summaries."""
+        status, body = server.handle("GET", "/packages")
+
+        assert status == 200
+        assert [(p["path"], p["name"], p["doc"]) for p in body] == [
+            ("example.com/shapes", "shapes", "Package shapes draws shapes."),
+            ("example.com/shapes/geo", "geo", ""),
+        ]
+
+    def test_search(self, server):
+        """/search fuzzy-matches names, best first, and doc comments with doc=true."""
+        status, body = server.handle("GET", "/search?q=new&limit=1")
+
+        assert status == 200
+        assert [(s["package"], s["name"], s["matched"]) for s in body] == [("example.com/shapes", "New", "name")]
+
+        _, body = server.handle("GET", "/search?q=makes&doc=true")
+        assert [(s["name"], s["matched"]) for s in body] == [("New", "doc")]
+
+    @pytest.mark.parametrize("target,message", [
+        ("/search", "Missing query"),
+        ("/search?q=new&limit=0", "Invalid limit"),
+        ("/search?q=new&doc=yes", "Invalid doc"),
+    ])
+    def test_bad_search(self, server, target, message):
+        """Bad search parameters are a 400."""
+        status, body = server.handle("GET", target)
+
+        assert status == 400
+        assert message in body["error"]
+
+    def test_reload(self, server):
+        """reload() picks up changed files."""
+        write(server.root, "geo/line.go", "package geo\n\ntype Line struct{}\n")
+        assert server.handle("GET", "/symbols/Line")[0] == 404
+
+        server.reload()
+
+        assert server.handle("GET", "/symbols/Line")[0] == 200
+
+    def test_events_requires_upgrade(self, server):
+        """/events answers plain GET requests with 426."""
+        status, body = server.handle("GET", "/events")
+
+        assert status == 426
+        assert "WebSocket" in body["error"]
+
+
+class TestChangeEvents:
+    """Tests for the events reload() sends to subscribers."""
+
+    def test_reload_events(self, server):
+        """Added, removed, and modified symbols become one event each, per package."""
+        subscription, index = server.subscribe()
+        write(server.root, "geo/point.go", "package geo\n\n// Point is a 2D point.\ntype Point struct{}\n\nfunc New() Point { return Point{} }\n")
+        write(server.root, "geo/line.go", "package geo\n\ntype Line struct{}\n")
+
+        server.reload()
+        events = [subscription.next(timeout=0) for _ in range(3)]
+
+        assert index is not server.index
+        assert [(e["type"], e["symbol"]["name"]) for e in events] == [
+            ("added", "Line"), ("removed", "X"), ("modified", "Point"),
+        ]
+        assert events[2]["previous"]["doc"] == "Point is a point."
+        assert [e["symbol"]["file"] for e in events] == ["geo/line.go", "geo/point.go", "geo/point.go"]
+        assert subscription.next(timeout=0) is None
+
+    def test_unchanged_reload(self, server):
+        """A reload without changes sends nothing."""
+        subscription, _ = server.subscribe()
+
+        server.reload()
+
+        assert subscription.next(timeout=0) is None
+
+    def test_unsubscribe(self, server):
+        """Unsubscribed clients get no more events."""
+        subscription, _ = server.subscribe()
+        server.unsubscribe(subscription)
+        write(server.root, "geo/line.go", "package geo\n\ntype Line struct{}\n")
+
+        server.reload()
+
+        assert subscription.closed
+        assert subscription.next(timeout=0) is None
+
+
+class TestSubscription:
+    """Tests for per-client event buffers."""
+
+    def test_order(self):
+        """Events come out in the order they were pushed."""
+        subscription = Subscription(limit=3)
+        subscription.push({"n": 1})
+        subscription.push({"n": 2})
+
+        assert [subscription.next(), subscription.next()] == [{"n": 1}, {"n": 2}]
+
+    def test_overflow_closes(self):
+        """Pushing past the limit drops the buffer and closes the subscription."""
+        subscription = Subscription(limit=2)
+        for n in range(3):
+            subscription.push({"n": n})
+
+        assert subscription.overflowed and subscription.closed
+        assert subscription.next() is None
+
+    def test_close_wakes_reader(self):
+        """Closing wakes a thread waiting for events."""
+        subscription = Subscription(limit=1)
+        results = []
+        thread = threading.Thread(target=lambda: results.append(subscription.next()))
+        thread.start()
+
+        subscription.close()
+        thread.join(timeout=5)
+
+        assert not thread.is_alive()
+        assert results == [None]
+
+
+class TestHttp:
+    """Round trips over a real socket."""
+
+    @pytest.fixture
+    def url(self, server):
+        """Base URL of the server listening on a free port."""
+        server.bind(("127.0.0.1", 0))
+        thread = threading.Thread(target=server.serve_forever, daemon=True)
+        thread.start()
+        host, port = server.address
+        yield f"http://{host}:{port}"
+        server.shutdown()
+        thread.join()
+
+    def test_json_with_cors(self, url):
+        """Responses are JSON and readable from other origins."""
+        with urllib.request.urlopen(f"{url}/packages") as response:
+            assert response.status == 200
+            assert response.headers["Content-Type"].startswith("application/json")
+            assert response.headers["Access-Control-Allow-Origin"] == "*"
+            assert len(json.loads(response.read())) == 2
+
+    def test_not_found(self, url):
+        """Errors carry their status code and a JSON error message."""
+        with pytest.raises(urllib.error.HTTPError) as excinfo:
+            urllib.request.urlopen(f"{url}/symbols/Missing")
+
+        assert excinfo.value.code == 404
+        assert json.loads(excinfo.value.read()) == {"error": "Unknown symbol: Missing"}
+
+    def test_events_snapshot_and_changes(self, server, url):
+        """/events sends the snapshot, then the changes of each re-index, until the client closes."""
+        client = EventsClient(url, "/events?snapshot=true")
+        assert client.handshake.startswith(b"HTTP/1.0 101")
+
+        snapshot = client.receive()
+        assert snapshot["type"] == "snapshot"
+        assert [s["name"] for s in snapshot["symbols"]] == ["New", "scale", "Point", "X", "New"]
+
+        write(server.root, "geo/line.go", "package geo\n\ntype Line struct{}\n")
+        server.reload()
+        event = client.receive()
+        assert (event["type"], event["symbol"]["name"], event["symbol"]["package"]) == ("added", "Line", "example.com/shapes/geo")
+
+        client.send(websocket.OP_PING, b"hi")
+        assert client.receive_frame() == (websocket.OP_PONG, b"hi")
+
+        client.send(websocket.OP_CLOSE, struct.pack("!H", websocket.CLOSE_NORMAL))
+        assert client.receive_frame() == (websocket.OP_CLOSE, struct.pack("!H", websocket.CLOSE_NORMAL))
+        client.close()
+        wait_for(lambda: not server._subscriptions)
+
+    def test_events_client_dropped(self, server, url):
+        """A client dropping the connection is unsubscribed."""
+        client = EventsClient(url, "/events")
+        wait_for(lambda: server._subscriptions)
+
+        client.close()
+        write(server.root, "geo/line.go", "package geo\n\ntype Line struct{}\n")
+        server.reload()
+
+        wait_for(lambda: not server._subscriptions)
+
+    def test_events_bad_handshake(self, url):
+        """Upgrade requests without a valid key are rejected."""
+        request = urllib.request.Request(f"{url}/events", headers={"Upgrade": "websocket", "Connection": "Upgrade"})
+        with pytest.raises(urllib.error.HTTPError) as excinfo:
+            urllib.request.urlopen(request)
+
+        assert excinfo.value.code == 400
+
+    def test_preflight(self, url):
+        """CORS preflight requests are answered without a body."""
+        request = urllib.request.Request(f"{url}/symbols", method="OPTIONS")
+        with urllib.request.urlopen(request) as response:
+            assert response.status == 204
+            assert "GET" in response.headers["Access-Control-Allow-Methods"]
+
+
+class EventsClient:
+    """Minimal WebSocket client of /events."""
+
+    def __init__(self, url: str, target: str):
+        """Connect and complete the handshake, keeping the response head in `handshake`."""
+        host, port = url.removeprefix("http://").rsplit(":", 1)
+        self.sock = socket.create_connection((host, int(port)), timeout=5)
+        key = base64.b64encode(os.urandom(16)).decode()
+        self.sock.sendall(
+            f"GET {target} HTTP/1.1\r\nHost: localhost\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n"
+            f"Sec-WebSocket-Key: {key}\r\nSec-WebSocket-Version: 13\r\n\r\n".encode()
+        )
+        self.stream = self.sock.makefile("rb")
+        lines = []
+        while (line := self.stream.readline()) not in (b"\r\n", b""):
+            lines.append(line)
+        self.handshake = b"".join(lines)
+        assert f"Sec-WebSocket-Accept: {websocket.accept_key(key)}".encode() in self.handshake
+
+    def receive_frame(self) -> tuple[int, bytes]:
+        """Read one unmasked server frame."""
+        first, second = self.stream.read(2)
+        length = second & 0x7F
+        if length == 126:
+            (length,) = struct.unpack("!H", self.stream.read(2))
+        elif length == 127:
+            (length,) = struct.unpack("!Q", self.stream.read(8))
+        return first & 0x0F, self.stream.read(length)
+
+    def receive(self) -> dict:
+        """Read one JSON text message."""
+        opcode, payload = self.receive_frame()
+        assert opcode == websocket.OP_TEXT
+        return json.loads(payload)
+
+    def send(self, opcode: int, payload: bytes) -> None:
+        """Send a masked frame."""
+        mask = os.urandom(4)
+        masked = bytes(b ^ mask[i % 4] for i, b in enumerate(payload))
+        self.sock.sendall(struct.pack("!BB", 0x80 | opcode, 0x80 | len(payload)) + mask + masked)
+
+    def close(self) -> None:
+        """Drop the connection."""
+        self.stream.close()
+        self.sock.close()
+
+
+def wait_for(condition, timeout: float = 5.0) -> None:
+    """Wait until condition() holds, failing after timeout seconds."""
+    import time
+
+    deadline = time.monotonic() + timeout
+    while not condition():
+        assert time.monotonic() < deadline, "Timed out"
+        time.sleep(0.01)
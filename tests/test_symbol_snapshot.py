@@ -0,0 +1,191 @@
+"""
+Unit tests for index snapshots.
+
+Tests saving and loading snapshots, warm starts against cold scans, partial
+re-parsing of changed files, and discarding outdated snapshots.
+"""
+
+import shutil
+import pytest
+from pathlib import Path
+from ctxd.symbols import Options, extract_dir
+from ctxd.symbols import snapshot as snapshot_module
+from ctxd.symbols import walker
+from ctxd.symbols.server import SymbolServer
+from ctxd.symbols.snapshot import IndexSnapshot, clear_snapshots, default_snapshot_path
+
+FIXTURES = Path(__file__).parent / "fixtures"
+
+
+@pytest.fixture
+def tree(tmp_path):
+    """A copy of the fixtures, which tests may edit."""
+    root = tmp_path / "tree"
+    shutil.copytree(FIXTURES, root)
+    return root
+
+
+@pytest.fixture
+def parsed(monkeypatch):
+    """Record the path of every file parsed."""
+    paths = []
+    parse = walker._parse
+
+    def recording_parse(extractor, job):
+        paths.append(job[1])
+        return parse(extractor, job)
+
+    monkeypatch.setattr(walker, "_parse", recording_parse)
+    return paths
+
+
+def warm(tree: Path, path: Path, options: Options):
+    """Extract a tree from the snapshot saved at path, and save it again."""
+    snapshot = IndexSnapshot(path)
+    snapshot.load()
+    index = extract_dir(tree, options, snapshot)
+    snapshot.save()
+    return index
+
+
+class TestWarmStart:
+    """Tests for extraction from a saved snapshot."""
+
+    def test_identical_to_cold_scan(self, tree, tmp_path, parsed):
+        """A warm start parses nothing and gives the index of a cold scan."""
+        options = Options(recursive=True, include_tests=True, calls=True, metrics=True, jobs=1)
+        path = tmp_path / "index.snapshot"
+        warm(tree, path, options)
+        parsed.clear()
+
+        index = warm(tree, path, options)
+
+        assert parsed == []
+        cold = extract_dir(tree, options)
+        assert index.packages == cold.packages
+        assert index.summaries == cold.summaries
+        assert index.errors == cold.errors
+        assert index.root == tree
+
+    def test_reparses_changed_files(self, tree, tmp_path, parsed):
+        """Only changed and added files are parsed, and relationships are resolved again."""
+        options = Options(recursive=True, jobs=1)
+        path = tmp_path / "index.snapshot"
+        (tree / "shape.go").write_text("package fixtures\n\ntype Shape interface {\n\tArea() int\n}\n")
+        warm(tree, path, options)
+        parsed.clear()
+
+        (tree / "square.go").write_text("package fixtures\n\ntype Square struct{}\n\nfunc (s Square) Area() int { return 0 }\n")
+        (tree / "shape.go").write_text("package fixtures\n\n// Shape has an area.\ntype Shape interface {\n\tArea() int\n}\n")
+        index = warm(tree, path, options)
+
+        assert sorted(Path(p).name for p in parsed) == ["shape.go", "square.go"]
+        assert index.packages == extract_dir(tree, options).packages
+        square = next(s for s in index.symbols() if s.name == "Square")
+        assert square.implements == ["Shape"]
+
+    def test_removed_files(self, tree, tmp_path, parsed):
+        """Symbols of deleted files are dropped from the index and the snapshot."""
+        options = Options(recursive=True, jobs=1)
+        path = tmp_path / "index.snapshot"
+        warm(tree, path, options)
+        before = IndexSnapshot(path)
+        before.load()
+
+        (tree / "sample.go").unlink()
+        index = warm(tree, path, options)
+        after = IndexSnapshot(path)
+        after.load()
+
+        assert parsed.count(str(tree / "sample.go")) == 1
+        assert not any(s.file.endswith("/sample.go") for s in index.symbols())
+        assert len(after.entries) == len(before.entries) - 1
+
+    def test_other_options(self, tree, tmp_path):
+        """A snapshot taken with other options is not served, only its per-file symbols."""
+        path = tmp_path / "index.snapshot"
+        warm(tree, path, Options(recursive=True, jobs=1))
+
+        index = warm(tree, path, Options(recursive=True, exported_only=True, jobs=1))
+
+        assert index.packages == extract_dir(tree, Options(recursive=True, exported_only=True)).packages
+
+
+class TestSnapshotFile:
+    """Tests for reading and writing snapshot files."""
+
+    def test_missing(self, tmp_path):
+        """A missing snapshot loads as empty."""
+        assert not IndexSnapshot(tmp_path / "none.snapshot").load()
+
+    def test_other_version(self, tree, tmp_path, monkeypatch):
+        """A snapshot written under another schema is discarded, and the tree scanned cold."""
+        path = tmp_path / "index.snapshot"
+        options = Options(recursive=True, jobs=1)
+        warm(tree, path, options)
+        monkeypatch.setattr("ctxd.symbols.snapshot.SCHEMA_VERSION", 0)
+        snapshot = IndexSnapshot(path)
+
+        assert not snapshot.load()
+        assert snapshot.entries == {}
+        assert extract_dir(tree, options, snapshot).packages == extract_dir(tree, options).packages
+
+    def test_corrupt(self, tree, tmp_path):
+        """A truncated snapshot is discarded."""
+        path = tmp_path / "index.snapshot"
+        warm(tree, path, Options(recursive=True, jobs=1))
+        path.write_bytes(path.read_bytes()[:-10])
+
+        assert not IndexSnapshot(path).load()
+
+    def test_nothing_recorded(self, tmp_path):
+        """Saving before any extraction writes nothing."""
+        path = tmp_path / "index.snapshot"
+
+        IndexSnapshot(path).save()
+
+        assert not path.exists()
+
+    def test_failed_write(self, tree, tmp_path, monkeypatch):
+        """A snapshot that cannot be moved into place leaves no temporary file behind."""
+        path = tmp_path / "index" / "index.snapshot"
+        snapshot = IndexSnapshot(path)
+        extract_dir(tree, Options(recursive=True, jobs=1), snapshot)
+
+        def fail(src, dst):
+            raise OSError("disk full")
+
+        monkeypatch.setattr(snapshot_module.os, "replace", fail)
+        snapshot.save()
+
+        assert list(path.parent.iterdir()) == []
+
+    def test_default_path(self, tmp_path, monkeypatch):
+        """Snapshots live beside the parse cache, one per directory, and are cleared with it."""
+        monkeypatch.setenv("XDG_CACHE_HOME", str(tmp_path))
+        path = default_snapshot_path(tmp_path / "a")
+        assert path.parent == tmp_path / "ctxd" / "index"
+        assert path != default_snapshot_path(tmp_path / "b")
+
+        path.parent.mkdir(parents=True)
+        path.write_bytes(b"")
+        assert clear_snapshots() == 1
+        assert not path.exists()
+
+
+class TestServerSnapshot:
+    """Tests for servers starting from a snapshot."""
+
+    def test_restart(self, tree, tmp_path, parsed):
+        """A server saves its index on shutdown, and the next one starts from it."""
+        options = Options(recursive=True, jobs=1)
+        path = tmp_path / "index.snapshot"
+        first = SymbolServer(tree, options, snapshot=IndexSnapshot(path))
+        first.shutdown()
+        parsed.clear()
+
+        second = SymbolServer(tree, options, snapshot=IndexSnapshot(path))
+
+        assert parsed == []
+        assert second.index.packages == first.index.packages
+        assert second.handle("GET", "/packages") == first.handle("GET", "/packages")
@@ -0,0 +1,135 @@
+"""
+Unit tests for symbol transformers.
+
+Tests the transformer chain, the Redactor, and transformers run by the
+extraction pipeline.
+"""
+
+import pytest
+from dataclasses import replace
+from pathlib import Path
+from ctxd.symbols import Options, Redactor, Symbol, Transformer, apply_transformers, extract_dir, extract_file
+
+FIXTURES = Path(__file__).parent / "fixtures"
+
+
+class Renamer(Transformer):
+    """Stub transformer prefixing every name, recording what it saw."""
+
+    def __init__(self, prefix: str):
+        self.prefix = prefix
+        self.seen: list[str] = []
+
+    def transform(self, symbol):
+        self.seen.append(symbol.name)
+        symbol.name = self.prefix + symbol.name
+        return symbol
+
+
+class DropUnexported(Transformer):
+    """Stub transformer dropping unexported symbols."""
+
+    def transform(self, symbol):
+        return symbol if symbol.exported else None
+
+
+def symbol(name: str, **kwargs) -> Symbol:
+    """Create a func symbol."""
+    return Symbol(name=name, kind="func", file="a.go", line=1, signature=f"func {name}()", exported=name[0].isupper(), **kwargs)
+
+
+class TestApplyTransformers:
+    """Tests for running a chain of transformers."""
+
+    def test_rewrites_names(self):
+        """A transformer's result replaces the symbol, in input order."""
+        symbols = apply_transformers([symbol("b"), symbol("a")], [Renamer("x_")])
+
+        assert [s.name for s in symbols] == ["x_b", "x_a"]
+
+    def test_chain_order(self):
+        """Transformers run in list order, each seeing what the ones before kept."""
+        renamer = Renamer("x_")
+
+        symbols = apply_transformers([symbol("Keep"), symbol("drop")], [DropUnexported(), renamer, Renamer("y_")])
+
+        assert [s.name for s in symbols] == ["y_x_Keep"]
+        assert renamer.seen == ["Keep"]
+
+    def test_none_drops(self):
+        """A transformer returning None drops the symbol without failing."""
+        class Nothing(Transformer):
+            def transform(self, symbol):
+                return None
+
+        assert apply_transformers([symbol("A")], [Nothing(), Renamer("x_")]) == []
+
+    def test_wrong_result(self):
+        """A result that is not a Symbol is reported."""
+        class Broken(Transformer):
+            def transform(self, symbol):
+                return True
+
+        with pytest.raises(TypeError, match="Broken.transform"):
+            apply_transformers([symbol("A")], [Broken()])
+
+
+class TestRedactor:
+    """Tests for the built-in redactor."""
+
+    def test_redacts_text(self):
+        """Matches are replaced in names, signatures, docs, and listed names."""
+        redacted = Redactor(r"secret\w*").transform(symbol(
+            "secretHelper", doc="secretHelper calls secretStore.", calls=["secretStore.Get"], package="example.com/secretpkg",
+        ))
+
+        assert redacted.name == "REDACTED"
+        assert redacted.signature == "func REDACTED()"
+        assert redacted.doc == "REDACTED calls REDACTED."
+        assert redacted.calls == ["REDACTED.Get"]
+        assert redacted.package == "example.com/REDACTED"
+        assert (redacted.file, redacted.line) == ("a.go", 1)
+
+    def test_members_and_replacement(self):
+        """Fields are rewritten too, with a custom replacement, and the original is kept."""
+        original = Symbol(
+            name="Config", kind="struct", file="a.go", line=1, signature="type Config struct",
+            fields=[Symbol(name="InternalKey", kind="field", file="a.go", line=2, signature="InternalKey string")],
+        )
+
+        redacted = Redactor("Internal", replacement="X").transform(original)
+
+        assert redacted.fields[0].signature == "XKey string"
+        assert original.fields[0].name == "InternalKey"
+
+    def test_invalid_pattern(self):
+        """Invalid patterns are rejected."""
+        with pytest.raises(ValueError, match="Invalid redact pattern"):
+            Redactor("(")
+
+
+class TestPipeline:
+    """Tests for transformers run by extraction."""
+
+    def test_extract_file(self):
+        """Transformers run after filtering, and the result is sorted by the new names."""
+        class Total(Transformer):
+            def transform(self, symbol):
+                return replace(symbol, name="Total") if symbol.name == "Add" else symbol
+
+        renamer = Renamer("")
+
+        symbols = extract_file(FIXTURES / "sample.go", Options(kinds=["func"], transformers=[renamer, Total()]))
+
+        assert renamer.seen == ["Add", "Multiply", "NewCalculator"]
+        assert [s.name for s in symbols] == ["Multiply", "NewCalculator", "Total"]
+
+    def test_extract_dir_summaries(self, tmp_path):
+        """Package summaries count what transformers kept."""
+        (tmp_path / "go.mod").write_text("module example.com/app\n")
+        (tmp_path / "a.go").write_text("package app\n\nfunc Run() {}\n\nfunc helper() {}\n")
+
+        index = extract_dir(tmp_path, Options(transformers=[DropUnexported()]))
+
+        assert [s.name for s in index.symbols()] == ["Run"]
+        assert index.summaries["example.com/app"].kinds == {"func": 1}
@@ -0,0 +1,191 @@
+"""
+Unit tests for unused unexported symbol detection.
+
+Tests reference collection, find_unused, and extract_unused over package
+directories.
+"""
+
+import pytest
+from ctxd.symbols import GoSymbolExtractor, Options, extract_unused
+from ctxd.symbols.unused import find_unused
+
+SOURCE = """package store
+
+import _ "embed"
+
+//go:embed schema.sql
+var schema string
+
+const (
+	defaultSize = 16
+	spareSize   = 32
+)
+
+type entry struct {
+	next *entry
+}
+
+type index map[string]int
+
+// open is referenced by Open
+func open(size int) index { return make(index, size) }
+
+// walk only calls itself
+func walk(depth int) { walk(depth - 1) }
+
+func byName() {}
+
+//go:noinline
+func hot() {}
+
+//go:linkname now runtime.nanotime
+func now() int64
+
+func init() {}
+
+func _() {}
+
+// Open opens a store
+func Open() {
+	open(defaultSize)
+	call("byName")
+}
+
+func call(name string) {}
+"""
+
+
+@pytest.fixture
+def extractor():
+    """Create a Go symbol extractor."""
+    return GoSymbolExtractor()
+
+
+def unused_names(extractor: GoSymbolExtractor, content: str) -> list[str]:
+    """Find the unused symbols of one file's source."""
+    return [s.name for s in find_unused(extractor.extract(content, "store.go"), extractor.references(content))]
+
+
+class TestFindUnused:
+    """Tests for unused declarations within one file."""
+
+    def test_unused_declarations(self, extractor):
+        """Unreferenced unexported funcs, types, consts, and vars are reported."""
+        assert unused_names(extractor, SOURCE) == ["spareSize", "entry", "walk"]
+
+    def test_referenced(self, extractor):
+        """Names used by other declarations are not reported."""
+        names = unused_names(extractor, SOURCE)
+
+        assert "open" not in names
+        assert "index" not in names
+        assert "defaultSize" not in names
+
+    def test_self_reference(self, extractor):
+        """Recursion and self-referential types do not count as uses."""
+        names = unused_names(extractor, SOURCE)
+
+        assert "walk" in names
+        assert "entry" in names
+
+    def test_directives(self, extractor):
+        """Declarations marked by //go: directives, or named by //go:linkname, are kept."""
+        names = unused_names(extractor, SOURCE)
+
+        assert "schema" not in names
+        assert "hot" not in names
+        assert "now" not in names
+
+    def test_reflective_names(self, extractor):
+        """A string literal spelling a name keeps it."""
+        assert "byName" not in unused_names(extractor, SOURCE)
+
+    def test_exported_and_special_names(self, extractor):
+        """Exported names, init, main, and _ are never reported."""
+        content = "package main\n\nfunc main() {}\n\nfunc init() {}\n\nvar _ = 1\n\nfunc Unused() {}\n"
+
+        assert unused_names(extractor, content) == []
+
+    def test_cgo_export(self, extractor):
+        """Functions exported to C by cgo are kept."""
+        content = 'package main\n\nimport "C"\n\n//export callback\nfunc callback() {}\n'
+
+        assert unused_names(extractor, content) == []
+
+    def test_methods_not_checked(self, extractor):
+        """Methods may satisfy interfaces, so they are never reported, and receivers use their type."""
+        content = "package p\n\ntype impl struct{}\n\nfunc (impl) close() {}\n"
+
+        assert unused_names(extractor, content) == []
+
+    def test_shadowing_local_counts(self, extractor):
+        """A local of the same name counts as a use, erring on the side of keeping."""
+        content = "package p\n\nvar size = 1\n\nfunc F() int {\n\tsize := 2\n\treturn size\n}\n"
+
+        assert unused_names(extractor, content) == []
+
+
+class TestExtractUnused:
+    """Tests for extract_unused over files and package trees."""
+
+    def write(self, root, files):
+        """Write files under root."""
+        for rel_path, content in files.items():
+            path = root / rel_path
+            path.parent.mkdir(parents=True, exist_ok=True)
+            path.write_text(content)
+
+    def test_references_across_files(self, tmp_path):
+        """A declaration used by another file of the package is kept."""
+        self.write(tmp_path, {
+            "go.mod": "module example.com/app\n",
+            "a.go": "package app\n\nfunc helper() {}\n\nfunc orphan() {}\n",
+            "b.go": "package app\n\nfunc Run() { helper() }\n",
+        })
+
+        unused = extract_unused(tmp_path)
+
+        assert [(s.name, s.file) for s in unused] == [("orphan", str(tmp_path / "a.go"))]
+        assert unused[0].line == 5
+
+    def test_tests_and_other_platforms_reference(self, tmp_path):
+        """Test files and files for other platforms count, even when not extracted."""
+        self.write(tmp_path, {
+            "go.mod": "module example.com/app\n",
+            "a.go": "package app\n\nfunc fixture() {}\n\nfunc syscallName() string { return \"\" }\n",
+            "a_test.go": "package app\n\nfunc TestA() { fixture() }\n",
+            "a_plan9.go": "package app\n\nvar _ = syscallName()\n",
+        })
+
+        assert extract_unused(tmp_path, Options(goos="linux", goarch="amd64")) == []
+
+    def test_packages_kept_apart(self, tmp_path):
+        """A use in another package does not keep an unexported name."""
+        self.write(tmp_path, {
+            "go.mod": "module example.com/app\n",
+            "a/a.go": "package a\n\nfunc helper() {}\n",
+            "b/b.go": "package b\n\nfunc Run() { helper() }\n",
+        })
+
+        unused = extract_unused(tmp_path, Options(recursive=True))
+
+        assert [(s.package, s.name) for s in unused] == [("example.com/app/a", "helper")]
+
+    def test_filters(self, tmp_path):
+        """Kind and name filters narrow the report."""
+        self.write(tmp_path, {
+            "go.mod": "module example.com/app\n",
+            "a.go": "package app\n\nfunc helper() {}\n\nvar spare = 1\n\ntype unusedType struct{}\n",
+        })
+
+        assert [s.name for s in extract_unused(tmp_path, Options(kinds=["func", "var"]))] == ["spare", "helper"]
+        assert [s.name for s in extract_unused(tmp_path, Options(name="^un"))] == ["unusedType"]
+
+    def test_single_file(self, tmp_path):
+        """A single file is checked against the rest of its directory."""
+        self.write(tmp_path, {
+            "a.go": "package app\n\nfunc helper() {}\n\nfunc orphan() {}\n",
+            "b.go": "package app\n\nfunc Run() { helper() }\n",
+        })
+
+        assert [s.name for s in extract_unused(tmp_path / "a.go")] == ["orphan"]
@@ -0,0 +1,482 @@
+"""
+Unit tests for recursive Go package walking.
+
+Tests find_go_files, extract_packages, and package summaries against
+in-memory package trees, and against temporary directories where OS
+behavior such as symlinks matters.
+"""
+
+import pytest
+from pathlib import Path
+from ctxd.symbols import GoSymbolExtractor, GoSyntaxError, MapFS, check_implements, extract_packages, find_go_files, group_methods, summarize_package
+from ctxd.symbols import walker
+from ctxd.symbols.cache import SymbolCache
+from ctxd.symbols.walker import file_directives, read_package_clause, stream_packages, summarize_packages
+from conftest import write
+
+FIXTURES = Path(__file__).parent / "fixtures"
+
+
+MODULE_FILES = {
+    "go.mod": "module example.com/shapes\n\ngo 1.22\n",
+    "shapes.go": "package shapes\n\nfunc Area() int { return 0 }\n",
+    "shapes_test.go": "package shapes\n\nfunc TestArea() {}\n",
+    "geo/point.go": "package geo\n\ntype Point struct{}\n",
+    "geo/line.go": "package geo\n\ntype Line struct{}\n",
+    "geo/polar/polar.go": "package polar\n\nfunc Convert() {}\n",
+    "vendor/dep/dep.go": "package dep\n\nfunc Vendored() {}\n",
+    "geo/testdata/bad.go": "package bad\n\nfunc Fixture() {}\n",
+    "tools.go": "//go:build ignore\n\npackage shapes\n\nfunc Tool() {}\n",
+    "README.md": "# shapes\n",
+}
+
+
+@pytest.fixture
+def module_tree():
+    """A small Go module with nested packages and excluded directories."""
+    return MapFS(MODULE_FILES)
+
+
+class TestFindGoFiles:
+    """Tests for Go file discovery."""
+
+    def test_recursive_discovery(self, module_tree):
+        """Nested Go files are found in sorted order."""
+        files = [str(p) for p in find_go_files(module_tree)]
+
+        assert files == ["geo/line.go", "geo/point.go", "geo/polar/polar.go", "shapes.go"]
+
+    def test_non_recursive(self, module_tree):
+        """Without recursion only the root directory is searched."""
+        files = [p.name for p in find_go_files(module_tree, recursive=False)]
+
+        assert files == ["shapes.go"]
+
+    @pytest.mark.parametrize("depth, expected", [
+        (0, ["shapes.go"]),
+        (1, ["geo/line.go", "geo/point.go", "shapes.go"]),
+        (2, ["geo/line.go", "geo/point.go", "geo/polar/polar.go", "shapes.go"]),
+    ])
+    def test_depth(self, module_tree, depth, expected):
+        """Depth limits how many directory levels below the root are walked."""
+        assert [str(p) for p in find_go_files(module_tree, depth=depth)] == expected
+
+    def test_depth_with_patterns(self, module_tree):
+        """Depth and patterns both apply, and excluded directories stay excluded."""
+        files = find_go_files(module_tree, depth=1, include=["geo/**", "vendor/**"], exclude=["**/line.go"])
+
+        assert [str(p) for p in files] == ["geo/point.go"]
+
+    def test_symlinked_directory_not_followed(self, tmp_path):
+        """Symlinks to directories are skipped, so they never count toward depth."""
+        write(tmp_path, "real/a.go", "package real\n")
+        (tmp_path / "link").symlink_to(tmp_path / "real", target_is_directory=True)
+
+        files = find_go_files(tmp_path, depth=1)
+
+        assert [p.relative_to(tmp_path).as_posix() for p in files] == ["real/a.go"]
+
+    def test_include_tests(self, module_tree):
+        """_test.go files are only included on request."""
+        files = [p.name for p in find_go_files(module_tree, include_tests=True)]
+
+        assert "shapes_test.go" in files
+
+    def test_build_ignore_skipped(self, module_tree):
+        """Files marked //go:build ignore are skipped."""
+        files = [p.name for p in find_go_files(module_tree)]
+
+        assert "tools.go" not in files
+
+
+class TestExtractPackages:
+    """Tests for package-grouped extraction."""
+
+    def test_grouped_by_import_path(self, module_tree):
+        """Symbols are keyed by import path derived from go.mod."""
+        packages = extract_packages(module_tree)
+
+        assert list(packages) == [
+            "example.com/shapes",
+            "example.com/shapes/geo",
+            "example.com/shapes/geo/polar",
+        ]
+        assert [s.name for s in packages["example.com/shapes/geo"]] == ["Line", "Point"]
+        assert packages["example.com/shapes/geo"][0].package == "example.com/shapes/geo"
+
+    def test_without_go_mod(self):
+        """Without a go.mod, packages are keyed by relative directory."""
+        packages = extract_packages(MapFS({
+            "main.go": "package main\n\nfunc main() {}\n",
+            "util/util.go": "package util\n\nfunc Help() {}\n",
+        }))
+
+        assert list(packages) == [".", "util"]
+
+    def test_subdirectory_of_module(self, module_tree):
+        """Walking a subdirectory still resolves import paths from the module root."""
+        packages = extract_packages(module_tree / "geo")
+
+        assert list(packages) == ["example.com/shapes/geo", "example.com/shapes/geo/polar"]
+
+    def test_interfaces_resolved_across_files(self):
+        """Embedded interfaces declared in sibling files are flattened."""
+        packages = extract_packages(MapFS({
+            "a.go": "package io\n\ntype ReadCloser interface {\n\tReader\n\tClose() error\n}\n",
+            "b.go": "package io\n\ntype Reader interface {\n\tRead() int\n}\n",
+        }))
+        read_closer = packages["."][0]
+
+        assert [(m.name, m.origin) for m in read_closer.methods] == [("Close", ""), ("Read", "Reader")]
+
+    def test_exported_only(self):
+        """Unexported symbols are dropped after package-wide resolution."""
+        fsys = MapFS({
+            "a.go": "package io\n\ntype Closer interface {\n\tcloser\n}\n",
+            "b.go": "package io\n\ntype closer interface {\n\tClose() error\n}\n",
+        })
+
+        packages = extract_packages(fsys, exported_only=True)
+
+        assert [s.name for s in packages["."]] == ["Closer"]
+        assert [(m.name, m.origin) for m in packages["."][0].methods] == [("Close", "closer")]
+
+    def test_empty_directory(self, tmp_path):
+        """A tree without Go files yields no packages."""
+        assert extract_packages(tmp_path) == {}
+        assert extract_packages(MapFS({"README.md": "# empty\n"})) == {}
+
+
+class TestParseErrorsInWalk:
+    """Tests for walks over trees with broken files."""
+
+    def test_walk_continues(self, tmp_path):
+        """Broken and unreadable files are reported without stopping the walk."""
+        write(tmp_path, "a.go", "package a\n\nfunc A() {}\n")
+        write(tmp_path, "b.go", "package a\n\nfunc B( {\n")
+        write(tmp_path, "c.go", "package a\n\nfunc C() {}\n")
+        (tmp_path / "d.go").symlink_to(tmp_path / "missing.go")
+
+        errors = []
+        packages = extract_packages(tmp_path, errors=errors)
+
+        names = [s.name for s in packages["."]]
+        assert "A" in names and "C" in names
+        assert [e.file for e in errors] == [str(tmp_path / "b.go"), str(tmp_path / "d.go")]
+        assert errors[1].line == 0
+
+    def test_strict(self):
+        """Strict walks stop at the first broken file."""
+        with pytest.raises(GoSyntaxError):
+            extract_packages(MapFS({"a.go": "package a\n\nfunc B( {\n"}), strict=True)
+
+
+class TestParallelWalk:
+    """Tests for walks parsed by worker processes."""
+
+    @pytest.fixture(autouse=True)
+    def parallel(self, monkeypatch):
+        """Use workers however few files there are."""
+        monkeypatch.setattr(walker, "PARALLEL_MIN_FILES", 1)
+
+    def test_matches_serial(self):
+        """Workers give exactly the symbols and errors of a serial walk."""
+        serial_errors, parallel_errors = [], []
+        serial = extract_packages(FIXTURES, calls=True, metrics=True, include_source=True, errors=serial_errors, jobs=1)
+        parallel = extract_packages(FIXTURES, calls=True, metrics=True, include_source=True, errors=parallel_errors, jobs=4)
+
+        assert list(parallel) == list(serial)
+        for import_path, symbols in serial.items():
+            assert [s.to_dict() for s in parallel[import_path]] == [s.to_dict() for s in symbols]
+        assert parallel_errors == serial_errors
+
+    def test_errors_in_file_order(self, tmp_path):
+        """Errors are reported in file order, whichever worker finishes first."""
+        for name in "abcdef":
+            write(tmp_path, f"{name}.go", f"package a\n\nfunc {name.upper()}( {{\n" if name in "bdf" else "package a\n")
+
+        errors = []
+        extract_packages(tmp_path, errors=errors, jobs=3)
+
+        assert [e.file for e in errors] == [str(tmp_path / f"{name}.go") for name in "bdf"]
+
+    def test_strict(self, tmp_path):
+        """Strict walks raise the error of the first broken file."""
+        write(tmp_path, "a.go", "package a\n")
+        write(tmp_path, "b.go", "package a\n\nfunc B( {\n")
+        write(tmp_path, "c.go", "package a\n\nfunc C( {\n")
+
+        with pytest.raises(GoSyntaxError) as excinfo:
+            extract_packages(tmp_path, strict=True, jobs=2)
+        assert excinfo.value.error.file == str(tmp_path / "b.go")
+
+    def test_cache(self, tmp_path):
+        """Files parsed by workers are cached, and cached files are not parsed again."""
+        write(tmp_path, "src/a.go", "package a\n\nfunc A() {}\n")
+        write(tmp_path, "src/b.go", "package a\n\nfunc B() {}\n")
+        cache = SymbolCache(tmp_path / "cache")
+
+        first = extract_packages(tmp_path / "src", cache=cache, jobs=2)
+        second = extract_packages(tmp_path / "src", cache=cache, jobs=2)
+
+        assert (cache.hits, cache.misses) == (2, 2)
+        assert [s.to_dict() for s in second["."]] == [s.to_dict() for s in first["."]]
+
+    def test_stream(self, tmp_path):
+        """Streamed files come as workers finish, each once, with its errors."""
+        for name in "abcdef":
+            write(tmp_path, f"{name}.go", f"package a\n\nfunc {name.upper()}( {{\n" if name == "c" else f"package a\n\nfunc {name.upper()}() {{}}\n")
+
+        errors = []
+        files = find_go_files(tmp_path)
+        streamed = list(stream_packages(tmp_path, files, errors=errors, jobs=3))
+
+        assert sorted(s.name for _, symbols in streamed for s in symbols) == list("ABCDEF")
+        assert {import_path for import_path, _ in streamed} == {"."}
+        assert [e.file for e in errors] == [str(tmp_path / "c.go")]
+
+
+class TestCrossPackageImplements:
+    """Tests for implements detection across the packages of a tree."""
+
+    def test_qualified_interface(self):
+        """Types implement interfaces of other packages by qualified name."""
+        packages = extract_packages(MapFS({
+            "go.mod": "module example.com/app\n\ngo 1.22\n",
+            "store/store.go": (
+                "package store\n\n"
+                "type Store interface { Get(key string) ([]byte, error) }\n\n"
+                "type Item struct{}\n\n"
+                "type Lister interface { List() []Item }\n\n"
+                "type sealed interface { seal() }\n"
+            ),
+            "mem/mem.go": (
+                "package mem\n\n"
+                "type Cache struct{}\n\n"
+                "func (c *Cache) Get(k string) ([]byte, error) { return nil, nil }\n\n"
+                "type Item struct{}\n\n"
+                "func (c *Cache) List() []Item { return nil }\n\n"
+                "func (c *Cache) seal() {}\n"
+            ),
+        }))
+        cache = next(s for s in packages["example.com/app/mem"] if s.name == "Cache")
+
+        # List returns mem.Item, not store.Item, and seal is unexported
+        assert cache.implements == []
+        assert cache.pointer_implements == ["example.com/app/store.Store"]
+
+    def test_imported_type_names(self):
+        """Types named through an import equal the same types in their own package."""
+        packages = extract_packages(MapFS(SHAPES_FILES))
+        square = next(s for s in packages["example.com/shapes"] if s.name == "Square")
+
+        assert square.pointer_implements == ["example.com/shapes/geo.Shape"]
+
+    def test_embedded_type_of_another_package(self):
+        """Methods promoted from a type of another package count toward implements."""
+        packages = extract_packages(MapFS({
+            **SHAPES_FILES,
+            "tiles/tiles.go": (
+                "package tiles\n\n"
+                'import "example.com/shapes"\n\n'
+                "type Tile struct {\n\t*shapes.Square\n}\n"
+            ),
+        }))
+        tile = next(s for s in packages["example.com/shapes/tiles"] if s.name == "Tile")
+
+        assert tile.implements == ["example.com/shapes/geo.Shape"]
+
+
+SHAPES_FILES = {
+    "go.mod": "module example.com/shapes\n\ngo 1.22\n",
+    "geo/geo.go": (
+        "package geo\n\n"
+        "type Point struct{}\n\n"
+        "type Shape interface {\n\tArea() float64\n\tMove(p Point)\n\tScale(f float64)\n}\n"
+    ),
+    "shapes.go": (
+        "package shapes\n\n"
+        'import "example.com/shapes/geo"\n\n'
+        "type Point struct{}\n\n"
+        "type Rect struct{}\n\n"
+        "func (r Rect) Area() int { return 0 }\n\n"
+        "func (r Rect) Move(p Point) {}\n\n"
+        "type Square struct{}\n\n"
+        "func (s Square) Area() float64 { return 0 }\n\n"
+        "func (s Square) Move(p geo.Point) {}\n\n"
+        "func (s *Square) Scale(f float64) {}\n"
+    ),
+}
+
+
+class TestExamplesAcrossFiles:
+    """Tests for examples documenting declarations of sibling files."""
+
+    def test_external_test_package(self):
+        """Examples in a `_test` package link to the package they test."""
+        packages = extract_packages(MapFS({
+            "go.mod": "module example.com/calc\n\ngo 1.22\n",
+            "calc.go": "package calc\n\ntype Calculator struct{}\n\nfunc (c *Calculator) Add(n int) {}\n",
+            "calc_test.go": (
+                "package calc_test\n\n"
+                "func ExampleCalculator_Add() {}\n\n"
+                "func ExampleCalculator_Sub() {}\n"
+            ),
+        }), include_tests=True)
+        examples = {s.name: s.subject for s in packages["example.com/calc"] if s.kind == "example"}
+
+        # Sub is neither a method of Calculator nor a lowercase suffix
+        assert examples == {"ExampleCalculator_Add": "Calculator.Add", "ExampleCalculator_Sub": ""}
+
+    def test_other_package(self):
+        """A declaration of another package is not a subject."""
+        packages = extract_packages(MapFS({
+            "go.mod": "module example.com/app\n\ngo 1.22\n",
+            "a/a.go": "package a\n\nfunc Run() {}\n",
+            "b/b_test.go": "package b\n\nfunc ExampleRun() {}\n",
+        }), recursive=True, include_tests=True)
+
+        assert packages["example.com/app/b"][0].subject == ""
+
+
+RUNNER_FILES = {
+    "go.mod": "module example.com/m\n\ngo 1.22\n",
+    "a/a.go": "package a\n\ntype Runner interface {\n\tRun(cb func(n int) error) error\n}\n",
+    "b/b.go": "package b\n\ntype Impl struct{}\n\nfunc (Impl) Run(cb func(n int) error) error { return nil }\n",
+}
+
+
+class TestCheckImplements:
+    """Tests for check_implements."""
+
+    @pytest.fixture
+    def symbols(self):
+        """Symbols of a module whose types partly implement geo.Shape."""
+        packages = extract_packages(MapFS(SHAPES_FILES))
+        return [s for symbols in packages.values() for s in symbols]
+
+    def find(self, symbols, qualified_name):
+        """The symbol with a qualified name."""
+        return next(s for s in symbols if s.qualified_name == qualified_name)
+
+    def test_pass(self, symbols):
+        """*Square has every method of Shape."""
+        square = self.find(symbols, "example.com/shapes.Square")
+        shape = self.find(symbols, "example.com/shapes/geo.Shape")
+
+        assert check_implements(square, shape, symbols, pointer=True) == []
+
+    def test_pointer_receiver(self, symbols):
+        """Square lacks the pointer-receiver method Scale."""
+        square = self.find(symbols, "example.com/shapes.Square")
+        shape = self.find(symbols, "example.com/shapes/geo.Shape")
+
+        [mismatch] = check_implements(square, shape, symbols)
+        assert (mismatch.name, mismatch.reason) == ("Scale", "pointer_receiver")
+        assert str(mismatch) == "method Scale has pointer receiver"
+
+    def test_missing_and_wrong_types(self, symbols):
+        """Mismatches name the method and both function types, with packages when only they differ."""
+        rect = self.find(symbols, "example.com/shapes.Rect")
+        shape = self.find(symbols, "example.com/shapes/geo.Shape")
+
+        assert [str(m) for m in check_implements(rect, shape, symbols, pointer=True)] == [
+            "wrong type for method Area: have func() int, want func() float64",
+            "wrong type for method Move: have func(example.com/shapes.Point), want func(example.com/shapes/geo.Point)",
+            "missing method Scale: want func(float64)",
+        ]
+
+    def test_func_parameter_names(self):
+        """Named parameters of func-typed parameters match across packages."""
+        symbols = [s for package in extract_packages(MapFS(RUNNER_FILES), recursive=True).values() for s in package]
+        impl = self.find(symbols, "example.com/m/b.Impl")
+        runner = self.find(symbols, "example.com/m/a.Runner")
+
+        assert check_implements(impl, runner, symbols) == []
+        assert impl.implements == ["example.com/m/a.Runner"]
+
+    def test_unknown_method_set(self):
+        """Interfaces embedding one of another module cannot be checked."""
+        symbols = extract_packages(MapFS({
+            "a.go": "package a\n\nimport \"io\"\n\ntype T struct{}\n\ntype RC interface {\n\tio.Reader\n\tClose() error\n}\n",
+        }))["."]
+        iface = next(s for s in symbols if s.name == "RC")
+
+        with pytest.raises(ValueError, match="not fully known"):
+            check_implements(symbols[0], iface, symbols)
+
+
+class TestPackageSummary:
+    """Tests for package clauses and summaries."""
+
+    def test_sample(self):
+        """The sample fixture summarizes as one undocumented file."""
+        symbols = GoSymbolExtractor().extract_file(FIXTURES / "sample.go")
+        summary = summarize_package("", [FIXTURES / "sample.go"], symbols)
+
+        assert (summary.name, summary.path, summary.doc, summary.files) == ("calculator", "", "", 1)
+        assert summary.kinds == {"func": 3, "method": 4, "struct": 2, "interface": 3}
+
+    def test_grouped_methods_counted(self):
+        """Methods nested under their type still count."""
+        symbols = group_methods(GoSymbolExtractor().extract_file(FIXTURES / "sample.go"))
+
+        assert summarize_package("", [FIXTURES / "sample.go"], symbols).kinds["method"] == 4
+
+    def test_package_doc(self, tmp_path):
+        """The comment group directly above the clause is the doc; directives are dropped."""
+        write(tmp_path, "a.go", (
+            "// Copyright notice, separated by a blank line.\n\n"
+            "//go:build linux\n"
+            "// Package shapes computes areas.\n"
+            "//\n"
+            "// It has no dependencies.\n"
+            "package shapes\n"
+        ))
+        write(tmp_path, "b.go", "/*\nPackage shapes in a block.\n*/\npackage shapes\n")
+        write(tmp_path, "c.go", "// Not a doc comment.\n\npackage shapes\n")
+
+        assert read_package_clause(tmp_path / "a.go") == (
+            "shapes", "Package shapes computes areas.\n\nIt has no dependencies."
+        )
+        assert read_package_clause(tmp_path / "b.go") == ("shapes", "Package shapes in a block.")
+        assert read_package_clause(tmp_path / "c.go") == ("shapes", "")
+        assert read_package_clause(tmp_path / "missing.go") == ("", "")
+
+    def test_doc_go_preferred(self, tmp_path):
+        """doc.go supplies the doc, and external test packages do not name the package."""
+        write(tmp_path, "a.go", "// Package geo from a.go.\npackage geo\n")
+        write(tmp_path, "a_test.go", "package geo_test\n")
+        write(tmp_path, "doc.go", "// Package geo from doc.go.\npackage geo\n")
+        files = sorted(tmp_path.glob("*.go"))
+
+        summary = summarize_package("m/geo", files, [])
+        assert (summary.name, summary.doc, summary.files, summary.kinds) == ("geo", "Package geo from doc.go.", 3, {})
+
+    def test_file_directives(self):
+        """Directives not attached to a declaration belong to the file."""
+        content = (FIXTURES / "directives.go").read_text()
+
+        assert file_directives(content) == [
+            "//go:build linux || darwin",
+            "//go:generate stringer -type=Mode",
+            "//go:generate go run gen.go",
+        ]
+
+    def test_package_directives(self, tmp_path):
+        """A package gathers the file directives of every file, without repeats."""
+        write(tmp_path, "a.go", "//go:build linux\n\npackage geo\n\n//go:generate stringer -type=Kind\n")
+        write(tmp_path, "b.go", "//go:build linux\n\npackage geo\n\n//go:noinline\nfunc f() {}\n")
+
+        summary = summarize_package("m/geo", sorted(tmp_path.glob("*.go")), [])
+        assert summary.directives == ["//go:build linux", "//go:generate stringer -type=Kind"]
+
+    def test_tree(self):
+        """Every package of a walk is summarized, files without symbols included."""
+        module_tree = MapFS({**MODULE_FILES, "geo/doc.go": "// Package geo has points and lines.\npackage geo\n"})
+        files = find_go_files(module_tree)
+        summaries = summarize_packages(module_tree, extract_packages(module_tree, files=files), files)
+
+        assert list(summaries) == ["example.com/shapes", "example.com/shapes/geo", "example.com/shapes/geo/polar"]
+        geo = summaries["example.com/shapes/geo"]
+        assert (geo.name, geo.doc, geo.files, geo.kinds) == ("geo", "Package geo has points and lines.", 3, {"struct": 2})
@@ -0,0 +1,90 @@
+"""
+Unit tests for WebSocket framing.
+
+Tests the handshake key and frames as RFC 6455 gives them.
+"""
+
+import io
+import os
+import struct
+import pytest
+from ctxd.symbols import websocket
+
+
+def client_frame(opcode: int, payload: bytes, final: bool = True, masked: bool = True) -> bytes:
+    """Encode a frame as a client sends it, masked."""
+    mask = os.urandom(4)
+    length = len(payload)
+    if length < 126:
+        header = struct.pack("!BB", (0x80 if final else 0) | opcode, (0x80 if masked else 0) | length)
+    else:
+        header = struct.pack("!BBH", (0x80 if final else 0) | opcode, (0x80 if masked else 0) | 126, length)
+    if not masked:
+        return header + payload
+    return header + mask + bytes(b ^ mask[i % 4] for i, b in enumerate(payload))
+
+
+class TestHandshake:
+    """Tests for the opening handshake."""
+
+    def test_accept_key(self):
+        """The accept key matches the example of RFC 6455."""
+        assert websocket.accept_key("dGhlIHNhbXBsZSBub25jZQ==") == "s3pPLMBiTxaQ9kYGzzhZRbK+xOo="
+
+    @pytest.mark.parametrize("key, valid", [
+        ("dGhlIHNhbXBsZSBub25jZQ==", True),
+        ("", False),
+        ("not base64!", False),
+        ("c2hvcnQ=", False),
+    ])
+    def test_valid_key(self, key, valid):
+        """Keys must be 16 base64-encoded bytes."""
+        assert websocket.is_valid_key(key) is valid
+
+
+class TestFrames:
+    """Tests for encoding and reading frames."""
+
+    @pytest.mark.parametrize("size, header_size", [(5, 2), (200, 4), (70000, 10)])
+    def test_encode_lengths(self, size, header_size):
+        """Payload lengths use the 7-bit, 16-bit, or 64-bit form."""
+        frame = websocket.encode_frame(websocket.OP_TEXT, b"x" * size)
+
+        assert frame[0] == 0x81
+        assert len(frame) == header_size + size
+
+    def test_encode_close(self):
+        """Close frames start with the status code."""
+        frame = websocket.encode_close(websocket.CLOSE_POLICY_VIOLATION, "slow")
+
+        assert frame == b"\x88\x06\x03\xf0slow"
+
+    @pytest.mark.parametrize("payload", [b"", b"ping", b"x" * 300])
+    def test_read_masked(self, payload):
+        """Client frames are unmasked."""
+        stream = io.BytesIO(client_frame(websocket.OP_BINARY, payload))
+
+        assert websocket.read_frame(stream) == (websocket.OP_BINARY, payload)
+
+    def test_unmasked_rejected(self):
+        """Unmasked client frames break the protocol."""
+        with pytest.raises(websocket.ProtocolError, match="masked"):
+            websocket.read_frame(io.BytesIO(client_frame(websocket.OP_TEXT, b"hi", masked=False)))
+
+    def test_fragment_rejected(self):
+        """Fragmented messages are not supported."""
+        with pytest.raises(websocket.ProtocolError, match="Fragmented"):
+            websocket.read_frame(io.BytesIO(client_frame(websocket.OP_TEXT, b"hi", final=False)))
+
+    def test_too_large(self):
+        """Frames over the limit close the connection as too big."""
+        stream = io.BytesIO(struct.pack("!BBQ", 0x82, 0xFF, websocket.MAX_CLIENT_PAYLOAD + 1))
+
+        with pytest.raises(websocket.ProtocolError) as excinfo:
+            websocket.read_frame(stream)
+        assert excinfo.value.close_code == websocket.CLOSE_TOO_BIG
+
+    def test_eof(self):
+        """A connection closing mid-frame ends reading."""
+        with pytest.raises(EOFError):
+            websocket.read_frame(io.BytesIO(b"\x81"))
@@ -0,0 +1,1342 @@
+"""
+Unit tests for Go symbol extraction.
+
+Tests GoSymbolExtractor against inline snippets and the Go fixture files.
+"""
+
+import pytest
+from pathlib import Path
+from ctxd.symbols import GoSymbolExtractor, GoSyntaxError, Symbol, build_call_graph, group_methods, sort_symbols
+from ctxd.symbols.resolve import find_implementations, promoted_methods
+
+FIXTURES = Path(__file__).parent / "fixtures"
+
+
+@pytest.fixture
+def extractor():
+    """Create a Go symbol extractor."""
+    return GoSymbolExtractor()
+
+
+def by_name(symbols: list[Symbol]) -> dict[str, Symbol]:
+    """Index symbols by name (last one wins for duplicate names)."""
+    return {s.name: s for s in symbols}
+
+
+class TestGoSymbolExtractor:
+    """Tests for basic declaration extraction."""
+
+    def test_extract_sample_fixture(self, extractor):
+        """All top-level declarations in sample.go are extracted."""
+        symbols = extractor.extract_file(FIXTURES / "sample.go")
+
+        names = [s.name for s in symbols]
+        assert names == [
+            "Add", "Multiply", "Calculator", "NewCalculator",
+            "Add", "Subtract", "GetValue", "Display",
+            "Adder", "Multiplier", "MathOperator", "Point",
+        ]
+
+    def test_function_signature(self, extractor):
+        """Plain function signatures are rendered without the body."""
+        symbols = extractor.extract_file(FIXTURES / "sample.go")
+        add = symbols[0]
+
+        assert add.kind == "func"
+        assert add.signature == "func Add(a, b int) int"
+        assert add.line == 6
+        assert add.doc == "Add adds two integers and returns the result"
+
+    def test_method_signature(self, extractor):
+        """Method signatures include the receiver."""
+        symbols = extractor.extract_file(FIXTURES / "sample.go")
+        methods = {s.name: s for s in symbols if s.kind == "method"}
+
+        assert methods["Add"].signature == "func (c *Calculator) Add(n int)"
+        assert methods["GetValue"].signature == "func (c Calculator) GetValue() int"
+
+    def test_method_receiver_type(self, extractor):
+        """Methods record their receiver type; functions have none."""
+        symbols = extractor.extract_file(FIXTURES / "sample.go")
+        methods = {s.name: s for s in symbols if s.kind == "method"}
+
+        assert methods["Add"].receiver == "*Calculator"
+        assert methods["GetValue"].receiver == "Calculator"
+        assert symbols[0].receiver == ""
+
+    def test_type_kinds(self, extractor):
+        """Structs and interfaces are distinguished by kind."""
+        symbols = by_name(extractor.extract_file(FIXTURES / "sample.go"))
+
+        assert symbols["Calculator"].kind == "struct"
+        assert symbols["Calculator"].signature == "type Calculator struct"
+        assert symbols["Adder"].kind == "interface"
+        assert symbols["Point"].kind == "struct"
+
+    def test_empty_source(self, extractor):
+        """Empty source yields no symbols."""
+        assert extractor.extract("", "empty.go") == []
+
+    def test_multiline_parameters(self, extractor):
+        """Multi-line parameter lists are rendered on one line."""
+        content = """package main
+
+func complexFunction(
+    a, b int,
+    name string,
+) (int, error) {
+    return a + b, nil
+}"""
+        symbols = extractor.extract(content, "test.go")
+
+        assert symbols[0].signature == "func complexFunction(a, b int, name string) (int, error)"
+
+    def test_grouped_type_declaration(self, extractor):
+        """Specs inside a grouped type declaration get their own docs."""
+        content = """package main
+
+type (
+    // First is the first type.
+    First struct{}
+
+    // Second is the second type.
+    Second int
+)
+"""
+        symbols = by_name(extractor.extract(content, "test.go"))
+
+        assert symbols["First"].doc == "First is the first type."
+        assert symbols["Second"].signature == "type Second int"
+        assert symbols["Second"].line == 8
+
+    def test_doc_comment_requires_adjacency(self, extractor):
+        """A comment separated by a blank line is not a doc comment."""
+        content = """package main
+
+// unrelated comment
+
+func Lonely() {}
+"""
+        symbols = extractor.extract(content, "test.go")
+
+        assert symbols[0].doc == ""
+
+
+class TestParseErrors:
+    """Tests for extraction from files with syntax errors."""
+
+    BROKEN = """package main
+
+func Before() {}
+
+func Broken( {
+}
+
+func After() {}
+"""
+
+    def test_partial_extraction(self, extractor):
+        """Declarations ahead of the error survive, and the error is recorded."""
+        symbols = extractor.extract(self.BROKEN, "broken.go")
+
+        assert symbols[0].name == "Before"
+        assert len(extractor.errors) >= 1
+        error = extractor.errors[0]
+        assert error.file == "broken.go"
+        assert 5 <= error.line <= 9
+        assert error.message
+        assert str(error).startswith(f"broken.go:{error.line}:{error.column}: ")
+
+    def test_clean_file_has_no_errors(self, extractor):
+        """Well-formed files record nothing."""
+        extractor.extract_file(FIXTURES / "sample.go")
+
+        assert extractor.errors == []
+
+    def test_strict(self):
+        """Strict mode raises with the first error instead of extracting."""
+        extractor = GoSymbolExtractor(strict=True)
+
+        with pytest.raises(GoSyntaxError, match="broken.go:"):
+            extractor.extract(self.BROKEN, "broken.go")
+        assert extractor.extract_file(FIXTURES / "sample.go")
+
+
+class TestGoSignatures:
+    """Tests for parameter and result rendering in signatures.go."""
+
+    @pytest.fixture
+    def symbols(self, extractor):
+        """Symbols of the signatures fixture, by local name."""
+        return {s.local_name: s for s in extractor.extract_file(FIXTURES / "signatures.go")}
+
+    def test_variadic_and_named_results(self, symbols):
+        """Variadic ellipses and named result lists are kept."""
+        assert symbols["Printf"].signature == "func Printf(format string, args ...interface{}) (n int, err error)"
+        assert symbols["Join"].signature == "func Join(sep string, parts ...[]string) string"
+        assert symbols["Count"].signature == "func Count(r io.Reader) (total int)"
+
+    def test_grouped_parameters(self, symbols):
+        """Parameters sharing a type stay grouped, blank names included."""
+        assert symbols["Clamp"].signature == "func Clamp(x, lo, hi int) int"
+        assert symbols["Ignore"].signature == "func Ignore(_ int, _, label string)"
+
+    def test_unnamed(self, symbols):
+        """Unnamed parameters and results render as bare types."""
+        assert symbols["Pair"].signature == "func Pair(int, string) (int, error)"
+
+    def test_type_expressions(self, symbols):
+        """Function and channel types are rendered as written."""
+        assert symbols["Apply"].signature == (
+            "func Apply(f func(a, b int) (int, error), g func(...int)) func() error"
+        )
+        assert symbols["Pipe"].signature == "func Pipe(in <-chan int, out chan<- []int)"
+
+    def test_multiline_with_comments(self, symbols):
+        """Line breaks and comments inside parameter lists are dropped."""
+        assert symbols["Open"].signature == (
+            "func Open(ctx context.Context, name string, flag int, opts ...Option) (f *File, err error)"
+        )
+        assert [m.signature for m in symbols["Stream"].methods] == [
+            "Read(p []byte) (n int, err error)",
+            "Write(p []byte) (int, error)",
+        ]
+
+    def test_method(self, symbols):
+        """Methods render their receiver before the name."""
+        assert symbols["File.ReadAt"].signature == "func (f *File) ReadAt(p []byte, off int64) (n int, err error)"
+
+    def test_function_type(self, symbols):
+        """The function type drops names and expands groups."""
+        assert symbols["Printf"].type == "func(string, ...interface{}) (int, error)"
+        assert symbols["Clamp"].type == "func(int, int, int) int"
+        assert symbols["Count"].type == "func(io.Reader) int"
+
+
+class TestGoInlineTypes:
+    """Tests for rendering anonymous struct, interface, and func types in inline.go."""
+
+    @pytest.fixture
+    def symbols(self, extractor):
+        """Symbols of the inline fixture, by name."""
+        return by_name(extractor.extract_file(FIXTURES / "inline.go"))
+
+    @staticmethod
+    def assert_parses(extractor, source: str):
+        """Assert that rendered Go source parses without errors."""
+        tree = extractor.parser.parse(f"package p\n\n{source}\n".encode())
+        assert not tree.root_node.has_error, source
+
+    def test_inline_struct_field(self, extractor, symbols):
+        """Multi-line inline structs join their fields with semicolons, keeping tags and embeds."""
+        fields = {f.name: f for f in symbols["Config"].fields}
+
+        assert fields["Server"].type == 'struct{ Host, Addr string `json:"host"`; Port int; *Base; io.Reader }'
+        assert fields["Limits"].type == "[4]struct{ Min, Max int }"
+        assert fields["Events"].type == "<-chan struct{}"
+        assert fields["Handler"].type == "interface{ Handle(ctx any) (n int, err error); io.Closer }"
+        for field in fields.values():
+            self.assert_parses(extractor, f"type _ {field.type}")
+
+    def test_func_type_parameter(self, extractor, symbols):
+        """Function-typed parameters render their own inline parameter types."""
+        walk = symbols["Walk"]
+
+        assert walk.signature == (
+            "func Walk(root string, visit func(path string, info struct{ Size int64; Dir bool }) error) error"
+        )
+        assert walk.type == "func(string, func(path string, info struct{ Size int64; Dir bool }) error) error"
+        self.assert_parses(extractor, walk.signature + " {}")
+        self.assert_parses(extractor, f"type _ {walk.type}")
+        self.assert_parses(extractor, f"type _ {symbols['Config'].fields[1].type}")
+
+    def test_alias_var_and_type_set(self, extractor, symbols):
+        """Alias targets, variable types, and type set elements are rendered on one line."""
+        assert symbols["Options"].type == "struct{ Verbose bool }"
+        assert symbols["Defaults"].signature == "var Defaults map[string]struct{ Value string; Set bool }"
+        assert symbols["Number"].embeds == ["~int | ~float64"]
+        self.assert_parses(extractor, symbols["Options"].signature)
+        self.assert_parses(extractor, symbols["Defaults"].signature)
+
+
+class TestGoGenerics:
+    """Tests for generic type parameter extraction."""
+
+    def test_multiple_type_params_share_constraint(self, extractor):
+        """Type parameters sharing a constraint stay grouped."""
+        symbols = by_name(extractor.extract_file(FIXTURES / "generics.go"))
+
+        assert symbols["Map"].signature == "func Map[T, U any](s []T, f func(T) U) []U"
+
+    def test_union_constraint_preserved(self, extractor):
+        """Union constraints with approximation elements are kept verbatim."""
+        symbols = by_name(extractor.extract_file(FIXTURES / "generics.go"))
+
+        assert symbols["Sum"].signature == "func Sum[T ~int | ~string](values ...T) T"
+
+    def test_constraint_referencing_other_params(self, extractor):
+        """Constraints that reference later type parameters are rendered in order."""
+        symbols = by_name(extractor.extract_file(FIXTURES / "generics.go"))
+
+        assert symbols["Keys"].signature == "func Keys[M ~map[K]V, K comparable, V any](m M) []K"
+
+    def test_generic_struct(self, extractor):
+        """Generic struct declarations keep their type parameter list."""
+        symbols = by_name(extractor.extract_file(FIXTURES / "generics.go"))
+
+        assert symbols["Stack"].signature == "type Stack[T comparable] struct"
+        assert symbols["Pair"].signature == "type Pair[K comparable, V any] struct"
+
+    def test_generic_defined_type(self, extractor):
+        """Generic non-struct types render their underlying type."""
+        symbols = by_name(extractor.extract_file(FIXTURES / "generics.go"))
+
+        assert symbols["List"].kind == "type"
+        assert symbols["List"].signature == "type List[T any] []T"
+
+    def test_generic_receiver(self, extractor):
+        """Methods on generic types keep the instantiated receiver."""
+        symbols = by_name(extractor.extract_file(FIXTURES / "generics.go"))
+
+        assert symbols["Push"].signature == "func (s *Stack[T]) Push(item T)"
+        assert symbols["Push"].receiver == "*Stack[T]"
+
+    def test_constraint_interface(self, extractor):
+        """Constraint interfaces are extracted as interfaces."""
+        symbols = by_name(extractor.extract_file(FIXTURES / "generics.go"))
+
+        assert symbols["Number"].kind == "interface"
+        assert symbols["Number"].doc == "Number is a constraint satisfied by numeric types."
+
+
+class TestGoStructFields:
+    """Tests for struct field extraction."""
+
+    def test_sample_fields(self, extractor):
+        """Struct fields are captured with names and types."""
+        symbols = by_name(extractor.extract_file(FIXTURES / "sample.go"))
+        point = symbols["Point"]
+
+        assert [(f.name, f.type) for f in point.fields] == [("X", "float64"), ("Y", "float64")]
+        assert all(f.kind == "field" for f in point.fields)
+
+    def test_unexported_fields_flagged(self, extractor):
+        """Unexported fields are kept but flagged."""
+        symbols = by_name(extractor.extract_file(FIXTURES / "sample.go"))
+        fields = {f.name: f for f in symbols["Calculator"].fields}
+
+        assert set(fields) == {"value", "name"}
+        assert not fields["value"].exported
+        assert not fields["name"].exported
+        assert symbols["Point"].fields[0].exported
+
+    def test_struct_tags(self, extractor):
+        """Struct tags are captured without their quotes."""
+        symbols = by_name(extractor.extract_file(FIXTURES / "structs.go"))
+        fields = {f.name: f for f in symbols["Point"].fields}
+
+        assert fields["X"].tag == 'json:"x"'
+        assert fields["Y"].tag == 'json:"y" yaml:"y"'
+        assert fields["X"].signature == 'X float64 `json:"x"`'
+
+    def test_interpreted_string_tag(self, extractor):
+        """Tags written as interpreted strings are unquoted too."""
+        symbols = by_name(extractor.extract_file(FIXTURES / "structs.go"))
+        fields = {f.name: f for f in symbols["Shape"].fields}
+
+        assert fields["label"].tag == "legacy"
+
+    def test_embedded_fields(self, extractor):
+        """Embedded fields have an empty name and the embedded type."""
+        symbols = by_name(extractor.extract_file(FIXTURES / "structs.go"))
+        embedded = [f for f in symbols["Shape"].fields if f.name == ""]
+
+        assert [f.type for f in embedded] == ["Base", "*Point", "io.Reader"]
+        assert all(f.exported for f in embedded)
+
+    def test_grouped_field_names(self, extractor):
+        """Fields sharing a type produce one symbol per name."""
+        symbols = by_name(extractor.extract_file(FIXTURES / "structs.go"))
+        names = [f.name for f in symbols["Shape"].fields if f.name]
+
+        assert names == ["Name", "w", "h", "label"]
+
+    def test_field_doc(self, extractor):
+        """Comments directly above a field become its doc."""
+        symbols = by_name(extractor.extract_file(FIXTURES / "structs.go"))
+        fields = {f.name: f for f in symbols["Shape"].fields}
+
+        assert fields["Name"].doc == "Name is the display name."
+        assert fields["Name"].line == 23
+
+    def test_non_struct_has_no_fields(self, extractor):
+        """Functions and interfaces have no fields."""
+        symbols = by_name(extractor.extract_file(FIXTURES / "sample.go"))
+
+        assert symbols["Multiply"].fields == []
+        assert symbols["Adder"].fields == []
+
+
+class TestGoInterfaceFlattening:
+    """Tests for resolving embedded interfaces into full method sets."""
+
+    def test_declared_methods(self, extractor):
+        """Interface methods are extracted with receiver-less signatures."""
+        symbols = by_name(extractor.extract_file(FIXTURES / "sample.go"))
+        adder = symbols["Adder"]
+
+        assert [m.signature for m in adder.methods] == ["Add(a, b int) int"]
+        assert adder.methods[0].kind == "method"
+        assert adder.methods[0].origin == ""
+
+    def test_embedded_methods_promoted(self, extractor):
+        """Embedded interfaces contribute their methods, tagged by origin."""
+        symbols = by_name(extractor.extract_file(FIXTURES / "sample.go"))
+        operator = symbols["MathOperator"]
+
+        assert operator.embeds == ["Adder", "Multiplier"]
+        assert [(m.signature, m.origin) for m in operator.methods] == [
+            ("Add(a, b int) int", "Adder"),
+            ("Multiply(x, y int) int", "Multiplier"),
+        ]
+
+    def test_forward_and_transitive_embeds(self, extractor):
+        """Embeds resolve regardless of declaration order, through several levels."""
+        symbols = by_name(extractor.extract_file(FIXTURES / "interfaces.go"))
+        methods = {m.name: m for m in symbols["ReadWriteCloser"].methods}
+
+        assert list(methods) == ["Read", "Write", "Close"]
+        assert methods["Read"].origin == "Reader"
+        assert methods["Read"].signature == "Read(p []byte) (n int, err error)"
+        assert methods["Read"].doc == "Read reads into p"
+        assert methods["Close"].origin == "Closer"
+
+    def test_unresolved_embed_kept(self, extractor):
+        """Embeds from other packages stay as written without promoted methods."""
+        symbols = by_name(extractor.extract_file(FIXTURES / "interfaces.go"))
+        flusher = symbols["Flusher"]
+
+        assert flusher.embeds == ["io.Writer"]
+        assert [m.name for m in flusher.methods] == ["Flush", "reset"]
+        assert not flusher.methods[1].exported
+
+    def test_duplicate_paths_deduplicated(self, extractor):
+        """A method reachable through several embeds appears once."""
+        symbols = by_name(extractor.extract_file(FIXTURES / "interfaces.go"))
+
+        names = [m.name for m in symbols["Overlap"].methods]
+        assert sorted(names) == ["Close", "Read", "Write"]
+
+    def test_embedding_cycle(self, extractor):
+        """Mutually embedding interfaces do not recurse forever."""
+        content = """package main
+
+type A interface {
+	B
+	Foo()
+}
+
+type B interface {
+	A
+	Bar()
+}
+"""
+        symbols = by_name(extractor.extract(content, "test.go"))
+
+        assert [m.name for m in symbols["A"].methods] == ["Foo", "Bar"]
+        assert [m.name for m in symbols["B"].methods] == ["Bar", "Foo"]
+
+    def test_type_set_elements(self, extractor):
+        """Constraint type sets are kept as embeds and add no methods."""
+        symbols = by_name(extractor.extract_file(FIXTURES / "generics.go"))
+
+        assert symbols["Number"].embeds == ["~int | ~int64 | ~float64"]
+        assert symbols["Number"].methods == []
+
+
+class TestDocSummary:
+    """Tests for the first-sentence doc summary."""
+
+    def test_name_prefix_stripped(self, extractor):
+        """A leading repeat of the symbol name is dropped from the summary."""
+        symbols = by_name(extractor.extract_file(FIXTURES / "sample.go"))
+
+        assert symbols["Multiply"].summary == "multiplies two integers"
+        assert symbols["Multiply"].doc == "Multiply multiplies two integers"
+
+    def test_first_sentence_only(self, extractor):
+        """Only the text up to the first sentence-ending period is kept."""
+        content = """package main
+
+// Parse reads a config file. It returns an error
+// if the file is missing.
+func Parse() {}
+
+// Version reports v1.2 compatibility.
+//
+// More detail follows here.
+func Version() {}
+"""
+        symbols = by_name(extractor.extract(content, "test.go"))
+
+        assert symbols["Parse"].summary == "reads a config file."
+        assert symbols["Parse"].doc == "Parse reads a config file. It returns an error\nif the file is missing."
+        assert symbols["Version"].summary == "reports v1.2 compatibility."
+
+    def test_multi_line_sentence(self, extractor):
+        """A sentence wrapped over several lines is joined."""
+        content = """package main
+
+// Run starts the server and
+// blocks until it stops
+func Run() {}
+"""
+        symbols = extractor.extract(content, "test.go")
+
+        assert symbols[0].summary == "starts the server and blocks until it stops"
+
+    def test_block_comment(self, extractor):
+        """Block comments are summarized like line comments."""
+        content = """package main
+
+/* Close releases resources.
+   Safe to call twice. */
+func Close() {}
+"""
+        symbols = extractor.extract(content, "test.go")
+
+        assert symbols[0].summary == "releases resources."
+
+    def test_name_without_prefix_kept(self, extractor):
+        """Docs that do not start with the name are kept as written."""
+        content = """package main
+
+// Adds things
+func Add() {}
+
+// Adder interface for addition
+type Addition int
+"""
+        symbols = by_name(extractor.extract(content, "test.go"))
+
+        assert symbols["Add"].summary == "Adds things"
+        assert symbols["Addition"].summary == "Adder interface for addition"
+
+    def test_empty_doc(self, extractor):
+        """Undocumented symbols have an empty summary."""
+        content = "package main\n\nfunc Bare() {}\n"
+
+        assert extractor.extract(content, "test.go")[0].summary == ""
+
+    def test_interface_method_summary(self, extractor):
+        """Interface methods are summarized too."""
+        symbols = by_name(extractor.extract_file(FIXTURES / "interfaces.go"))
+
+        read = symbols["Reader"].methods[0]
+        assert read.summary == "reads into p"
+
+
+class TestSymbolRanges:
+    """Tests for line/column spans."""
+
+    def test_method_span(self, extractor):
+        """A method's range runs from `func` through its closing brace."""
+        symbols = by_name(extractor.extract_file(FIXTURES / "sample.go"))
+        display = symbols["Display"]
+
+        assert (display.line, display.column) == (45, 1)
+        assert (display.end_line, display.end_column) == (47, 2)
+
+    def test_member_spans(self, extractor):
+        """Fields and interface methods carry their own ranges."""
+        symbols = by_name(extractor.extract_file(FIXTURES / "sample.go"))
+        x_field = symbols["Point"].fields[0]
+        add = symbols["Adder"].methods[0]
+
+        assert (x_field.line, x_field.column, x_field.end_line, x_field.end_column) == (67, 2, 67, 11)
+        assert (add.line, add.column, add.end_line, add.end_column) == (51, 2, 51, 19)
+
+    def test_grouped_type_span(self, extractor):
+        """Grouped type specs span only their own spec."""
+        content = """package main
+
+type (
+	ID int
+	Name string
+)
+"""
+        symbols = by_name(extractor.extract(content, "test.go"))
+
+        assert (symbols["Name"].line, symbols["Name"].column) == (5, 2)
+        assert (symbols["Name"].end_line, symbols["Name"].end_column) == (5, 13)
+
+    def test_crlf_line_endings(self, extractor):
+        """CRLF files get the same positions and clean doc comments."""
+        content = "package main\r\n\r\n// Run runs\r\nfunc Run() {\r\n\treturn\r\n}\r\n"
+        symbol = extractor.extract(content, "test.go")[0]
+
+        assert (symbol.line, symbol.column, symbol.end_line, symbol.end_column) == (4, 1, 6, 2)
+        assert symbol.doc == "Run runs"
+
+    def test_multibyte_columns(self, extractor):
+        """Columns count characters, not bytes."""
+        content = 'package main\n\ntype T struct {\n\tA string `json:"é"`; B int\n}\n'
+        fields = by_name(extractor.extract(content, "test.go"))["T"].fields
+
+        assert fields[1].column == 23
+
+
+class TestCallGraph:
+    """Tests for call extraction and the call graph."""
+
+    CONTENT = """package calc
+
+import "fmt"
+
+type Calculator struct{ value int }
+
+func (c *Calculator) Add(n int) { c.value += n }
+
+func (c *Calculator) Double() {
+	c.Add(c.value)
+	c.Add(1)
+	fmt.Println(len("x"))
+}
+
+func Run(items []int) {
+	calc := NewCalculator()
+	calc.Double()
+	defer func() { cleanup() }()
+	_ = Map[int, string](items, nil)
+}
+
+func NewCalculator() *Calculator { return &Calculator{} }
+
+func Map[T, U any](s []T, f func(T) U) []U { return nil }
+
+func cleanup() {}
+"""
+
+    @pytest.fixture
+    def call_extractor(self):
+        """Extractor with call extraction enabled."""
+        return GoSymbolExtractor(calls=True)
+
+    def test_disabled_by_default(self, extractor):
+        """Calls are only extracted on request."""
+        symbols = by_name(extractor.extract(self.CONTENT, "calc.go"))
+
+        assert symbols["Double"].calls == []
+
+    def test_receiver_calls_resolved(self, call_extractor):
+        """Calls through the receiver name the receiver type, deduplicated."""
+        symbols = by_name(call_extractor.extract(self.CONTENT, "calc.go"))
+
+        assert symbols["Double"].calls == ["Calculator.Add", "fmt.Println"]
+
+    def test_function_calls(self, call_extractor):
+        """Local calls, closures, and explicit instantiations are recorded; builtins are not."""
+        symbols = by_name(call_extractor.extract(self.CONTENT, "calc.go"))
+
+        assert symbols["Run"].calls == ["NewCalculator", "calc.Double", "cleanup", "Map"]
+
+    def test_sample_display(self, call_extractor):
+        """Display in the sample fixture calls fmt.Printf."""
+        symbols = [s for s in call_extractor.extract_file(FIXTURES / "sample.go") if s.name == "Display"]
+
+        assert symbols[0].calls == ["fmt.Printf"]
+
+    def test_graph_keys(self, call_extractor):
+        """The graph is keyed by caller name, methods qualified by receiver."""
+        graph = build_call_graph(call_extractor.extract(self.CONTENT, "calc.go"))
+
+        assert list(graph) == ["Calculator.Add", "Calculator.Double", "Run", "NewCalculator", "Map", "cleanup"]
+        assert graph["Calculator.Double"] == ["Calculator.Add", "fmt.Println"]
+
+    def test_graph_package_qualification(self, call_extractor):
+        """With a package, local callees are qualified and foreign ones stay raw."""
+        symbols = call_extractor.extract(self.CONTENT, "calc.go")
+        for symbol in symbols:
+            symbol.package = "example.com/calc"
+
+        graph = build_call_graph(symbols)
+
+        assert graph["example.com/calc.Calculator.Double"] == ["example.com/calc.Calculator.Add", "fmt.Println"]
+        assert graph["example.com/calc.Run"] == [
+            "example.com/calc.NewCalculator", "calc.Double", "example.com/calc.cleanup", "example.com/calc.Map",
+        ]
+
+
+class TestGoTypeAliases:
+    """Tests for distinguishing aliases from defined types."""
+
+    def test_alias_kind_and_target(self, extractor):
+        """Aliases get kind "alias" and record their target."""
+        symbols = by_name(extractor.extract_file(FIXTURES / "aliases.go"))
+        temperature = symbols["Temperature"]
+
+        assert temperature.kind == "alias"
+        assert temperature.type == "Celsius"
+        assert temperature.signature == "type Temperature = Celsius"
+        assert temperature.doc == "Temperature is an alias; it is the same type as Celsius"
+
+    def test_defined_type_distinct(self, extractor):
+        """Defined types keep kind "type" and their own signature."""
+        symbols = by_name(extractor.extract_file(FIXTURES / "aliases.go"))
+
+        assert symbols["Celsius"].kind == "type"
+        assert symbols["Celsius"].signature == "type Celsius float64"
+        assert symbols["Celsius"].type == ""
+        assert symbols["Set"].kind == "type"
+
+    def test_qualified_target(self, extractor):
+        """Aliases of imported types keep the package selector."""
+        symbols = by_name(extractor.extract_file(FIXTURES / "aliases.go"))
+
+        assert symbols["Reader"].type == "io.Reader"
+
+    def test_grouped_forms(self, extractor):
+        """Aliases and defined types can share a grouped declaration."""
+        symbols = by_name(extractor.extract_file(FIXTURES / "aliases.go"))
+
+        assert (symbols["ID"].kind, symbols["Key"].kind) == ("type", "alias")
+        assert symbols["Key"].doc == "Key aliases ID"
+        assert symbols["Key"].line == 18
+
+    def test_embedded_alias_resolved(self, extractor):
+        """Embedding an alias of an interface embeds the target's method set."""
+        content = """package main
+
+type Closer interface {
+	Close() error
+}
+
+type C = Closer
+
+type Resource interface {
+	C
+	Open() error
+}
+
+type Self = Loop
+
+type Loop interface {
+	Self
+}
+"""
+        symbols = by_name(extractor.extract(content, "test.go"))
+
+        assert [(m.name, m.origin) for m in symbols["Resource"].methods] == [("Open", ""), ("Close", "Closer")]
+        assert symbols["Loop"].methods == []
+
+
+class TestGoConstVar:
+    """Tests for package-level const and var extraction."""
+
+    def test_iota_enum(self, extractor):
+        """Implicitly repeated iota specs get their effective values."""
+        symbols = by_name(extractor.extract_file(FIXTURES / "values.go"))
+
+        days = [symbols[n] for n in ("Sunday", "Monday", "Tuesday", "Thursday")]
+        assert [d.value for d in days] == ["0", "1", "2", "4"]
+        assert all(d.kind == "const" and d.type == "Weekday" for d in days)
+        assert symbols["Monday"].signature == "const Monday Weekday = 1"
+        assert "_" not in symbols
+
+    def test_iota_expression(self, extractor):
+        """Integer expressions of iota are evaluated per spec."""
+        symbols = by_name(extractor.extract_file(FIXTURES / "values.go"))
+
+        assert symbols["KB"].value == "1024"
+        assert symbols["MB"].value == "1048576"
+        assert symbols["GB"].value == "1073741824"
+        assert symbols["GB"].type == "ByteSize"
+
+    def test_untyped_literals(self, extractor):
+        """Untyped constants keep their literal as written."""
+        symbols = by_name(extractor.extract_file(FIXTURES / "values.go"))
+
+        assert symbols["Pi"].type == ""
+        assert symbols["Pi"].value == "3.14159"
+        assert symbols["Pi"].signature == "const Pi = 3.14159"
+        assert symbols["Pi"].doc == "Pi is an untyped constant"
+        assert symbols["Greeting"].value == '"hello"'
+        assert symbols["Timeout"].value == "5 * time.Second"
+
+    def test_multiple_names_per_spec(self, extractor):
+        """Each name of a spec is its own symbol with its own value."""
+        symbols = by_name(extractor.extract_file(FIXTURES / "values.go"))
+
+        assert (symbols["big"].value, symbols["small"].value) == ("1 << 62", "-1")
+        assert not symbols["big"].exported
+        assert (symbols["x"].value, symbols["y"].value) == ("1", "2")
+        assert symbols["x"].type == "int"
+
+    def test_grouped_var_types(self, extractor):
+        """Variables get declared types or the default type of their literal."""
+        symbols = by_name(extractor.extract_file(FIXTURES / "values.go"))
+
+        types = {n: symbols[n].type for n in ("Count", "Ratio", "Label", "Enabled", "Default", "Current", "Started")}
+        assert types == {
+            "Count": "int",
+            "Ratio": "float64",
+            "Label": "string",
+            "Enabled": "bool",
+            "Default": "Config",
+            "Current": "*Config",
+            "Started": "time.Time",
+        }
+        assert symbols["Count"].kind == "var"
+        assert symbols["Count"].doc == "Count is inferred as int"
+        assert symbols["Handler"].type == "func(n int) error"
+
+    def test_uninferred_var(self, extractor):
+        """Variables with a non-literal initializer show it in the signature."""
+        symbols = by_name(extractor.extract_file(FIXTURES / "values.go"))
+        err = symbols["ErrMissing"]
+
+        assert err.type == ""
+        assert err.value == 'newError("missing")'
+        assert err.signature == 'var ErrMissing = newError("missing")'
+        assert err.doc == "ErrMissing reports a missing value"
+
+    def test_local_declarations_skipped(self, extractor):
+        """Only package-level declarations are extracted."""
+        content = """package main
+
+func run() {
+    const limit = 3
+    var count int
+}
+"""
+        symbols = extractor.extract(content, "test.go")
+
+        assert [s.name for s in symbols] == ["run"]
+
+
+class TestImplements:
+    """Tests for interface satisfaction detection."""
+
+    def test_sample_mismatched_signature(self, extractor):
+        """Calculator.Add(n int) does not satisfy Adder's Add(a, b int) int."""
+        symbols = by_name(extractor.extract_file(FIXTURES / "sample.go"))
+
+        assert symbols["Calculator"].implements == []
+        assert symbols["Calculator"].pointer_implements == []
+        assert symbols["Point"].implements == []
+
+    def test_function_types(self, extractor):
+        """Functions and methods record their type without parameter names."""
+        symbols = extractor.extract_file(FIXTURES / "sample.go")
+        types = {(s.receiver_type_name, s.name): s.type for s in symbols if s.kind in ("func", "method")}
+
+        assert types[("", "Add")] == "func(int, int) int"
+        assert types[("Calculator", "Add")] == "func(int)"
+        assert types[("", "NewCalculator")] == "func(int) *Calculator"
+        assert by_name(symbols)["Adder"].methods[0].type == "func(int, int) int"
+
+    def test_value_and_pointer_receivers(self, extractor):
+        """Interfaces needing a pointer-receiver method are only met by *T."""
+        symbols = by_name(extractor.extract_file(FIXTURES / "implements.go"))
+
+        assert symbols["Rect"].implements == ["Shape"]
+        assert symbols["Rect"].pointer_implements == ["MutableShape", "Scaler"]
+
+    def test_result_type_mismatch(self, extractor):
+        """A method with the right name but another result type does not match."""
+        symbols = by_name(extractor.extract_file(FIXTURES / "implements.go"))
+
+        assert symbols["Circle"].implements == []
+
+    def test_variadic_matching(self, extractor):
+        """Parameter names are ignored but variadics must line up."""
+        symbols = by_name(extractor.extract_file(FIXTURES / "implements.go"))
+
+        assert symbols["Printer"].implements == ["Formatter"]
+        assert symbols["Joiner"].implements == []
+
+    def test_defined_type(self, extractor):
+        """Non-struct defined types implement interfaces too."""
+        symbols = by_name(extractor.extract_file(FIXTURES / "implements.go"))
+
+        assert symbols["Celsius"].implements == ["Shape"]
+
+    def test_skipped_interfaces(self, extractor):
+        """Empty interfaces and ones with unknown embedded method sets are not reported."""
+        symbols = extractor.extract_file(FIXTURES / "implements.go")
+        reported = {i for s in symbols for i in s.implements + s.pointer_implements}
+
+        assert "Empty" not in reported
+        assert "Streamer" not in reported
+
+    def test_grouped_result_types(self, extractor):
+        """Named and grouped results compare by their types."""
+        content = """package main
+
+type Divider interface {
+    Divide(a, b int) (int, error)
+}
+
+type Calc struct{}
+
+func (Calc) Divide(x int, y int) (q int, err error) { return 0, nil }
+"""
+        symbols = by_name(extractor.extract(content, "test.go"))
+
+        assert symbols["Calc"].implements == ["Divider"]
+
+    def test_alias_receiver(self, extractor):
+        """Methods declared on an alias count for the aliased type."""
+        content = """package main
+
+type Namer interface { Name() string }
+
+type User struct{}
+
+type Account = User
+
+func (a Account) Name() string { return "" }
+"""
+        symbols = by_name(extractor.extract(content, "test.go"))
+
+        assert symbols["User"].implements == ["Namer"]
+
+
+class TestGroupMethods:
+    """Tests for nesting methods under their receiver type."""
+
+    def test_sample_grouping(self, extractor):
+        """Calculator's methods move under it, leaving functions and types."""
+        symbols = group_methods(extractor.extract_file(FIXTURES / "sample.go"))
+        calculator = by_name(symbols)["Calculator"]
+
+        assert [m.name for m in calculator.methods] == ["Add", "Subtract", "GetValue", "Display"]
+        assert all(s.kind != "method" for s in symbols)
+        assert [s.name for s in symbols] == [
+            "Add", "Multiply", "Calculator", "NewCalculator",
+            "Adder", "Multiplier", "MathOperator", "Point",
+        ]
+
+    def test_receiver_kinds(self, extractor):
+        """Methods record whether their receiver is a pointer."""
+        symbols = extractor.extract_file(FIXTURES / "sample.go")
+        methods = {s.name: s for s in symbols if s.kind == "method"}
+
+        assert methods["Add"].pointer_receiver
+        assert not methods["GetValue"].pointer_receiver
+
+    def test_unknown_receiver_stays_top_level(self, extractor):
+        """Methods on types outside the analyzed set are not grouped."""
+        content = "package main\n\nfunc (r *Remote) Close() error { return nil }\n"
+        symbols = group_methods(extractor.extract(content, "test.go"))
+
+        assert [(s.name, s.kind) for s in symbols] == [("Close", "method")]
+
+    def test_alias_receiver(self, extractor):
+        """Methods declared through a local alias attach to the target type."""
+        content = """package main
+
+type Celsius float64
+
+type C = Celsius
+
+func (c C) String() string { return "" }
+"""
+        symbols = by_name(group_methods(extractor.extract(content, "test.go")))
+
+        assert [m.name for m in symbols["Celsius"].methods] == ["String"]
+        assert "String" not in symbols
+
+    def test_generic_receiver(self, extractor):
+        """Receivers with type parameters resolve to the generic type."""
+        symbols = by_name(group_methods(extractor.extract_file(FIXTURES / "generics.go")))
+
+        assert [m.name for m in symbols["Stack"].methods] == ["Push"]
+
+    def test_packages_kept_apart(self):
+        """A method only groups under a type of its own package."""
+        symbols = [
+            Symbol(name="T", kind="struct", file="a/t.go", line=1, signature="type T struct", package="m/a"),
+            Symbol(name="M", kind="method", file="b/t.go", line=1, signature="func (T) M()",
+                   receiver="T", package="m/b"),
+        ]
+
+        assert [s.name for s in group_methods(symbols)] == ["T", "M"]
+
+    def test_input_not_mutated(self, extractor):
+        """Grouping copies the types it changes."""
+        symbols = extractor.extract_file(FIXTURES / "sample.go")
+        group_methods(symbols)
+
+        assert by_name(symbols)["Calculator"].methods == []
+
+    def test_grouped_methods_sorted(self, extractor):
+        """Canonical ordering sorts grouped methods by name."""
+        symbols = sort_symbols(group_methods(extractor.extract_file(FIXTURES / "sample.go")))
+        calculator = by_name(symbols)["Calculator"]
+
+        assert [m.name for m in calculator.methods] == ["Add", "Display", "GetValue", "Subtract"]
+
+
+class TestPromotedMethods:
+    """Tests for methods promoted through embedded struct fields."""
+
+    @pytest.fixture
+    def symbols(self, extractor):
+        """Symbols of sample.go and promoted.go, which embeds its Calculator."""
+        symbols = extractor.extract_file(FIXTURES / "sample.go") + extractor.extract_file(FIXTURES / "promoted.go")
+        find_implementations(symbols)
+        return symbols
+
+    def test_embedded_value(self, symbols):
+        """Embedding Calculator promotes all its methods, from depth 1."""
+        counter = by_name(group_methods(symbols))["Counter"]
+
+        assert [m.name for m in counter.methods] == ["Add", "Subtract", "GetValue", "Display"]
+        assert all(m.origin == "Calculator" and m.promotion_depth == 1 for m in counter.methods)
+        assert [m.receiver for m in counter.methods] == ["*Calculator", "*Calculator", "Calculator", "*Calculator"]
+
+    def test_value_and_pointer_embedding(self, symbols):
+        """Pointer-receiver methods embedded by value only belong to *T; embedded by pointer, to T."""
+        types = by_name(symbols)
+
+        assert types["Counter"].implements == ["Valuer"]
+        assert types["Counter"].pointer_implements == ["Accumulator"]
+        assert types["SharedCounter"].implements == ["Accumulator", "Valuer"]
+        assert types["SharedCounter"].pointer_implements == []
+
+    def test_depth_and_shadowing(self, symbols):
+        """Methods promote through several levels, and a declared method shadows them."""
+        promoted = {m.name: m for m, _ in promoted_methods(symbols)[("", "Labeled")]}
+        labeled = by_name(group_methods(symbols))["Labeled"]
+
+        assert sorted(promoted) == ["Add", "Display", "Subtract"]
+        assert promoted["Add"].promotion_depth == 2
+        assert [(m.name, m.promotion_depth) for m in labeled.methods] == [
+            ("GetValue", 0), ("Add", 2), ("Subtract", 2), ("Display", 2),
+        ]
+        assert by_name(symbols)["Labeled"].implements == ["Valuer"]
+
+    def test_ambiguous_methods(self, symbols):
+        """Methods of the same name at the same depth cancel each other out."""
+        promoted = [m.name for m, _ in promoted_methods(symbols)[("", "Panel")]]
+
+        assert promoted == ["Add", "Subtract", "GetValue"]
+
+    def test_field_shadows_method(self, extractor):
+        """A field of the struct hides a promoted method of the same name."""
+        content = """package main
+
+type Base struct{}
+
+func (Base) Name() string { return "" }
+func (Base) ID() int { return 0 }
+
+type User struct {
+    Base
+    Name string
+}
+"""
+        promoted = promoted_methods(extractor.extract(content, "test.go"))
+
+        assert [m.name for m, _ in promoted[("", "User")]] == ["ID"]
+
+    def test_embedded_interface(self, extractor):
+        """An embedded interface promotes its method set."""
+        content = """package main
+
+type Namer interface { Name() string }
+
+type Named interface {
+    Namer
+}
+
+type Wrapper struct {
+    Named
+}
+"""
+        symbols = extractor.extract(content, "test.go")
+
+        assert by_name(symbols)["Wrapper"].implements == ["Named", "Namer"]
+
+    def test_recursive_embedding(self, extractor):
+        """Types that embed pointers to themselves do not loop."""
+        content = """package main
+
+type Node struct {
+    *Node
+}
+
+func (n *Node) Next() *Node { return n.Node }
+"""
+        symbols = extractor.extract(content, "test.go")
+
+        assert promoted_methods(symbols) == {}
+
+
+class TestSourceText:
+    """Tests for capturing the source of each declaration."""
+
+    @pytest.fixture
+    def source_extractor(self):
+        """Extractor with source capture enabled."""
+        return GoSymbolExtractor(include_source=True)
+
+    def test_disabled_by_default(self, extractor):
+        """Source is only captured on request."""
+        assert all(s.source == "" for s in extractor.extract_file(FIXTURES / "sample.go"))
+
+    def test_function_with_body(self, source_extractor):
+        """A function's source runs from `func` through its closing brace, tabs intact."""
+        symbols = by_name(source_extractor.extract_file(FIXTURES / "sample.go"))
+
+        assert symbols["NewCalculator"].source == (
+            "func NewCalculator(initialValue int) *Calculator {\n"
+            "\treturn &Calculator{\n"
+            "\t\tvalue: initialValue,\n"
+            "\t\tname:  \"default\",\n"
+            "\t}\n"
+            "}"
+        )
+
+    def test_members(self, source_extractor):
+        """Types include their members, and members carry their own source."""
+        content = "package main\n\n// Point is a point.\ntype Point struct {\n    X int `json:\"x\"` // spaces, not tabs\n}\n"
+        point = source_extractor.extract(content, "test.go")[0]
+
+        assert point.source == "type Point struct {\n    X int `json:\"x\"` // spaces, not tabs\n}"
+        assert point.fields[0].source == "X int `json:\"x\"`"
+
+    def test_grouped_spec(self, source_extractor):
+        """A spec of a grouped declaration is its exact text, without the group."""
+        content = "package main\n\nconst (\n\tA = 1\n\tB = 2\n)\n"
+        symbols = by_name(source_extractor.extract(content, "test.go"))
+
+        assert symbols["B"].source == "B = 2"
+
+    def test_no_final_newline(self, source_extractor):
+        """A declaration that ends the file without a newline is captured whole."""
+        content = "package main\n\nfunc Last() int {\n\treturn 1\n}"
+        symbol = source_extractor.extract(content, "test.go")[0]
+
+        assert symbol.source == "func Last() int {\n\treturn 1\n}"
+
+    def test_multibyte(self, source_extractor):
+        """Byte offsets are sliced before decoding, so non-ASCII text is kept."""
+        content = 'package main\n\nvar Greeting = "héllo, 世界"\n'
+        symbol = source_extractor.extract(content, "test.go")[0]
+
+        assert symbol.source == 'var Greeting = "héllo, 世界"'
+
+
+class TestDeprecation:
+    """Tests for `Deprecated:` paragraphs."""
+
+    @pytest.fixture
+    def symbols(self, extractor):
+        """Symbols of the deprecated.go fixture."""
+        return by_name(extractor.extract_file(FIXTURES / "deprecated.go"))
+
+    def test_function(self, symbols):
+        """The note is the rest of the paragraph, joined into one line."""
+        assert symbols["Connect"].deprecated
+        assert symbols["Connect"].deprecation_note == "Use Dial instead, which supports timeouts."
+        assert not symbols["Dial"].deprecated
+        assert symbols["Dial"].deprecation_note == ""
+
+    def test_members(self, symbols):
+        """Struct fields and interface methods are flagged on their own."""
+        fields = by_name(symbols["Options"].fields)
+        send = symbols["Client"].methods[0]
+
+        assert not symbols["Options"].deprecated
+        assert (fields["Host"].deprecated, fields["Host"].deprecation_note) == (True, "Set Addr instead.")
+        assert not fields["Addr"].deprecated
+        assert symbols["Client"].deprecation_note == "Clients are created by Dial."
+        assert (send.deprecated, send.deprecation_note) == (True, "Use SendContext.")
+
+    def test_mention_is_not_a_marker(self, symbols):
+        """Only a line starting with the marker counts."""
+        assert not symbols["DeprecatedMode"].deprecated
+
+
+class TestDirectives:
+    """Tests for `//go:` directive comments."""
+
+    @pytest.fixture
+    def symbols(self, extractor):
+        """Symbols of the directives.go fixture."""
+        return by_name(extractor.extract_file(FIXTURES / "directives.go"))
+
+    def test_attached(self, symbols):
+        """Directives in a declaration's comment block are recorded on it as written."""
+        assert symbols["schema"].directives == ["//go:embed schema.sql"]
+        assert symbols["Hash"].directives == ["//go:noinline"]
+        assert symbols["banner"].directives == ["//go:embed banner.txt"]
+
+    def test_not_in_doc(self, symbols):
+        """Directives are left out of the doc and its summary."""
+        assert symbols["schema"].doc == "schema holds the bundled schema."
+        assert symbols["Hash"].doc == "Hash hashes its input."
+        assert symbols["Hash"].summary == "hashes its input."
+
+    def test_separated_by_blank_line(self, symbols):
+        """A directive above a blank line is not the next declaration's."""
+        assert symbols["Mode"].directives == []
+        assert symbols["Mode"].doc == "Mode selects how assets are served."
+
+    def test_space_after_slashes(self, symbols):
+        """A comment with a space after the slashes is ordinary doc text."""
+        assert symbols["Plain"].directives == []
+        assert symbols["Plain"].doc == "go:generate is only mentioned here, with a space."
+
+
+class TestParserModes:
+    """Tests for skipping comments and function bodies."""
+
+    SOURCE = (
+        'package p\n\nimport (\n\t"fmt"\n\t"io"\n)\n\n'
+        "// Write writes a greeting.\n//\n// Deprecated: use Print.\n//go:noinline\n"
+        'func Write(w io.Writer) {\n\tif w != nil {\n\t\tfmt.Fprintln(w, "hi")\n\t}\n}\n'
+    )
+
+    def test_no_comments(self):
+        """Without comments, docs, summaries, deprecations, and directives are empty."""
+        write = GoSymbolExtractor(comments=False).extract(self.SOURCE, "p.go")[0]
+
+        assert (write.doc, write.summary, write.deprecated, write.directives) == ("", "", False, [])
+        assert write.signature == "func Write(w io.Writer)"
+
+    def test_skip_bodies(self):
+        """Skipping bodies keeps signatures but records no calls, metrics, or body-only imports."""
+        write = GoSymbolExtractor(calls=True, metrics=True, skip_bodies=True).extract(self.SOURCE, "p.go")[0]
+
+        assert write.signature == "func Write(w io.Writer)"
+        assert write.doc.startswith("Write writes a greeting.")
+        assert (write.calls, write.complexity, write.lines) == ([], 0, 0)
+        assert write.imports == ["io"]
+
+    def test_bodies_by_default(self):
+        """With bodies, the same function has calls, metrics, and both imports."""
+        write = GoSymbolExtractor(calls=True, metrics=True).extract(self.SOURCE, "p.go")[0]
+
+        assert write.calls == ["fmt.Fprintln"]
+        assert write.complexity == 2
+        assert write.imports == ["fmt", "io"]
+
+    def test_cache_keys_differ(self, tmp_path):
+        """Entries written in one mode are not served in another."""
+        from ctxd.symbols.cache import SymbolCache
+
+        cache = SymbolCache(tmp_path)
+        keys = {
+            GoSymbolExtractor(cache=cache, comments=comments, skip_bodies=skip).cache_key(self.SOURCE)
+            for comments in (True, False) for skip in (True, False)
+        }
+
+        assert len(keys) == 4
+
+
+class TestTestFunctions:
+    """Tests for the tests, benchmarks, and examples of `_test.go` files."""
+
+    SOURCE = (
+        "package calc\n\n"
+        'import "testing"\n\n'
+        "type Calculator struct{}\n\n"
+        "func (c *Calculator) Add(n int) {}\n\n"
+        "func (c *Calculator) reset() {}\n\n"
+        "func New() *Calculator { return nil }\n\n"
+        "func TestAdd(t *testing.T) {}\n\n"
+        "func Test(t *testing.T) {}\n\n"
+        "func Test_add(t *testing.T) {}\n\n"
+        "func Testify(t *testing.T) {}\n\n"
+        "func TestMain(m *testing.M) {}\n\n"
+        "func TestGeneric[T any](t *testing.T) {}\n\n"
+        "func TestResult(t *testing.T) error { return nil }\n\n"
+        "func BenchmarkAdd(b *testing.B) {}\n\n"
+        "func BenchmarkWrongType(t *testing.T) {}\n\n"
+        "func Example() {}\n\n"
+        "func Example_basic() {}\n\n"
+        "func ExampleCalculator() {}\n\n"
+        "func ExampleCalculator_Add() {}\n\n"
+        "func ExampleCalculator_Add_second() {}\n\n"
+        "func ExampleCalculator_second() {}\n\n"
+        "func ExampleCalculator_reset() {}\n\n"
+        "func ExampleNew() {}\n\n"
+        "func ExampleMissing() {}\n\n"
+        "func ExampleNew_Bad() {}\n\n"
+        "func ExampleWithArgs(n int) {}\n"
+    )
+
+    @pytest.fixture
+    def symbols(self, extractor):
+        """Symbols of SOURCE as a test file."""
+        return by_name(extractor.extract(self.SOURCE, "calc_test.go"))
+
+    def test_kinds(self, symbols):
+        """Functions go test runs get their own kinds, by name and signature."""
+        kinds = {name: s.kind for name, s in symbols.items() if s.kind in ("test", "benchmark")}
+
+        assert kinds == {"TestAdd": "test", "Test": "test", "Test_add": "test", "BenchmarkAdd": "benchmark"}
+        assert symbols["Example"].kind == "example"
+
+    @pytest.mark.parametrize("name", [
+        "Testify", "TestMain", "TestGeneric", "TestResult", "BenchmarkWrongType", "ExampleWithArgs", "New",
+    ])
+    def test_other_functions(self, symbols, name):
+        """A lowercase letter after the prefix, or another signature, makes a plain function."""
+        assert symbols[name].kind == "func"
+
+    def test_only_in_test_files(self, extractor):
+        """The same functions outside a `_test.go` file are plain functions."""
+        kinds = {s.kind for s in extractor.extract(self.SOURCE, "calc.go")}
+
+        assert kinds == {"struct", "method", "func"}
+
+    def test_testing_qualifier(self, extractor):
+        """The testing package may be renamed or dot-imported, but not replaced."""
+        source = (
+            "package calc\n\n"
+            'import (\n\ttt "testing"\n\t. "testing"\n\t"example.com/mytesting"\n)\n\n'
+            "func TestRenamed(t *tt.T) {}\n\n"
+            "func TestDot(t *T) {}\n\n"
+            "func TestOther(t *mytesting.T) {}\n"
+        )
+        symbols = by_name(extractor.extract(source, "calc_test.go"))
+
+        assert (symbols["TestRenamed"].kind, symbols["TestDot"].kind) == ("test", "test")
+        assert symbols["TestOther"].kind == "func"
+
+    @pytest.mark.parametrize("name,subject", [
+        ("ExampleCalculator", "Calculator"),
+        ("ExampleCalculator_Add", "Calculator.Add"),
+        ("ExampleCalculator_Add_second", "Calculator.Add"),
+        ("ExampleCalculator_second", "Calculator"),
+        ("ExampleCalculator_reset", "Calculator"),
+        ("ExampleNew", "New"),
+        ("Example", ""),
+        ("Example_basic", ""),
+        ("ExampleMissing", ""),
+        ("ExampleNew_Bad", ""),
+    ])
+    def test_subjects(self, symbols, name, subject):
+        """Examples are linked to the symbol they document by go/doc's naming rules."""
+        assert symbols[name].subject == subject
+
+    def test_subjects_recomputed(self, extractor):
+        """Linking again after the subject disappears clears it."""
+        from ctxd.symbols.resolve import link_examples
+
+        symbols = extractor.extract(self.SOURCE, "calc_test.go")
+        example = by_name(symbols)["ExampleNew"]
+        link_examples([s for s in symbols if s.name != "New"])
+
+        assert example.subject == ""
+
+    def test_call_graph(self):
+        """Tests are callers in the call graph."""
+        source = "package calc\n\nimport \"testing\"\n\nfunc Add() {}\n\nfunc TestAdd(t *testing.T) { Add() }\n"
+        symbols = GoSymbolExtractor(calls=True).extract(source, "calc_test.go")
+
+        assert build_call_graph(symbols)["TestAdd"] == ["Add"]